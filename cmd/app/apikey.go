@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"github.com/booking_backend/internal/auth"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"os"
+	"strconv"
+	"time"
+)
+
+const apiKeyUsage = "usage: app apikey create NAME [ROLE [TENANT]]"
+
+// runAPIKey creates an API key from the command line, which is the way to
+// get the first key of a tenant as every endpoint requires authentication.
+// The key is given the admin role of the default tenant unless others are passed
+func runAPIKey(useCase auth.AuthUseCase, args []string) int {
+	if len(args) < 2 || len(args) > 4 || args[0] != "create" {
+		fmt.Fprintln(os.Stderr, apiKeyUsage)
+		return 2
+	}
+
+	apiKey := &models.APIKey{Name: args[1], Role: models.RoleAdmin,
+		Tenant: models.DefaultTenantID, Created: time.Now()}
+	if len(args) >= 3 {
+		apiKey.Role = args[2]
+	}
+	if len(args) == 4 {
+		tenant, err := strconv.ParseUint(args[3], 10, 64)
+		if err != nil || tenant == 0 {
+			fmt.Fprintln(os.Stderr, apiKeyUsage)
+			return 2
+		}
+		apiKey.Tenant = tenant
+	}
+	if !rbac.IsRole(apiKey.Role) {
+		fmt.Fprintln(os.Stderr, apiKeyUsage)
+		return 2
+	}
+	key, customErr := useCase.CreateAPIKey(apiKey)
+	if customErr != nil {
+		fmt.Fprintln(os.Stderr, customErr.Message)
+		return 1
+	}
+
+	fmt.Println(key)
+	return 0
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	ServerAddr        string
+	HoldTTL           time.Duration
+	HoldSweepInterval time.Duration
+	// CalendarImportTimeout bounds the download of a calendar feed
+	CalendarImportTimeout time.Duration
+	// Deleted rooms can be restored during RoomRetention, then they are purged
+	RoomRetention     time.Duration
+	RoomPurgeInterval time.Duration
+	// The dispatcher sends at most OutboxBatchSize events every OutboxInterval,
+	// the batch of a sink is claimed for OutboxLease
+	OutboxInterval  time.Duration
+	OutboxBatchSize int
+	OutboxLease     time.Duration
+	// A webhook delivery is attempted WebhookAttempts times, the pause starts
+	// with WebhookBackoff and doubles after every attempt
+	WebhookAttempts int
+	WebhookBackoff  time.Duration
+	WebhookInterval time.Duration
+	WebhookTimeout  time.Duration
+	// The scheduler runs at most JobBatchSize due jobs every JobInterval, a
+	// failed job is run JobAttempts times with the doubling JobBackoff
+	JobInterval  time.Duration
+	JobBatchSize int
+	JobAttempts  int
+	JobBackoff   time.Duration
+	// Guests are reminded ReminderLeadDays before the check-in at ReminderTime,
+	// the front desk gets the arrivals at ArrivalsTime through a webhook.
+	// Both are the local time of the property
+	ReminderLeadDays int
+	ReminderTime     time.Duration
+	ArrivalsTime     time.Duration
+	// The responses to the requests with Idempotency-Key are kept for IdempotencyTTL
+	IdempotencyTTL           time.Duration
+	IdempotencySweepInterval time.Duration
+	// Emails go through the SMTP server when SMTPHost is set, otherwise they
+	// are logged and saved to MailDir if it is set. Sending an email through
+	// the server takes at most SMTPTimeout
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTimeout  time.Duration
+	MailFrom     string
+	MailDir      string
+	// Every room and booking query is cancelled after QueryTimeout
+	QueryTimeout time.Duration
+	// A transaction failed on a serialization failure or a deadlock is run
+	// TxAttempts times, the pause starts with TxBackoff and doubles after every attempt
+	TxAttempts int
+	TxBackoff  time.Duration
+	// On SIGTERM the instance reports not ready and keeps serving for
+	// ShutdownDelay, then the requests in flight and the runs of the
+	// background workers are given ShutdownTimeout to finish
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+	// JWTSecret verifies bearer tokens, without it only API keys are accepted
+	JWTSecret string
+}
+
+// LoadConfig reads the settings from the environment, the unset ones get the
+// defaults. A setting that can't be parsed or is out of range is an error
+// rather than silently replaced with the default
+func LoadConfig() (*Config, error) {
+	env := &envReader{}
+	config := &Config{
+		ServerAddr:        env.str("SERVER_ADDR", ":9000"),
+		HoldTTL:           env.duration("HOLD_TTL", 15*time.Minute),
+		HoldSweepInterval: env.duration("HOLD_SWEEP_INTERVAL", time.Minute),
+
+		CalendarImportTimeout:    env.duration("CALENDAR_IMPORT_TIMEOUT", 30*time.Second),
+		RoomRetention:            env.duration("ROOM_RETENTION", 30*24*time.Hour),
+		RoomPurgeInterval:        env.duration("ROOM_PURGE_INTERVAL", time.Hour),
+		OutboxInterval:           env.duration("OUTBOX_INTERVAL", time.Second),
+		OutboxBatchSize:          env.integer("OUTBOX_BATCH_SIZE", 100),
+		OutboxLease:              env.duration("OUTBOX_LEASE", 5*time.Minute),
+		WebhookAttempts:          env.integer("WEBHOOK_ATTEMPTS", 8),
+		WebhookBackoff:           env.delay("WEBHOOK_BACKOFF", 30*time.Second),
+		WebhookInterval:          env.duration("WEBHOOK_INTERVAL", 5*time.Second),
+		WebhookTimeout:           env.duration("WEBHOOK_TIMEOUT", 10*time.Second),
+		JobInterval:              env.duration("JOB_INTERVAL", time.Minute),
+		JobBatchSize:             env.integer("JOB_BATCH_SIZE", 100),
+		JobAttempts:              env.integer("JOB_ATTEMPTS", 5),
+		JobBackoff:               env.delay("JOB_BACKOFF", time.Minute),
+		ReminderLeadDays:         env.integer("REMINDER_LEAD_DAYS", 2),
+		ReminderTime:             env.timeOfDay("REMINDER_TIME", 10*time.Hour),
+		ArrivalsTime:             env.timeOfDay("ARRIVALS_TIME", 7*time.Hour),
+		IdempotencyTTL:           env.duration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencySweepInterval: env.duration("IDEMPOTENCY_SWEEP_INTERVAL", time.Hour),
+		QueryTimeout:             env.duration("QUERY_TIMEOUT", 5*time.Second),
+		TxAttempts:               env.integer("TX_ATTEMPTS", 5),
+		TxBackoff:                env.delay("TX_BACKOFF", 20*time.Millisecond),
+		ShutdownDelay:            env.delay("SHUTDOWN_DELAY", 5*time.Second),
+		ShutdownTimeout:          env.duration("SHUTDOWN_TIMEOUT", 15*time.Second),
+		SMTPHost:                 env.str("SMTP_HOST", ""),
+		SMTPPort:                 env.integer("SMTP_PORT", 587),
+		SMTPUsername:             env.str("SMTP_USERNAME", ""),
+		SMTPPassword:             env.str("SMTP_PASSWORD", ""),
+		SMTPTimeout:              env.duration("SMTP_TIMEOUT", 30*time.Second),
+		MailFrom:                 env.str("MAIL_FROM", "booking@localhost"),
+		MailDir:                  env.str("MAIL_DIR", ""),
+		JWTSecret:                env.str("JWT_SECRET", ""),
+	}
+	if len(env.errs) != 0 {
+		return nil, fmt.Errorf("invalid config: %s", strings.Join(env.errs, "; "))
+	}
+	return config, nil
+}
+
+// envReader collects the invalid settings, so all of them are reported at once
+type envReader struct {
+	errs []string
+}
+
+func (env *envReader) fail(key string, value string, reason string) {
+	env.errs = append(env.errs, fmt.Sprintf("%s=%q %s", key, value, reason))
+}
+
+func (env *envReader) str(key string, defaultValue string) string {
+	if value, has := os.LookupEnv(key); has {
+		return value
+	}
+	return defaultValue
+}
+
+func (env *envReader) integer(key string, defaultValue int) int {
+	value, has := os.LookupEnv(key)
+	if !has {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		env.fail(key, value, "is not an integer")
+		return defaultValue
+	}
+	return number
+}
+
+// parseDuration returns false when the value is set but can't be parsed
+func (env *envReader) parseDuration(key string, defaultValue time.Duration) (string, time.Duration, bool) {
+	value, has := os.LookupEnv(key)
+	if !has {
+		return "", defaultValue, false
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		env.fail(key, value, "is not a duration, e.g. 30s or 5m")
+		return value, defaultValue, false
+	}
+	return value, duration, true
+}
+
+// duration reads an interval, a timeout or a TTL, none of them can be zero
+func (env *envReader) duration(key string, defaultValue time.Duration) time.Duration {
+	value, duration, ok := env.parseDuration(key, defaultValue)
+	if ok && duration <= 0 {
+		env.fail(key, value, "must be positive")
+		return defaultValue
+	}
+	return duration
+}
+
+// delay reads a pause that may be skipped with zero
+func (env *envReader) delay(key string, defaultValue time.Duration) time.Duration {
+	value, duration, ok := env.parseDuration(key, defaultValue)
+	if ok && duration < 0 {
+		env.fail(key, value, "must not be negative")
+		return defaultValue
+	}
+	return duration
+}
+
+// timeOfDay reads the time since midnight, e.g. 10h30m
+func (env *envReader) timeOfDay(key string, defaultValue time.Duration) time.Duration {
+	value, duration, ok := env.parseDuration(key, defaultValue)
+	if ok && (duration < 0 || duration >= 24*time.Hour) {
+		env.fail(key, value, "must be within a day")
+		return defaultValue
+	}
+	return duration
+}
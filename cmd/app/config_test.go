@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func setEnv(t *testing.T, values map[string]string) {
+	for key, value := range values {
+		assert.NoError(t, os.Setenv(key, value))
+	}
+	t.Cleanup(func() {
+		for key := range values {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	setEnv(t, map[string]string{"OUTBOX_INTERVAL": "2s", "SHUTDOWN_DELAY": "0s", "REMINDER_TIME": "9h30m"})
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, config.OutboxInterval)
+	assert.Equal(t, time.Duration(0), config.ShutdownDelay)
+	assert.Equal(t, 9*time.Hour+30*time.Minute, config.ReminderTime)
+	assert.Equal(t, time.Hour, config.RoomPurgeInterval)
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	setEnv(t, map[string]string{
+		"OUTBOX_INTERVAL":     "0s",
+		"QUERY_TIMEOUT":       "5",
+		"SHUTDOWN_DELAY":      "-1s",
+		"ARRIVALS_TIME":       "25h",
+		"JOB_BATCH_SIZE":      "many",
+		"HOLD_TTL":            "-15m",
+		"ROOM_PURGE_INTERVAL": "1h",
+	})
+
+	_, err := LoadConfig()
+	if assert.Error(t, err) {
+		for _, key := range []string{"OUTBOX_INTERVAL", "QUERY_TIMEOUT", "SHUTDOWN_DELAY",
+			"ARRIVALS_TIME", "JOB_BATCH_SIZE", "HOLD_TTL"} {
+			assert.Contains(t, err.Error(), key+"=")
+		}
+		assert.NotContains(t, err.Error(), "ROOM_PURGE_INTERVAL")
+	}
+}
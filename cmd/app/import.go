@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/booking_backend/internal/bulk"
+	"github.com/booking_backend/internal/models"
+	"os"
+)
+
+const importUsage = "usage: app import [-atomic] [-tenant ID] rooms|bookings FILE"
+
+// runImport imports a CSV file from the command line, prints the report
+// and returns the exit code, which is not zero if any row failed. The rows
+// are recorded in the audit log as created by the system
+func runImport(useCase bulk.BulkUseCase, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	atomic := flags.Bool("atomic", false, "reject the whole file on any error")
+	tenant := flags.Uint64("tenant", models.DefaultTenantID, "tenant the rows are imported for")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, importUsage)
+		return 2
+	}
+
+	importFile := useCase.ImportRooms
+	switch flags.Arg(0) {
+	case "rooms":
+	case "bookings":
+		importFile = useCase.ImportBookings
+	default:
+		fmt.Fprintln(os.Stderr, importUsage)
+		return 2
+	}
+
+	file, err := os.Open(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer file.Close()
+
+	report, customErr := importFile(context.Background(), *tenant, models.SystemActor, file, *atomic)
+	if customErr != nil {
+		fmt.Fprintln(os.Stderr, customErr.Message)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(report.Errors) != 0 {
+		return 1
+	}
+	return 0
+}
@@ -3,19 +3,80 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	auditDelivery "github.com/booking_backend/internal/audit/delivery"
+	auditRepository "github.com/booking_backend/internal/audit/repository"
+	auditUseCase "github.com/booking_backend/internal/audit/usecases"
+	authDelivery "github.com/booking_backend/internal/auth/delivery"
+	authRepository "github.com/booking_backend/internal/auth/repository"
+	authUseCase "github.com/booking_backend/internal/auth/usecases"
 	bookingDelivery "github.com/booking_backend/internal/booking/delivery"
 	bookingRepository "github.com/booking_backend/internal/booking/repository"
+	"github.com/booking_backend/internal/booking/sweeper"
 	bookingUseCase "github.com/booking_backend/internal/booking/usecases"
+	bulkDelivery "github.com/booking_backend/internal/bulk/delivery"
+	bulkRepository "github.com/booking_backend/internal/bulk/repository"
+	bulkUseCase "github.com/booking_backend/internal/bulk/usecases"
+	calendarDelivery "github.com/booking_backend/internal/calendar/delivery"
+	calendarRepository "github.com/booking_backend/internal/calendar/repository"
+	calendarUseCase "github.com/booking_backend/internal/calendar/usecases"
+	healthDelivery "github.com/booking_backend/internal/health/delivery"
+	healthRepository "github.com/booking_backend/internal/health/repository"
+	healthUseCase "github.com/booking_backend/internal/health/usecases"
+	"github.com/booking_backend/internal/helpers/safehttp"
+	"github.com/booking_backend/internal/helpers/transaction"
+	idempotencyDelivery "github.com/booking_backend/internal/idempotency/delivery"
+	idempotencyRepository "github.com/booking_backend/internal/idempotency/repository"
+	idempotencySweeper "github.com/booking_backend/internal/idempotency/sweeper"
+	idempotencyUseCase "github.com/booking_backend/internal/idempotency/usecases"
+	invoiceDelivery "github.com/booking_backend/internal/invoice/delivery"
+	"github.com/booking_backend/internal/invoice/issuer"
+	invoiceRepository "github.com/booking_backend/internal/invoice/repository"
+	invoiceUseCase "github.com/booking_backend/internal/invoice/usecases"
+	"github.com/booking_backend/internal/job"
+	jobRepository "github.com/booking_backend/internal/job/repository"
+	"github.com/booking_backend/internal/job/scheduler"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/notification"
+	"github.com/booking_backend/internal/notification/mailer"
+	notificationSink "github.com/booking_backend/internal/notification/sink"
+	"github.com/booking_backend/internal/outbox"
+	"github.com/booking_backend/internal/outbox/dispatcher"
+	outboxRepository "github.com/booking_backend/internal/outbox/repository"
+	"github.com/booking_backend/internal/outbox/sinks"
+	paymentDelivery "github.com/booking_backend/internal/payment/delivery"
+	"github.com/booking_backend/internal/payment/gateway"
+	paymentRepository "github.com/booking_backend/internal/payment/repository"
+	paymentUseCase "github.com/booking_backend/internal/payment/usecases"
+	promoDelivery "github.com/booking_backend/internal/promo/delivery"
+	promoRepository "github.com/booking_backend/internal/promo/repository"
+	promoUseCase "github.com/booking_backend/internal/promo/usecases"
+	propertyDelivery "github.com/booking_backend/internal/property/delivery"
+	propertyRepository "github.com/booking_backend/internal/property/repository"
+	propertyUseCase "github.com/booking_backend/internal/property/usecases"
+	reservationDelivery "github.com/booking_backend/internal/reservation/delivery"
+	reservationRepository "github.com/booking_backend/internal/reservation/repository"
+	reservationUseCase "github.com/booking_backend/internal/reservation/usecases"
 	roomDelivery "github.com/booking_backend/internal/room/delivery"
+	"github.com/booking_backend/internal/room/purger"
 	roomRepository "github.com/booking_backend/internal/room/repository"
 	roomUseCase "github.com/booking_backend/internal/room/usecases"
+	"github.com/booking_backend/internal/webhook"
+	webhookDelivery "github.com/booking_backend/internal/webhook/delivery"
+	webhookRepository "github.com/booking_backend/internal/webhook/repository"
+	webhookSender "github.com/booking_backend/internal/webhook/sender"
+	webhookSink "github.com/booking_backend/internal/webhook/sink"
+	webhookUseCase "github.com/booking_backend/internal/webhook/usecases"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
-const serverAddr = ":9000"
-
 func GetDbConnString() string {
 	return fmt.Sprintf("postgres://%v:%v@%v/%v?sslmode=disable",
 		"postgres",
@@ -24,7 +85,21 @@ func GetDbConnString() string {
 		"booking")
 }
 
+// NewMailer falls back to the file mailer when no SMTP server is configured
+func NewMailer(config *Config) notification.Mailer {
+	if config.SMTPHost == "" {
+		return mailer.NewFileMailer(config.MailDir, config.MailFrom)
+	}
+	return mailer.NewSMTPMailer(config.SMTPHost, config.SMTPPort,
+		config.SMTPUsername, config.SMTPPassword, config.MailFrom, config.SMTPTimeout)
+}
+
 func main() {
+	config, err := LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Database
 	dbConnection, err := sql.Open("postgres", GetDbConnString())
 	if err != nil {
@@ -36,18 +111,140 @@ func main() {
 		log.Fatal(err)
 	}
 
-	roomRepo := roomRepository.NewRoomRepository(dbConnection)
-	roomUseCase := roomUseCase.NewRoomUseCase(roomRepo)
-	roomHandler := roomDelivery.NewRoomHandler(roomUseCase)
+	healthUseCase := healthUseCase.NewHealthUseCase(
+		healthRepository.NewHealthRepository(dbConnection, config.QueryTimeout))
+	healthHandler := healthDelivery.NewHealthHandler(healthUseCase)
+
+	authRepo := authRepository.NewAuthRepository(dbConnection)
+	authUseCase := authUseCase.NewAuthUseCase(authRepo, []byte(config.JWTSecret))
+	authHandler := authDelivery.NewAuthHandler(authUseCase)
+
+	auditRepo := auditRepository.NewAuditRepository(dbConnection)
+	auditUseCase := auditUseCase.NewAuditUseCase(auditRepo)
+	auditHandler := auditDelivery.NewAuditHandler(auditUseCase)
+
+	propertyRepo := propertyRepository.NewPropertyRepository(dbConnection)
+	propertyUseCase := propertyUseCase.NewPropertyUseCase(propertyRepo)
+	propertyHandler := propertyDelivery.NewPropertyHandler(propertyUseCase)
+
+	idempotencyUseCase := idempotencyUseCase.NewIdempotencyUseCase(
+		idempotencyRepository.NewIdempotencyRepository(dbConnection), config.IdempotencyTTL)
+	idempotent := idempotencyDelivery.NewIdempotencyHandler(idempotencyUseCase).Idempotent()
+	idempotencySweeper := idempotencySweeper.NewIdempotencySweeper(idempotencyUseCase,
+		config.IdempotencySweepInterval)
+
+	transactions := transaction.NewManager(dbConnection, config.QueryTimeout,
+		transaction.RetryPolicy{Attempts: config.TxAttempts, Backoff: config.TxBackoff})
+
+	roomRepo := roomRepository.NewRoomRepository(dbConnection, config.QueryTimeout)
+	roomUseCase := roomUseCase.NewRoomUseCase(roomRepo, propertyRepo)
+	roomHandler := roomDelivery.NewRoomHandler(roomUseCase, idempotent)
+	roomPurger := purger.NewRoomPurger(roomUseCase, config.RoomPurgeInterval, config.RoomRetention)
 
-	bookingRepo := bookingRepository.NewBookingRepository(dbConnection)
-	bookingUseCase := bookingUseCase.NewBookingUseCase(bookingRepo, roomRepo)
-	bookingHandler := bookingDelivery.NewBookingHandler(bookingUseCase)
+	promoRepo := promoRepository.NewPromoRepository(dbConnection)
+	promoUseCase := promoUseCase.NewPromoUseCase(promoRepo)
+	promoHandler := promoDelivery.NewPromoHandler(promoUseCase)
+
+	paymentRepo := paymentRepository.NewPaymentRepository(dbConnection)
+	paymentUseCase := paymentUseCase.NewPaymentUseCase(paymentRepo, gateway.NewFakeGateway())
+
+	bookingRepo := bookingRepository.NewBookingRepository(dbConnection, config.QueryTimeout)
+	bookingUseCase := bookingUseCase.NewBookingUseCase(transactions, bookingRepo, roomRepo,
+		propertyUseCase, promoUseCase, paymentUseCase, config.HoldTTL)
+	bookingHandler := bookingDelivery.NewBookingHandler(bookingUseCase, idempotent)
+	paymentHandler := paymentDelivery.NewPaymentHandler(paymentUseCase, bookingUseCase)
+	holdSweeper := sweeper.NewHoldSweeper(bookingUseCase, config.HoldSweepInterval)
+
+	reservationRepo := reservationRepository.NewReservationRepository(dbConnection, config.QueryTimeout)
+	reservationUseCase := reservationUseCase.NewReservationUseCase(transactions, reservationRepo,
+		roomRepo, propertyUseCase, bookingUseCase, config.HoldTTL)
+	reservationHandler := reservationDelivery.NewReservationHandler(reservationUseCase)
+
+	invoiceRepo := invoiceRepository.NewInvoiceRepository(dbConnection)
+	invoiceUseCase := invoiceUseCase.NewInvoiceUseCase(invoiceRepo, bookingRepo, paymentUseCase)
+	invoiceHandler := invoiceDelivery.NewInvoiceHandler(invoiceUseCase)
+
+	calendarRepo := calendarRepository.NewCalendarRepository(dbConnection)
+	calendarUseCase := calendarUseCase.NewCalendarUseCase(calendarRepo, bookingRepo, roomRepo,
+		safehttp.NewClient(config.CalendarImportTimeout, true))
+	calendarHandler := calendarDelivery.NewCalendarHandler(calendarUseCase)
+
+	bulkRepo := bulkRepository.NewBulkRepository(dbConnection)
+	bulkUseCase := bulkUseCase.NewBulkUseCase(bulkRepo, roomRepo, propertyRepo, propertyUseCase)
+	bulkHandler := bulkDelivery.NewBulkHandler(bulkUseCase)
+
+	webhookUseCase := webhookUseCase.NewWebhookUseCase(
+		webhookRepository.NewWebhookRepository(dbConnection),
+		safehttp.NewClient(config.WebhookTimeout, false),
+		webhook.RetryPolicy{Attempts: config.WebhookAttempts, Backoff: config.WebhookBackoff})
+	webhookHandler := webhookDelivery.NewWebhookHandler(webhookUseCase)
+	sender := webhookSender.NewWebhookSender(webhookUseCase, config.WebhookInterval)
+
+	jobScheduler := scheduler.NewScheduler(jobRepository.NewJobRepository(dbConnection),
+		job.Plan{LeadDays: config.ReminderLeadDays, ReminderTime: config.ReminderTime,
+			ArrivalsTime: config.ArrivalsTime},
+		job.RetryPolicy{Attempts: config.JobAttempts, Backoff: config.JobBackoff},
+		map[string]job.Runner{
+			models.JobRefund:  sweeper.NewRefundRunner(bookingUseCase),
+			models.JobInvoice: issuer.NewInvoiceRunner(invoiceUseCase),
+		},
+		config.JobInterval, config.JobBatchSize)
+
+	outboxDispatcher := dispatcher.NewDispatcher(outboxRepository.NewOutboxRepository(dbConnection),
+		config.OutboxInterval, config.OutboxBatchSize, config.OutboxLease,
+		map[string]outbox.Sink{
+			"log":           sinks.NewLogSink(),
+			"webhooks":      webhookSink.NewWebhookSink(webhookUseCase),
+			"notifications": notificationSink.NewNotificationSink(NewMailer(config)),
+		})
+
+	if len(os.Args) > 1 {
+		code := 2
+		switch os.Args[1] {
+		case "import":
+			code = runImport(bulkUseCase, os.Args[2:])
+		case "apikey":
+			code = runAPIKey(authUseCase, os.Args[2:])
+		case "migrate":
+			code = runMigrate(dbConnection, os.Args[2:])
+		default:
+			fmt.Fprintln(os.Stderr, "usage: app [import|apikey|migrate] ...")
+		}
+		dbConnection.Close()
+		os.Exit(code)
+	}
 
 	e := echo.New()
+	// X-Request-ID is set before authentication, so rejected requests get one too
+	e.Use(middleware.RequestID())
 
+	// The probes of the container orchestrator come without credentials
+	authHandler.Public(healthDelivery.LivenessPath, healthDelivery.ReadinessPath)
+	authHandler.Configure(e)
+	healthHandler.Configure(e)
 	roomHandler.Configure(e)
 	bookingHandler.Configure(e)
+	reservationHandler.Configure(e)
+	paymentHandler.Configure(e)
+	invoiceHandler.Configure(e)
+	propertyHandler.Configure(e)
+	promoHandler.Configure(e)
+	calendarHandler.Configure(e)
+	bulkHandler.Configure(e)
+	auditHandler.Configure(e)
+	webhookHandler.Configure(e)
+
+	stopWorkers, workers := startWorkers(holdSweeper.Run, roomPurger.Run, outboxDispatcher.Run,
+		jobScheduler.Run, idempotencySweeper.Run, sender.Run)
+
+	go func() {
+		if err := e.Start(config.ServerAddr); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
 
-	log.Fatal(e.Start(serverAddr))
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	logrus.Info("shutting down on ", <-signals)
+	shutdown(e, healthUseCase, stopWorkers, workers, config.ShutdownDelay, config.ShutdownTimeout)
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/booking_backend/internal/helpers/migration"
+	"os"
+)
+
+const (
+	migrateUsage        = "usage: app migrate [DIR]"
+	defaultMigrationDir = "scripts/migrations"
+)
+
+// runMigrate brings the schema of the database to the last migration, the
+// instance is reported ready by /readyz only once it is there. The instances
+// started at once may all run it, every migration is applied only once
+func runMigrate(db *sql.DB, args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	dir := defaultMigrationDir
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	applied, err := migration.Apply(context.Background(), db, dir)
+	for _, m := range applied {
+		fmt.Println("applied", m.Path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
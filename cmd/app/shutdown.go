@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"github.com/booking_backend/internal/health"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+// Worker is a background process running until its context is cancelled
+type Worker func(ctx context.Context)
+
+// startWorkers runs the workers until stop is called, the returned group
+// is done once all of them have returned
+func startWorkers(workers ...Worker) (stop context.CancelFunc, group *sync.WaitGroup) {
+	ctx, stop := context.WithCancel(context.Background())
+	group = &sync.WaitGroup{}
+	for _, worker := range workers {
+		group.Add(1)
+		go func(worker Worker) {
+			defer group.Done()
+			worker(ctx)
+		}(worker)
+	}
+	return stop, group
+}
+
+// shutdown makes the instance not ready and keeps serving for the delay, so
+// that the load balancer notices it and stops sending requests. Then it stops
+// taking new requests and waits for the requests in flight and for the runs
+// of the workers to finish. Whatever is still running after the timeout is
+// abandoned, the timeout starts after the delay
+func shutdown(e *echo.Echo, healthUseCase health.HealthUseCase, stopWorkers context.CancelFunc,
+	workers *sync.WaitGroup, delay time.Duration, timeout time.Duration) {
+	healthUseCase.Drain()
+	if delay > 0 {
+		logrus.Infof("not ready, serving for %s before shutdown", delay)
+		time.Sleep(delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := e.Shutdown(ctx); err != nil {
+		logrus.Error("requests are not finished: ", err)
+	}
+
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		logrus.Info("shutdown is complete")
+	case <-ctx.Done():
+		logrus.Error("workers are not finished: ", ctx.Err())
+	}
+}
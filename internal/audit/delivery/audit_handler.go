@@ -0,0 +1,81 @@
+package delivery
+
+import (
+	"github.com/booking_backend/internal/audit"
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	auditUseCase audit.AuditUseCase
+}
+
+func NewAuditHandler(useCase audit.AuditUseCase) *AuditHandler {
+	return &AuditHandler{auditUseCase: useCase}
+}
+
+func (ah *AuditHandler) Configure(e *echo.Echo) {
+	e.GET("audit", ah.GetEntries(), principal.Require(rbac.ViewAudit))
+}
+
+// parseTime returns nil for an empty parameter
+func parseTime(param string) (*time.Time, error) {
+	if param == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (ah *AuditHandler) readFilter(context echo.Context) (*models.AuditFilter, error) {
+	filter := &models.AuditFilter{
+		Tenant: principal.Tenant(context),
+		Entity: context.QueryParam("entity"),
+	}
+
+	var err error
+	if entityID := context.QueryParam("entity_id"); entityID != "" {
+		if filter.EntityID, err = strconv.ParseUint(entityID, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if filter.From, err = parseTime(context.QueryParam("from")); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTime(context.QueryParam("to")); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// GetEntries filters the audit log by entity and by time range [from, to)
+func (ah *AuditHandler) GetEntries() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		filter, parseErr := ah.readFilter(context)
+		if parseErr != nil {
+			customErr := errors.New(CodeBadRequest, parseErr)
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		entries, customErr := ah.auditUseCase.GetEntries(filter)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, entries)
+	}
+}
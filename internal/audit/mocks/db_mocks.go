@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"database/sql/driver"
+	"github.com/booking_backend/internal/models"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var entryColumns = []string{"id", "actor", "action", "entity", "entity_id",
+	"before", "after", "request_id", "created", "tenant"}
+
+func MockInsertEntry(mock sqlmock.Sqlmock, entry *models.AuditEntry) {
+	mock.ExpectQuery(`INSERT INTO audit_log`).
+		WithArgs(entry.Tenant, entry.Actor, entry.Action, entry.Entity, entry.EntityID,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), entry.RequestID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(entry.ID, time.Now()))
+}
+
+func MockSelectEntries(mock sqlmock.Sqlmock, entries []*models.AuditEntry, args ...driver.Value) {
+	rows := sqlmock.NewRows(entryColumns)
+	for _, entry := range entries {
+		rows.AddRow(entry.ID, entry.Actor, entry.Action, entry.Entity, entry.EntityID,
+			entry.Before, entry.After, entry.RequestID, entry.Created, entry.Tenant)
+	}
+	mock.ExpectQuery(`SELECT (.+) FROM audit_log`).
+		WithArgs(args...).
+		WillReturnRows(rows)
+}
@@ -0,0 +1,7 @@
+package audit
+
+import "github.com/booking_backend/internal/models"
+
+type AuditRepository interface {
+	SelectEntries(filter *models.AuditFilter) ([]*models.AuditEntry, error)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/booking_backend/internal/audit"
+	"github.com/booking_backend/internal/models"
+	"strings"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) audit.AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// encodeState turns the entity into a jsonb value, NULL when there is none
+func encodeState(state interface{}) (interface{}, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
+
+// InsertEntry records the change within the transaction making it, so the
+// entry is stored if and only if the change is
+func InsertEntry(ctx context.Context, tx *sql.Tx, entry *models.AuditEntry) error {
+	before, err := encodeState(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := encodeState(entry.After)
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO audit_log(tenant, actor, action, entity, entity_id, before, after, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created`,
+		entry.Tenant, entry.Actor, entry.Action, entry.Entity, entry.EntityID,
+		before, after, entry.RequestID).
+		Scan(&entry.ID, &entry.Created)
+}
+
+func createSelectQuery(filter *models.AuditFilter) (string, []interface{}) {
+	conditions := []string{"tenant=$1"}
+	args := []interface{}{filter.Tenant}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Entity != "" {
+		where("entity=$%d", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		where("entity_id=$%d", filter.EntityID)
+	}
+	if filter.From != nil {
+		where("created>=$%d", *filter.From)
+	}
+	if filter.To != nil {
+		where("created<$%d", *filter.To)
+	}
+
+	query := strings.Join([]string{
+		"SELECT id, actor, action, entity, entity_id, before, after, request_id, created, tenant",
+		"FROM audit_log WHERE", strings.Join(conditions, " AND "),
+		"ORDER BY created, id"}, " ")
+	return query, args
+}
+
+func (rep *AuditRepository) SelectEntries(filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	query, args := createSelectQuery(filter)
+
+	rows, err := rep.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		var before, after []byte
+		if err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.Entity,
+			&entry.EntityID, &before, &after, &entry.RequestID, &entry.Created,
+			&entry.Tenant); err != nil {
+			return nil, err
+		}
+		if before != nil {
+			entry.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			entry.After = json.RawMessage(after)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/audit/mocks"
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInsertEntry(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	entry := &models.AuditEntry{
+		Tenant:    models.DefaultTenantID,
+		Actor:     "manager@hotel",
+		Action:    models.AuditActionCreate,
+		Entity:    models.AuditEntityRoom,
+		EntityID:  3,
+		After:     &models.Room{ID: 3, Price: 500},
+		RequestID: "request",
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO audit_log`).
+		WithArgs(entry.Tenant, entry.Actor, entry.Action, entry.Entity, entry.EntityID,
+			nil, []byte(`{"room_id":3,"description":"","price":500,"created":"0001-01-01T00:00:00Z","property":0,"version":0}`),
+			entry.RequestID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(7, time.Now()))
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, InsertEntry(context.Background(), tx, entry))
+	assert.NoError(t, tx.Commit())
+	assert.Equal(t, uint64(7), entry.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_SelectEntries(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+	entries := []*models.AuditEntry{
+		{
+			ID:       1,
+			Actor:    "manager@hotel",
+			Action:   models.AuditActionDelete,
+			Entity:   models.AuditEntityBooking,
+			EntityID: 5,
+			Before:   json.RawMessage(`{"booking_id":5}`),
+			Created:  from.Add(time.Hour),
+			Tenant:   models.DefaultTenantID,
+		},
+	}
+	mocks.MockSelectEntries(mock, entries, models.DefaultTenantID,
+		models.AuditEntityBooking, uint64(5), from, to)
+
+	auditPgRep := NewAuditRepository(db)
+	actual, err := auditPgRep.SelectEntries(&models.AuditFilter{
+		Tenant:   models.DefaultTenantID,
+		Entity:   models.AuditEntityBooking,
+		EntityID: 5,
+		From:     &from,
+		To:       &to,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, entries, actual)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateSelectQuery(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	query, args := createSelectQuery(&models.AuditFilter{Tenant: 2, From: &from})
+	assert.Equal(t, "SELECT id, actor, action, entity, entity_id, before, after, request_id, created, tenant "+
+		"FROM audit_log WHERE tenant=$1 AND created>=$2 ORDER BY created, id", query)
+	assert.Equal(t, []interface{}{uint64(2), from}, args)
+}
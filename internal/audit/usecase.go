@@ -0,0 +1,10 @@
+package audit
+
+import (
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type AuditUseCase interface {
+	GetEntries(filter *models.AuditFilter) ([]*models.AuditEntry, *errors.Error)
+}
@@ -0,0 +1,30 @@
+package usecases
+
+import (
+	"github.com/booking_backend/internal/audit"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type AuditUseCase struct {
+	auditRepo audit.AuditRepository
+}
+
+func NewAuditUseCase(auditRepository audit.AuditRepository) audit.AuditUseCase {
+	return &AuditUseCase{auditRepo: auditRepository}
+}
+
+func (uc *AuditUseCase) GetEntries(filter *models.AuditFilter) ([]*models.AuditEntry, *errors.Error) {
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, errors.Get(consts.CodeIncorrectDates)
+	}
+
+	entries, err := uc.auditRepo.SelectEntries(filter)
+	if err == nil && entries == nil {
+		return []*models.AuditEntry{}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,40 @@
+package usecases
+
+import (
+	"github.com/booking_backend/internal/audit/mocks"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAuditUseCase_GetEntries_Empty(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	filter := &models.AuditFilter{Tenant: models.DefaultTenantID, Entity: models.AuditEntityRoom}
+	auditRep := mocks.NewMockAuditRepository(ctrl)
+	auditRep.EXPECT().SelectEntries(filter).Return(nil, nil)
+
+	uc := NewAuditUseCase(auditRep)
+	entries, err := uc.GetEntries(filter)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, []*models.AuditEntry{}, entries)
+}
+
+func TestAuditUseCase_GetEntries_IncorrectDates(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	from := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	uc := NewAuditUseCase(mocks.NewMockAuditRepository(ctrl))
+	_, err := uc.GetEntries(&models.AuditFilter{Tenant: models.DefaultTenantID, From: &from, To: &to})
+	assert.Equal(t, errors.Get(consts.CodeIncorrectDates), err)
+}
@@ -0,0 +1,151 @@
+package delivery
+
+import (
+	"github.com/booking_backend/internal/auth"
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/request_reader"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderAPIKey = "X-API-Key"
+	// calendarPath is the only route taking the key from the query, as
+	// calendar applications subscribe by URL and can't send headers
+	calendarPath = "/rooms/:id/calendar.ics"
+)
+
+type AuthHandler struct {
+	authUseCase auth.AuthUseCase
+	public      map[string]bool
+}
+
+func NewAuthHandler(useCase auth.AuthUseCase) *AuthHandler {
+	return &AuthHandler{authUseCase: useCase, public: map[string]bool{}}
+}
+
+// Public lets the requests to the routes through without authentication,
+// it has to be called before the server is started
+func (ah *AuthHandler) Public(paths ...string) {
+	for _, path := range paths {
+		ah.public[path] = true
+	}
+}
+
+// Configure protects every route of e, including the ones added later
+func (ah *AuthHandler) Configure(e *echo.Echo) {
+	e.Use(ah.Authenticate())
+	e.POST("api_keys/create", ah.CreateAPIKey(), principal.Require(rbac.ManageAPIKeys))
+	e.GET("api_keys/list", ah.GetAPIKeys(), principal.Require(rbac.ManageAPIKeys))
+	e.DELETE("api_keys/:id", ah.RevokeAPIKey(), principal.Require(rbac.ManageAPIKeys))
+}
+
+func (ah *AuthHandler) authenticate(context echo.Context) (*models.Principal, *errors.Error) {
+	authorization := context.Request().Header.Get(echo.HeaderAuthorization)
+	if token := strings.TrimPrefix(authorization, "Bearer "); token != authorization {
+		return ah.authUseCase.AuthenticateToken(strings.TrimSpace(token))
+	}
+	if key := context.Request().Header.Get(HeaderAPIKey); key != "" {
+		return ah.authUseCase.AuthenticateAPIKey(key)
+	}
+	if key := context.QueryParam("api_key"); key != "" && context.Path() == calendarPath {
+		return ah.authUseCase.AuthenticateAPIKey(key)
+	}
+	return nil, errors.Get(CodeUnauthorized)
+}
+
+// Authenticate accepts either a bearer token in Authorization or an API key in X-API-Key
+func (ah *AuthHandler) Authenticate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			if ah.public[context.Path()] {
+				return next(context)
+			}
+
+			caller, customErr := ah.authenticate(context)
+			if customErr != nil {
+				logrus.Info(customErr)
+				context.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+			}
+
+			principal.Set(context, caller)
+			return next(context)
+		}
+	}
+}
+
+type APIKey struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+func (ah *AuthHandler) CreateAPIKey() echo.HandlerFunc {
+	type Request struct {
+		Name string `form:"name" validate:"required,max=128"`
+		Role string `form:"role" validate:"required,oneof=admin manager front_desk guest"`
+	}
+
+	return func(context echo.Context) error {
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		apiKey := &models.APIKey{
+			Name:    req.Name,
+			Role:    req.Role,
+			Created: time.Now(),
+			Tenant:  principal.Tenant(context),
+		}
+		key, customErr := ah.authUseCase.CreateAPIKey(apiKey)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusCreated, APIKey{APIKey: apiKey, Key: key})
+	}
+}
+
+func (ah *AuthHandler) GetAPIKeys() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		keys, customErr := ah.authUseCase.GetAPIKeys(principal.Tenant(context))
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, keys)
+	}
+}
+
+func (ah *AuthHandler) RevokeAPIKey() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		keyID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		if customErr := ah.authUseCase.RevokeAPIKey(principal.Tenant(context), keyID); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{
+			Message: "success",
+		})
+	}
+}
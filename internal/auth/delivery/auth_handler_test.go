@@ -0,0 +1,128 @@
+package delivery
+
+import (
+	"encoding/json"
+	"github.com/booking_backend/internal/auth/mocks"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/response"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newServer(ctrl *gomock.Controller) (*echo.Echo, *mocks.MockAuthUseCase) {
+	useCase := mocks.NewMockAuthUseCase(ctrl)
+	e := echo.New()
+	handler := NewAuthHandler(useCase)
+	handler.Public("/healthz")
+	handler.Configure(e)
+	e.GET("healthz", func(context echo.Context) error {
+		return context.NoContent(http.StatusOK)
+	})
+	e.GET("rooms/list", func(context echo.Context) error {
+		return context.JSON(http.StatusOK, principal.Get(context))
+	})
+	e.GET("rooms/:id/calendar.ics", func(context echo.Context) error {
+		return context.NoContent(http.StatusOK)
+	})
+	e.DELETE("rooms/:id", func(context echo.Context) error {
+		return context.NoContent(http.StatusOK)
+	}, principal.Require(rbac.DeleteRooms))
+	return e, useCase
+}
+
+func TestAuthenticate_Unauthorized(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e, _ := newServer(ctrl)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rooms/list", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	body := &response.Response{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), body))
+	assert.Equal(t, errors.Get(consts.CodeUnauthorized).Code, body.Error.Code)
+	assert.Equal(t, errors.Get(consts.CodeUnauthorized).UserMessage, body.Error.UserMessage)
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e, useCase := newServer(ctrl)
+	useCase.EXPECT().AuthenticateToken("token").
+		Return(&models.Principal{Subject: "manager@hotel", Role: models.RoleManager,
+			Tenant: models.DefaultTenantID}, nil)
+	useCase.EXPECT().AuthenticateAPIKey("bk_key").
+		Return(&models.Principal{Subject: "channel manager", KeyID: 3}, nil).Times(2)
+
+	req := httptest.NewRequest(http.MethodGet, "/rooms/list", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"subject":"manager@hotel","role":"manager","tenant":1}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/rooms/list", nil)
+	req.Header.Set(HeaderAPIKey, "bk_key")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rooms/1/calendar.ics?api_key=bk_key", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Keys in the query are only accepted by the calendar feed
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rooms/list?api_key=bk_key", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuthenticate_Public(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e, _ := newServer(ctrl)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuthenticate_Forbidden(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e, useCase := newServer(ctrl)
+	useCase.EXPECT().AuthenticateToken("guest").
+		Return(&models.Principal{Subject: "guest@mail", Role: models.RoleGuest}, nil)
+	useCase.EXPECT().AuthenticateToken("manager").
+		Return(&models.Principal{Subject: "manager@hotel", Role: models.RoleManager}, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/rooms/1", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer guest")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	body := &response.Response{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), body))
+	assert.Equal(t, errors.Get(consts.CodeForbidden).Code, body.Error.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/rooms/1", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer manager")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
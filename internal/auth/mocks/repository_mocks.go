@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mock_auth is a generated GoMock package.
+package mocks
+
+import (
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockAuthRepository is a mock of AuthRepository interface
+type MockAuthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthRepositoryMockRecorder
+}
+
+// MockAuthRepositoryMockRecorder is the mock recorder for MockAuthRepository
+type MockAuthRepositoryMockRecorder struct {
+	mock *MockAuthRepository
+}
+
+// NewMockAuthRepository creates a new mock instance
+func NewMockAuthRepository(ctrl *gomock.Controller) *MockAuthRepository {
+	mock := &MockAuthRepository{ctrl: ctrl}
+	mock.recorder = &MockAuthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuthRepository) EXPECT() *MockAuthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// InsertAPIKey mocks base method
+func (m *MockAuthRepository) InsertAPIKey(key *models.APIKey, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAPIKey", key, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAPIKey indicates an expected call of InsertAPIKey
+func (mr *MockAuthRepositoryMockRecorder) InsertAPIKey(key, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAPIKey", reflect.TypeOf((*MockAuthRepository)(nil).InsertAPIKey), key, hash)
+}
+
+// UseAPIKey mocks base method
+func (m *MockAuthRepository) UseAPIKey(hash string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAPIKey", hash)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAPIKey indicates an expected call of UseAPIKey
+func (mr *MockAuthRepositoryMockRecorder) UseAPIKey(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAPIKey", reflect.TypeOf((*MockAuthRepository)(nil).UseAPIKey), hash)
+}
+
+// SelectAPIKeys mocks base method
+func (m *MockAuthRepository) SelectAPIKeys(tenant uint64) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectAPIKeys", tenant)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAPIKeys indicates an expected call of SelectAPIKeys
+func (mr *MockAuthRepositoryMockRecorder) SelectAPIKeys(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAPIKeys", reflect.TypeOf((*MockAuthRepository)(nil).SelectAPIKeys), tenant)
+}
+
+// RevokeAPIKey mocks base method
+func (m *MockAuthRepository) RevokeAPIKey(tenant, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", tenant, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey
+func (mr *MockAuthRepositoryMockRecorder) RevokeAPIKey(tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAuthRepository)(nil).RevokeAPIKey), tenant, id)
+}
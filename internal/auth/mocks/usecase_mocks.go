@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_auth is a generated GoMock package.
+package mocks
+
+import (
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockAuthUseCase is a mock of AuthUseCase interface
+type MockAuthUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthUseCaseMockRecorder
+}
+
+// MockAuthUseCaseMockRecorder is the mock recorder for MockAuthUseCase
+type MockAuthUseCaseMockRecorder struct {
+	mock *MockAuthUseCase
+}
+
+// NewMockAuthUseCase creates a new mock instance
+func NewMockAuthUseCase(ctrl *gomock.Controller) *MockAuthUseCase {
+	mock := &MockAuthUseCase{ctrl: ctrl}
+	mock.recorder = &MockAuthUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuthUseCase) EXPECT() *MockAuthUseCaseMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateToken mocks base method
+func (m *MockAuthUseCase) AuthenticateToken(token string) (*models.Principal, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateToken", token)
+	ret0, _ := ret[0].(*models.Principal)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// AuthenticateToken indicates an expected call of AuthenticateToken
+func (mr *MockAuthUseCaseMockRecorder) AuthenticateToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateToken", reflect.TypeOf((*MockAuthUseCase)(nil).AuthenticateToken), token)
+}
+
+// AuthenticateAPIKey mocks base method
+func (m *MockAuthUseCase) AuthenticateAPIKey(key string) (*models.Principal, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", key)
+	ret0, _ := ret[0].(*models.Principal)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey
+func (mr *MockAuthUseCaseMockRecorder) AuthenticateAPIKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockAuthUseCase)(nil).AuthenticateAPIKey), key)
+}
+
+// CreateAPIKey mocks base method
+func (m *MockAuthUseCase) CreateAPIKey(key *models.APIKey) (string, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey
+func (mr *MockAuthUseCaseMockRecorder) CreateAPIKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAuthUseCase)(nil).CreateAPIKey), key)
+}
+
+// GetAPIKeys mocks base method
+func (m *MockAuthUseCase) GetAPIKeys(tenant uint64) ([]*models.APIKey, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", tenant)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys
+func (mr *MockAuthUseCaseMockRecorder) GetAPIKeys(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockAuthUseCase)(nil).GetAPIKeys), tenant)
+}
+
+// RevokeAPIKey mocks base method
+func (m *MockAuthUseCase) RevokeAPIKey(tenant, id uint64) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", tenant, id)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey
+func (mr *MockAuthUseCaseMockRecorder) RevokeAPIKey(tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAuthUseCase)(nil).RevokeAPIKey), tenant, id)
+}
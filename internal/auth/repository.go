@@ -0,0 +1,16 @@
+package auth
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/models"
+)
+
+var ErrKeyDoesNotExist = errors.New("api key doesn't exist")
+
+type AuthRepository interface {
+	InsertAPIKey(key *models.APIKey, hash string) error
+	// UseAPIKey returns the unrevoked key with the hash and marks it as used
+	UseAPIKey(hash string) (*models.APIKey, error)
+	SelectAPIKeys(tenant uint64) ([]*models.APIKey, error)
+	RevokeAPIKey(tenant uint64, id uint64) error
+}
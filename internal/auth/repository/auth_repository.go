@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/auth"
+	"github.com/booking_backend/internal/models"
+)
+
+type AuthRepository struct {
+	db *sql.DB
+}
+
+func NewAuthRepository(db *sql.DB) auth.AuthRepository {
+	return &AuthRepository{db: db}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row scanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var lastUsed, revoked sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Role, &key.Created,
+		&lastUsed, &revoked, &key.Tenant); err != nil {
+		return nil, err
+	}
+	if lastUsed.Valid {
+		key.LastUsed = &lastUsed.Time
+	}
+	if revoked.Valid {
+		key.Revoked = &revoked.Time
+	}
+	return key, nil
+}
+
+func (rep *AuthRepository) InsertAPIKey(key *models.APIKey, hash string) error {
+	return rep.db.QueryRow(`
+		INSERT INTO api_keys(name, prefix, role, hash, created, tenant)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		key.Name, key.Prefix, key.Role, hash, key.Created, key.Tenant).
+		Scan(&key.ID)
+}
+
+func (rep *AuthRepository) UseAPIKey(hash string) (*models.APIKey, error) {
+	row := rep.db.QueryRow(`
+		UPDATE api_keys
+		SET last_used=now()
+		WHERE hash=$1 AND revoked IS NULL
+		RETURNING id, name, prefix, role, created, last_used, revoked, tenant`, hash)
+	return scanAPIKey(row)
+}
+
+func (rep *AuthRepository) SelectAPIKeys(tenant uint64) ([]*models.APIKey, error) {
+	rows, err := rep.db.Query(`
+		SELECT id, name, prefix, role, created, last_used, revoked, tenant
+		FROM api_keys
+		WHERE tenant=$1
+		ORDER BY id`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey keeps the key to show when it was revoked
+func (rep *AuthRepository) RevokeAPIKey(tenant uint64, id uint64) error {
+	res, err := rep.db.Exec(`
+		UPDATE api_keys
+		SET revoked=now()
+		WHERE id=$1 AND tenant=$2 AND revoked IS NULL`, id, tenant)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return auth.ErrKeyDoesNotExist
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type AuthUseCase interface {
+	AuthenticateToken(token string) (*models.Principal, *errors.Error)
+	AuthenticateAPIKey(key string) (*models.Principal, *errors.Error)
+	// CreateAPIKey returns the key, which can't be retrieved later
+	CreateAPIKey(key *models.APIKey) (string, *errors.Error)
+	GetAPIKeys(tenant uint64) ([]*models.APIKey, *errors.Error)
+	RevokeAPIKey(tenant uint64, id uint64) *errors.Error
+}
@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/booking_backend/internal/auth"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/jwt"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+const (
+	keyPrefix = "bk_"
+	// keyPrefixLength is how much of the key is kept to tell the keys apart
+	keyPrefixLength = len(keyPrefix) + 8
+	keyBytes        = 32
+)
+
+type AuthUseCase struct {
+	authRepo  auth.AuthRepository
+	jwtSecret []byte
+}
+
+// NewAuthUseCase takes the secret of HS256 bearer tokens, with an empty one
+// only API keys are accepted
+func NewAuthUseCase(authRepository auth.AuthRepository, jwtSecret []byte) auth.AuthUseCase {
+	return &AuthUseCase{authRepo: authRepository, jwtSecret: jwtSecret}
+}
+
+// HashAPIKey is enough for keys as they are random, unlike passwords
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func (uc *AuthUseCase) AuthenticateToken(token string) (*models.Principal, *errors.Error) {
+	if len(uc.jwtSecret) == 0 {
+		return nil, errors.Get(consts.CodeUnauthorized)
+	}
+
+	claims, err := jwt.Parse(token, uc.jwtSecret, time.Now())
+	if err != nil {
+		return nil, errors.New(consts.CodeUnauthorized, err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New(consts.CodeUnauthorized, fmt.Errorf("token has no subject"))
+	}
+	// Tokens without a role are issued to guests
+	role := claims.Role
+	if role == "" {
+		role = models.RoleGuest
+	}
+	if !rbac.IsRole(role) {
+		return nil, errors.New(consts.CodeUnauthorized, fmt.Errorf("token has unknown role %q", role))
+	}
+	tenant := claims.Tenant
+	if tenant == 0 {
+		tenant = models.DefaultTenantID
+	}
+	return &models.Principal{Subject: claims.Subject, Role: role, Tenant: tenant}, nil
+}
+
+func (uc *AuthUseCase) AuthenticateAPIKey(key string) (*models.Principal, *errors.Error) {
+	apiKey, err := uc.authRepo.UseAPIKey(HashAPIKey(key))
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeUnauthorized)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return &models.Principal{Subject: apiKey.Name, Role: apiKey.Role,
+		Tenant: apiKey.Tenant, KeyID: apiKey.ID}, nil
+}
+
+func (uc *AuthUseCase) CreateAPIKey(apiKey *models.APIKey) (string, *errors.Error) {
+	random := make([]byte, keyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.New(consts.CodeInternalError, err)
+	}
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(random)
+	apiKey.Prefix = key[:keyPrefixLength]
+
+	if err := uc.authRepo.InsertAPIKey(apiKey, HashAPIKey(key)); err != nil {
+		return "", errors.New(consts.CodeInternalError, err)
+	}
+	return key, nil
+}
+
+func (uc *AuthUseCase) GetAPIKeys(tenant uint64) ([]*models.APIKey, *errors.Error) {
+	keys, err := uc.authRepo.SelectAPIKeys(tenant)
+	if err == nil && keys == nil {
+		return []*models.APIKey{}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return keys, nil
+}
+
+func (uc *AuthUseCase) RevokeAPIKey(tenant uint64, id uint64) *errors.Error {
+	err := uc.authRepo.RevokeAPIKey(tenant, id)
+	if err == auth.ErrKeyDoesNotExist {
+		return errors.Get(consts.CodeAPIKeyDoesNotExist)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
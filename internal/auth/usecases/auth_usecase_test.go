@@ -0,0 +1,137 @@
+package usecases
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/auth"
+	"github.com/booking_backend/internal/auth/mocks"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/jwt"
+	"github.com/booking_backend/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("secret")
+
+func TestAuthUseCase_AuthenticateToken(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	token, err := jwt.Sign(&jwt.Claims{
+		Subject:   "manager@hotel",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, secret)
+	assert.NoError(t, err)
+
+	uc := NewAuthUseCase(mocks.NewMockAuthRepository(ctrl), secret)
+	principal, customErr := uc.AuthenticateToken(token)
+	assert.Equal(t, (*errors.Error)(nil), customErr)
+	assert.Equal(t, &models.Principal{Subject: "manager@hotel", Role: models.RoleGuest,
+		Tenant: models.DefaultTenantID}, principal)
+
+	token, err = jwt.Sign(&jwt.Claims{
+		Subject:   "manager@hotel",
+		Role:      models.RoleManager,
+		Tenant:    2,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, secret)
+	assert.NoError(t, err)
+
+	principal, customErr = uc.AuthenticateToken(token)
+	assert.Equal(t, (*errors.Error)(nil), customErr)
+	assert.Equal(t, &models.Principal{Subject: "manager@hotel", Role: models.RoleManager,
+		Tenant: 2}, principal)
+}
+
+func TestAuthUseCase_AuthenticateToken_Rejected(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expired, err := jwt.Sign(&jwt.Claims{
+		Subject:   "manager@hotel",
+		ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+	}, secret)
+	assert.NoError(t, err)
+	anonymous, err := jwt.Sign(&jwt.Claims{}, secret)
+	assert.NoError(t, err)
+	unknownRole, err := jwt.Sign(&jwt.Claims{
+		Subject:   "manager@hotel",
+		Role:      "owner",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, secret)
+	assert.NoError(t, err)
+
+	uc := NewAuthUseCase(mocks.NewMockAuthRepository(ctrl), secret)
+	for _, token := range []string{expired, anonymous, unknownRole, "garbage"} {
+		_, customErr := uc.AuthenticateToken(token)
+		assert.Equal(t, consts.CodeUnauthorized, customErr.Code)
+	}
+
+	withoutSecret := NewAuthUseCase(mocks.NewMockAuthRepository(ctrl), nil)
+	_, customErr := withoutSecret.AuthenticateToken(expired)
+	assert.Equal(t, consts.CodeUnauthorized, customErr.Code)
+}
+
+func TestAuthUseCase_CreateAPIKey(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var storedHash string
+	authRep := mocks.NewMockAuthRepository(ctrl)
+	authRep.EXPECT().InsertAPIKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(key *models.APIKey, hash string) error {
+			storedHash = hash
+			key.ID = 3
+			return nil
+		})
+
+	uc := NewAuthUseCase(authRep, secret)
+	apiKey := &models.APIKey{Name: "channel manager"}
+	key, customErr := uc.CreateAPIKey(apiKey)
+	assert.Equal(t, (*errors.Error)(nil), customErr)
+	assert.True(t, strings.HasPrefix(key, apiKey.Prefix))
+	assert.Len(t, apiKey.Prefix, keyPrefixLength)
+	assert.Equal(t, HashAPIKey(key), storedHash)
+	assert.NotContains(t, storedHash, key[len(keyPrefix):])
+}
+
+func TestAuthUseCase_AuthenticateAPIKey(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authRep := mocks.NewMockAuthRepository(ctrl)
+	authRep.EXPECT().UseAPIKey(HashAPIKey("bk_valid")).
+		Return(&models.APIKey{ID: 3, Name: "channel manager", Role: models.RoleFrontDesk,
+			Tenant: 2}, nil)
+	authRep.EXPECT().UseAPIKey(HashAPIKey("bk_revoked")).Return(nil, sql.ErrNoRows)
+
+	uc := NewAuthUseCase(authRep, secret)
+	principal, customErr := uc.AuthenticateAPIKey("bk_valid")
+	assert.Equal(t, (*errors.Error)(nil), customErr)
+	assert.Equal(t, &models.Principal{Subject: "channel manager",
+		Role: models.RoleFrontDesk, Tenant: 2, KeyID: 3}, principal)
+
+	_, customErr = uc.AuthenticateAPIKey("bk_revoked")
+	assert.Equal(t, errors.Get(consts.CodeUnauthorized), customErr)
+}
+
+func TestAuthUseCase_RevokeAPIKey_DoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authRep := mocks.NewMockAuthRepository(ctrl)
+	authRep.EXPECT().RevokeAPIKey(models.DefaultTenantID, uint64(9)).Return(auth.ErrKeyDoesNotExist)
+
+	uc := NewAuthUseCase(authRep, secret)
+	customErr := uc.RevokeAPIKey(models.DefaultTenantID, 9)
+	assert.Equal(t, errors.Get(consts.CodeAPIKeyDoesNotExist), customErr)
+}
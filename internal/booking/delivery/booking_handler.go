@@ -1,34 +1,57 @@
 package delivery
 
 import (
+	"bytes"
 	"github.com/booking_backend/internal/booking"
 	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/csvio"
+	"github.com/booking_backend/internal/helpers/dates"
 	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
 	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/etag"
+	"github.com/booking_backend/tools/principal"
 	"github.com/booking_backend/tools/request_reader"
 	"github.com/booking_backend/tools/response"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type BookingHandler struct {
 	bookingUseCase booking.BookingUseCase
+	idempotent     echo.MiddlewareFunc
 }
 
-func NewBookingHandler(useCase booking.BookingUseCase) *BookingHandler {
-	return &BookingHandler{bookingUseCase: useCase}
+// NewBookingHandler lets the booking creation be retried with the idempotent middleware
+func NewBookingHandler(useCase booking.BookingUseCase,
+	idempotent echo.MiddlewareFunc) *BookingHandler {
+	return &BookingHandler{bookingUseCase: useCase, idempotent: idempotent}
 }
 
 func (bh *BookingHandler) Configure(e *echo.Echo) {
-	e.POST("bookings/create", bh.CreateBooking())
-	e.GET("bookings/list", bh.GetRoomBookings())
-	e.DELETE("bookings/:id", bh.DeleteBooking())
+	e.POST("bookings/create",
+		bh.CreateBooking(), principal.Require(rbac.CreateBookings), bh.idempotent)
+	e.GET("bookings/quote",
+		bh.GetQuote(), principal.Require(rbac.ViewRooms))
+	e.GET("bookings/list",
+		bh.GetRoomBookings(), principal.Require(rbac.ViewBookings, rbac.ViewOwnBookings))
+	e.DELETE("bookings/:id",
+		bh.CancelBooking(), principal.Require(rbac.CancelBookings, rbac.CancelOwnBookings))
+	e.POST("bookings/:id/confirm",
+		bh.ConfirmBooking(), principal.Require(rbac.CreateBookings))
+	e.POST("bookings/:id/refund",
+		bh.RetryRefund(), principal.Require(rbac.ManageBookings))
+	e.GET("bookings/:id",
+		bh.GetBooking(), principal.Require(rbac.ViewBookings, rbac.ViewOwnBookings))
 }
 
 type BookingID struct {
-	ID uint64 `json:"booking_id"`
+	ID          uint64     `json:"booking_id"`
+	HoldExpires *time.Time `json:"hold_expires,omitempty"`
 }
 
 func (bh *BookingHandler) CreateBooking() echo.HandlerFunc {
@@ -36,6 +59,17 @@ func (bh *BookingHandler) CreateBooking() echo.HandlerFunc {
 		RoomID    uint64            `form:"room_id" validate:"required"`
 		DateStart models.CustomDate `form:"date_start" validate:"required"`
 		DateEnd   models.CustomDate `form:"date_end" validate:"required"`
+		Guests    uint64            `form:"guests"`
+		PromoCode string            `form:"promo_code"`
+		Hold      bool              `form:"hold"`
+		// Guest is the login of the guest the staff books for,
+		// guests always book for themselves
+		Guest string `form:"guest"`
+		// Email gets the notifications about the booking in Language
+		Email    string `form:"email" validate:"omitempty,email,max=254"`
+		Language string `form:"language" validate:"omitempty,oneof=ru en"`
+		// PaymentToken is issued by the payment provider on the client side
+		PaymentToken string `form:"payment_token"`
 	}
 
 	return func(context echo.Context) error {
@@ -49,14 +83,58 @@ func (bh *BookingHandler) CreateBooking() echo.HandlerFunc {
 			DateStart: req.DateStart.Date,
 			DateEnd:   req.DateEnd.Date,
 			Room:      req.RoomID,
+			Guests:    req.Guests,
+			PromoCode: req.PromoCode,
+			Guest:     req.Guest,
+			Email:     req.Email,
+			Language:  req.Language,
+			Tenant:    principal.Tenant(context),
+		}
+		if !principal.Can(context, rbac.ManageBookings) {
+			booking.Guest = principal.Get(context).Subject
+		}
+		if req.Hold {
+			booking.Status = models.BookingStatusHeld
+		}
+
+		if customErr := bh.bookingUseCase.CreateBooking(context.Request().Context(),
+			principal.Actor(context), booking, req.PaymentToken); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusCreated, BookingID{
+			ID:          booking.ID,
+			HoldExpires: booking.HoldExpires,
+		})
+	}
+}
+
+func (bh *BookingHandler) GetQuote() echo.HandlerFunc {
+	type Request struct {
+		RoomID    uint64            `query:"room_id" validate:"required"`
+		DateStart models.CustomDate `query:"date_start" validate:"required"`
+		DateEnd   models.CustomDate `query:"date_end" validate:"required"`
+		Guests    uint64            `query:"guests"`
+		PromoCode string            `query:"promo_code"`
+	}
+
+	return func(context echo.Context) error {
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
 
-		if customErr := bh.bookingUseCase.CreateBooking(booking); customErr != nil {
+		quote, customErr := bh.bookingUseCase.GetQuote(context.Request().Context(),
+			principal.Tenant(context), req.RoomID, req.DateStart.Date, req.DateEnd.Date, req.Guests,
+			req.PromoCode)
+		if customErr != nil {
 			logrus.Info(customErr)
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
 
-		return context.JSON(http.StatusCreated, BookingID{ID: booking.ID})
+		return context.JSON(http.StatusOK, quote)
 	}
 }
 
@@ -73,17 +151,84 @@ func (bh *BookingHandler) GetRoomBookings() echo.HandlerFunc {
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
 
-		bookings, customErr := bh.bookingUseCase.GetRoomBookings(roomID)
+		bookings, customErr := bh.bookingUseCase.GetRoomBookings(context.Request().Context(),
+			principal.Tenant(context), roomID)
 		if customErr != nil {
 			logrus.Error(customErr)
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
+		if !principal.Can(context, rbac.ViewBookings) {
+			bookings = ownBookings(context, bookings)
+		}
+
+		if !csvio.WantsCSV(context) {
+			return context.JSON(http.StatusOK, bookings)
+		}
+
+		file := &bytes.Buffer{}
+		if err := writeBookingsCSV(file, bookings); err != nil {
+			customErr := errors.New(CodeInternalError, err)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		return context.Blob(http.StatusOK, csvio.MIMETextCSV, file.Bytes())
+	}
+}
+
+func ownBookings(context echo.Context, bookings []*models.Booking) []*models.Booking {
+	own := make([]*models.Booking, 0, len(bookings))
+	for _, booking := range bookings {
+		if principal.Owns(context, booking) {
+			own = append(own, booking)
+		}
+	}
+	return own
+}
 
-		return context.JSON(http.StatusOK, bookings)
+// checkOwner lets a caller without the staff permission act only on
+// the bookings made for them
+func (bh *BookingHandler) checkOwner(context echo.Context,
+	bookingID uint64, permission rbac.Permission) (bool, *errors.Error) {
+	if principal.Can(context, permission) {
+		return true, nil
+	}
+	booking, customErr := bh.bookingUseCase.GetBooking(context.Request().Context(),
+		principal.Tenant(context), bookingID)
+	if customErr != nil {
+		return false, customErr
 	}
+	return principal.Owns(context, booking), nil
 }
 
-func (bh *BookingHandler) DeleteBooking() echo.HandlerFunc {
+// writeBookingsCSV uses the columns of the import, so the file can be
+// imported into another deployment
+func writeBookingsCSV(w io.Writer, bookings []*models.Booking) error {
+	rows := make([][]string, 0, len(bookings))
+	for _, booking := range bookings {
+		dateStart, err := dates.Parse(booking.DateStart)
+		if err != nil {
+			return err
+		}
+		dateEnd, err := dates.Parse(booking.DateEnd)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, []string{
+			strconv.FormatUint(booking.ID, 10),
+			strconv.FormatUint(booking.Room, 10),
+			dateStart.Format(dates.Layout),
+			dateEnd.Format(dates.Layout),
+			booking.Status,
+			strconv.FormatUint(booking.Guests, 10),
+			strconv.FormatUint(booking.Amount, 10),
+			booking.PromoCode,
+		})
+	}
+	return csvio.Write(w, []string{"booking_id", "room_id", "date_start", "date_end",
+		"status", "guests", "amount", "promo_code"}, rows)
+}
+
+func (bh *BookingHandler) CancelBooking() echo.HandlerFunc {
 	return func(context echo.Context) error {
 		bookingID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
 		if parseErr != nil {
@@ -92,7 +237,75 @@ func (bh *BookingHandler) DeleteBooking() echo.HandlerFunc {
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
 
-		customErr := bh.bookingUseCase.DeleteBooking(bookingID)
+		allowed, customErr := bh.checkOwner(context, bookingID, rbac.CancelBookings)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		if !allowed {
+			return principal.Forbidden(context)
+		}
+
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		cancelled, customErr := bh.bookingUseCase.CancelBooking(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), bookingID, version)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{
+			Message: "success",
+			Body: &response.Body{
+				"cancellation_fee": cancelled.CancellationFee,
+				"refund_status":    cancelled.RefundStatus,
+				"refund_amount":    cancelled.RefundAmount,
+			},
+		})
+	}
+}
+
+func (bh *BookingHandler) ConfirmBooking() echo.HandlerFunc {
+	type Request struct {
+		PaymentToken string `form:"payment_token"`
+	}
+
+	return func(context echo.Context) error {
+		bookingID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		allowed, customErr := bh.checkOwner(context, bookingID, rbac.ManageBookings)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		if !allowed {
+			return principal.Forbidden(context)
+		}
+
+		customErr = bh.bookingUseCase.ConfirmBooking(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), bookingID, version, req.PaymentToken)
 		if customErr != nil {
 			logrus.Error(customErr)
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
@@ -101,3 +314,47 @@ func (bh *BookingHandler) DeleteBooking() echo.HandlerFunc {
 		return context.JSON(http.StatusOK, response.Response{Message: "success"})
 	}
 }
+
+func (bh *BookingHandler) GetBooking() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		bookingID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		booking, customErr := bh.bookingUseCase.GetBooking(context.Request().Context(),
+			principal.Tenant(context), bookingID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		if !principal.Can(context, rbac.ViewBookings) && !principal.Owns(context, booking) {
+			return principal.Forbidden(context)
+		}
+
+		etag.Set(context, booking.Version)
+		return context.JSON(http.StatusOK, booking)
+	}
+}
+
+func (bh *BookingHandler) RetryRefund() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		bookingID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		booking, customErr := bh.bookingUseCase.RetryRefund(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), bookingID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, booking)
+	}
+}
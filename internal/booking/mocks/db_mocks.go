@@ -2,52 +2,217 @@ package mocks
 
 import (
 	"database/sql"
+	"encoding/json"
+	auditMocks "github.com/booking_backend/internal/audit/mocks"
 	"github.com/booking_backend/internal/models"
+	outboxMocks "github.com/booking_backend/internal/outbox/mocks"
+	promoMocks "github.com/booking_backend/internal/promo/mocks"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
 
-func MockInsertSuccess(mock sqlmock.Sqlmock, booking *models.Booking) {
+var bookingColumns = []string{"id", "date_start", "date_end", "room",
+	"reservation", "status", "guests", "guest", "email", "language", "amount", "quote", "promo_code", "hold_expires",
+	"cancellation_fee", "cancelled", "refund_status", "refund_amount", "version", "tenant", "updated"}
+
+func quoteValue(booking *models.Booking) interface{} {
+	if booking.Quote == nil {
+		return nil
+	}
+	quote, _ := json.Marshal(booking.Quote)
+	return quote
+}
+
+func MockCheckRoomIsFree(mock sqlmock.Sqlmock, booking *models.Booking, occupied bool) {
+	mock.ExpectQuery(`SELECT id FROM rooms`).
+		WithArgs(booking.Room).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(booking.Room))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(booking.Room, sqlmock.AnyArg(), booking.DateStart, booking.DateEnd,
+			models.BookingStatusConfirmed, models.BookingStatusHeld).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(occupied))
+}
+
+func MockInsertSuccess(mock sqlmock.Sqlmock, booking *models.Booking, entry *models.AuditEntry,
+	event *models.Event) {
 	mock.ExpectBegin()
-	rows := sqlmock.NewRows([]string{"id"}).AddRow(booking.ID)
+	MockCheckRoomIsFree(mock, booking, false)
+	rows := sqlmock.NewRows([]string{"id", "version", "tenant"}).
+		AddRow(booking.ID, booking.Version, booking.Tenant)
 	mock.ExpectQuery(`INSERT INTO bookings`).
-		WithArgs(booking.DateStart, booking.DateEnd, booking.Room).
+		WithArgs(booking.DateStart, booking.DateEnd, booking.Room,
+			booking.Status, booking.Guests, booking.Guest, booking.Email, booking.Language,
+			booking.Amount, sqlmock.AnyArg(), booking.PromoCode, booking.HoldExpires).
 		WillReturnRows(rows)
+	auditMocks.MockInsertEntry(mock, entry)
+	outboxMocks.MockInsertEvent(mock, event)
 	mock.ExpectCommit()
 }
 
-func MockDeleteSuccess(mock sqlmock.Sqlmock, id uint64) {
+func MockInsertPromoCodeExhausted(mock sqlmock.Sqlmock, booking *models.Booking) {
 	mock.ExpectBegin()
-	res := sqlmock.NewResult(0, 1)
-	mock.ExpectExec(`DELETE FROM bookings`).
+	MockCheckRoomIsFree(mock, booking, false)
+	promoMocks.MockRedeem(mock, booking.Tenant, booking.PromoCode, 1, 1)
+	mock.ExpectRollback()
+}
+
+func MockInsertRoomIsOccupied(mock sqlmock.Sqlmock, booking *models.Booking) {
+	mock.ExpectBegin()
+	MockCheckRoomIsFree(mock, booking, true)
+	mock.ExpectRollback()
+}
+
+func MockConfirmHoldExpired(mock sqlmock.Sqlmock, tenant uint64, id uint64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(models.BookingStatusConfirmed, id, tenant, models.BookingStatusHeld).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+}
+
+func MockTouchReservation(mock sqlmock.Sqlmock, id uint64) {
+	mock.ExpectExec(`UPDATE reservations SET version=version\+1`).
 		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func MockConfirmSuccess(mock sqlmock.Sqlmock, tenant uint64, id uint64, entry *models.AuditEntry,
+	event *models.Event) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(models.BookingStatusConfirmed, id, tenant, models.BookingStatusHeld).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	MockTouchReservation(mock, id)
+	auditMocks.MockInsertEntry(mock, entry)
+	outboxMocks.MockInsertEvent(mock, event)
+	mock.ExpectCommit()
+}
+
+func MockCancelSuccess(mock sqlmock.Sqlmock, booking *models.Booking, entry *models.AuditEntry) {
+	mock.ExpectBegin()
+	res := sqlmock.NewResult(0, 1)
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(models.BookingStatusCancelled, booking.CancellationFee,
+			booking.Cancelled, booking.ID, booking.Tenant, booking.Version).
 		WillReturnResult(res)
+	MockTouchReservation(mock, booking.ID)
+	auditMocks.MockInsertEntry(mock, entry)
+	mock.ExpectCommit()
+}
+
+func MockUpdateRefundSuccess(mock sqlmock.Sqlmock, booking *models.Booking, entry *models.AuditEntry,
+	event *models.Event) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE bookings SET refund_status=\$1, refund_amount=\$2`).
+		WithArgs(booking.RefundStatus, booking.RefundAmount, booking.ID, booking.Tenant).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(booking.Version + 1))
+	MockTouchReservation(mock, booking.ID)
+	auditMocks.MockInsertEntry(mock, entry)
+	outboxMocks.MockInsertEvent(mock, event)
 	mock.ExpectCommit()
 }
 
-func MockSelectBookingByIDReturnErrNoRows(mock sqlmock.Sqlmock, id uint64) {
+func MockCancelVersionMismatch(mock sqlmock.Sqlmock, booking *models.Booking) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(models.BookingStatusCancelled, booking.CancellationFee,
+			booking.Cancelled, booking.ID, booking.Tenant, booking.Version).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+}
+
+func MockSelectBookingByIDReturnErrNoRows(mock sqlmock.Sqlmock, tenant uint64, id uint64) {
 	mock.ExpectQuery(`SELECT`).
-		WithArgs(id).
+		WithArgs(id, tenant).
 		WillReturnError(sql.ErrNoRows)
 }
 
 func MockSelectReturnRows(mock sqlmock.Sqlmock, booking *models.Booking) {
-	rows := sqlmock.NewRows([]string{"id", "date_start", "date_end", "room"})
-	rows.AddRow(booking.ID, booking.DateStart, booking.DateEnd, booking.Room)
+	rows := sqlmock.NewRows(bookingColumns)
+	rows.AddRow(booking.ID, booking.DateStart, booking.DateEnd, booking.Room,
+		nil, booking.Status, booking.Guests, booking.Guest,
+		booking.Email, booking.Language, booking.Amount, quoteValue(booking),
+		booking.PromoCode, booking.HoldExpires,
+		booking.CancellationFee, booking.Cancelled,
+		booking.RefundStatus, booking.RefundAmount, booking.Version, booking.Tenant, booking.Updated)
 	mock.ExpectQuery(`SELECT`).
-		WithArgs(booking.ID).
+		WithArgs(booking.ID, booking.Tenant).
+		WillReturnRows(rows)
+}
+
+func MockSelectForUpdate(mock sqlmock.Sqlmock, booking *models.Booking) {
+	rows := sqlmock.NewRows(bookingColumns)
+	rows.AddRow(booking.ID, booking.DateStart, booking.DateEnd, booking.Room,
+		nil, booking.Status, booking.Guests, booking.Guest,
+		booking.Email, booking.Language, booking.Amount, quoteValue(booking),
+		booking.PromoCode, booking.HoldExpires,
+		booking.CancellationFee, booking.Cancelled,
+		booking.RefundStatus, booking.RefundAmount, booking.Version, booking.Tenant, booking.Updated)
+	mock.ExpectQuery(`SELECT (.+) FROM bookings WHERE (.+) FOR UPDATE`).
+		WithArgs(booking.ID, booking.Tenant).
 		WillReturnRows(rows)
 }
 
-func MockSelectBookingList(mock sqlmock.Sqlmock,
+func MockSelectBookingList(mock sqlmock.Sqlmock, tenant uint64,
 	roomID uint64, resultBookings []*models.Booking) {
-	rows := sqlmock.NewRows([]string{"id", "date_start", "date_end", "room"})
-	for _, booking := range resultBookings {
-		rows.AddRow(booking.ID, booking.DateStart,
-			booking.DateEnd, booking.Room)
-	}
+	mock.ExpectQuery(`SELECT`).
+		WithArgs(roomID, tenant, models.BookingStatusCancelled).
+		WillReturnRows(bookingRows(resultBookings))
+}
 
+func MockSelectRoomCalendar(mock sqlmock.Sqlmock, tenant uint64,
+	roomID uint64, resultBookings []*models.Booking) {
 	mock.ExpectQuery(`SELECT`).
-		WithArgs(roomID).
-		WillReturnRows(rows)
+		WithArgs(roomID, tenant).
+		WillReturnRows(bookingRows(resultBookings))
+}
+
+func bookingRows(bookings []*models.Booking) *sqlmock.Rows {
+	rows := sqlmock.NewRows(bookingColumns)
+	for _, booking := range bookings {
+		rows.AddRow(booking.ID, booking.DateStart, booking.DateEnd,
+			booking.Room, nil, booking.Status, booking.Guests, booking.Guest,
+			booking.Email, booking.Language, booking.Amount,
+			quoteValue(booking), booking.PromoCode, booking.HoldExpires,
+			booking.CancellationFee, booking.Cancelled,
+			booking.RefundStatus, booking.RefundAmount, booking.Version, booking.Tenant, booking.Updated)
+	}
+	return rows
+}
+
+// MockDeleteRoomBookings expects the bookings to be marked deleted within a running transaction
+func MockDeleteRoomBookings(mock sqlmock.Sqlmock, tenant uint64,
+	roomID uint64, deletedAt time.Time, deleted []*models.Booking) {
+	mock.ExpectQuery(`UPDATE bookings SET deleted_at=\$3, version=version\+1`).
+		WithArgs(roomID, tenant, deletedAt).
+		WillReturnRows(bookingRows(deleted))
+}
+
+// MockRestoreRoomBookings expects the bookings to be restored within a running transaction
+func MockRestoreRoomBookings(mock sqlmock.Sqlmock, tenant uint64,
+	roomID uint64, deletedAt time.Time, restored []*models.Booking) {
+	mock.ExpectQuery(`UPDATE bookings SET deleted_at=NULL, version=version\+1`).
+		WithArgs(roomID, tenant, deletedAt).
+		WillReturnRows(bookingRows(restored))
+}
+
+func MockCancelExpiredHolds(mock sqlmock.Sqlmock, released []*models.Booking,
+	entries []*models.AuditEntry) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE bookings SET status=\$1, cancelled=now\(\)`).
+		WithArgs(models.BookingStatusCancelled, models.BookingStatusHeld).
+		WillReturnRows(bookingRows(released))
+	for i, entry := range entries {
+		MockTouchReservation(mock, released[i].ID)
+		auditMocks.MockInsertEntry(mock, entry)
+	}
+	mock.ExpectCommit()
+}
+
+func MockSelectArrivals(mock sqlmock.Sqlmock, tenant uint64, date string, id uint64,
+	bookings []*models.Booking) {
+	mock.ExpectQuery(`SELECT (.+) FROM bookings`).
+		WithArgs(tenant, date, models.BookingStatusConfirmed, id).
+		WillReturnRows(bookingRows(bookings))
 }
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	models "github.com/booking_backend/internal/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
@@ -34,59 +35,147 @@ func (m *MockBookingRepository) EXPECT() *MockBookingRepositoryMockRecorder {
 }
 
 // Insert mocks base method
-func (m *MockBookingRepository) Insert(booking *models.Booking) error {
+func (m *MockBookingRepository) Insert(ctx context.Context, booking *models.Booking, entry *models.AuditEntry, events ...*models.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Insert", booking)
+	varargs := []interface{}{ctx, booking, entry}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Insert", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Insert indicates an expected call of Insert
-func (mr *MockBookingRepositoryMockRecorder) Insert(booking interface{}) *gomock.Call {
+func (mr *MockBookingRepositoryMockRecorder) Insert(ctx, booking, entry interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockBookingRepository)(nil).Insert), booking)
+	varargs := append([]interface{}{ctx, booking, entry}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockBookingRepository)(nil).Insert), varargs...)
 }
 
 // SelectByID mocks base method
-func (m *MockBookingRepository) SelectByID(id uint64) (*models.Booking, error) {
+func (m *MockBookingRepository) SelectByID(ctx context.Context, tenant, id uint64) (*models.Booking, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectByID", id)
+	ret := m.ctrl.Call(m, "SelectByID", ctx, tenant, id)
 	ret0, _ := ret[0].(*models.Booking)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SelectByID indicates an expected call of SelectByID
-func (mr *MockBookingRepositoryMockRecorder) SelectByID(id interface{}) *gomock.Call {
+func (mr *MockBookingRepositoryMockRecorder) SelectByID(ctx, tenant, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByID", reflect.TypeOf((*MockBookingRepository)(nil).SelectByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByID", reflect.TypeOf((*MockBookingRepository)(nil).SelectByID), ctx, tenant, id)
 }
 
-// DeleteByID mocks base method
-func (m *MockBookingRepository) DeleteByID(id uint64) error {
+// SelectForUpdate mocks base method
+func (m *MockBookingRepository) SelectForUpdate(ctx context.Context, tenant, id uint64) (*models.Booking, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByID", id)
+	ret := m.ctrl.Call(m, "SelectForUpdate", ctx, tenant, id)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectForUpdate indicates an expected call of SelectForUpdate
+func (mr *MockBookingRepositoryMockRecorder) SelectForUpdate(ctx, tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectForUpdate", reflect.TypeOf((*MockBookingRepository)(nil).SelectForUpdate), ctx, tenant, id)
+}
+
+// Cancel mocks base method
+func (m *MockBookingRepository) Cancel(ctx context.Context, booking *models.Booking, entry *models.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, booking, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel
+func (mr *MockBookingRepositoryMockRecorder) Cancel(ctx, booking, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockBookingRepository)(nil).Cancel), ctx, booking, entry)
+}
+
+// UpdateRefund mocks base method
+func (m *MockBookingRepository) UpdateRefund(ctx context.Context, booking *models.Booking, entry *models.AuditEntry, events ...*models.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, booking, entry}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateRefund", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteByID indicates an expected call of DeleteByID
-func (mr *MockBookingRepositoryMockRecorder) DeleteByID(id interface{}) *gomock.Call {
+// UpdateRefund indicates an expected call of UpdateRefund
+func (mr *MockBookingRepositoryMockRecorder) UpdateRefund(ctx, booking, entry interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockBookingRepository)(nil).DeleteByID), id)
+	varargs := append([]interface{}{ctx, booking, entry}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefund", reflect.TypeOf((*MockBookingRepository)(nil).UpdateRefund), varargs...)
 }
 
 // SelectRoomBookings mocks base method
-func (m *MockBookingRepository) SelectRoomBookings(roomID uint64) ([]*models.Booking, error) {
+func (m *MockBookingRepository) SelectRoomBookings(ctx context.Context, tenant, roomID uint64) ([]*models.Booking, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectRoomBookings", roomID)
+	ret := m.ctrl.Call(m, "SelectRoomBookings", ctx, tenant, roomID)
 	ret0, _ := ret[0].([]*models.Booking)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SelectRoomBookings indicates an expected call of SelectRoomBookings
-func (mr *MockBookingRepositoryMockRecorder) SelectRoomBookings(roomID interface{}) *gomock.Call {
+func (mr *MockBookingRepositoryMockRecorder) SelectRoomBookings(ctx, tenant, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRoomBookings", reflect.TypeOf((*MockBookingRepository)(nil).SelectRoomBookings), ctx, tenant, roomID)
+}
+
+// SelectRoomCalendar mocks base method
+func (m *MockBookingRepository) SelectRoomCalendar(ctx context.Context, tenant, roomID uint64) ([]*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectRoomCalendar", ctx, tenant, roomID)
+	ret0, _ := ret[0].([]*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectRoomCalendar indicates an expected call of SelectRoomCalendar
+func (mr *MockBookingRepositoryMockRecorder) SelectRoomCalendar(ctx, tenant, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRoomCalendar", reflect.TypeOf((*MockBookingRepository)(nil).SelectRoomCalendar), ctx, tenant, roomID)
+}
+
+// Confirm mocks base method
+func (m *MockBookingRepository) Confirm(ctx context.Context, tenant, id uint64, entry *models.AuditEntry, events ...*models.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, tenant, id, entry}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Confirm", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm indicates an expected call of Confirm
+func (mr *MockBookingRepositoryMockRecorder) Confirm(ctx, tenant, id, entry interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, tenant, id, entry}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockBookingRepository)(nil).Confirm), varargs...)
+}
+
+// CancelExpiredHolds mocks base method
+func (m *MockBookingRepository) CancelExpiredHolds(ctx context.Context, actor *models.Actor) ([]*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelExpiredHolds", ctx, actor)
+	ret0, _ := ret[0].([]*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelExpiredHolds indicates an expected call of CancelExpiredHolds
+func (mr *MockBookingRepositoryMockRecorder) CancelExpiredHolds(ctx, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRoomBookings", reflect.TypeOf((*MockBookingRepository)(nil).SelectRoomBookings), roomID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelExpiredHolds", reflect.TypeOf((*MockBookingRepository)(nil).CancelExpiredHolds), ctx, actor)
 }
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	errors "github.com/booking_backend/internal/helpers/errors"
 	models "github.com/booking_backend/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,44 +36,148 @@ func (m *MockBookingUseCase) EXPECT() *MockBookingUseCaseMockRecorder {
 }
 
 // CreateBooking mocks base method
-func (m *MockBookingUseCase) CreateBooking(booking *models.Booking) *errors.Error {
+func (m *MockBookingUseCase) CreateBooking(ctx context.Context, actor *models.Actor, booking *models.Booking, paymentToken string) *errors.Error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateBooking", booking)
+	ret := m.ctrl.Call(m, "CreateBooking", ctx, actor, booking, paymentToken)
 	ret0, _ := ret[0].(*errors.Error)
 	return ret0
 }
 
 // CreateBooking indicates an expected call of CreateBooking
-func (mr *MockBookingUseCaseMockRecorder) CreateBooking(booking interface{}) *gomock.Call {
+func (mr *MockBookingUseCaseMockRecorder) CreateBooking(ctx, actor, booking, paymentToken interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooking", reflect.TypeOf((*MockBookingUseCase)(nil).CreateBooking), booking)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooking", reflect.TypeOf((*MockBookingUseCase)(nil).CreateBooking), ctx, actor, booking, paymentToken)
 }
 
-// DeleteBooking mocks base method
-func (m *MockBookingUseCase) DeleteBooking(id uint64) *errors.Error {
+// GetQuote mocks base method
+func (m *MockBookingUseCase) GetQuote(ctx context.Context, tenant, roomID uint64, dateStart, dateEnd string, guests uint64, promoCode string) (*models.Quote, *errors.Error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBooking", id)
-	ret0, _ := ret[0].(*errors.Error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetQuote", ctx, tenant, roomID, dateStart, dateEnd, guests, promoCode)
+	ret0, _ := ret[0].(*models.Quote)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote
+func (mr *MockBookingUseCaseMockRecorder) GetQuote(ctx, tenant, roomID, dateStart, dateEnd, guests, promoCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockBookingUseCase)(nil).GetQuote), ctx, tenant, roomID, dateStart, dateEnd, guests, promoCode)
+}
+
+// GetBooking mocks base method
+func (m *MockBookingUseCase) GetBooking(ctx context.Context, tenant, id uint64) (*models.Booking, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBooking", ctx, tenant, id)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
 }
 
-// DeleteBooking indicates an expected call of DeleteBooking
-func (mr *MockBookingUseCaseMockRecorder) DeleteBooking(id interface{}) *gomock.Call {
+// GetBooking indicates an expected call of GetBooking
+func (mr *MockBookingUseCaseMockRecorder) GetBooking(ctx, tenant, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBooking", reflect.TypeOf((*MockBookingUseCase)(nil).DeleteBooking), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBooking", reflect.TypeOf((*MockBookingUseCase)(nil).GetBooking), ctx, tenant, id)
+}
+
+// CancelBooking mocks base method
+func (m *MockBookingUseCase) CancelBooking(ctx context.Context, tenant uint64, actor *models.Actor, id, version uint64) (*models.Booking, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBooking", ctx, tenant, actor, id, version)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// CancelBooking indicates an expected call of CancelBooking
+func (mr *MockBookingUseCaseMockRecorder) CancelBooking(ctx, tenant, actor, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBooking", reflect.TypeOf((*MockBookingUseCase)(nil).CancelBooking), ctx, tenant, actor, id, version)
+}
+
+// CancelBookings mocks base method
+func (m *MockBookingUseCase) CancelBookings(ctx context.Context, tenant uint64, actor *models.Actor, bookings []*models.Booking) ([]*models.Booking, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBookings", ctx, tenant, actor, bookings)
+	ret0, _ := ret[0].([]*models.Booking)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// CancelBookings indicates an expected call of CancelBookings
+func (mr *MockBookingUseCaseMockRecorder) CancelBookings(ctx, tenant, actor, bookings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBookings", reflect.TypeOf((*MockBookingUseCase)(nil).CancelBookings), ctx, tenant, actor, bookings)
+}
+
+// RetryRefund mocks base method
+func (m *MockBookingUseCase) RetryRefund(ctx context.Context, tenant uint64, actor *models.Actor, id uint64) (*models.Booking, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryRefund", ctx, tenant, actor, id)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// RetryRefund indicates an expected call of RetryRefund
+func (mr *MockBookingUseCaseMockRecorder) RetryRefund(ctx, tenant, actor, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryRefund", reflect.TypeOf((*MockBookingUseCase)(nil).RetryRefund), ctx, tenant, actor, id)
 }
 
 // GetRoomBookings mocks base method
-func (m *MockBookingUseCase) GetRoomBookings(roomID uint64) ([]*models.Booking, *errors.Error) {
+func (m *MockBookingUseCase) GetRoomBookings(ctx context.Context, tenant, roomID uint64) ([]*models.Booking, *errors.Error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRoomBookings", roomID)
+	ret := m.ctrl.Call(m, "GetRoomBookings", ctx, tenant, roomID)
 	ret0, _ := ret[0].([]*models.Booking)
 	ret1, _ := ret[1].(*errors.Error)
 	return ret0, ret1
 }
 
 // GetRoomBookings indicates an expected call of GetRoomBookings
-func (mr *MockBookingUseCaseMockRecorder) GetRoomBookings(roomID interface{}) *gomock.Call {
+func (mr *MockBookingUseCaseMockRecorder) GetRoomBookings(ctx, tenant, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomBookings", reflect.TypeOf((*MockBookingUseCase)(nil).GetRoomBookings), ctx, tenant, roomID)
+}
+
+// ConfirmBooking mocks base method
+func (m *MockBookingUseCase) ConfirmBooking(ctx context.Context, tenant uint64, actor *models.Actor, id, version uint64, paymentToken string) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmBooking", ctx, tenant, actor, id, version, paymentToken)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// ConfirmBooking indicates an expected call of ConfirmBooking
+func (mr *MockBookingUseCaseMockRecorder) ConfirmBooking(ctx, tenant, actor, id, version, paymentToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmBooking", reflect.TypeOf((*MockBookingUseCase)(nil).ConfirmBooking), ctx, tenant, actor, id, version, paymentToken)
+}
+
+// ConfirmHolds mocks base method
+func (m *MockBookingUseCase) ConfirmHolds(ctx context.Context, actor *models.Actor, held []*models.Booking, paymentToken string) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmHolds", ctx, actor, held, paymentToken)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// ConfirmHolds indicates an expected call of ConfirmHolds
+func (mr *MockBookingUseCaseMockRecorder) ConfirmHolds(ctx, actor, held, paymentToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmHolds", reflect.TypeOf((*MockBookingUseCase)(nil).ConfirmHolds), ctx, actor, held, paymentToken)
+}
+
+// ReleaseExpiredHolds mocks base method
+func (m *MockBookingUseCase) ReleaseExpiredHolds(ctx context.Context) (int64, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpiredHolds", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// ReleaseExpiredHolds indicates an expected call of ReleaseExpiredHolds
+func (mr *MockBookingUseCaseMockRecorder) ReleaseExpiredHolds(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomBookings", reflect.TypeOf((*MockBookingUseCase)(nil).GetRoomBookings), roomID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpiredHolds", reflect.TypeOf((*MockBookingUseCase)(nil).ReleaseExpiredHolds), ctx)
 }
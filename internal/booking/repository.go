@@ -1,10 +1,30 @@
 package booking
 
-import "github.com/booking_backend/internal/models"
+import (
+	"context"
+	"errors"
+	"github.com/booking_backend/internal/models"
+)
+
+var (
+	ErrRoomIsOccupied = errors.New("room is occupied on these dates")
+	ErrHoldExpired    = errors.New("hold has expired")
+	// ErrVersionMismatch means the booking has been changed since it was read
+	ErrVersionMismatch = errors.New("booking has been changed")
+)
 
 type BookingRepository interface {
-	Insert(booking *models.Booking) error
-	SelectByID(id uint64) (*models.Booking, error)
-	DeleteByID(id uint64) error
-	SelectRoomBookings(roomID uint64) ([]*models.Booking, error)
+	Insert(ctx context.Context, booking *models.Booking, entry *models.AuditEntry,
+		events ...*models.Event) error
+	SelectByID(ctx context.Context, tenant uint64, id uint64) (*models.Booking, error)
+	// SelectForUpdate locks the booking until the end of the unit of work running with ctx
+	SelectForUpdate(ctx context.Context, tenant uint64, id uint64) (*models.Booking, error)
+	Cancel(ctx context.Context, booking *models.Booking, entry *models.AuditEntry) error
+	UpdateRefund(ctx context.Context, booking *models.Booking, entry *models.AuditEntry,
+		events ...*models.Event) error
+	SelectRoomBookings(ctx context.Context, tenant uint64, roomID uint64) ([]*models.Booking, error)
+	SelectRoomCalendar(ctx context.Context, tenant uint64, roomID uint64) ([]*models.Booking, error)
+	Confirm(ctx context.Context, tenant uint64, id uint64, entry *models.AuditEntry,
+		events ...*models.Event) error
+	CancelExpiredHolds(ctx context.Context, actor *models.Actor) ([]*models.Booking, error)
 }
@@ -3,97 +3,301 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	auditRepository "github.com/booking_backend/internal/audit/repository"
 	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/helpers/query"
+	"github.com/booking_backend/internal/helpers/transaction"
 	"github.com/booking_backend/internal/models"
+	outboxRepository "github.com/booking_backend/internal/outbox/repository"
+	promoRepository "github.com/booking_backend/internal/promo/repository"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
 type BookingRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
 }
 
-func NewBookingRepository(db *sql.DB) booking.BookingRepository {
-	return &BookingRepository{db: db}
+// NewBookingRepository bounds every call of the repository with the timeout
+func NewBookingRepository(db *sql.DB, timeout time.Duration) booking.BookingRepository {
+	return &BookingRepository{db: db, timeout: timeout}
 }
 
-func (rep *BookingRepository) Insert(booking *models.Booking) error {
-	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if rollbackErr := transaction.Rollback(ctx, tx); rollbackErr != nil {
+		logrus.Info(rollbackErr)
+	}
+}
+
+// commit writes the audit entry and the events of the change made within
+// the transaction and commits them all
+func commit(ctx context.Context, tx *sql.Tx, entry *models.AuditEntry, events ...*models.Event) error {
+	if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	for _, event := range events {
+		if err := outboxRepository.InsertEvent(ctx, tx, event); err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+	}
+	return transaction.Commit(ctx, tx)
+}
+
+// CheckRoomIsFree locks the room until the end of the transaction and makes
+// sure that neither a confirmed booking, an unexpired hold nor a block overlaps
+// the dates. Booking with exceptID is not taken into account, so it can be rescheduled.
+// Deleted rooms are never free, sql.ErrNoRows is returned for them
+func CheckRoomIsFree(ctx context.Context, tx *sql.Tx, roomID uint64,
+	dateStart string, dateEnd string, exceptID uint64) error {
+	var id uint64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM rooms
+		WHERE id=$1 AND deleted_at IS NULL
+		FOR UPDATE`, roomID).
+		Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	var occupied bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM bookings
+			WHERE room=$1 AND id<>$2
+				AND date_start < $4 AND date_end > $3
+				AND (status=$5 OR (status=$6 AND hold_expires > now())))
+			OR EXISTS(
+			SELECT 1
+			FROM room_blocks
+			WHERE room=$1 AND date_start < $4 AND date_end > $3)`,
+		roomID, exceptID, dateStart, dateEnd,
+		models.BookingStatusConfirmed, models.BookingStatusHeld).
+		Scan(&occupied)
+	if err != nil {
+		return err
+	}
+	if occupied {
+		return booking.ErrRoomIsOccupied
+	}
+	return nil
+}
+
+// touchReservation moves the reservation of the booking, if any, to the next
+// version along with the booking
+func touchReservation(ctx context.Context, tx *sql.Tx, id uint64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE reservations
+		SET version=version+1
+		WHERE id=(SELECT reservation FROM bookings WHERE id=$1)`, id)
+	return err
+}
+
+// EncodeQuote turns the price breakdown into a jsonb value, NULL when there is none
+func EncodeQuote(quote *models.Quote) (interface{}, error) {
+	if quote == nil {
+		return nil, nil
+	}
+	return json.Marshal(quote)
+}
+
+// InsertBooking stores the booking within the transaction once the room
+// turns out to be free and the promo code has uses left. The booking is
+// given the tenant of the room
+func InsertBooking(ctx context.Context, tx *sql.Tx, booking *models.Booking) error {
+	quote, err := EncodeQuote(booking.Quote)
 	if err != nil {
 		return err
 	}
 
-	err = tx.QueryRow(`
-		INSERT INTO bookings(date_start, date_end, room) 
-		VALUES ($1, $2, $3) RETURNING id`,
-		booking.DateStart, booking.DateEnd, booking.Room).
-		Scan(&booking.ID)
+	err = CheckRoomIsFree(ctx, tx, booking.Room, booking.DateStart, booking.DateEnd, 0)
 	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			logrus.Info(rollbackErr)
+		return err
+	}
+
+	if booking.PromoCode != "" {
+		if err := promoRepository.Redeem(tx, booking.Tenant, booking.PromoCode); err != nil {
+			return err
 		}
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO bookings(date_start, date_end, room, status, guests, guest, email, language,
+			amount, quote, promo_code, hold_expires, tenant)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12,
+			(SELECT tenant FROM rooms WHERE id=$3)) RETURNING id, version, tenant`,
+		booking.DateStart, booking.DateEnd, booking.Room, booking.Status, booking.Guests,
+		booking.Guest, booking.Email, booking.Language, booking.Amount, quote,
+		booking.PromoCode, booking.HoldExpires).
+		Scan(&booking.ID, &booking.Version, &booking.Tenant)
+}
+
+func (rep *BookingRepository) Insert(ctx context.Context, booking *models.Booking,
+	entry *models.AuditEntry, events ...*models.Event) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	err = InsertBooking(ctx, tx, booking)
+	if err != nil {
+		rollback(ctx, tx)
 		return err
 	}
 
-	return nil
+	entry.EntityID = booking.ID
+	return commit(ctx, tx, entry, events...)
 }
 
-func (rep *BookingRepository) SelectByID(id uint64) (*models.Booking, error) {
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBooking(row scanner) (*models.Booking, error) {
 	booking := &models.Booking{}
-	err := rep.db.QueryRow(`
-		SELECT id, date_start, date_end, room
-		FROM bookings
-		WHERE id=$1`, id).
-		Scan(&booking.ID, &booking.DateStart, &booking.DateEnd, &booking.Room)
-	if err != nil {
+	var reservation sql.NullInt64
+	var holdExpires, cancelled sql.NullTime
+	var quote []byte
+	if err := row.Scan(&booking.ID, &booking.DateStart, &booking.DateEnd,
+		&booking.Room, &reservation, &booking.Status, &booking.Guests, &booking.Guest,
+		&booking.Email, &booking.Language, &booking.Amount, &quote,
+		&booking.PromoCode, &holdExpires,
+		&booking.CancellationFee, &cancelled,
+		&booking.RefundStatus, &booking.RefundAmount, &booking.Version, &booking.Tenant,
+		&booking.Updated); err != nil {
 		return nil, err
 	}
+	booking.Reservation = uint64(reservation.Int64)
+	if quote != nil {
+		booking.Quote = &models.Quote{}
+		if err := json.Unmarshal(quote, booking.Quote); err != nil {
+			return nil, err
+		}
+	}
+	if holdExpires.Valid {
+		booking.HoldExpires = &holdExpires.Time
+	}
+	if cancelled.Valid {
+		booking.Cancelled = &cancelled.Time
+	}
 	return booking, nil
 }
 
-func (rep *BookingRepository) DeleteByID(id uint64) error {
-	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+func (rep *BookingRepository) SelectByID(ctx context.Context, tenant uint64,
+	id uint64) (*models.Booking, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	row := transaction.Querier(ctx, rep.db).QueryRowContext(ctx, `
+		SELECT id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated
+		FROM bookings
+		WHERE id=$1 AND tenant=$2 AND deleted_at IS NULL`, id, tenant)
+	return scanBooking(row)
+}
+
+func (rep *BookingRepository) SelectForUpdate(ctx context.Context, tenant uint64,
+	id uint64) (*models.Booking, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	row := transaction.Querier(ctx, rep.db).QueryRowContext(ctx, `
+		SELECT id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated
+		FROM bookings
+		WHERE id=$1 AND tenant=$2 AND deleted_at IS NULL
+		FOR UPDATE`, id, tenant)
+	return scanBooking(row)
+}
+
+// Cancel keeps the booking row to remember the charged cancellation fee. The
+// booking is cancelled only in the version it was read in. The cancellation is
+// announced by UpdateRefund, once the refund is known
+func (rep *BookingRepository) Cancel(ctx context.Context, cancelled *models.Booking,
+	entry *models.AuditEntry) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec(`
-		DELETE
-		FROM bookings
-		WHERE id=$1`, id)
+	res, err := tx.ExecContext(ctx, `
+		UPDATE bookings
+		SET status=$1, cancellation_fee=$2, cancelled=$3, hold_expires=NULL, version=version+1
+		WHERE id=$4 AND tenant=$5 AND version=$6`,
+		models.BookingStatusCancelled, cancelled.CancellationFee, cancelled.Cancelled,
+		cancelled.ID, cancelled.Tenant, cancelled.Version)
 	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			logrus.Info(rollbackErr)
-		}
+		rollback(ctx, tx)
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if affected == 0 {
+		rollback(ctx, tx)
+		return booking.ErrVersionMismatch
+	}
+	cancelled.Version++
+	if err = touchReservation(ctx, tx, cancelled.ID); err != nil {
+		rollback(ctx, tx)
 		return err
 	}
 
-	return nil
+	return commit(ctx, tx, entry)
 }
 
-func (rep *BookingRepository) SelectRoomBookings(roomID uint64) ([]*models.Booking, error) {
-	rows, err := rep.db.Query(`
-		SELECT id, date_start, date_end, room
-		FROM bookings
-		WHERE room=$1
-		ORDER BY date_start`, roomID)
+func (rep *BookingRepository) UpdateRefund(ctx context.Context, booking *models.Booking,
+	entry *models.AuditEntry, events ...*models.Event) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
 	if err != nil {
-		return nil, err
+		return err
 	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE bookings
+		SET refund_status=$1, refund_amount=$2, version=version+1
+		WHERE id=$3 AND tenant=$4
+		RETURNING version`,
+		booking.RefundStatus, booking.RefundAmount, booking.ID, booking.Tenant).
+		Scan(&booking.Version)
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if err = touchReservation(ctx, tx, booking.ID); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	return commit(ctx, tx, entry, events...)
+}
+
+func scanBookings(rows *sql.Rows) ([]*models.Booking, error) {
 	defer rows.Close()
 
 	var bookings []*models.Booking
 	for rows.Next() {
-		booking := &models.Booking{}
-		if err := rows.Scan(&booking.ID, &booking.DateStart,
-			&booking.DateEnd, &booking.Room); err != nil {
+		booking, err := scanBooking(rows)
+		if err != nil {
 			return nil, err
 		}
 		bookings = append(bookings, booking)
@@ -104,3 +308,184 @@ func (rep *BookingRepository) SelectRoomBookings(roomID uint64) ([]*models.Booki
 	}
 	return bookings, nil
 }
+
+func (rep *BookingRepository) SelectRoomBookings(ctx context.Context, tenant uint64,
+	roomID uint64) ([]*models.Booking, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	rows, err := transaction.Querier(ctx, rep.db).QueryContext(ctx, `
+		SELECT id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated
+		FROM bookings
+		WHERE room=$1 AND tenant=$2 AND status<>$3 AND deleted_at IS NULL
+		ORDER BY date_start`, roomID, tenant, models.BookingStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// SelectRoomCalendar returns the bookings of the room together with the
+// cancelled ones, so the calendar feed can withdraw them from the channels
+func (rep *BookingRepository) SelectRoomCalendar(ctx context.Context, tenant uint64,
+	roomID uint64) ([]*models.Booking, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	rows, err := transaction.Querier(ctx, rep.db).QueryContext(ctx, `
+		SELECT id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated
+		FROM bookings
+		WHERE room=$1 AND tenant=$2 AND deleted_at IS NULL
+		ORDER BY date_start`, roomID, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// DeleteRoomBookings marks the bookings of the room deleted within the
+// transaction. The bookings keep deletedAt of the room, so they can be told
+// apart from the ones deleted before
+func DeleteRoomBookings(ctx context.Context, tx *sql.Tx, tenant uint64, roomID uint64,
+	deletedAt time.Time) ([]*models.Booking, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE bookings
+		SET deleted_at=$3, version=version+1
+		WHERE room=$1 AND tenant=$2 AND deleted_at IS NULL
+		RETURNING id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated`, roomID, tenant, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// RestoreRoomBookings brings back the bookings deleted together with the room
+func RestoreRoomBookings(ctx context.Context, tx *sql.Tx, tenant uint64, roomID uint64,
+	deletedAt time.Time) ([]*models.Booking, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE bookings
+		SET deleted_at=NULL, version=version+1
+		WHERE room=$1 AND tenant=$2 AND deleted_at=$3
+		RETURNING id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated`, roomID, tenant, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// SelectArrivals returns the confirmed bookings of the tenant starting on the
+// date within the transaction, a non-zero id narrows them down to that booking
+func SelectArrivals(ctx context.Context, tx *sql.Tx, tenant uint64, date string,
+	id uint64) ([]*models.Booking, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated
+		FROM bookings
+		WHERE tenant=$1 AND date_start=$2 AND status=$3 AND deleted_at IS NULL AND ($4=0 OR id=$4)
+		ORDER BY room, id`, tenant, date, models.BookingStatusConfirmed, id)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// Confirm turns an unexpired hold into a confirmed booking
+func (rep *BookingRepository) Confirm(ctx context.Context, tenant uint64, id uint64,
+	entry *models.AuditEntry, events ...*models.Event) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE bookings
+		SET status=$1, hold_expires=NULL, version=version+1
+		WHERE id=$2 AND tenant=$3 AND status=$4 AND hold_expires > now()`,
+		models.BookingStatusConfirmed, id, tenant, models.BookingStatusHeld)
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if affected == 0 {
+		rollback(ctx, tx)
+		return booking.ErrHoldExpired
+	}
+	if err = touchReservation(ctx, tx, id); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	return commit(ctx, tx, entry, events...)
+}
+
+// CancelExpiredHolds is run by the sweeper for all the tenants at once. The
+// holds are kept cancelled along with their payments, hold_expires tells when
+// they ran out. The cancellation is announced once the authorization is released
+func (rep *BookingRepository) CancelExpiredHolds(ctx context.Context,
+	actor *models.Actor) ([]*models.Booking, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE bookings
+		SET status=$1, cancelled=now(), version=version+1
+		WHERE status=$2 AND hold_expires <= now()
+		RETURNING id, date_start, date_end, room, reservation, status, guests, guest, email, language, amount, quote,
+			COALESCE(promo_code, ''), hold_expires, cancellation_fee, cancelled, refund_status, refund_amount,
+			version, tenant, updated`,
+		models.BookingStatusCancelled, models.BookingStatusHeld)
+	if err != nil {
+		rollback(ctx, tx)
+		return nil, err
+	}
+	released, err := scanBookings(rows)
+	if err != nil {
+		rollback(ctx, tx)
+		return nil, err
+	}
+
+	for _, cancelled := range released {
+		if err := touchReservation(ctx, tx, cancelled.ID); err != nil {
+			rollback(ctx, tx)
+			return nil, err
+		}
+		held := *cancelled
+		held.Status = models.BookingStatusHeld
+		held.Cancelled = nil
+		held.Version--
+		entry := actor.Entry(cancelled.Tenant, models.AuditActionUpdate, models.AuditEntityBooking,
+			cancelled.ID, &held, cancelled)
+		if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+			rollback(ctx, tx)
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(ctx, tx); err != nil {
+		return nil, err
+	}
+	return released, nil
+}
@@ -1,15 +1,25 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/booking"
 	"github.com/booking_backend/internal/booking/mocks"
+	"github.com/booking_backend/internal/helpers/query"
 	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/promo"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
+const otherTenant uint64 = 2
+
+var ctx = context.Background()
+
 var bookingModel = &models.Booking{
+	Tenant:    models.DefaultTenantID,
 	ID:        1,
 	DateStart: "2020-12-10",
 	DateEnd:   "2021-12-10",
@@ -22,23 +32,27 @@ var firstRoom = &models.Room{
 
 var bookingsOfFirstRoom = []*models.Booking{
 	&models.Booking{
+		Tenant:    models.DefaultTenantID,
 		ID:        1,
 		DateStart: "2020-12-10",
 		DateEnd:   "2021-12-10",
 		Room:      1,
 	},
 	&models.Booking{
+		Tenant:    models.DefaultTenantID,
 		ID:        5,
 		DateStart: "2020-12-10",
 		DateEnd:   "2021-12-10",
 		Room:      1,
 	},
 	&models.Booking{
+		Tenant:    models.DefaultTenantID,
 		ID:        7,
 		DateStart: "2020-12-10",
 		DateEnd:   "2021-12-10",
 		Room:      1,
 	}, &models.Booking{
+		Tenant:    models.DefaultTenantID,
 		ID:        97,
 		DateStart: "2020-12-10",
 		DateEnd:   "2021-12-10",
@@ -54,11 +68,28 @@ func TestBookingRepository_Insert(t *testing.T) {
 	}
 	defer db.Close()
 
-	bookingPgRep := NewBookingRepository(db)
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
 
-	mocks.MockInsertSuccess(mock, bookingModel)
-	err = bookingPgRep.Insert(bookingModel)
+	entry := &models.AuditEntry{
+		Tenant: models.DefaultTenantID,
+		Actor:  "manager@hotel",
+		Action: models.AuditActionCreate,
+		Entity: models.AuditEntityBooking,
+		After:  bookingModel,
+	}
+	event := &models.Event{ID: 3, Tenant: models.DefaultTenantID,
+		Type: models.EventBookingCreated, Payload: bookingModel}
+	mocks.MockInsertSuccess(mock, bookingModel, &models.AuditEntry{
+		Tenant:   models.DefaultTenantID,
+		Actor:    "manager@hotel",
+		Action:   models.AuditActionCreate,
+		Entity:   models.AuditEntityBooking,
+		EntityID: bookingModel.ID,
+	}, event)
+	err = bookingPgRep.Insert(ctx, bookingModel, entry, event)
 	assert.NoError(t, err)
+	assert.Equal(t, bookingModel.ID, entry.EntityID)
+	assert.NotZero(t, event.ID)
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
@@ -73,10 +104,10 @@ func TestBookingRepository_SelectByID(t *testing.T) {
 	}
 	defer db.Close()
 
-	bookingPgRep := NewBookingRepository(db)
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
 
 	mocks.MockSelectReturnRows(mock, bookingModel)
-	resultBooking, err := bookingPgRep.SelectByID(bookingModel.ID)
+	resultBooking, err := bookingPgRep.SelectByID(ctx, models.DefaultTenantID, bookingModel.ID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, bookingModel, resultBooking)
@@ -85,6 +116,92 @@ func TestBookingRepository_SelectByID(t *testing.T) {
 	}
 }
 
+func TestBookingRepository_SelectForUpdate(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	mocks.MockSelectForUpdate(mock, bookingModel)
+	resultBooking, err := bookingPgRep.SelectForUpdate(ctx, models.DefaultTenantID, bookingModel.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, bookingModel, resultBooking)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_Insert_PromoCodeExhausted(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+	promoBooking := &models.Booking{
+		DateStart: "2020-12-10",
+		DateEnd:   "2020-12-12",
+		Room:      4,
+		PromoCode: "WINTER",
+	}
+
+	mocks.MockInsertPromoCodeExhausted(mock, promoBooking)
+	err = bookingPgRep.Insert(ctx, promoBooking, &models.AuditEntry{}, &models.Event{})
+
+	assert.Equal(t, promo.ErrExhausted, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_SelectByID_WithQuote(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+	quoted := &models.Booking{
+		Tenant:    models.DefaultTenantID,
+		ID:        2,
+		DateStart: "2020-12-10",
+		DateEnd:   "2020-12-12",
+		Room:      4,
+		Status:    models.BookingStatusConfirmed,
+		Guests:    2,
+		Amount:    1240,
+		Quote: &models.Quote{
+			Nights:        2,
+			Guests:        2,
+			NightPrice:    500,
+			Accommodation: 1000,
+			TouristTax:    40,
+			VATPercent:    20,
+			TaxMode:       models.TaxModeExclusive,
+			VAT:           200,
+			Total:         1240,
+		},
+	}
+
+	mocks.MockSelectReturnRows(mock, quoted)
+	resultBooking, err := bookingPgRep.SelectByID(ctx, models.DefaultTenantID, quoted.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, quoted, resultBooking)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestBookingRepository_SelectByID_ErrNoRows(t *testing.T) {
 	t.Parallel()
 	db, mock, err := sqlmock.New()
@@ -93,10 +210,10 @@ func TestBookingRepository_SelectByID_ErrNoRows(t *testing.T) {
 	}
 	defer db.Close()
 
-	bookingPgRep := NewBookingRepository(db)
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
 
-	mocks.MockSelectBookingByIDReturnErrNoRows(mock, bookingModel.ID)
-	resultBooking, err := bookingPgRep.SelectByID(bookingModel.ID)
+	mocks.MockSelectBookingByIDReturnErrNoRows(mock, models.DefaultTenantID, bookingModel.ID)
+	resultBooking, err := bookingPgRep.SelectByID(ctx, models.DefaultTenantID, bookingModel.ID)
 
 	assert.Error(t, sql.ErrNoRows)
 	assert.Nil(t, resultBooking)
@@ -113,10 +230,10 @@ func TestBookingRepository_SelectRoomBookings(t *testing.T) {
 	}
 	defer db.Close()
 
-	bookingPgRep := NewBookingRepository(db)
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
 
-	mocks.MockSelectBookingList(mock, firstRoom.ID, bookingsOfFirstRoom)
-	resultBooking, err := bookingPgRep.SelectRoomBookings(firstRoom.ID)
+	mocks.MockSelectBookingList(mock, models.DefaultTenantID, firstRoom.ID, bookingsOfFirstRoom)
+	resultBooking, err := bookingPgRep.SelectRoomBookings(ctx, models.DefaultTenantID, firstRoom.ID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, bookingsOfFirstRoom, resultBooking)
@@ -133,10 +250,10 @@ func TestBookingRepository_SelectRoomBookings_Nil(t *testing.T) {
 	}
 	defer db.Close()
 
-	bookingPgRep := NewBookingRepository(db)
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
 
-	mocks.MockSelectBookingList(mock, firstRoom.ID, nil)
-	resultBooking, err := bookingPgRep.SelectRoomBookings(firstRoom.ID)
+	mocks.MockSelectBookingList(mock, models.DefaultTenantID, firstRoom.ID, nil)
+	resultBooking, err := bookingPgRep.SelectRoomBookings(ctx, models.DefaultTenantID, firstRoom.ID)
 
 	assert.NoError(t, err)
 	assert.Nil(t, resultBooking)
@@ -145,7 +262,7 @@ func TestBookingRepository_SelectRoomBookings_Nil(t *testing.T) {
 	}
 }
 
-func TestBookingRepository_DeleteByID(t *testing.T) {
+func TestBookingRepository_SelectRoomCalendar(t *testing.T) {
 	t.Parallel()
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -153,12 +270,317 @@ func TestBookingRepository_DeleteByID(t *testing.T) {
 	}
 	defer db.Close()
 
-	bookingPgRep := NewBookingRepository(db)
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	cancelled := *bookingsOfFirstRoom[0]
+	cancelled.Status = models.BookingStatusCancelled
+	cancelled.Version = 2
+	cancelled.Updated = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	bookings := append([]*models.Booking{&cancelled}, bookingsOfFirstRoom[1:]...)
 
-	mocks.MockDeleteSuccess(mock, bookingModel.ID)
-	err = bookingPgRep.DeleteByID(bookingModel.ID)
+	mocks.MockSelectRoomCalendar(mock, models.DefaultTenantID, firstRoom.ID, bookings)
+	resultBooking, err := bookingPgRep.SelectRoomCalendar(ctx, models.DefaultTenantID, firstRoom.ID)
 
 	assert.NoError(t, err)
+	assert.Equal(t, bookings, resultBooking)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_Cancel(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	cancelled := time.Now()
+	cancelledBooking := &models.Booking{
+		Tenant:          models.DefaultTenantID,
+		ID:              bookingModel.ID,
+		Status:          models.BookingStatusCancelled,
+		CancellationFee: 500,
+		Cancelled:       &cancelled,
+	}
+
+	entry := &models.AuditEntry{
+		Tenant:    models.DefaultTenantID,
+		Actor:     "manager@hotel",
+		Action:    models.AuditActionUpdate,
+		Entity:    models.AuditEntityBooking,
+		EntityID:  cancelledBooking.ID,
+		After:     cancelledBooking,
+		RequestID: "request",
+	}
+	mocks.MockCancelSuccess(mock, cancelledBooking, entry)
+	err = bookingPgRep.Cancel(ctx, cancelledBooking, entry)
+
+	assert.NoError(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_UpdateRefund(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	refunded := &models.Booking{
+		Tenant:       models.DefaultTenantID,
+		ID:           bookingModel.ID,
+		Status:       models.BookingStatusCancelled,
+		RefundStatus: models.RefundStatusRefunded,
+		RefundAmount: 3500,
+		Version:      2,
+	}
+	entry := &models.AuditEntry{
+		Tenant:   models.DefaultTenantID,
+		Actor:    "manager@hotel",
+		Action:   models.AuditActionUpdate,
+		Entity:   models.AuditEntityBooking,
+		EntityID: refunded.ID,
+		After:    refunded,
+	}
+	// The cancellation is announced with the refund in the payload
+	event := models.NewEvent(models.DefaultTenantID, models.EventBookingCancelled, refunded)
+	mocks.MockUpdateRefundSuccess(mock, refunded, entry, event)
+	err = bookingPgRep.UpdateRefund(ctx, refunded, entry, event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), refunded.Version)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_Cancel_VersionMismatch(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	cancelled := time.Now()
+	cancelledBooking := &models.Booking{
+		Tenant:    models.DefaultTenantID,
+		ID:        bookingModel.ID,
+		Status:    models.BookingStatusCancelled,
+		Cancelled: &cancelled,
+		Version:   1,
+	}
+	mocks.MockCancelVersionMismatch(mock, cancelledBooking)
+	err = bookingPgRep.Cancel(ctx, cancelledBooking, &models.AuditEntry{})
+
+	assert.Equal(t, booking.ErrVersionMismatch, err)
+	assert.Equal(t, uint64(1), cancelledBooking.Version)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_Insert_RoomIsOccupied(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	mocks.MockInsertRoomIsOccupied(mock, bookingModel)
+	err = bookingPgRep.Insert(ctx, bookingModel, &models.AuditEntry{}, &models.Event{})
+
+	assert.Equal(t, booking.ErrRoomIsOccupied, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_Confirm_HoldExpired(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	mocks.MockConfirmHoldExpired(mock, models.DefaultTenantID, bookingModel.ID)
+	err = bookingPgRep.Confirm(ctx, models.DefaultTenantID, bookingModel.ID, &models.AuditEntry{})
+
+	assert.Equal(t, booking.ErrHoldExpired, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_SelectByID_OtherTenant(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	mocks.MockSelectBookingByIDReturnErrNoRows(mock, otherTenant, bookingModel.ID)
+	resultBooking, err := bookingPgRep.SelectByID(ctx, otherTenant, bookingModel.ID)
+
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Nil(t, resultBooking)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_SelectRoomBookings_OtherTenant(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	mocks.MockSelectBookingList(mock, otherTenant, firstRoom.ID, nil)
+	resultBooking, err := bookingPgRep.SelectRoomBookings(ctx, otherTenant, firstRoom.ID)
+
+	assert.NoError(t, err)
+	assert.Nil(t, resultBooking)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_Confirm_OtherTenant(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	// The hold of another tenant is not touched
+	mocks.MockConfirmHoldExpired(mock, otherTenant, bookingModel.ID)
+	err = bookingPgRep.Confirm(ctx, otherTenant, bookingModel.ID, &models.AuditEntry{})
+
+	assert.Equal(t, booking.ErrHoldExpired, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_Confirm(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	entry := &models.AuditEntry{
+		Tenant:   models.DefaultTenantID,
+		Actor:    "guest@mail",
+		Action:   models.AuditActionUpdate,
+		Entity:   models.AuditEntityBooking,
+		EntityID: bookingModel.ID,
+	}
+	event := models.NewEvent(models.DefaultTenantID, models.EventBookingConfirmed, bookingModel)
+	mocks.MockConfirmSuccess(mock, models.DefaultTenantID, bookingModel.ID, entry, event)
+	err = bookingPgRep.Confirm(ctx, models.DefaultTenantID, bookingModel.ID, entry, event)
+
+	assert.NoError(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookingRepository_CancelExpiredHolds(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bookingPgRep := NewBookingRepository(db, query.DefaultTimeout)
+
+	// Every released hold is recorded for its own tenant
+	released := []*models.Booking{
+		{Tenant: models.DefaultTenantID, ID: 3, Room: 1, Status: models.BookingStatusCancelled, Version: 2},
+		{Tenant: otherTenant, ID: 8, Room: 6, Status: models.BookingStatusCancelled, Version: 5},
+	}
+	entries := make([]*models.AuditEntry, len(released))
+	for i, cancelled := range released {
+		entries[i] = &models.AuditEntry{
+			Tenant:   cancelled.Tenant,
+			Actor:    models.SystemActor.Subject,
+			Action:   models.AuditActionUpdate,
+			Entity:   models.AuditEntityBooking,
+			EntityID: cancelled.ID,
+		}
+	}
+	mocks.MockCancelExpiredHolds(mock, released, entries)
+	cancelled, err := bookingPgRep.CancelExpiredHolds(ctx, models.SystemActor)
+
+	assert.NoError(t, err)
+	assert.Len(t, cancelled, 2)
+	assert.Equal(t, models.BookingStatusCancelled, cancelled[1].Status)
+	assert.Equal(t, uint64(5), cancelled[1].Version)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteRoomBookings_Restore(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Restore brings back only the bookings deleted along with the room
+	deletedAt := time.Date(2021, 1, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mocks.MockDeleteRoomBookings(mock, models.DefaultTenantID, firstRoom.ID, deletedAt, bookingsOfFirstRoom)
+	mocks.MockRestoreRoomBookings(mock, models.DefaultTenantID, firstRoom.ID, deletedAt, bookingsOfFirstRoom)
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := DeleteRoomBookings(ctx, tx, models.DefaultTenantID, firstRoom.ID, deletedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, len(bookingsOfFirstRoom), len(deleted))
+
+	restored, err := RestoreRoomBookings(ctx, tx, models.DefaultTenantID, firstRoom.ID, deletedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, restored)
+	assert.NoError(t, tx.Commit())
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
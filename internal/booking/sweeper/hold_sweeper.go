@@ -0,0 +1,45 @@
+package sweeper
+
+import (
+	"context"
+	"github.com/booking_backend/internal/booking"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// HoldSweeper periodically releases holds whose TTL has passed
+type HoldSweeper struct {
+	bookingUseCase booking.BookingUseCase
+	interval       time.Duration
+}
+
+func NewHoldSweeper(useCase booking.BookingUseCase, interval time.Duration) *HoldSweeper {
+	return &HoldSweeper{bookingUseCase: useCase, interval: interval}
+}
+
+// Run blocks until the context is cancelled, the run in progress is finished
+// rather than cut off, so the shutdown can wait for it
+func (hs *HoldSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(hs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			hs.Sweep(context.Background())
+		}
+	}
+}
+
+func (hs *HoldSweeper) Sweep(ctx context.Context) {
+	released, customErr := hs.bookingUseCase.ReleaseExpiredHolds(ctx)
+	if customErr != nil {
+		logrus.Error(customErr)
+		return
+	}
+	if released > 0 {
+		logrus.Infof("released %d expired holds", released)
+	}
+}
@@ -0,0 +1,23 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/job"
+	"github.com/booking_backend/internal/models"
+)
+
+// NewRefundRunner retries the failed refunds in the scheduler jobs, so the
+// cancellation requests don't wait for the payment gateway to recover. A
+// refund made meanwhile, e.g. by hand, leaves the job nothing to do
+func NewRefundRunner(useCase booking.BookingUseCase) job.Runner {
+	return func(ctx context.Context, j *models.Job) error {
+		_, customErr := useCase.RetryRefund(ctx, j.Tenant, models.SystemActor, j.Booking)
+		if customErr == nil || customErr.Code == consts.CodeNothingToRefund {
+			return nil
+		}
+		return errors.New(customErr.Message)
+	}
+}
@@ -1,12 +1,33 @@
 package booking
 
 import (
+	"context"
 	"github.com/booking_backend/internal/helpers/errors"
 	"github.com/booking_backend/internal/models"
 )
 
+// BookingUseCase runs the queries with the context of the request, so they
+// stop along with it
 type BookingUseCase interface {
-	CreateBooking(booking *models.Booking) *errors.Error
-	DeleteBooking(id uint64) *errors.Error
-	GetRoomBookings(roomID uint64) ([]*models.Booking, *errors.Error)
+	CreateBooking(ctx context.Context, actor *models.Actor, booking *models.Booking,
+		paymentToken string) *errors.Error
+	GetQuote(ctx context.Context, tenant uint64, roomID uint64, dateStart string, dateEnd string,
+		guests uint64, promoCode string) (*models.Quote, *errors.Error)
+	GetBooking(ctx context.Context, tenant uint64, id uint64) (*models.Booking, *errors.Error)
+	// The booking is cancelled only in the version, AnyVersion matches any
+	CancelBooking(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		version uint64) (*models.Booking, *errors.Error)
+	// Either all the bookings are cancelled or none, each only in its version
+	CancelBookings(ctx context.Context, tenant uint64, actor *models.Actor,
+		bookings []*models.Booking) ([]*models.Booking, *errors.Error)
+	RetryRefund(ctx context.Context, tenant uint64, actor *models.Actor,
+		id uint64) (*models.Booking, *errors.Error)
+	GetRoomBookings(ctx context.Context, tenant uint64, roomID uint64) ([]*models.Booking, *errors.Error)
+	// The hold is confirmed only in the version, AnyVersion matches any
+	ConfirmBooking(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		version uint64, paymentToken string) *errors.Error
+	// Either all the holds are confirmed or all of them are released
+	ConfirmHolds(ctx context.Context, actor *models.Actor, held []*models.Booking,
+		paymentToken string) *errors.Error
+	ReleaseExpiredHolds(ctx context.Context) (int64, *errors.Error)
 }
@@ -1,84 +1,454 @@
 package usecases
 
 import (
+	"context"
 	"database/sql"
 	"github.com/booking_backend/internal/booking"
 	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/dates"
 	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/transaction"
 	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/payment"
+	"github.com/booking_backend/internal/promo"
+	"github.com/booking_backend/internal/property"
 	"github.com/booking_backend/internal/room"
+	"github.com/sirupsen/logrus"
 	"time"
 )
 
-func NewBookingUseCase(bookingRepository booking.BookingRepository,
-	roomRepository room.RoomRepository) booking.BookingUseCase {
-	return &BookingUseCase{bookingRepo: bookingRepository,
-		roomRepo: roomRepository}
+func NewBookingUseCase(transactions transaction.Manager,
+	bookingRepository booking.BookingRepository, roomRepository room.RoomRepository,
+	propertyUseCase property.PropertyUseCase, promoUseCase promo.PromoUseCase,
+	paymentUseCase payment.PaymentUseCase, holdTTL time.Duration) booking.BookingUseCase {
+	return &BookingUseCase{transactions: transactions, bookingRepo: bookingRepository,
+		roomRepo: roomRepository, propertyUseCase: propertyUseCase, promoUseCase: promoUseCase,
+		paymentUseCase: paymentUseCase, holdTTL: holdTTL}
 }
 
 type BookingUseCase struct {
-	bookingRepo booking.BookingRepository
-	roomRepo    room.RoomRepository
+	transactions    transaction.Manager
+	bookingRepo     booking.BookingRepository
+	roomRepo        room.RoomRepository
+	propertyUseCase property.PropertyUseCase
+	promoUseCase    promo.PromoUseCase
+	paymentUseCase  payment.PaymentUseCase
+	holdTTL         time.Duration
 }
 
-func checkDates(booking *models.Booking) *errors.Error {
-	dateStart, err := time.Parse(`2006-01-02`, booking.DateStart)
-	if err != nil {
-		return errors.New(consts.CodeInternalError, err)
+// quote prices the stay, applying the promo code when one is given
+func (uc *BookingUseCase) quote(room *models.Room, dateStart string, dateEnd string,
+	guests uint64, code string) (*models.Quote, *errors.Error) {
+	var promoCode *models.PromoCode
+	if code != "" {
+		var customErr *errors.Error
+		promoCode, customErr = uc.promoUseCase.CheckPromoCode(code, room, dateStart, dateEnd)
+		if customErr != nil {
+			return nil, customErr
+		}
+	}
+	return uc.propertyUseCase.QuoteStay(room, dateStart, dateEnd, guests, promoCode)
+}
+
+func (uc *BookingUseCase) GetQuote(ctx context.Context, tenant uint64, roomID uint64, dateStart string,
+	dateEnd string, guests uint64, promoCode string) (*models.Quote, *errors.Error) {
+	if err := dates.CheckDates(dateStart, dateEnd); err != nil {
+		return nil, err
+	}
+
+	room, err := uc.roomRepo.SelectByID(ctx, tenant, roomID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeRoomDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	return uc.quote(room, dateStart, dateEnd, guests, promoCode)
+}
+
+// CreateBooking always starts with a hold. Unless the caller asked only for
+// a hold, the booking is confirmed right away with the given payment token
+func (uc *BookingUseCase) CreateBooking(ctx context.Context, actor *models.Actor,
+	booking *models.Booking, paymentToken string) *errors.Error {
+	if err := dates.CheckDates(booking.DateStart, booking.DateEnd); err != nil {
+		return err
 	}
-	dateEnd, err := time.Parse(`2006-01-02`, booking.DateEnd)
+
+	holdOnly := booking.Status == models.BookingStatusHeld
+	var customErr *errors.Error
+	// The room is read in the transaction of the hold, so it can be neither
+	// deleted nor repriced before the hold is stored
+	err := uc.transactions.Run(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		room, err := uc.roomRepo.SelectByID(ctx, booking.Tenant, booking.Room)
+		if err != nil {
+			return err
+		}
+
+		var quote *models.Quote
+		quote, customErr = uc.quote(room, booking.DateStart, booking.DateEnd,
+			booking.Guests, booking.PromoCode)
+		if customErr != nil {
+			// Nothing has been written yet
+			return nil
+		}
+		booking.Quote = quote
+		booking.Guests = quote.Guests
+		booking.Amount = quote.Total
+
+		holdExpires := time.Now().Add(uc.holdTTL)
+		booking.Status = models.BookingStatusHeld
+		booking.HoldExpires = &holdExpires
+
+		entry := actor.Entry(booking.Tenant, models.AuditActionCreate, models.AuditEntityBooking,
+			0, nil, booking)
+		if !holdOnly {
+			// The booking is announced once it is confirmed
+			return uc.bookingRepo.Insert(ctx, booking, entry)
+		}
+		event := models.NewEvent(booking.Tenant, models.EventBookingCreated, booking)
+		return uc.bookingRepo.Insert(ctx, booking, entry, event)
+	})
 	if err != nil {
-		return errors.New(consts.CodeInternalError, err)
+		return insertError(err)
+	}
+	if customErr != nil || holdOnly {
+		return customErr
 	}
-	if dateEnd.Before(dateStart) {
-		return errors.Get(consts.CodeIncorrectDates)
+
+	customErr = uc.confirm(ctx, actor, []*models.Booking{booking}, paymentToken,
+		models.EventBookingCreated)
+	if customErr != nil {
+		uc.release(actor, booking)
+		return customErr
 	}
 	return nil
 }
 
-func (uc *BookingUseCase) CreateBooking(booking *models.Booking) *errors.Error {
-	if err := checkDates(booking); err != nil {
-		return err
+// update starts the audit entry of the change of the booking, the entity
+// states are stored when the entry is written
+func update(actor *models.Actor, before *models.Booking, after *models.Booking) *models.AuditEntry {
+	return actor.Entry(before.Tenant, models.AuditActionUpdate, models.AuditEntityBooking,
+		before.ID, before, after)
+}
+
+// confirm turns the holds into bookings only after a successful payment
+// authorization of every one of them. Whatever makes the confirmation fail,
+// the authorizations made are voided: the request may have been cancelled
+// right after the payment was authorized, and nothing would void it later.
+// Every confirmed booking is announced with an event of eventType
+func (uc *BookingUseCase) confirm(ctx context.Context, actor *models.Actor, held []*models.Booking,
+	paymentToken string, eventType string) *errors.Error {
+	authorized, customErr := uc.confirmHolds(ctx, actor, held, paymentToken, eventType)
+	if customErr != nil {
+		for _, hold := range held[:authorized] {
+			if voidErr := uc.paymentUseCase.Void(hold.ID); voidErr != nil {
+				logrus.Error(voidErr)
+			}
+		}
+		return customErr
 	}
 
-	_, err := uc.roomRepo.SelectByID(booking.Room)
-	if err == sql.ErrNoRows {
-		return errors.Get(consts.CodeRoomDoesNotExist)
+	for _, hold := range held {
+		hold.Status = models.BookingStatusConfirmed
+		hold.HoldExpires = nil
+		hold.Version++
+	}
+	return nil
+}
+
+// ConfirmHolds confirms all the holds in one transaction. If any of them
+// can't be confirmed, all the holds are released, so either every room is
+// booked or every room is free again
+func (uc *BookingUseCase) ConfirmHolds(ctx context.Context, actor *models.Actor,
+	held []*models.Booking, paymentToken string) *errors.Error {
+	customErr := uc.confirm(ctx, actor, held, paymentToken, models.EventBookingCreated)
+	if customErr != nil {
+		for _, hold := range held {
+			uc.release(actor, hold)
+		}
+		return customErr
+	}
+	return nil
+}
+
+// confirmHolds returns how many holds have got their payment authorized
+func (uc *BookingUseCase) confirmHolds(ctx context.Context, actor *models.Actor,
+	held []*models.Booking, paymentToken string, eventType string) (int, *errors.Error) {
+	for i, hold := range held {
+		if customErr := uc.paymentUseCase.Authorize(hold, paymentToken); customErr != nil {
+			return i, customErr
+		}
+	}
+
+	err := uc.transactions.Run(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		for _, hold := range held {
+			confirmed := *hold
+			confirmed.Status = models.BookingStatusConfirmed
+			confirmed.HoldExpires = nil
+			confirmed.Version++
+			event := models.NewEvent(hold.Tenant, eventType, &confirmed)
+			err := uc.bookingRepo.Confirm(ctx, hold.Tenant, hold.ID, update(actor, hold, &confirmed),
+				event)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == booking.ErrHoldExpired {
+		return len(held), errors.Get(consts.CodeHoldExpired)
 	} else if err != nil {
+		return len(held), errors.New(consts.CodeInternalError, err)
+	}
+	return len(held), nil
+}
+
+// release frees the room taken by a hold which could not be confirmed. It
+// does not stop with the request, the confirmation may have failed because
+// of its cancellation. The hold has never been announced, so neither is its release
+func (uc *BookingUseCase) release(actor *models.Actor, held *models.Booking) {
+	before := *held
+	now := time.Now()
+	held.Status = models.BookingStatusCancelled
+	held.Cancelled = &now
+	if err := uc.bookingRepo.Cancel(context.Background(), held,
+		update(actor, &before, held)); err != nil {
+		logrus.Error(err)
+	}
+}
+
+func insertError(err error) *errors.Error {
+	switch err {
+	case sql.ErrNoRows:
+		return errors.Get(consts.CodeRoomDoesNotExist)
+	case booking.ErrRoomIsOccupied:
+		return errors.Get(consts.CodeRoomIsOccupied)
+	case promo.ErrExhausted:
+		return errors.Get(consts.CodePromoCodeExhausted)
+	default:
 		return errors.New(consts.CodeInternalError, err)
 	}
+}
+
+func (uc *BookingUseCase) CancelBooking(ctx context.Context, tenant uint64, actor *models.Actor,
+	id uint64, version uint64) (*models.Booking, *errors.Error) {
+	cancelled, customErr := uc.CancelBookings(ctx, tenant, actor,
+		[]*models.Booking{{ID: id, Version: version}})
+	if customErr != nil {
+		return nil, customErr
+	}
+	return cancelled[0], nil
+}
+
+// CancelBookings charges every booking the fee of its cancellation policy and
+// cancels all of them in one transaction. The refunds are made once the
+// cancellation is stored
+func (uc *BookingUseCase) CancelBookings(ctx context.Context, tenant uint64, actor *models.Actor,
+	bookings []*models.Booking) ([]*models.Booking, *errors.Error) {
+	now := time.Now()
+	cancelled := make([]*models.Booking, len(bookings))
+	entries := make([]*models.AuditEntry, len(bookings))
+	for i, read := range bookings {
+		var customErr *errors.Error
+		cancelled[i], entries[i], customErr = uc.prepareCancel(ctx, tenant, actor,
+			read.ID, read.Version, now)
+		if customErr != nil {
+			return nil, customErr
+		}
+	}
+
+	// Every booking is cancelled only in the version its fee was evaluated in
+	err := uc.transactions.Run(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		for i, booking := range cancelled {
+			if err := uc.bookingRepo.Cancel(ctx, booking, entries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == booking.ErrVersionMismatch {
+		return nil, errors.Get(consts.CodeVersionMismatch)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	// Cancellation is already stored, a failed refund can be retried later.
+	// The cancellation is announced together with its refund
+	for _, booking := range cancelled {
+		event := models.NewEvent(tenant, models.EventBookingCancelled, booking)
+		if customErr := uc.settle(ctx, actor, booking, event); customErr != nil {
+			logrus.Error(customErr)
+		}
+	}
+	return cancelled, nil
+}
+
+// prepareCancel reads the booking in the version the caller has seen and
+// evaluates the fee of its cancellation
+func (uc *BookingUseCase) prepareCancel(ctx context.Context, tenant uint64, actor *models.Actor,
+	id uint64, version uint64, now time.Time) (*models.Booking, *models.AuditEntry, *errors.Error) {
+	cancelled, customErr := uc.GetBooking(ctx, tenant, id)
+	if customErr != nil {
+		return nil, nil, customErr
+	}
+	if version != models.AnyVersion && version != cancelled.Version {
+		return nil, nil, errors.Get(consts.CodeVersionMismatch)
+	}
+	if cancelled.Status == models.BookingStatusCancelled {
+		return nil, nil, errors.Get(consts.CodeBookingAlreadyCancelled)
+	}
+	before := *cancelled
+
+	// Holds are not paid yet, so they are always released for free
+	if cancelled.Status == models.BookingStatusConfirmed {
+		fee, customErr := uc.evaluateCancellationFee(ctx, cancelled, now)
+		if customErr != nil {
+			return nil, nil, customErr
+		}
+		cancelled.CancellationFee = fee
+	}
+	cancelled.Status = models.BookingStatusCancelled
+	cancelled.Cancelled = &now
+	return cancelled, update(actor, &before, cancelled), nil
+}
 
-	err = uc.bookingRepo.Insert(booking)
+// settle refunds the cancelled booking and stores the refund together with
+// the events, the payload of which is taken when they are stored
+func (uc *BookingUseCase) settle(ctx context.Context, actor *models.Actor,
+	cancelled *models.Booking, events ...*models.Event) *errors.Error {
+	before := *cancelled
+	settleErr := uc.paymentUseCase.Settle(cancelled)
+	err := uc.bookingRepo.UpdateRefund(ctx, cancelled, update(actor, &before, cancelled), events...)
 	if err != nil {
 		return errors.New(consts.CodeInternalError, err)
 	}
-	return nil
+	return settleErr
 }
 
-func (uc *BookingUseCase) DeleteBooking(id uint64) *errors.Error {
-	_, err := uc.bookingRepo.SelectByID(id)
+// RetryRefund keeps the booking locked from reading the failed refund till
+// storing the new outcome, so the caller and the refund job never refund twice
+func (uc *BookingUseCase) RetryRefund(ctx context.Context, tenant uint64, actor *models.Actor,
+	id uint64) (*models.Booking, *errors.Error) {
+	var cancelled *models.Booking
+	var customErr *errors.Error
+	err := uc.transactions.Run(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		var err error
+		cancelled, err = uc.bookingRepo.SelectForUpdate(ctx, tenant, id)
+		if err != nil {
+			return err
+		}
+		if cancelled.Status != models.BookingStatusCancelled ||
+			cancelled.RefundStatus != models.RefundStatusFailed {
+			customErr = errors.Get(consts.CodeNothingToRefund)
+			return nil
+		}
+
+		// The outcome is stored even if the refund fails again
+		before := *cancelled
+		customErr = uc.paymentUseCase.Settle(cancelled)
+		return uc.bookingRepo.UpdateRefund(ctx, cancelled, update(actor, &before, cancelled))
+	})
 	if err == sql.ErrNoRows {
-		return errors.Get(consts.CodeBookingDoesNotExist)
+		return nil, errors.Get(consts.CodeBookingDoesNotExist)
 	} else if err != nil {
-		return errors.New(consts.CodeInternalError, err)
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	if customErr != nil {
+		return nil, customErr
 	}
+	return cancelled, nil
+}
 
-	err = uc.bookingRepo.DeleteByID(id)
+func (uc *BookingUseCase) GetBooking(ctx context.Context, tenant uint64,
+	id uint64) (*models.Booking, *errors.Error) {
+	booking, err := uc.bookingRepo.SelectByID(ctx, tenant, id)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeBookingDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return booking, nil
+}
+
+func (uc *BookingUseCase) evaluateCancellationFee(ctx context.Context, booking *models.Booking,
+	now time.Time) (uint64, *errors.Error) {
+	room, err := uc.roomRepo.SelectByID(ctx, booking.Tenant, booking.Room)
 	if err != nil {
-		return errors.New(consts.CodeInternalError, err)
+		return 0, errors.New(consts.CodeInternalError, err)
 	}
-	return nil
+
+	policy, err := uc.roomRepo.SelectCancellationPolicy(ctx, booking.Tenant, booking.Room)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.New(consts.CodeInternalError, err)
+	}
+
+	property, customErr := uc.propertyUseCase.GetProperty(booking.Tenant, room.Property)
+	if customErr != nil {
+		return 0, customErr
+	}
+	location, err := property.Location()
+	if err != nil {
+		return 0, errors.New(consts.CodeInternalError, err)
+	}
+
+	fee, err := cancellationFee(policy, booking, dates.Today(now, location))
+	if err != nil {
+		return 0, errors.New(consts.CodeInternalError, err)
+	}
+	return fee, nil
 }
 
-func (uc *BookingUseCase) GetRoomBookings(roomID uint64) ([]*models.Booking, *errors.Error) {
-	_, err := uc.roomRepo.SelectByID(roomID)
+// cancellationFee is free until policy.FreeDays days before arrival, after that
+// either a percentage of the stay or the price of the first night is charged.
+// The stay is priced as it was paid, promo code discount included, and the fee
+// never exceeds the amount of the booking. Today is the date at the property
+func cancellationFee(policy *models.CancellationPolicy, booking *models.Booking,
+	today time.Time) (uint64, error) {
+	arrival, err := dates.Parse(booking.DateStart)
+	if err != nil {
+		return 0, err
+	}
+	nights, err := dates.Nights(booking.DateStart, booking.DateEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	daysBeforeArrival := int64(arrival.Sub(today).Hours() / 24)
+	if daysBeforeArrival >= int64(policy.FreeDays) || nights == 0 {
+		return 0, nil
+	}
+
+	// Bookings without the breakdown are charged on the amount as a whole
+	stay := booking.Amount
+	if booking.Quote != nil {
+		stay = booking.Quote.Accommodation - booking.Quote.Discount
+	}
+
+	var fee uint64
+	switch policy.PenaltyType {
+	case models.PenaltyTypeFirstNight:
+		fee = (stay + nights/2) / nights
+	case models.PenaltyTypePercent:
+		fee = stay * policy.PenaltyPercent / 100
+	}
+	if fee > booking.Amount {
+		fee = booking.Amount
+	}
+	return fee, nil
+}
+
+func (uc *BookingUseCase) GetRoomBookings(ctx context.Context, tenant uint64,
+	roomID uint64) ([]*models.Booking, *errors.Error) {
+	_, err := uc.roomRepo.SelectByID(ctx, tenant, roomID)
 	if err == sql.ErrNoRows {
 		return nil, errors.Get(consts.CodeRoomDoesNotExist)
 	} else if err != nil {
 		return nil, errors.New(consts.CodeInternalError, err)
 	}
 
-	bookings, err := uc.bookingRepo.SelectRoomBookings(roomID)
+	bookings, err := uc.bookingRepo.SelectRoomBookings(ctx, tenant, roomID)
 	if bookings == nil && err == nil {
 		return []*models.Booking{}, nil
 	} else if err != nil {
@@ -86,3 +456,48 @@ func (uc *BookingUseCase) GetRoomBookings(roomID uint64) ([]*models.Booking, *er
 	}
 	return bookings, nil
 }
+
+func (uc *BookingUseCase) ConfirmBooking(ctx context.Context, tenant uint64, actor *models.Actor,
+	id uint64, version uint64, paymentToken string) *errors.Error {
+	held, err := uc.bookingRepo.SelectByID(ctx, tenant, id)
+	if err == sql.ErrNoRows {
+		return errors.Get(consts.CodeBookingDoesNotExist)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	if version != models.AnyVersion && version != held.Version {
+		return errors.Get(consts.CodeVersionMismatch)
+	}
+
+	switch held.Status {
+	case models.BookingStatusConfirmed:
+		return nil
+	case models.BookingStatusCancelled:
+		return errors.Get(consts.CodeBookingAlreadyCancelled)
+	}
+	if held.HoldExpires != nil && held.HoldExpires.Before(time.Now()) {
+		return errors.Get(consts.CodeHoldExpired)
+	}
+
+	return uc.confirm(ctx, actor, []*models.Booking{held}, paymentToken, models.EventBookingConfirmed)
+}
+
+// ReleaseExpiredHolds cancels the holds whose TTL has passed and releases their
+// payment authorizations like any other cancellation, a failed release is
+// retried by the refund job
+func (uc *BookingUseCase) ReleaseExpiredHolds(ctx context.Context) (int64, *errors.Error) {
+	released, err := uc.bookingRepo.CancelExpiredHolds(ctx, models.SystemActor)
+	if err != nil {
+		return 0, errors.New(consts.CodeInternalError, err)
+	}
+
+	// Only the holds made without a payment are meant to outlive their
+	// request, and those were announced as created, so their release is announced too
+	for _, hold := range released {
+		event := models.NewEvent(hold.Tenant, models.EventBookingCancelled, hold)
+		if customErr := uc.settle(ctx, models.SystemActor, hold, event); customErr != nil {
+			logrus.Error(customErr)
+		}
+	}
+	return int64(len(released)), nil
+}
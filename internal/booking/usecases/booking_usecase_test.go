@@ -1,11 +1,19 @@
 package usecases
 
 import (
+	"context"
 	"database/sql"
+	"github.com/booking_backend/internal/booking"
 	"github.com/booking_backend/internal/booking/mocks"
 	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/dates"
 	"github.com/booking_backend/internal/helpers/errors"
+	mockTransaction "github.com/booking_backend/internal/helpers/transaction/mocks"
 	"github.com/booking_backend/internal/models"
+	mockPayment "github.com/booking_backend/internal/payment/mocks"
+	"github.com/booking_backend/internal/promo"
+	mockPromo "github.com/booking_backend/internal/promo/mocks"
+	mockProperty "github.com/booking_backend/internal/property/mocks"
 	mockRoom "github.com/booking_backend/internal/room/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -13,7 +21,19 @@ import (
 	"time"
 )
 
+const (
+	holdTTL      = 15 * time.Minute
+	paymentToken = "tok_visa"
+)
+
+const tenantID uint64 = models.DefaultTenantID
+
+var ctx = context.Background()
+
+var actor = &models.Actor{Subject: "manager@hotel", RequestID: "request"}
+
 var bookingModel = &models.Booking{
+	Tenant:    tenantID,
 	ID:        3,
 	DateStart: "2022-01-02",
 	DateEnd:   "2022-01-02",
@@ -29,18 +49,21 @@ var firstRoom = &models.Room{
 
 var bookings = []*models.Booking{
 	&models.Booking{
+		Tenant:    tenantID,
 		ID:        1,
 		DateStart: "2022-01-02",
 		DateEnd:   "2023-01-02",
 		Room:      1,
 	},
 	&models.Booking{
+		Tenant:    tenantID,
 		ID:        2,
 		DateStart: "2022-01-02",
 		DateEnd:   "2023-01-02",
 		Room:      1,
 	},
 	&models.Booking{
+		Tenant:    tenantID,
 		ID:        4,
 		DateStart: "2022-01-02",
 		DateEnd:   "2023-01-02",
@@ -48,26 +71,105 @@ var bookings = []*models.Booking{
 	},
 }
 
+// runInPlace makes the unit of work run the repository calls right away
+func runInPlace(ctrl *gomock.Controller) *mockTransaction.MockManager {
+	transactions := mockTransaction.NewMockManager(ctrl)
+	transactions.
+		EXPECT().
+		Run(gomock.Any(), sql.LevelSerializable, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel,
+			work func(ctx context.Context) error) error {
+			return work(ctx)
+		}).
+		AnyTimes()
+	return transactions
+}
+
+func expectQuote(propertyUseCase *mockProperty.MockPropertyUseCase,
+	booking *models.Booking, total uint64) {
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, booking.DateStart, booking.DateEnd, gomock.Any(), nil).
+		Return(&models.Quote{Guests: 1, Total: total}, nil)
+}
+
 func TestBookingUseCase_CreateBooking_Success(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	bookingRep := mocks.NewMockBookingRepository(ctrl)
 	roomRep := mockRoom.NewMockRoomRepository(ctrl)
-	bookingUseCase := NewBookingUseCase(bookingRep, roomRep)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
 
 	roomRep.
 		EXPECT().
-		SelectByID(bookingModel.Room).
+		SelectByID(gomock.Any(), tenantID, bookingModel.Room).
 		Return(firstRoom, nil)
+	expectQuote(propertyUseCase, bookingModel, 0)
 
+	// The booking paid right away is not announced until it is confirmed
+	bookingRep.
+		EXPECT().
+		Insert(gomock.Any(), bookingModel, gomock.Any()).
+		Return(nil)
+	paymentUseCase.
+		EXPECT().
+		Authorize(bookingModel, paymentToken).
+		Return(nil)
 	bookingRep.
 		EXPECT().
-		Insert(bookingModel).
+		Confirm(gomock.Any(), tenantID, bookingModel.ID, gomock.Any(), gomock.Any()).
 		Return(nil)
 
-	err := bookingUseCase.CreateBooking(bookingModel)
+	err := bookingUseCase.CreateBooking(ctx, actor, bookingModel, paymentToken)
 	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.BookingStatusConfirmed, bookingModel.Status)
+}
+
+func TestBookingUseCase_CreateBooking_PaymentDeclined(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	declinedBooking := &models.Booking{
+		Tenant:    tenantID,
+		DateStart: "2022-01-02",
+		DateEnd:   "2022-01-04",
+		Room:      firstRoom.ID,
+	}
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, declinedBooking.Room).
+		Return(firstRoom, nil)
+	expectQuote(propertyUseCase, declinedBooking, 1000)
+	bookingRep.
+		EXPECT().
+		Insert(gomock.Any(), declinedBooking, gomock.Any(), gomock.Any()).
+		Return(nil)
+	paymentUseCase.
+		EXPECT().
+		Authorize(declinedBooking, paymentToken).
+		Return(errors.Get(consts.CodePaymentDeclined))
+	bookingRep.
+		EXPECT().
+		Cancel(gomock.Any(), declinedBooking, gomock.Any()).
+		Return(nil)
+
+	err := bookingUseCase.CreateBooking(ctx, actor, declinedBooking, paymentToken)
+	assert.Equal(t, errors.Get(consts.CodePaymentDeclined), err)
+	assert.Equal(t, uint64(1000), declinedBooking.Amount)
+	assert.Equal(t, models.BookingStatusCancelled, declinedBooking.Status)
 }
 
 func TestBookingUseCase_CreateBooking_RoomDoesNotExist(t *testing.T) {
@@ -76,14 +178,18 @@ func TestBookingUseCase_CreateBooking_RoomDoesNotExist(t *testing.T) {
 	defer ctrl.Finish()
 	bookingRep := mocks.NewMockBookingRepository(ctrl)
 	roomRep := mockRoom.NewMockRoomRepository(ctrl)
-	bookingUseCase := NewBookingUseCase(bookingRep, roomRep)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
 
 	roomRep.
 		EXPECT().
-		SelectByID(bookingModel.Room).
+		SelectByID(gomock.Any(), tenantID, bookingModel.Room).
 		Return(nil, sql.ErrNoRows)
 
-	err := bookingUseCase.CreateBooking(bookingModel)
+	err := bookingUseCase.CreateBooking(ctx, actor, bookingModel, paymentToken)
 	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), err)
 }
 
@@ -93,19 +199,23 @@ func TestBookingUseCase_GetRoomBookings_Success(t *testing.T) {
 	defer ctrl.Finish()
 	bookingRep := mocks.NewMockBookingRepository(ctrl)
 	roomRep := mockRoom.NewMockRoomRepository(ctrl)
-	bookingUseCase := NewBookingUseCase(bookingRep, roomRep)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
 
 	roomRep.
 		EXPECT().
-		SelectByID(bookingModel.Room).
+		SelectByID(gomock.Any(), tenantID, bookingModel.Room).
 		Return(firstRoom, nil)
 
 	bookingRep.
 		EXPECT().
-		SelectRoomBookings(bookingModel.Room).
+		SelectRoomBookings(gomock.Any(), tenantID, bookingModel.Room).
 		Return(bookings, nil)
 
-	bookingsResult, err := bookingUseCase.GetRoomBookings(bookingModel.Room)
+	bookingsResult, err := bookingUseCase.GetRoomBookings(ctx, tenantID, bookingModel.Room)
 	assert.Equal(t, (*errors.Error)(nil), err)
 	assert.Equal(t, bookings, bookingsResult)
 }
@@ -116,19 +226,23 @@ func TestBookingUseCase_GetRoomBookings_NoBookings(t *testing.T) {
 	defer ctrl.Finish()
 	bookingRep := mocks.NewMockBookingRepository(ctrl)
 	roomRep := mockRoom.NewMockRoomRepository(ctrl)
-	bookingUseCase := NewBookingUseCase(bookingRep, roomRep)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
 
 	roomRep.
 		EXPECT().
-		SelectByID(bookingModel.Room).
+		SelectByID(gomock.Any(), tenantID, bookingModel.Room).
 		Return(firstRoom, nil)
 
 	bookingRep.
 		EXPECT().
-		SelectRoomBookings(bookingModel.Room).
+		SelectRoomBookings(gomock.Any(), tenantID, bookingModel.Room).
 		Return(nil, nil)
 
-	bookings, err := bookingUseCase.GetRoomBookings(bookingModel.Room)
+	bookings, err := bookingUseCase.GetRoomBookings(ctx, tenantID, bookingModel.Room)
 	assert.Equal(t, (*errors.Error)(nil), err)
 	assert.Equal(t, []*models.Booking{}, bookings)
 }
@@ -139,52 +253,1010 @@ func TestBookingUseCase_GetRoomBookings_RoomDoesNotExist(t *testing.T) {
 	defer ctrl.Finish()
 	bookingRep := mocks.NewMockBookingRepository(ctrl)
 	roomRep := mockRoom.NewMockRoomRepository(ctrl)
-	bookingUseCase := NewBookingUseCase(bookingRep, roomRep)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
 
 	roomRep.
 		EXPECT().
-		SelectByID(bookingModel.Room).
+		SelectByID(gomock.Any(), tenantID, bookingModel.Room).
 		Return(nil, sql.ErrNoRows)
 
-	bookings, err := bookingUseCase.GetRoomBookings(bookingModel.Room)
+	bookings, err := bookingUseCase.GetRoomBookings(ctx, tenantID, bookingModel.Room)
 	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), err)
 	assert.Nil(t, bookings)
 }
 
-func TestBookingUseCase_DeleteBooking_NoBooking(t *testing.T) {
+func TestBookingUseCase_GetRoomBookings_Timeout(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, bookingModel.Room).
+		Return(firstRoom, nil)
+	bookingRep.
+		EXPECT().
+		SelectRoomBookings(gomock.Any(), tenantID, bookingModel.Room).
+		Return(nil, context.DeadlineExceeded)
+
+	bookings, err := bookingUseCase.GetRoomBookings(ctx, tenantID, bookingModel.Room)
+	assert.Equal(t, errors.Get(consts.CodeTimeout), err)
+	assert.Nil(t, bookings)
+}
+
+func TestBookingUseCase_CancelBooking_NoBooking(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	bookingRep := mocks.NewMockBookingRepository(ctrl)
 	roomRep := mockRoom.NewMockRoomRepository(ctrl)
-	bookingUseCase := NewBookingUseCase(bookingRep, roomRep)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
 
 	bookingRep.
 		EXPECT().
-		SelectByID(bookingModel.ID).
+		SelectByID(gomock.Any(), tenantID, bookingModel.ID).
 		Return(nil, sql.ErrNoRows)
 
-	err := bookingUseCase.DeleteBooking(bookingModel.ID)
+	_, err := bookingUseCase.CancelBooking(ctx, tenantID, actor, bookingModel.ID, models.AnyVersion)
 	assert.Equal(t, errors.Get(consts.CodeBookingDoesNotExist), err)
 }
 
-func TestBookingUseCase_DeleteBooking_Success(t *testing.T) {
+func TestBookingUseCase_CancelBooking_NoPolicy(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	confirmedBooking := &models.Booking{
+		Tenant:    tenantID,
+		ID:        3,
+		DateStart: "2022-01-02",
+		DateEnd:   "2022-01-04",
+		Room:      firstRoom.ID,
+		Status:    models.BookingStatusConfirmed,
+	}
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, confirmedBooking.ID).
+		Return(confirmedBooking, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(firstRoom, nil)
+	roomRep.
+		EXPECT().
+		SelectCancellationPolicy(gomock.Any(), tenantID, firstRoom.ID).
+		Return(nil, sql.ErrNoRows)
+	bookingRep.
+		EXPECT().
+		Cancel(gomock.Any(), confirmedBooking, gomock.Any()).
+		DoAndReturn(func(_ context.Context, cancelled *models.Booking, entry *models.AuditEntry) error {
+			// The entry keeps the booking as it was before the cancellation
+			assert.Equal(t, &models.AuditEntry{
+				Actor:     actor.Subject,
+				Action:    models.AuditActionUpdate,
+				Entity:    models.AuditEntityBooking,
+				EntityID:  confirmedBooking.ID,
+				RequestID: actor.RequestID,
+				Tenant:    tenantID,
+				Before:    entry.Before,
+				After:     cancelled,
+			}, entry)
+			assert.Equal(t, models.BookingStatusConfirmed, entry.Before.(*models.Booking).Status)
+			return nil
+		})
+	paymentUseCase.
+		EXPECT().
+		Settle(confirmedBooking).
+		DoAndReturn(func(cancelled *models.Booking) *errors.Error {
+			cancelled.RefundStatus = models.RefundStatusRefunded
+			cancelled.RefundAmount = 4000
+			return nil
+		})
+	bookingRep.
+		EXPECT().
+		UpdateRefund(gomock.Any(), confirmedBooking, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refunded *models.Booking, _ *models.AuditEntry,
+			events ...*models.Event) error {
+			// The cancellation is announced once the refund is known
+			assert.Equal(t, []*models.Event{
+				models.NewEvent(tenantID, models.EventBookingCancelled, refunded)}, events)
+			assert.Equal(t, uint64(4000), refunded.RefundAmount)
+			return nil
+		})
+
+	cancelled, err := bookingUseCase.CancelBooking(ctx, tenantID, actor, confirmedBooking.ID, models.AnyVersion)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.BookingStatusCancelled, cancelled.Status)
+	assert.Equal(t, uint64(0), cancelled.CancellationFee)
+}
+
+func TestBookingUseCase_CancelBooking_PropertyLocalDate(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	// The guest arrives tomorrow at the property, which may still be two
+	// days ahead in UTC
+	location, err := time.LoadLocation("Pacific/Kiritimati")
+	if err != nil {
+		t.Skip(err)
+	}
+	tomorrow := time.Now().In(location).AddDate(0, 0, 1)
+	confirmedBooking := &models.Booking{
+		Tenant:    tenantID,
+		ID:        3,
+		DateStart: tomorrow.Format(dates.Layout),
+		DateEnd:   tomorrow.AddDate(0, 0, 1).Format(dates.Layout),
+		Room:      firstRoom.ID,
+		Status:    models.BookingStatusConfirmed,
+		Amount:    firstRoom.Price,
+		Quote:     &models.Quote{Nights: 1, NightPrice: firstRoom.Price, Accommodation: firstRoom.Price},
+	}
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, confirmedBooking.ID).
+		Return(confirmedBooking, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(firstRoom, nil)
+	roomRep.
+		EXPECT().
+		SelectCancellationPolicy(gomock.Any(), tenantID, firstRoom.ID).
+		Return(&models.CancellationPolicy{FreeDays: 2, PenaltyType: models.PenaltyTypeFirstNight}, nil)
+	propertyUseCase.
+		EXPECT().
+		GetProperty(tenantID, firstRoom.Property).
+		Return(&models.Property{TimeZone: location.String()}, nil)
+	bookingRep.
+		EXPECT().
+		Cancel(gomock.Any(), confirmedBooking, gomock.Any()).
+		Return(nil)
+	paymentUseCase.
+		EXPECT().
+		Settle(confirmedBooking).
+		Return(nil)
+	bookingRep.
+		EXPECT().
+		UpdateRefund(gomock.Any(), confirmedBooking, gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	cancelled, customErr := bookingUseCase.CancelBooking(ctx, tenantID, actor, confirmedBooking.ID,
+		models.AnyVersion)
+	assert.Equal(t, (*errors.Error)(nil), customErr)
+	assert.Equal(t, firstRoom.Price, cancelled.CancellationFee)
+}
+
+func TestBookingUseCase_CancelBookings_VersionMismatch(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, uint64(3)).
+		Return(&models.Booking{Tenant: tenantID, ID: 3, Status: models.BookingStatusHeld, Version: 1}, nil)
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, uint64(4)).
+		Return(&models.Booking{Tenant: tenantID, ID: 4, Status: models.BookingStatusHeld, Version: 1}, nil)
+	bookingRep.
+		EXPECT().
+		Cancel(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil)
+	bookingRep.
+		EXPECT().
+		Cancel(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(booking.ErrVersionMismatch)
+
+	// No refund is made when the bookings are not cancelled all together
+	_, err := bookingUseCase.CancelBookings(ctx, tenantID, actor, []*models.Booking{
+		{ID: 3, Version: 1}, {ID: 4, Version: 1}})
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), err)
+}
+
+func TestBookingUseCase_CancelBooking_AlreadyCancelled(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, uint64(3)).
+		Return(&models.Booking{Tenant: tenantID, ID: 3, Status: models.BookingStatusCancelled}, nil)
+
+	_, err := bookingUseCase.CancelBooking(ctx, tenantID, actor, 3, models.AnyVersion)
+	assert.Equal(t, errors.Get(consts.CodeBookingAlreadyCancelled), err)
+}
+
+func TestBookingUseCase_CancelBooking_VersionMismatch(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	bookingRep := mocks.NewMockBookingRepository(ctrl)
 	roomRep := mockRoom.NewMockRoomRepository(ctrl)
-	bookingUseCase := NewBookingUseCase(bookingRep, roomRep)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
 
 	bookingRep.
 		EXPECT().
-		SelectByID(bookingModel.ID).
-		Return(bookingModel, nil)
+		SelectByID(gomock.Any(), tenantID, uint64(3)).
+		Return(&models.Booking{Tenant: tenantID, ID: 3, Status: models.BookingStatusHeld, Version: 2}, nil)
+
+	_, err := bookingUseCase.CancelBooking(ctx, tenantID, actor, 3, 1)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), err)
+}
+
+func TestBookingUseCase_CancelBooking_ChangedConcurrently(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, uint64(3)).
+		Return(&models.Booking{Tenant: tenantID, ID: 3, Status: models.BookingStatusHeld, Version: 2}, nil)
+	bookingRep.
+		EXPECT().
+		Cancel(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(booking.ErrVersionMismatch)
+
+	_, err := bookingUseCase.CancelBooking(ctx, tenantID, actor, 3, 2)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), err)
+}
+
+func TestCancellationFee(t *testing.T) {
+	t.Parallel()
+	today := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	stay := &models.Booking{DateStart: "2022-01-05", DateEnd: "2022-01-08", Amount: 1800,
+		Quote: &models.Quote{Nights: 3, NightPrice: 500, Accommodation: 1500, CleaningFee: 300, Total: 1800}}
+
+	cases := []struct {
+		name   string
+		policy *models.CancellationPolicy
+		fee    uint64
+	}{
+		{"free period", &models.CancellationPolicy{FreeDays: 4,
+			PenaltyType: models.PenaltyTypePercent, PenaltyPercent: 50}, 0},
+		{"percent", &models.CancellationPolicy{FreeDays: 5,
+			PenaltyType: models.PenaltyTypePercent, PenaltyPercent: 50}, 750},
+		{"first night", &models.CancellationPolicy{FreeDays: 7,
+			PenaltyType: models.PenaltyTypeFirstNight}, 500},
+	}
+
+	for _, c := range cases {
+		fee, err := cancellationFee(c.policy, stay, today)
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.fee, fee, c.name)
+	}
+}
+
+func TestCancellationFee_PaidPrice(t *testing.T) {
+	t.Parallel()
+	today := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &models.CancellationPolicy{FreeDays: 7, PenaltyType: models.PenaltyTypePercent, PenaltyPercent: 100}
+
+	// The promo code discount is not charged back
+	discounted := &models.Booking{DateStart: "2022-01-05", DateEnd: "2022-01-08", Amount: 1200,
+		Quote: &models.Quote{Nights: 3, NightPrice: 500, Accommodation: 1500, PromoCode: "WINTER",
+			Discount: 300, Total: 1200}}
+	fee, err := cancellationFee(policy, discounted, today)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1200), fee)
+
+	firstNight := &models.CancellationPolicy{FreeDays: 7, PenaltyType: models.PenaltyTypeFirstNight}
+	fee, err = cancellationFee(firstNight, discounted, today)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(400), fee)
+
+	// Without the breakdown the amount is the price of the stay
+	unquoted := &models.Booking{DateStart: "2022-01-05", DateEnd: "2022-01-08", Amount: 900}
+	fee, err = cancellationFee(firstNight, unquoted, today)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(300), fee)
+
+	// The fee never exceeds what was paid
+	overpriced := &models.CancellationPolicy{FreeDays: 7, PenaltyType: models.PenaltyTypePercent, PenaltyPercent: 150}
+	fee, err = cancellationFee(overpriced, unquoted, today)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(900), fee)
+}
+
+func TestCancellationFee_PropertyLocalDate(t *testing.T) {
+	t.Parallel()
+	location, err := time.LoadLocation("Asia/Vladivostok")
+	if err != nil {
+		t.Skip(err)
+	}
+	// It is already the 2nd of January at the property
+	now := time.Date(2022, 1, 1, 15, 0, 0, 0, time.UTC)
+	stay := &models.Booking{DateStart: "2022-01-05", DateEnd: "2022-01-08", Amount: 1500,
+		Quote: &models.Quote{Nights: 3, NightPrice: 500, Accommodation: 1500, Total: 1500}}
+	policy := &models.CancellationPolicy{FreeDays: 4, PenaltyType: models.PenaltyTypeFirstNight}
+
+	fee, err := cancellationFee(policy, stay, dates.Today(now, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), fee)
+
+	fee, err = cancellationFee(policy, stay, dates.Today(now, location))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500), fee)
+}
+
+func TestBookingUseCase_CreateBooking_Hold(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	heldBooking := &models.Booking{
+		Tenant:    tenantID,
+		DateStart: "2022-01-02",
+		DateEnd:   "2022-01-04",
+		Room:      firstRoom.ID,
+		Status:    models.BookingStatusHeld,
+	}
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, heldBooking.Room).
+		Return(firstRoom, nil)
+	expectQuote(propertyUseCase, heldBooking, 1000)
 	bookingRep.
 		EXPECT().
-		DeleteByID(bookingModel.ID).
+		Insert(gomock.Any(), heldBooking, gomock.Any(), gomock.Any()).
 		Return(nil)
 
-	err := bookingUseCase.DeleteBooking(bookingModel.ID)
+	err := bookingUseCase.CreateBooking(ctx, actor, heldBooking, paymentToken)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.BookingStatusHeld, heldBooking.Status)
+	assert.WithinDuration(t, time.Now().Add(holdTTL), *heldBooking.HoldExpires, time.Minute)
+}
+
+func TestBookingUseCase_CreateBooking_RoomIsOccupied(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, bookingModel.Room).
+		Return(firstRoom, nil)
+	expectQuote(propertyUseCase, bookingModel, 0)
+	bookingRep.
+		EXPECT().
+		Insert(gomock.Any(), bookingModel, gomock.Any(), gomock.Any()).
+		Return(booking.ErrRoomIsOccupied)
+
+	err := bookingUseCase.CreateBooking(ctx, actor, bookingModel, paymentToken)
+	assert.Equal(t, errors.Get(consts.CodeRoomIsOccupied), err)
+}
+
+func newHolds() []*models.Booking {
+	return []*models.Booking{
+		{Tenant: tenantID, ID: 10, Room: 1, Reservation: 1, Status: models.BookingStatusHeld, Amount: 1500},
+		{Tenant: tenantID, ID: 11, Room: 2, Reservation: 1, Status: models.BookingStatusHeld, Amount: 2100},
+	}
+}
+
+func TestBookingUseCase_ConfirmHolds_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	held := newHolds()
+
+	for _, hold := range held {
+		paymentUseCase.
+			EXPECT().
+			Authorize(hold, paymentToken).
+			Return(nil)
+		bookingRep.
+			EXPECT().
+			Confirm(gomock.Any(), tenantID, hold.ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, _ uint64, _ *models.AuditEntry,
+				events ...*models.Event) error {
+				// The booking is announced from its confirmed state
+				assert.Len(t, events, 1)
+				assert.Equal(t, models.EventBookingCreated, events[0].Type)
+				assert.Equal(t, models.BookingStatusConfirmed,
+					events[0].Payload.(*models.Booking).Status)
+				return nil
+			})
+	}
+
+	err := bookingUseCase.ConfirmHolds(ctx, actor, held, paymentToken)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	for _, hold := range held {
+		assert.Equal(t, models.BookingStatusConfirmed, hold.Status)
+	}
+}
+
+func TestBookingUseCase_ConfirmHolds_PaymentDeclined(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	held := newHolds()
+
+	paymentUseCase.
+		EXPECT().
+		Authorize(held[0], paymentToken).
+		Return(nil)
+	paymentUseCase.
+		EXPECT().
+		Authorize(held[1], paymentToken).
+		Return(errors.Get(consts.CodePaymentDeclined))
+	// Only the authorization made is voided, every room is released
+	paymentUseCase.
+		EXPECT().
+		Void(held[0].ID).
+		Return(nil)
+	for _, hold := range held {
+		bookingRep.
+			EXPECT().
+			Cancel(gomock.Any(), hold, gomock.Any()).
+			Return(nil)
+	}
+
+	err := bookingUseCase.ConfirmHolds(ctx, actor, held, paymentToken)
+	assert.Equal(t, errors.Get(consts.CodePaymentDeclined), err)
+	for _, hold := range held {
+		assert.Equal(t, models.BookingStatusCancelled, hold.Status)
+	}
+}
+
+func TestBookingUseCase_ConfirmHolds_HoldExpired(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	held := newHolds()
+
+	for _, hold := range held {
+		paymentUseCase.
+			EXPECT().
+			Authorize(hold, paymentToken).
+			Return(nil)
+		paymentUseCase.
+			EXPECT().
+			Void(hold.ID).
+			Return(nil)
+		bookingRep.
+			EXPECT().
+			Cancel(gomock.Any(), hold, gomock.Any()).
+			Return(nil)
+	}
+	bookingRep.
+		EXPECT().
+		Confirm(gomock.Any(), tenantID, held[0].ID, gomock.Any(), gomock.Any()).
+		Return(nil)
+	bookingRep.
+		EXPECT().
+		Confirm(gomock.Any(), tenantID, held[1].ID, gomock.Any(), gomock.Any()).
+		Return(booking.ErrHoldExpired)
+
+	err := bookingUseCase.ConfirmHolds(ctx, actor, held, paymentToken)
+	assert.Equal(t, errors.Get(consts.CodeHoldExpired), err)
+}
+
+func TestBookingUseCase_ConfirmBooking_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	heldBooking := &models.Booking{Tenant: tenantID, ID: 5, Status: models.BookingStatusHeld}
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, heldBooking.ID).
+		Return(heldBooking, nil)
+	paymentUseCase.
+		EXPECT().
+		Authorize(heldBooking, paymentToken).
+		Return(nil)
+	bookingRep.
+		EXPECT().
+		Confirm(gomock.Any(), tenantID, heldBooking.ID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint64, _ uint64, _ *models.AuditEntry,
+			events ...*models.Event) error {
+			// The hold was announced when it was made, now its confirmation is
+			assert.Len(t, events, 1)
+			assert.Equal(t, models.EventBookingConfirmed, events[0].Type)
+			return nil
+		})
+
+	err := bookingUseCase.ConfirmBooking(ctx, tenantID, actor, heldBooking.ID, models.AnyVersion,
+		paymentToken)
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestBookingUseCase_ConfirmBooking_HoldExpired(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	heldBooking := &models.Booking{Tenant: tenantID, ID: 5, Status: models.BookingStatusHeld}
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, heldBooking.ID).
+		Return(heldBooking, nil)
+	paymentUseCase.
+		EXPECT().
+		Authorize(heldBooking, paymentToken).
+		Return(nil)
+	bookingRep.
+		EXPECT().
+		Confirm(gomock.Any(), tenantID, heldBooking.ID, gomock.Any(), gomock.Any()).
+		Return(booking.ErrHoldExpired)
+	paymentUseCase.
+		EXPECT().
+		Void(heldBooking.ID).
+		Return(nil)
+
+	err := bookingUseCase.ConfirmBooking(ctx, tenantID, actor, heldBooking.ID, models.AnyVersion,
+		paymentToken)
+	assert.Equal(t, errors.Get(consts.CodeHoldExpired), err)
+}
+
+func TestBookingUseCase_ConfirmBooking_RequestCancelled(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	heldBooking := &models.Booking{Tenant: tenantID, ID: 5, Status: models.BookingStatusHeld}
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, heldBooking.ID).
+		Return(heldBooking, nil)
+	paymentUseCase.
+		EXPECT().
+		Authorize(heldBooking, paymentToken).
+		Return(nil)
+	// The client has gone after the payment was authorized
+	bookingRep.
+		EXPECT().
+		Confirm(gomock.Any(), tenantID, heldBooking.ID, gomock.Any(), gomock.Any()).
+		Return(context.Canceled)
+	paymentUseCase.
+		EXPECT().
+		Void(heldBooking.ID).
+		Return(nil)
+
+	err := bookingUseCase.ConfirmBooking(ctx, tenantID, actor, heldBooking.ID, models.AnyVersion,
+		paymentToken)
+	assert.NotNil(t, err)
+	assert.Equal(t, models.BookingStatusHeld, heldBooking.Status)
+}
+
+func TestBookingUseCase_ConfirmBooking_VersionMismatch(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	heldBooking := &models.Booking{Tenant: tenantID, ID: 5, Status: models.BookingStatusHeld, Version: 2}
+
+	// The guest is not charged for a hold changed since it was read
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, heldBooking.ID).
+		Return(heldBooking, nil)
+
+	err := bookingUseCase.ConfirmBooking(ctx, tenantID, actor, heldBooking.ID, 1, paymentToken)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), err)
+}
+
+func TestBookingUseCase_RetryRefund_NothingToRefund(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	bookingRep.
+		EXPECT().
+		SelectForUpdate(gomock.Any(), tenantID, uint64(3)).
+		Return(&models.Booking{
+			Tenant:       tenantID,
+			ID:           3,
+			Status:       models.BookingStatusCancelled,
+			RefundStatus: models.RefundStatusRefunded,
+		}, nil)
+
+	_, err := bookingUseCase.RetryRefund(ctx, tenantID, actor, 3)
+	assert.Equal(t, errors.Get(consts.CodeNothingToRefund), err)
+}
+
+func TestBookingUseCase_RetryRefund_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	failedRefund := &models.Booking{
+		Tenant:       tenantID,
+		ID:           3,
+		Status:       models.BookingStatusCancelled,
+		RefundStatus: models.RefundStatusFailed,
+	}
+
+	bookingRep.
+		EXPECT().
+		SelectForUpdate(gomock.Any(), tenantID, failedRefund.ID).
+		Return(failedRefund, nil)
+	paymentUseCase.
+		EXPECT().
+		Settle(failedRefund).
+		DoAndReturn(func(booking *models.Booking) *errors.Error {
+			booking.RefundStatus = models.RefundStatusRefunded
+			return nil
+		})
+	bookingRep.
+		EXPECT().
+		UpdateRefund(gomock.Any(), failedRefund, gomock.Any()).
+		Return(nil)
+
+	refunded, err := bookingUseCase.RetryRefund(ctx, tenantID, actor, failedRefund.ID)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.RefundStatusRefunded, refunded.RefundStatus)
+}
+
+func TestBookingUseCase_RetryRefund_FailedAgain(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	failedRefund := &models.Booking{
+		Tenant:       tenantID,
+		ID:           3,
+		Status:       models.BookingStatusCancelled,
+		RefundStatus: models.RefundStatusFailed,
+	}
+
+	// The failed attempt is stored while the booking is still locked
+	gomock.InOrder(
+		bookingRep.
+			EXPECT().
+			SelectForUpdate(gomock.Any(), tenantID, failedRefund.ID).
+			Return(failedRefund, nil),
+		paymentUseCase.
+			EXPECT().
+			Settle(failedRefund).
+			Return(errors.Get(consts.CodePaymentGatewayError)),
+		bookingRep.
+			EXPECT().
+			UpdateRefund(gomock.Any(), failedRefund, gomock.Any()).
+			Return(nil),
+	)
+
+	_, err := bookingUseCase.RetryRefund(ctx, tenantID, actor, failedRefund.ID)
+	assert.Equal(t, errors.Get(consts.CodePaymentGatewayError), err)
+}
+
+func TestBookingUseCase_GetQuote_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	quote := &models.Quote{Nights: 2, Guests: 2, NightPrice: 500, Total: 1000}
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(firstRoom, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, "2022-01-02", "2022-01-04", uint64(2), nil).
+		Return(quote, nil)
+
+	result, err := bookingUseCase.GetQuote(ctx, tenantID, firstRoom.ID, "2022-01-02", "2022-01-04", 2, "")
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, quote, result)
+}
+
+func TestBookingUseCase_GetQuote_IncorrectDates(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+
+	_, err := bookingUseCase.GetQuote(ctx, tenantID, firstRoom.ID, "2022-01-04", "2022-01-02", 1, "")
+	assert.Equal(t, errors.Get(consts.CodeIncorrectDates), err)
+}
+
+func TestBookingUseCase_GetQuote_PromoCode(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	promoCode := &models.PromoCode{Code: "WINTER",
+		DiscountType: models.DiscountTypeFixed, DiscountValue: 100}
+	quote := &models.Quote{Nights: 2, Guests: 1, NightPrice: 500,
+		PromoCode: "WINTER", Discount: 100, Total: 900}
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(firstRoom, nil)
+	promoUseCase.
+		EXPECT().
+		CheckPromoCode("WINTER", firstRoom, "2022-01-02", "2022-01-04").
+		Return(promoCode, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, "2022-01-02", "2022-01-04", uint64(1), promoCode).
+		Return(quote, nil)
+
+	result, err := bookingUseCase.GetQuote(ctx, tenantID, firstRoom.ID, "2022-01-02", "2022-01-04", 1, "WINTER")
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, quote, result)
+}
+
+func TestBookingUseCase_CreateBooking_PromoCodeExhausted(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	promoBooking := &models.Booking{
+		Tenant:    tenantID,
+		DateStart: "2022-01-02",
+		DateEnd:   "2022-01-04",
+		Room:      firstRoom.ID,
+		PromoCode: "WINTER",
+	}
+	promoCode := &models.PromoCode{Code: "WINTER", UsageLimit: 1,
+		DiscountType: models.DiscountTypeFixed, DiscountValue: 100}
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(firstRoom, nil)
+	promoUseCase.
+		EXPECT().
+		CheckPromoCode("WINTER", firstRoom, "2022-01-02", "2022-01-04").
+		Return(promoCode, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, "2022-01-02", "2022-01-04", uint64(0), promoCode).
+		Return(&models.Quote{Guests: 1, PromoCode: "WINTER", Discount: 100, Total: 900}, nil)
+	// Another booking took the last use after the check
+	bookingRep.
+		EXPECT().
+		Insert(gomock.Any(), promoBooking, gomock.Any(), gomock.Any()).
+		Return(promo.ErrExhausted)
+
+	err := bookingUseCase.CreateBooking(ctx, actor, promoBooking, paymentToken)
+	assert.Equal(t, errors.Get(consts.CodePromoCodeExhausted), err)
+}
+
+func TestBookingUseCase_OtherTenant(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	const otherTenant uint64 = 2
+
+	// Neither the booking nor the room of another tenant is found,
+	// so nothing gets cancelled, confirmed or listed
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), otherTenant, bookingModel.ID).
+		Return(nil, sql.ErrNoRows).
+		Times(3)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), otherTenant, bookingModel.Room).
+		Return(nil, sql.ErrNoRows)
+
+	_, err := bookingUseCase.GetBooking(ctx, otherTenant, bookingModel.ID)
+	assert.Equal(t, errors.Get(consts.CodeBookingDoesNotExist), err)
+	_, err = bookingUseCase.CancelBooking(ctx, otherTenant, actor, bookingModel.ID, models.AnyVersion)
+	assert.Equal(t, errors.Get(consts.CodeBookingDoesNotExist), err)
+	err = bookingUseCase.ConfirmBooking(ctx, otherTenant, actor, bookingModel.ID, models.AnyVersion,
+		paymentToken)
+	assert.Equal(t, errors.Get(consts.CodeBookingDoesNotExist), err)
+	_, err = bookingUseCase.GetRoomBookings(ctx, otherTenant, bookingModel.Room)
+	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), err)
+}
+
+func TestBookingUseCase_ReleaseExpiredHolds(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bookingRep := mocks.NewMockBookingRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	promoUseCase := mockPromo.NewMockPromoUseCase(ctrl)
+	bookingUseCase := NewBookingUseCase(runInPlace(ctrl), bookingRep, roomRep, propertyUseCase,
+		promoUseCase, paymentUseCase, holdTTL)
+	expired := &models.Booking{Tenant: tenantID, ID: 3, Status: models.BookingStatusCancelled, Version: 2}
+
+	// The hold is kept cancelled and its authorization is released
+	bookingRep.
+		EXPECT().
+		CancelExpiredHolds(gomock.Any(), models.SystemActor).
+		Return([]*models.Booking{expired}, nil)
+	paymentUseCase.
+		EXPECT().
+		Settle(expired).
+		DoAndReturn(func(cancelled *models.Booking) *errors.Error {
+			cancelled.RefundStatus = models.RefundStatusFailed
+			return errors.Get(consts.CodePaymentGatewayError)
+		})
+	bookingRep.
+		EXPECT().
+		UpdateRefund(gomock.Any(), expired, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, cancelled *models.Booking, _ *models.AuditEntry,
+			events ...*models.Event) error {
+			// The failed release is left to the refund job
+			assert.Equal(t, models.RefundStatusFailed, cancelled.RefundStatus)
+			assert.Equal(t, []*models.Event{
+				models.NewEvent(tenantID, models.EventBookingCancelled, cancelled)}, events)
+			return nil
+		})
+
+	released, err := bookingUseCase.ReleaseExpiredHolds(ctx)
 	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, int64(1), released)
 }
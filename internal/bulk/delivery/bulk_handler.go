@@ -0,0 +1,75 @@
+package delivery
+
+import (
+	"context"
+	"github.com/booking_backend/internal/bulk"
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type BulkHandler struct {
+	bulkUseCase bulk.BulkUseCase
+}
+
+func NewBulkHandler(useCase bulk.BulkUseCase) *BulkHandler {
+	return &BulkHandler{bulkUseCase: useCase}
+}
+
+func (bh *BulkHandler) Configure(e *echo.Echo) {
+	e.POST("rooms/import",
+		bh.Import(bh.bulkUseCase.ImportRooms), principal.Require(rbac.ImportData))
+	e.POST("bookings/import",
+		bh.Import(bh.bulkUseCase.ImportBookings), principal.Require(rbac.ImportData))
+}
+
+type importFunc func(ctx context.Context, tenant uint64, actor *models.Actor, file io.Reader,
+	atomic bool) (*models.ImportReport, *errors.Error)
+
+// Import takes the CSV file either from the multipart field file or as the
+// request body. ?atomic=true rejects the whole file on any error
+func (bh *BulkHandler) Import(importFile importFunc) echo.HandlerFunc {
+	return func(context echo.Context) error {
+		atomic := false
+		if value := context.FormValue("atomic"); value != "" {
+			var parseErr error
+			if atomic, parseErr = strconv.ParseBool(value); parseErr != nil {
+				customErr := errors.New(CodeBadRequest, parseErr)
+				logrus.Info(customErr)
+				return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+			}
+		}
+
+		var file io.Reader = context.Request().Body
+		if header, err := context.FormFile("file"); err == nil {
+			upload, err := header.Open()
+			if err != nil {
+				customErr := errors.New(CodeInternalError, err)
+				logrus.Error(customErr)
+				return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+			}
+			defer upload.Close()
+			file = upload
+		}
+
+		report, customErr := importFile(context.Request().Context(), principal.Tenant(context), principal.Actor(context),
+			file, atomic)
+		if customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		if report.Imported == 0 && len(report.Errors) != 0 {
+			return context.JSON(http.StatusUnprocessableEntity, report)
+		}
+		return context.JSON(http.StatusOK, report)
+	}
+}
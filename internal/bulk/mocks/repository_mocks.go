@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mock_bulk is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockBulkRepository is a mock of BulkRepository interface
+type MockBulkRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBulkRepositoryMockRecorder
+}
+
+// MockBulkRepositoryMockRecorder is the mock recorder for MockBulkRepository
+type MockBulkRepositoryMockRecorder struct {
+	mock *MockBulkRepository
+}
+
+// NewMockBulkRepository creates a new mock instance
+func NewMockBulkRepository(ctrl *gomock.Controller) *MockBulkRepository {
+	mock := &MockBulkRepository{ctrl: ctrl}
+	mock.recorder = &MockBulkRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBulkRepository) EXPECT() *MockBulkRepositoryMockRecorder {
+	return m.recorder
+}
+
+// InsertRooms mocks base method
+func (m *MockBulkRepository) InsertRooms(ctx context.Context, actor *models.Actor, rooms []*models.Room, atomic bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRooms", ctx, actor, rooms, atomic)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRooms indicates an expected call of InsertRooms
+func (mr *MockBulkRepositoryMockRecorder) InsertRooms(ctx, actor, rooms, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRooms", reflect.TypeOf((*MockBulkRepository)(nil).InsertRooms), ctx, actor, rooms, atomic)
+}
+
+// InsertBookings mocks base method
+func (m *MockBulkRepository) InsertBookings(ctx context.Context, actor *models.Actor, bookings []*models.Booking, atomic bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBookings", ctx, actor, bookings, atomic)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertBookings indicates an expected call of InsertBookings
+func (mr *MockBulkRepositoryMockRecorder) InsertBookings(ctx, actor, bookings, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBookings", reflect.TypeOf((*MockBulkRepository)(nil).InsertBookings), ctx, actor, bookings, atomic)
+}
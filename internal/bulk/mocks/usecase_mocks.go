@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_bulk is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	io "io"
+	reflect "reflect"
+)
+
+// MockBulkUseCase is a mock of BulkUseCase interface
+type MockBulkUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockBulkUseCaseMockRecorder
+}
+
+// MockBulkUseCaseMockRecorder is the mock recorder for MockBulkUseCase
+type MockBulkUseCaseMockRecorder struct {
+	mock *MockBulkUseCase
+}
+
+// NewMockBulkUseCase creates a new mock instance
+func NewMockBulkUseCase(ctrl *gomock.Controller) *MockBulkUseCase {
+	mock := &MockBulkUseCase{ctrl: ctrl}
+	mock.recorder = &MockBulkUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBulkUseCase) EXPECT() *MockBulkUseCaseMockRecorder {
+	return m.recorder
+}
+
+// ImportRooms mocks base method
+func (m *MockBulkUseCase) ImportRooms(ctx context.Context, tenant uint64, actor *models.Actor, file io.Reader, atomic bool) (*models.ImportReport, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportRooms", ctx, tenant, actor, file, atomic)
+	ret0, _ := ret[0].(*models.ImportReport)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// ImportRooms indicates an expected call of ImportRooms
+func (mr *MockBulkUseCaseMockRecorder) ImportRooms(ctx, tenant, actor, file, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportRooms", reflect.TypeOf((*MockBulkUseCase)(nil).ImportRooms), ctx, tenant, actor, file, atomic)
+}
+
+// ImportBookings mocks base method
+func (m *MockBulkUseCase) ImportBookings(ctx context.Context, tenant uint64, actor *models.Actor, file io.Reader, atomic bool) (*models.ImportReport, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportBookings", ctx, tenant, actor, file, atomic)
+	ret0, _ := ret[0].(*models.ImportReport)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// ImportBookings indicates an expected call of ImportBookings
+func (mr *MockBulkUseCaseMockRecorder) ImportBookings(ctx, tenant, actor, file, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportBookings", reflect.TypeOf((*MockBulkUseCase)(nil).ImportBookings), ctx, tenant, actor, file, atomic)
+}
@@ -0,0 +1,17 @@
+package bulk
+
+import (
+	"context"
+	"github.com/booking_backend/internal/models"
+)
+
+// BulkRepository stores the rows in one transaction and returns the error
+// of every row, nil for stored ones. In the atomic mode nothing is stored
+// if any row fails. Every stored row is recorded in the audit log on behalf
+// of the actor and announced in the outbox, as a row created one by one is
+type BulkRepository interface {
+	InsertRooms(ctx context.Context, actor *models.Actor, rooms []*models.Room,
+		atomic bool) ([]error, error)
+	InsertBookings(ctx context.Context, actor *models.Actor, bookings []*models.Booking,
+		atomic bool) ([]error, error)
+}
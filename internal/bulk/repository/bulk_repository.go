@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	auditRepository "github.com/booking_backend/internal/audit/repository"
+	bookingRepository "github.com/booking_backend/internal/booking/repository"
+	"github.com/booking_backend/internal/bulk"
+	"github.com/booking_backend/internal/models"
+	outboxRepository "github.com/booking_backend/internal/outbox/repository"
+	roomRepository "github.com/booking_backend/internal/room/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type BulkRepository struct {
+	db *sql.DB
+}
+
+func NewBulkRepository(db *sql.DB) bulk.BulkRepository {
+	return &BulkRepository{db: db}
+}
+
+func rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		logrus.Info(err)
+	}
+}
+
+// insertRows inserts every row after a savepoint, so a failed row is undone
+// without aborting the transaction and the following rows can be checked
+func (rep *BulkRepository) insertRows(ctx context.Context, count int, atomic bool,
+	insert func(tx *sql.Tx, i int) error) ([]error, error) {
+	tx, err := rep.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	rowErrs := make([]error, count)
+	failed := false
+	for i := 0; i < count; i++ {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT bulk_row`); err != nil {
+			rollback(tx)
+			return nil, err
+		}
+
+		if rowErrs[i] = insert(tx, i); rowErrs[i] != nil {
+			failed = true
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT bulk_row`); err != nil {
+				rollback(tx)
+				return nil, err
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT bulk_row`); err != nil {
+			rollback(tx)
+			return nil, err
+		}
+	}
+
+	if atomic && failed {
+		rollback(tx)
+		return rowErrs, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return rowErrs, nil
+}
+
+// record writes the audit entry and the event of the row, they are undone
+// along with the row when it fails
+func record(ctx context.Context, tx *sql.Tx, entry *models.AuditEntry, event *models.Event) error {
+	if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+	return outboxRepository.InsertEvent(ctx, tx, event)
+}
+
+func (rep *BulkRepository) InsertRooms(ctx context.Context, actor *models.Actor,
+	rooms []*models.Room, atomic bool) ([]error, error) {
+	return rep.insertRows(ctx, len(rooms), atomic, func(tx *sql.Tx, i int) error {
+		room := rooms[i]
+		if err := roomRepository.InsertRoom(ctx, tx, room); err != nil {
+			return err
+		}
+		return record(ctx, tx,
+			actor.Entry(room.Tenant, models.AuditActionCreate, models.AuditEntityRoom,
+				room.ID, nil, room),
+			models.NewEvent(room.Tenant, models.EventRoomCreated, room))
+	})
+}
+
+// InsertBookings checks every booking against the rooms as they are after
+// the previous rows, so bookings of the same file can't overlap either
+func (rep *BulkRepository) InsertBookings(ctx context.Context, actor *models.Actor,
+	bookings []*models.Booking, atomic bool) ([]error, error) {
+	return rep.insertRows(ctx, len(bookings), atomic, func(tx *sql.Tx, i int) error {
+		booking := bookings[i]
+		if err := bookingRepository.InsertBooking(ctx, tx, booking); err != nil {
+			return err
+		}
+		return record(ctx, tx,
+			actor.Entry(booking.Tenant, models.AuditActionCreate, models.AuditEntityBooking,
+				booking.ID, nil, booking),
+			models.NewEvent(booking.Tenant, models.EventBookingCreated, booking))
+	})
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	auditMocks "github.com/booking_backend/internal/audit/mocks"
+	"github.com/booking_backend/internal/models"
+	outboxMocks "github.com/booking_backend/internal/outbox/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var errPriceCheck = errors.New("price check violated")
+
+var actor = &models.Actor{Subject: "key:1", RequestID: "request-1"}
+
+func expectRooms(mock sqlmock.Sqlmock, rooms []*models.Room) {
+	mock.ExpectBegin()
+	for i, room := range rooms {
+		mock.ExpectExec(`SAVEPOINT bulk_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+		query := mock.ExpectQuery(`INSERT INTO rooms`).
+			WithArgs(room.Description, room.Price, room.Created, room.Property, room.Tenant)
+		if i == 1 {
+			query.WillReturnError(errPriceCheck)
+			mock.ExpectExec(`ROLLBACK TO SAVEPOINT bulk_row`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			continue
+		}
+		query.WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(i+1, 1))
+		auditMocks.MockInsertEntry(mock, actor.Entry(room.Tenant, models.AuditActionCreate,
+			models.AuditEntityRoom, uint64(i+1), nil, room))
+		outboxMocks.MockInsertEvent(mock, models.NewEvent(room.Tenant, models.EventRoomCreated, room))
+		mock.ExpectExec(`RELEASE SAVEPOINT bulk_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
+func newRooms() []*models.Room {
+	created := time.Date(2021, 12, 1, 12, 0, 0, 0, time.UTC)
+	return []*models.Room{
+		{Description: "Standard", Price: 500, Created: created, Property: 1, Tenant: 1},
+		{Description: "Suite", Price: 1500, Created: created, Property: 1, Tenant: 1},
+		{Description: "Family", Price: 900, Created: created, Property: 1, Tenant: 1},
+	}
+}
+
+func TestBulkRepository_InsertRooms(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rooms := newRooms()
+	expectRooms(mock, rooms)
+	mock.ExpectCommit()
+
+	rep := NewBulkRepository(db)
+	rowErrs, err := rep.InsertRooms(context.Background(), actor, rooms, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []error{nil, errPriceCheck, nil}, rowErrs)
+	assert.Equal(t, uint64(3), rooms[2].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkRepository_InsertRooms_Atomic(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rooms := newRooms()
+	expectRooms(mock, rooms)
+	mock.ExpectRollback()
+
+	rep := NewBulkRepository(db)
+	rowErrs, err := rep.InsertRooms(context.Background(), actor, rooms, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []error{nil, errPriceCheck, nil}, rowErrs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// The audit entry is written after the savepoint of the row, a failed one
+// undoes the row instead of aborting the import
+func TestBulkRepository_InsertRooms_AuditFails(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rooms := newRooms()[:1]
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT bulk_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO rooms`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+	mock.ExpectQuery(`INSERT INTO audit_log`).WillReturnError(errPriceCheck)
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT bulk_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	rep := NewBulkRepository(db)
+	rowErrs, err := rep.InsertRooms(context.Background(), actor, rooms, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []error{errPriceCheck}, rowErrs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
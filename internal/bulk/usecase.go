@@ -0,0 +1,15 @@
+package bulk
+
+import (
+	"context"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"io"
+)
+
+type BulkUseCase interface {
+	ImportRooms(ctx context.Context, tenant uint64, actor *models.Actor, file io.Reader,
+		atomic bool) (*models.ImportReport, *errors.Error)
+	ImportBookings(ctx context.Context, tenant uint64, actor *models.Actor, file io.Reader,
+		atomic bool) (*models.ImportReport, *errors.Error)
+}
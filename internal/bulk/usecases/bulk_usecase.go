@@ -0,0 +1,273 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/bulk"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/csvio"
+	"github.com/booking_backend/internal/helpers/dates"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/property"
+	"github.com/booking_backend/internal/room"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxRows keeps a single import within one reasonable transaction
+const maxRows = 10000
+
+type BulkUseCase struct {
+	bulkRepo        bulk.BulkRepository
+	roomRepo        room.RoomRepository
+	propertyRepo    property.PropertyRepository
+	propertyUseCase property.PropertyUseCase
+}
+
+func NewBulkUseCase(bulkRepository bulk.BulkRepository, roomRepository room.RoomRepository,
+	propertyRepository property.PropertyRepository,
+	propertyUseCase property.PropertyUseCase) bulk.BulkUseCase {
+	return &BulkUseCase{
+		bulkRepo:        bulkRepository,
+		roomRepo:        roomRepository,
+		propertyRepo:    propertyRepository,
+		propertyUseCase: propertyUseCase,
+	}
+}
+
+func rowError(row *csvio.Row, column string, message string) *models.ImportError {
+	return &models.ImportError{Row: row.Number, Column: column, Message: message}
+}
+
+func parseUint(row *csvio.Row, column string, required bool) (uint64, *models.ImportError) {
+	value := row.Get(column)
+	if value == "" {
+		if required {
+			return 0, rowError(row, column, column+" is required")
+		}
+		return 0, nil
+	}
+	number, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || (required && number == 0) {
+		return 0, rowError(row, column, column+" must be a positive integer")
+	}
+	return number, nil
+}
+
+func parseDate(row *csvio.Row, column string) (string, *models.ImportError) {
+	value := row.Get(column)
+	if value == "" {
+		return "", rowError(row, column, column+" is required")
+	}
+	if _, err := time.Parse(dates.Layout, value); err != nil {
+		return "", rowError(row, column, column+" must be a date in YYYY-MM-DD format")
+	}
+	return value, nil
+}
+
+// readRows calls parse for every row of the file. parse returns a row error
+// for invalid data and a custom error for failures aborting the import
+func readRows(file io.Reader, columns []string,
+	parse func(row *csvio.Row) (*models.ImportError, *errors.Error)) (*models.ImportReport, *errors.Error) {
+	reader, err := csvio.NewReader(file, columns...)
+	if err != nil {
+		return nil, errors.New(consts.CodeBadRequest, err)
+	}
+
+	report := &models.ImportReport{IDs: []uint64{}, Errors: []*models.ImportError{}}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.New(consts.CodeBadRequest, err)
+		}
+		if row.Number > maxRows {
+			return nil, errors.New(consts.CodeBadRequest,
+				fmt.Errorf("file has more than %d rows", maxRows))
+		}
+
+		report.Rows++
+		rowErr, customErr := parse(row)
+		if customErr != nil {
+			return nil, customErr
+		}
+		if rowErr != nil {
+			report.Errors = append(report.Errors, rowErr)
+		}
+	}
+	return report, nil
+}
+
+func addResult(report *models.ImportReport, row uint64, id uint64, err error) {
+	switch err {
+	case nil:
+		report.Imported++
+		report.IDs = append(report.IDs, id)
+	case booking.ErrRoomIsOccupied:
+		report.Errors = append(report.Errors, &models.ImportError{
+			Row: row, Message: errors.Get(consts.CodeRoomIsOccupied).Message})
+	case sql.ErrNoRows:
+		report.Errors = append(report.Errors, &models.ImportError{
+			Row: row, Column: "room_id", Message: errors.Get(consts.CodeRoomDoesNotExist).Message})
+	default:
+		report.Errors = append(report.Errors, &models.ImportError{Row: row, Message: err.Error()})
+	}
+}
+
+// finish orders the errors by rows. A failed atomic import stores nothing
+func finish(report *models.ImportReport, atomic bool) *models.ImportReport {
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Row < report.Errors[j].Row
+	})
+	if atomic && len(report.Errors) != 0 {
+		report.Imported = 0
+		report.IDs = []uint64{}
+	}
+	return report
+}
+
+func (uc *BulkUseCase) ImportRooms(ctx context.Context, tenant uint64, actor *models.Actor, file io.Reader,
+	atomic bool) (*models.ImportReport, *errors.Error) {
+	var rooms []*models.Room
+	var rows []uint64
+	properties := map[uint64]bool{}
+	created := time.Now()
+
+	report, customErr := readRows(file, []string{"description", "price"},
+		func(row *csvio.Row) (*models.ImportError, *errors.Error) {
+			roomModel := &models.Room{
+				Description: row.Get("description"),
+				Created:     created,
+				Property:    models.DefaultPropertyID,
+				Tenant:      tenant,
+			}
+			if roomModel.Description == "" {
+				return rowError(row, "description", "description is required"), nil
+			}
+
+			var rowErr *models.ImportError
+			if roomModel.Price, rowErr = parseUint(row, "price", true); rowErr != nil {
+				return rowErr, nil
+			}
+			propertyID, rowErr := parseUint(row, "property_id", false)
+			if rowErr != nil {
+				return rowErr, nil
+			}
+			if propertyID != 0 {
+				roomModel.Property = propertyID
+			}
+
+			exists, has := properties[roomModel.Property]
+			if !has {
+				_, err := uc.propertyRepo.SelectByID(tenant, roomModel.Property)
+				if err != nil && err != sql.ErrNoRows {
+					return nil, errors.New(consts.CodeInternalError, err)
+				}
+				exists = err == nil
+				properties[roomModel.Property] = exists
+			}
+			if !exists {
+				return rowError(row, "property_id",
+					errors.Get(consts.CodePropertyDoesNotExist).Message), nil
+			}
+
+			rooms = append(rooms, roomModel)
+			rows = append(rows, row.Number)
+			return nil, nil
+		})
+	if customErr != nil {
+		return nil, customErr
+	}
+	if atomic && len(report.Errors) != 0 {
+		return report, nil
+	}
+
+	rowErrs, err := uc.bulkRepo.InsertRooms(ctx, actor, rooms, atomic)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	for i, roomModel := range rooms {
+		addResult(report, rows[i], roomModel.ID, rowErrs[i])
+	}
+	return finish(report, atomic), nil
+}
+
+func (uc *BulkUseCase) ImportBookings(ctx context.Context, tenant uint64, actor *models.Actor, file io.Reader,
+	atomic bool) (*models.ImportReport, *errors.Error) {
+	var bookings []*models.Booking
+	var rows []uint64
+	rooms := map[uint64]*models.Room{}
+
+	report, customErr := readRows(file, []string{"room_id", "date_start", "date_end"},
+		func(row *csvio.Row) (*models.ImportError, *errors.Error) {
+			bookingModel := &models.Booking{Status: models.BookingStatusConfirmed}
+
+			var rowErr *models.ImportError
+			if bookingModel.Room, rowErr = parseUint(row, "room_id", true); rowErr != nil {
+				return rowErr, nil
+			}
+			if bookingModel.DateStart, rowErr = parseDate(row, "date_start"); rowErr != nil {
+				return rowErr, nil
+			}
+			if bookingModel.DateEnd, rowErr = parseDate(row, "date_end"); rowErr != nil {
+				return rowErr, nil
+			}
+			if dates.CheckDates(bookingModel.DateStart, bookingModel.DateEnd) != nil {
+				return rowError(row, "date_end",
+					errors.Get(consts.CodeIncorrectDates).Message), nil
+			}
+			if bookingModel.Guests, rowErr = parseUint(row, "guests", false); rowErr != nil {
+				return rowErr, nil
+			}
+			if bookingModel.Guests == 0 {
+				bookingModel.Guests = 1
+			}
+
+			roomModel, has := rooms[bookingModel.Room]
+			if !has {
+				var err error
+				roomModel, err = uc.roomRepo.SelectByID(ctx, tenant, bookingModel.Room)
+				if err != nil && err != sql.ErrNoRows {
+					return nil, errors.New(consts.CodeInternalError, err)
+				}
+				rooms[bookingModel.Room] = roomModel
+			}
+			if roomModel == nil {
+				return rowError(row, "room_id",
+					errors.Get(consts.CodeRoomDoesNotExist).Message), nil
+			}
+
+			quote, customErr := uc.propertyUseCase.QuoteStay(roomModel,
+				bookingModel.DateStart, bookingModel.DateEnd, bookingModel.Guests, nil)
+			if customErr != nil {
+				return nil, customErr
+			}
+			bookingModel.Quote = quote
+			bookingModel.Amount = quote.Total
+
+			bookings = append(bookings, bookingModel)
+			rows = append(rows, row.Number)
+			return nil, nil
+		})
+	if customErr != nil {
+		return nil, customErr
+	}
+	if atomic && len(report.Errors) != 0 {
+		return report, nil
+	}
+
+	rowErrs, err := uc.bulkRepo.InsertBookings(ctx, actor, bookings, atomic)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	for i, bookingModel := range bookings {
+		addResult(report, rows[i], bookingModel.ID, rowErrs[i])
+	}
+	return finish(report, atomic), nil
+}
@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/bulk/mocks"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	propertyMocks "github.com/booking_backend/internal/property/mocks"
+	roomMocks "github.com/booking_backend/internal/room/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const tenantID = models.DefaultTenantID
+
+var actor = &models.Actor{Subject: "key:1"}
+
+var roomModel = &models.Room{
+	ID:       2,
+	Price:    500,
+	Property: 1,
+	Tenant:   tenantID,
+}
+
+type fixture struct {
+	bulkRep         *mocks.MockBulkRepository
+	roomRep         *roomMocks.MockRoomRepository
+	propertyRep     *propertyMocks.MockPropertyRepository
+	propertyUseCase *propertyMocks.MockPropertyUseCase
+	useCase         *BulkUseCase
+}
+
+func newFixture(ctrl *gomock.Controller) *fixture {
+	f := &fixture{
+		bulkRep:         mocks.NewMockBulkRepository(ctrl),
+		roomRep:         roomMocks.NewMockRoomRepository(ctrl),
+		propertyRep:     propertyMocks.NewMockPropertyRepository(ctrl),
+		propertyUseCase: propertyMocks.NewMockPropertyUseCase(ctrl),
+	}
+	f.useCase = NewBulkUseCase(f.bulkRep, f.roomRep, f.propertyRep, f.propertyUseCase).(*BulkUseCase)
+	return f
+}
+
+const roomsFile = "description,price,property_id\n" +
+	"Standard,500,\n" +
+	"Suite,abc,1\n" +
+	",700,\n" +
+	"Lux,1500,9\n" +
+	"Family,900,1\n"
+
+func TestBulkUseCase_ImportRooms(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	f := newFixture(ctrl)
+	f.propertyRep.EXPECT().SelectByID(tenantID, uint64(1)).Return(&models.Property{ID: 1}, nil)
+	f.propertyRep.EXPECT().SelectByID(tenantID, uint64(9)).Return(nil, sql.ErrNoRows)
+	f.bulkRep.EXPECT().InsertRooms(gomock.Any(), actor, gomock.Any(), false).
+		DoAndReturn(func(ctx context.Context, actor *models.Actor, rooms []*models.Room, atomic bool) ([]error, error) {
+			assert.Len(t, rooms, 2)
+			assert.Equal(t, "Standard", rooms[0].Description)
+			assert.Equal(t, uint64(500), rooms[0].Price)
+			assert.Equal(t, uint64(models.DefaultPropertyID), rooms[0].Property)
+			assert.Equal(t, tenantID, rooms[0].Tenant)
+			assert.Equal(t, "Family", rooms[1].Description)
+			rooms[0].ID, rooms[1].ID = 10, 11
+			return []error{nil, nil}, nil
+		})
+
+	report, err := f.useCase.ImportRooms(context.Background(), tenantID, actor, strings.NewReader(roomsFile), false)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, &models.ImportReport{
+		Rows:     5,
+		Imported: 2,
+		IDs:      []uint64{10, 11},
+		Errors: []*models.ImportError{
+			{Row: 2, Column: "price", Message: "price must be a positive integer"},
+			{Row: 3, Column: "description", Message: "description is required"},
+			{Row: 4, Column: "property_id", Message: "property with this id doesn't exist"},
+		},
+	}, report)
+}
+
+func TestBulkUseCase_ImportRooms_Atomic(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	f := newFixture(ctrl)
+	f.propertyRep.EXPECT().SelectByID(tenantID, uint64(1)).Return(&models.Property{ID: 1}, nil)
+	f.propertyRep.EXPECT().SelectByID(tenantID, uint64(9)).Return(nil, sql.ErrNoRows)
+
+	report, err := f.useCase.ImportRooms(context.Background(), tenantID, actor, strings.NewReader(roomsFile), true)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, uint64(0), report.Imported)
+	assert.Len(t, report.Errors, 3)
+}
+
+func TestBulkUseCase_ImportRooms_MissingColumn(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	f := newFixture(ctrl)
+	_, err := f.useCase.ImportRooms(context.Background(), tenantID, actor, strings.NewReader("description\nStandard\n"), false)
+	assert.Equal(t, consts.CodeBadRequest, err.Code)
+}
+
+func TestBulkUseCase_ImportBookings_Atomic(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	file := "room_id,date_start,date_end,guests\n" +
+		"2,2022-01-02,2022-01-05,2\n" +
+		"2,2022-01-04,2022-01-06,\n"
+	quote := &models.Quote{Nights: 3, Guests: 2, Total: 1500}
+	f := newFixture(ctrl)
+	f.roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+	f.propertyUseCase.EXPECT().
+		QuoteStay(roomModel, "2022-01-02", "2022-01-05", uint64(2), nil).
+		Return(quote, nil)
+	f.propertyUseCase.EXPECT().
+		QuoteStay(roomModel, "2022-01-04", "2022-01-06", uint64(1), nil).
+		Return(&models.Quote{Nights: 2, Guests: 1, Total: 1000}, nil)
+	f.bulkRep.EXPECT().InsertBookings(gomock.Any(), actor, gomock.Any(), true).
+		DoAndReturn(func(ctx context.Context, actor *models.Actor, bookings []*models.Booking, atomic bool) ([]error, error) {
+			assert.Len(t, bookings, 2)
+			assert.Equal(t, models.BookingStatusConfirmed, bookings[0].Status)
+			assert.Equal(t, uint64(1500), bookings[0].Amount)
+			assert.Equal(t, quote, bookings[0].Quote)
+			bookings[0].ID = 7
+			return []error{nil, booking.ErrRoomIsOccupied}, nil
+		})
+
+	report, err := f.useCase.ImportBookings(context.Background(), tenantID, actor, strings.NewReader(file), true)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, &models.ImportReport{
+		Rows:   2,
+		IDs:    []uint64{},
+		Errors: []*models.ImportError{{Row: 2, Message: "room is occupied on these dates"}},
+	}, report)
+}
+
+func TestBulkUseCase_ImportBookings_InvalidRows(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	file := "room_id,date_start,date_end\n" +
+		"3,2022-01-02,2022-01-05\n" +
+		"2,02.01.2022,2022-01-05\n" +
+		"2,2022-01-05,2022-01-02\n"
+	f := newFixture(ctrl)
+	f.roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, uint64(3)).Return(nil, sql.ErrNoRows)
+	f.bulkRep.EXPECT().InsertBookings(gomock.Any(), actor, nil, false).Return([]error{}, nil)
+
+	report, err := f.useCase.ImportBookings(context.Background(), tenantID, actor, strings.NewReader(file), false)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, []*models.ImportError{
+		{Row: 1, Column: "room_id", Message: "room with this id doesn't exist"},
+		{Row: 2, Column: "date_start", Message: "date_start must be a date in YYYY-MM-DD format"},
+		{Row: 3, Column: "date_end", Message: errors.Get(consts.CodeIncorrectDates).Message},
+	}, report.Errors)
+}
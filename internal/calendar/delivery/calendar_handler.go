@@ -0,0 +1,208 @@
+package delivery
+
+import (
+	"bytes"
+	"github.com/booking_backend/internal/calendar"
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/ical"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/request_reader"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const MIMETextCalendar = "text/calendar; charset=utf-8"
+
+type CalendarHandler struct {
+	calendarUseCase calendar.CalendarUseCase
+}
+
+func NewCalendarHandler(useCase calendar.CalendarUseCase) *CalendarHandler {
+	return &CalendarHandler{calendarUseCase: useCase}
+}
+
+func (ch *CalendarHandler) Configure(e *echo.Echo) {
+	e.GET("rooms/:id/calendar.ics",
+		ch.GetRoomCalendar(), principal.Require(rbac.ViewBookings))
+	e.POST("rooms/:id/calendar/import",
+		ch.ImportRoomCalendar(), principal.Require(rbac.ManageRooms))
+	e.POST("rooms/:id/blocks",
+		ch.CreateBlock(), principal.Require(rbac.ManageRooms))
+	e.GET("rooms/:id/blocks",
+		ch.GetRoomBlocks(), principal.Require(rbac.ViewBookings))
+	e.DELETE("rooms/:id/blocks/:block_id",
+		ch.DeleteBlock(), principal.Require(rbac.ManageRooms))
+}
+
+type BlockID struct {
+	ID uint64 `json:"block_id"`
+}
+
+func (ch *CalendarHandler) GetRoomCalendar() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		roomCalendar, customErr := ch.calendarUseCase.GetRoomCalendar(principal.Tenant(context), roomID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		feed := &bytes.Buffer{}
+		if err := ical.Write(feed, roomCalendar, time.Now()); err != nil {
+			customErr := errors.New(CodeInternalError, err)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		return context.Blob(http.StatusOK, MIMETextCalendar, feed.Bytes())
+	}
+}
+
+// ImportRoomCalendar takes the feed either from the uploaded file or from
+// the URL. The source tells the feeds apart, so importing the same feed again
+// replaces its blocks. It is the URL or, for a file, the source parameter
+// falling back to the file name
+func (ch *CalendarHandler) ImportRoomCalendar() echo.HandlerFunc {
+	type Request struct {
+		URL    string `form:"url" validate:"omitempty,url"`
+		Source string `form:"source" validate:"max=256"`
+	}
+
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		var result *models.CalendarImport
+		var customErr *errors.Error
+		if header, err := context.FormFile("file"); err == nil {
+			file, err := header.Open()
+			if err != nil {
+				customErr := errors.New(CodeInternalError, err)
+				logrus.Error(customErr)
+				return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+			}
+			defer file.Close()
+
+			source := req.Source
+			if source == "" {
+				source = header.Filename
+			}
+			result, customErr = ch.calendarUseCase.ImportRoomCalendar(principal.Tenant(context), roomID, source, file)
+		} else if req.URL != "" {
+			result, customErr = ch.calendarUseCase.ImportRoomCalendarURL(principal.Tenant(context), roomID, req.URL)
+		} else {
+			customErr = errors.Get(CodeBadRequest)
+		}
+		if customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, result)
+	}
+}
+
+func (ch *CalendarHandler) CreateBlock() echo.HandlerFunc {
+	type Request struct {
+		DateStart models.CustomDate `form:"date_start" validate:"required"`
+		DateEnd   models.CustomDate `form:"date_end" validate:"required"`
+		Reason    string            `form:"reason" validate:"max=256"`
+	}
+
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		block := &models.RoomBlock{
+			Room:      roomID,
+			DateStart: req.DateStart.Date,
+			DateEnd:   req.DateEnd.Date,
+			Reason:    req.Reason,
+			Created:   time.Now(),
+		}
+
+		if customErr := ch.calendarUseCase.CreateBlock(principal.Tenant(context), block); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, BlockID{ID: block.ID})
+	}
+}
+
+func (ch *CalendarHandler) GetRoomBlocks() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		blocks, customErr := ch.calendarUseCase.GetRoomBlocks(principal.Tenant(context), roomID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, blocks)
+	}
+}
+
+func (ch *CalendarHandler) DeleteBlock() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		blockID, parseErr := strconv.ParseUint(context.Param("block_id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		if customErr := ch.calendarUseCase.DeleteBlock(principal.Tenant(context), roomID, blockID); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{
+			Message: "success",
+		})
+	}
+}
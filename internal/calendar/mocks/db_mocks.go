@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func MockLockRoom(mock sqlmock.Sqlmock, roomID uint64) {
+	mock.ExpectQuery(`SELECT id FROM rooms`).
+		WithArgs(roomID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(roomID))
+}
+
+// MockUpsertExternalBlock expects the block to be inserted, updated or,
+// with changed false, left as it is
+func MockUpsertExternalBlock(mock sqlmock.Sqlmock, block *models.RoomBlock,
+	inserted bool, changed bool) {
+	query := mock.ExpectQuery(`INSERT INTO room_blocks`).
+		WithArgs(block.Room, block.DateStart, block.DateEnd, block.Reason,
+			block.Source, block.SourceUID, block.Created)
+	if !changed {
+		query.WillReturnError(sql.ErrNoRows)
+		return
+	}
+	query.WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).
+		AddRow(block.ID, inserted))
+}
+
+func MockRemoveExternalBlocks(mock sqlmock.Sqlmock, roomID uint64,
+	source string, removed int64) {
+	mock.ExpectExec(`DELETE FROM room_blocks`).
+		WithArgs(roomID, source, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, removed))
+}
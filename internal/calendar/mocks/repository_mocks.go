@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mock_calendar is a generated GoMock package.
+package mocks
+
+import (
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockCalendarRepository is a mock of CalendarRepository interface
+type MockCalendarRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCalendarRepositoryMockRecorder
+}
+
+// MockCalendarRepositoryMockRecorder is the mock recorder for MockCalendarRepository
+type MockCalendarRepositoryMockRecorder struct {
+	mock *MockCalendarRepository
+}
+
+// NewMockCalendarRepository creates a new mock instance
+func NewMockCalendarRepository(ctrl *gomock.Controller) *MockCalendarRepository {
+	mock := &MockCalendarRepository{ctrl: ctrl}
+	mock.recorder = &MockCalendarRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCalendarRepository) EXPECT() *MockCalendarRepositoryMockRecorder {
+	return m.recorder
+}
+
+// InsertBlock mocks base method
+func (m *MockCalendarRepository) InsertBlock(block *models.RoomBlock) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBlock", block)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBlock indicates an expected call of InsertBlock
+func (mr *MockCalendarRepositoryMockRecorder) InsertBlock(block interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlock", reflect.TypeOf((*MockCalendarRepository)(nil).InsertBlock), block)
+}
+
+// DeleteBlock mocks base method
+func (m *MockCalendarRepository) DeleteBlock(roomID, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlock", roomID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlock indicates an expected call of DeleteBlock
+func (mr *MockCalendarRepositoryMockRecorder) DeleteBlock(roomID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlock", reflect.TypeOf((*MockCalendarRepository)(nil).DeleteBlock), roomID, id)
+}
+
+// SelectRoomBlocks mocks base method
+func (m *MockCalendarRepository) SelectRoomBlocks(roomID uint64) ([]*models.RoomBlock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectRoomBlocks", roomID)
+	ret0, _ := ret[0].([]*models.RoomBlock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectRoomBlocks indicates an expected call of SelectRoomBlocks
+func (mr *MockCalendarRepositoryMockRecorder) SelectRoomBlocks(roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRoomBlocks", reflect.TypeOf((*MockCalendarRepository)(nil).SelectRoomBlocks), roomID)
+}
+
+// ReplaceExternalBlocks mocks base method
+func (m *MockCalendarRepository) ReplaceExternalBlocks(roomID uint64, source string, blocks []*models.RoomBlock) (*models.CalendarImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceExternalBlocks", roomID, source, blocks)
+	ret0, _ := ret[0].(*models.CalendarImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceExternalBlocks indicates an expected call of ReplaceExternalBlocks
+func (mr *MockCalendarRepositoryMockRecorder) ReplaceExternalBlocks(roomID, source, blocks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceExternalBlocks", reflect.TypeOf((*MockCalendarRepository)(nil).ReplaceExternalBlocks), roomID, source, blocks)
+}
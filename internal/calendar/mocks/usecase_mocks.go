@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_calendar is a generated GoMock package.
+package mocks
+
+import (
+	errors "github.com/booking_backend/internal/helpers/errors"
+	ical "github.com/booking_backend/internal/helpers/ical"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	io "io"
+	reflect "reflect"
+)
+
+// MockCalendarUseCase is a mock of CalendarUseCase interface
+type MockCalendarUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockCalendarUseCaseMockRecorder
+}
+
+// MockCalendarUseCaseMockRecorder is the mock recorder for MockCalendarUseCase
+type MockCalendarUseCaseMockRecorder struct {
+	mock *MockCalendarUseCase
+}
+
+// NewMockCalendarUseCase creates a new mock instance
+func NewMockCalendarUseCase(ctrl *gomock.Controller) *MockCalendarUseCase {
+	mock := &MockCalendarUseCase{ctrl: ctrl}
+	mock.recorder = &MockCalendarUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCalendarUseCase) EXPECT() *MockCalendarUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateBlock mocks base method
+func (m *MockCalendarUseCase) CreateBlock(tenant uint64, block *models.RoomBlock) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlock", tenant, block)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// CreateBlock indicates an expected call of CreateBlock
+func (mr *MockCalendarUseCaseMockRecorder) CreateBlock(tenant, block interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlock", reflect.TypeOf((*MockCalendarUseCase)(nil).CreateBlock), tenant, block)
+}
+
+// DeleteBlock mocks base method
+func (m *MockCalendarUseCase) DeleteBlock(tenant, roomID, id uint64) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlock", tenant, roomID, id)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// DeleteBlock indicates an expected call of DeleteBlock
+func (mr *MockCalendarUseCaseMockRecorder) DeleteBlock(tenant, roomID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlock", reflect.TypeOf((*MockCalendarUseCase)(nil).DeleteBlock), tenant, roomID, id)
+}
+
+// GetRoomBlocks mocks base method
+func (m *MockCalendarUseCase) GetRoomBlocks(tenant, roomID uint64) ([]*models.RoomBlock, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomBlocks", tenant, roomID)
+	ret0, _ := ret[0].([]*models.RoomBlock)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetRoomBlocks indicates an expected call of GetRoomBlocks
+func (mr *MockCalendarUseCaseMockRecorder) GetRoomBlocks(tenant, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomBlocks", reflect.TypeOf((*MockCalendarUseCase)(nil).GetRoomBlocks), tenant, roomID)
+}
+
+// GetRoomCalendar mocks base method
+func (m *MockCalendarUseCase) GetRoomCalendar(tenant, roomID uint64) (*ical.Calendar, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomCalendar", tenant, roomID)
+	ret0, _ := ret[0].(*ical.Calendar)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetRoomCalendar indicates an expected call of GetRoomCalendar
+func (mr *MockCalendarUseCaseMockRecorder) GetRoomCalendar(tenant, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomCalendar", reflect.TypeOf((*MockCalendarUseCase)(nil).GetRoomCalendar), tenant, roomID)
+}
+
+// ImportRoomCalendar mocks base method
+func (m *MockCalendarUseCase) ImportRoomCalendar(tenant, roomID uint64, source string, feed io.Reader) (*models.CalendarImport, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportRoomCalendar", tenant, roomID, source, feed)
+	ret0, _ := ret[0].(*models.CalendarImport)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// ImportRoomCalendar indicates an expected call of ImportRoomCalendar
+func (mr *MockCalendarUseCaseMockRecorder) ImportRoomCalendar(tenant, roomID, source, feed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportRoomCalendar", reflect.TypeOf((*MockCalendarUseCase)(nil).ImportRoomCalendar), tenant, roomID, source, feed)
+}
+
+// ImportRoomCalendarURL mocks base method
+func (m *MockCalendarUseCase) ImportRoomCalendarURL(tenant, roomID uint64, url string) (*models.CalendarImport, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportRoomCalendarURL", tenant, roomID, url)
+	ret0, _ := ret[0].(*models.CalendarImport)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// ImportRoomCalendarURL indicates an expected call of ImportRoomCalendarURL
+func (mr *MockCalendarUseCaseMockRecorder) ImportRoomCalendarURL(tenant, roomID, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportRoomCalendarURL", reflect.TypeOf((*MockCalendarUseCase)(nil).ImportRoomCalendarURL), tenant, roomID, url)
+}
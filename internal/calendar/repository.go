@@ -0,0 +1,16 @@
+package calendar
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/models"
+)
+
+var ErrBlockDoesNotExist = errors.New("block doesn't exist")
+
+type CalendarRepository interface {
+	InsertBlock(block *models.RoomBlock) error
+	DeleteBlock(roomID uint64, id uint64) error
+	SelectRoomBlocks(roomID uint64) ([]*models.RoomBlock, error)
+	ReplaceExternalBlocks(roomID uint64, source string,
+		blocks []*models.RoomBlock) (*models.CalendarImport, error)
+}
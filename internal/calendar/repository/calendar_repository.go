@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	bookingRepository "github.com/booking_backend/internal/booking/repository"
+	"github.com/booking_backend/internal/calendar"
+	"github.com/booking_backend/internal/models"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+type CalendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) calendar.CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+func rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		logrus.Info(err)
+	}
+}
+
+// InsertBlock closes the room only if nothing is booked on the dates yet
+func (rep *CalendarRepository) InsertBlock(block *models.RoomBlock) error {
+	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	err = bookingRepository.CheckRoomIsFree(context.Background(), tx, block.Room,
+		block.DateStart, block.DateEnd, 0)
+	if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO room_blocks(room, date_start, date_end, reason, created)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		block.Room, block.DateStart, block.DateEnd, block.Reason, block.Created).
+		Scan(&block.ID)
+	if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (rep *CalendarRepository) DeleteBlock(roomID uint64, id uint64) error {
+	res, err := rep.db.Exec(`
+		DELETE FROM room_blocks
+		WHERE id=$1 AND room=$2`, id, roomID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return calendar.ErrBlockDoesNotExist
+	}
+	return nil
+}
+
+func (rep *CalendarRepository) SelectRoomBlocks(roomID uint64) ([]*models.RoomBlock, error) {
+	rows, err := rep.db.Query(`
+		SELECT id, room, date_start, date_end, reason, source, source_uid, created
+		FROM room_blocks
+		WHERE room=$1
+		ORDER BY date_start`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*models.RoomBlock
+	for rows.Next() {
+		block := &models.RoomBlock{}
+		if err := rows.Scan(&block.ID, &block.Room, &block.DateStart, &block.DateEnd,
+			&block.Reason, &block.Source, &block.SourceUID, &block.Created); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// ReplaceExternalBlocks makes the blocks of the source match the feed: events
+// are matched by UID, so they are updated in place and the ones that left
+// the feed are removed. The room stays locked, so imports of the same room
+// don't interleave
+func (rep *CalendarRepository) ReplaceExternalBlocks(roomID uint64, source string,
+	blocks []*models.RoomBlock) (*models.CalendarImport, error) {
+	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var id uint64
+	err = tx.QueryRow(`
+		SELECT id
+		FROM rooms
+		WHERE id=$1 AND deleted_at IS NULL
+		FOR UPDATE`, roomID).
+		Scan(&id)
+	if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+
+	result := &models.CalendarImport{Source: source}
+	uids := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		uids = append(uids, block.SourceUID)
+
+		var inserted bool
+		err = tx.QueryRow(`
+			INSERT INTO room_blocks(room, date_start, date_end, reason, source, source_uid, created)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (room, source, source_uid) WHERE source <> '' DO UPDATE
+			SET date_start=EXCLUDED.date_start, date_end=EXCLUDED.date_end, reason=EXCLUDED.reason
+			WHERE (room_blocks.date_start, room_blocks.date_end, room_blocks.reason)
+				IS DISTINCT FROM (EXCLUDED.date_start, EXCLUDED.date_end, EXCLUDED.reason)
+			RETURNING id, xmax=0`,
+			roomID, block.DateStart, block.DateEnd, block.Reason,
+			source, block.SourceUID, block.Created).
+			Scan(&block.ID, &inserted)
+		switch {
+		case err == sql.ErrNoRows:
+			// The event hasn't changed since the last import
+		case err != nil:
+			rollback(tx)
+			return nil, err
+		case inserted:
+			result.Created++
+		default:
+			result.Updated++
+		}
+	}
+
+	res, err := tx.Exec(`
+		DELETE FROM room_blocks
+		WHERE room=$1 AND source=$2 AND NOT (source_uid = ANY($3))`,
+		roomID, source, pq.Array(uids))
+	if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+	result.Removed = uint64(removed)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/booking"
+	bookingMocks "github.com/booking_backend/internal/booking/mocks"
+	"github.com/booking_backend/internal/calendar/mocks"
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var created = time.Date(2021, 12, 1, 12, 0, 0, 0, time.UTC)
+
+func externalBlock(id uint64, uid string, dateStart string, dateEnd string) *models.RoomBlock {
+	return &models.RoomBlock{
+		ID:        id,
+		Room:      2,
+		DateStart: dateStart,
+		DateEnd:   dateEnd,
+		Reason:    "Reserved",
+		Source:    "https://channel.test/room.ics",
+		SourceUID: uid,
+		Created:   created,
+	}
+}
+
+func TestCalendarRepository_ReplaceExternalBlocks(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	blocks := []*models.RoomBlock{
+		externalBlock(1, "a1@channel", "2022-01-02", "2022-01-05"),
+		externalBlock(2, "a2@channel", "2022-01-10", "2022-01-12"),
+		externalBlock(3, "a3@channel", "2022-01-20", "2022-01-22"),
+	}
+	source := blocks[0].Source
+
+	mock.ExpectBegin()
+	mocks.MockLockRoom(mock, 2)
+	mocks.MockUpsertExternalBlock(mock, blocks[0], true, true)
+	mocks.MockUpsertExternalBlock(mock, blocks[1], false, true)
+	mocks.MockUpsertExternalBlock(mock, blocks[2], false, false)
+	mocks.MockRemoveExternalBlocks(mock, 2, source, 4)
+	mock.ExpectCommit()
+
+	rep := NewCalendarRepository(db)
+	result, err := rep.ReplaceExternalBlocks(2, source, blocks)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.CalendarImport{Source: source, Created: 1, Updated: 1, Removed: 4}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCalendarRepository_InsertBlock_RoomIsOccupied(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	block := &models.RoomBlock{Room: 2, DateStart: "2022-01-02", DateEnd: "2022-01-05"}
+	mock.ExpectBegin()
+	bookingMocks.MockCheckRoomIsFree(mock, &models.Booking{
+		Room: block.Room, DateStart: block.DateStart, DateEnd: block.DateEnd}, true)
+	mock.ExpectRollback()
+
+	rep := NewCalendarRepository(db)
+	err = rep.InsertBlock(block)
+	assert.Equal(t, booking.ErrRoomIsOccupied, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,19 @@
+package calendar
+
+import (
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/ical"
+	"github.com/booking_backend/internal/models"
+	"io"
+)
+
+type CalendarUseCase interface {
+	CreateBlock(tenant uint64, block *models.RoomBlock) *errors.Error
+	DeleteBlock(tenant uint64, roomID uint64, id uint64) *errors.Error
+	GetRoomBlocks(tenant uint64, roomID uint64) ([]*models.RoomBlock, *errors.Error)
+	GetRoomCalendar(tenant uint64, roomID uint64) (*ical.Calendar, *errors.Error)
+	ImportRoomCalendar(tenant uint64, roomID uint64, source string,
+		feed io.Reader) (*models.CalendarImport, *errors.Error)
+	ImportRoomCalendarURL(tenant uint64, roomID uint64,
+		url string) (*models.CalendarImport, *errors.Error)
+}
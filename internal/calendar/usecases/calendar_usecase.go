@@ -0,0 +1,318 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/calendar"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/dates"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/ical"
+	"github.com/booking_backend/internal/helpers/safehttp"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/room"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	productID = "-//booking_backend//Room calendar//EN"
+	uidDomain = "booking_backend"
+	// maxFeedSize limits the downloaded feed, a room calendar is much smaller
+	maxFeedSize = 10 << 20
+)
+
+type CalendarUseCase struct {
+	calendarRepo calendar.CalendarRepository
+	bookingRepo  booking.BookingRepository
+	roomRepo     room.RoomRepository
+	client       *http.Client
+}
+
+// NewCalendarUseCase takes the client downloading feeds for import,
+// its timeout bounds the whole download
+func NewCalendarUseCase(calendarRepository calendar.CalendarRepository,
+	bookingRepository booking.BookingRepository,
+	roomRepository room.RoomRepository, client *http.Client) calendar.CalendarUseCase {
+	return &CalendarUseCase{
+		calendarRepo: calendarRepository,
+		bookingRepo:  bookingRepository,
+		roomRepo:     roomRepository,
+		client:       client,
+	}
+}
+
+func (uc *CalendarUseCase) checkRoom(tenant uint64, roomID uint64) (*models.Room, *errors.Error) {
+	roomModel, err := uc.roomRepo.SelectByID(context.Background(), tenant, roomID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeRoomDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return roomModel, nil
+}
+
+func (uc *CalendarUseCase) CreateBlock(tenant uint64, block *models.RoomBlock) *errors.Error {
+	if customErr := dates.CheckDates(block.DateStart, block.DateEnd); customErr != nil {
+		return customErr
+	}
+	if _, customErr := uc.checkRoom(tenant, block.Room); customErr != nil {
+		return customErr
+	}
+
+	err := uc.calendarRepo.InsertBlock(block)
+	if err == booking.ErrRoomIsOccupied {
+		return errors.Get(consts.CodeRoomIsOccupied)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+func (uc *CalendarUseCase) DeleteBlock(tenant uint64, roomID uint64, id uint64) *errors.Error {
+	if _, customErr := uc.checkRoom(tenant, roomID); customErr != nil {
+		return customErr
+	}
+
+	err := uc.calendarRepo.DeleteBlock(roomID, id)
+	if err == calendar.ErrBlockDoesNotExist {
+		return errors.Get(consts.CodeBlockDoesNotExist)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+func (uc *CalendarUseCase) GetRoomBlocks(tenant uint64, roomID uint64) ([]*models.RoomBlock, *errors.Error) {
+	if _, customErr := uc.checkRoom(tenant, roomID); customErr != nil {
+		return nil, customErr
+	}
+
+	blocks, err := uc.calendarRepo.SelectRoomBlocks(roomID)
+	if err == nil && blocks == nil {
+		return []*models.RoomBlock{}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return blocks, nil
+}
+
+// GetRoomCalendar lists the bookings and blocks of the room as calendar events.
+// UIDs only depend on the booking or block id, so subscribed calendars update
+// the event when it is rescheduled and drop it when it disappears from the feed.
+// Cancelled bookings stay in the feed as cancelled events, so the calendars
+// that only apply changes free the dates as well
+func (uc *CalendarUseCase) GetRoomCalendar(tenant uint64, roomID uint64) (*ical.Calendar, *errors.Error) {
+	if _, customErr := uc.checkRoom(tenant, roomID); customErr != nil {
+		return nil, customErr
+	}
+
+	bookings, err := uc.bookingRepo.SelectRoomCalendar(context.Background(), tenant, roomID)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	blocks, err := uc.calendarRepo.SelectRoomBlocks(roomID)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	roomCalendar := &ical.Calendar{
+		ProductID: productID,
+		Name:      fmt.Sprintf("Room %d", roomID),
+		Events:    make([]*ical.Event, 0, len(bookings)+len(blocks)),
+	}
+	for _, bookingModel := range bookings {
+		event, err := bookingEvent(bookingModel)
+		if err != nil {
+			return nil, errors.New(consts.CodeInternalError, err)
+		}
+		roomCalendar.Events = append(roomCalendar.Events, event)
+	}
+	for _, block := range blocks {
+		event, err := blockEvent(block)
+		if err != nil {
+			return nil, errors.New(consts.CodeInternalError, err)
+		}
+		roomCalendar.Events = append(roomCalendar.Events, event)
+	}
+	return roomCalendar, nil
+}
+
+func bookingEvent(bookingModel *models.Booking) (*ical.Event, error) {
+	start, err := dates.Parse(bookingModel.DateStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := dates.Parse(bookingModel.DateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &ical.Event{
+		UID:          fmt.Sprintf("booking-%d@%s", bookingModel.ID, uidDomain),
+		Start:        start,
+		End:          end,
+		Summary:      fmt.Sprintf("Booking #%d", bookingModel.ID),
+		Description:  fmt.Sprintf("Guests: %d", bookingModel.Guests),
+		Status:       ical.StatusConfirmed,
+		LastModified: bookingModel.Updated,
+	}
+	// Bookings start with version 1, events with sequence 0
+	if bookingModel.Version > 0 {
+		event.Sequence = bookingModel.Version - 1
+	}
+	switch bookingModel.Status {
+	case models.BookingStatusHeld:
+		event.Summary = fmt.Sprintf("Hold #%d", bookingModel.ID)
+		event.Status = ical.StatusTentative
+	case models.BookingStatusCancelled:
+		event.Summary = fmt.Sprintf("Cancelled booking #%d", bookingModel.ID)
+		event.Status = ical.StatusCancelled
+	}
+	return event, nil
+}
+
+func blockEvent(block *models.RoomBlock) (*ical.Event, error) {
+	start, err := dates.Parse(block.DateStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := dates.Parse(block.DateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ical.Event{
+		UID:         fmt.Sprintf("block-%d@%s", block.ID, uidDomain),
+		Start:       start,
+		End:         end,
+		Summary:     "Blocked",
+		Description: block.Reason,
+		Status:      ical.StatusConfirmed,
+	}, nil
+}
+
+// ImportRoomCalendar turns the events of the feed into blocks of the room.
+// Cancelled events are left out, so their blocks are removed, and so are our
+// own events in case the feed repeats the exported calendar. The bookings made
+// on other channels are already accepted, so they are imported even over our
+// confirmed bookings, which are reported as conflicts
+func (uc *CalendarUseCase) ImportRoomCalendar(tenant uint64, roomID uint64, source string,
+	feed io.Reader) (*models.CalendarImport, *errors.Error) {
+	if _, customErr := uc.checkRoom(tenant, roomID); customErr != nil {
+		return nil, customErr
+	}
+	return uc.importFeed(tenant, roomID, source, feed)
+}
+
+func (uc *CalendarUseCase) importFeed(tenant uint64, roomID uint64, source string,
+	feed io.Reader) (*models.CalendarImport, *errors.Error) {
+	imported, err := ical.Parse(feed)
+	if err != nil {
+		return nil, errors.New(consts.CodeCalendarInvalid, err)
+	}
+
+	created := time.Now()
+	blocks := make([]*models.RoomBlock, 0, len(imported.Events))
+	for _, event := range imported.Events {
+		if event.Status == ical.StatusCancelled || strings.HasSuffix(event.UID, "@"+uidDomain) {
+			continue
+		}
+		blocks = append(blocks, &models.RoomBlock{
+			Room:      roomID,
+			DateStart: event.Start.Format(dates.Layout),
+			DateEnd:   event.End.Format(dates.Layout),
+			Reason:    event.Summary,
+			Source:    source,
+			SourceUID: event.UID,
+			Created:   created,
+		})
+	}
+
+	result, err := uc.calendarRepo.ReplaceExternalBlocks(roomID, source, blocks)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeRoomDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	bookings, err := uc.bookingRepo.SelectRoomBookings(context.Background(), tenant, roomID)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	result.Conflicts, err = conflicts(bookings, blocks)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	if len(result.Conflicts) > 0 {
+		logrus.Warnf("calendar %s of room %d overlaps %d confirmed bookings",
+			source, roomID, len(result.Conflicts))
+	}
+	return result, nil
+}
+
+// conflicts pairs the confirmed bookings with the imported blocks they overlap
+func conflicts(bookings []*models.Booking, blocks []*models.RoomBlock) ([]*models.CalendarConflict, error) {
+	var found []*models.CalendarConflict
+	for _, bookingModel := range bookings {
+		if bookingModel.Status != models.BookingStatusConfirmed {
+			continue
+		}
+		start, err := dates.Parse(bookingModel.DateStart)
+		if err != nil {
+			return nil, err
+		}
+		end, err := dates.Parse(bookingModel.DateEnd)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			blockStart, err := dates.Parse(block.DateStart)
+			if err != nil {
+				return nil, err
+			}
+			blockEnd, err := dates.Parse(block.DateEnd)
+			if err != nil {
+				return nil, err
+			}
+			if blockStart.Before(end) && blockEnd.After(start) {
+				found = append(found, &models.CalendarConflict{
+					BookingID: bookingModel.ID,
+					SourceUID: block.SourceUID,
+					DateStart: block.DateStart,
+					DateEnd:   block.DateEnd,
+				})
+			}
+		}
+	}
+	return found, nil
+}
+
+// ImportRoomCalendarURL downloads the feed and imports it with the URL as the
+// source. The client is expected to connect only to public addresses, see
+// safehttp.NewClient, so the URL can not reach the internal network
+func (uc *CalendarUseCase) ImportRoomCalendarURL(tenant uint64, roomID uint64,
+	url string) (*models.CalendarImport, *errors.Error) {
+	if _, customErr := uc.checkRoom(tenant, roomID); customErr != nil {
+		return nil, customErr
+	}
+
+	resp, err := uc.client.Get(url)
+	if safehttp.IsForbidden(err) {
+		return nil, errors.New(consts.CodeURLForbidden, err)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeCalendarUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(consts.CodeCalendarUnavailable,
+			fmt.Errorf("calendar feed responded with %s", resp.Status))
+	}
+
+	return uc.importFeed(tenant, roomID, url, io.LimitReader(resp.Body, maxFeedSize))
+}
@@ -0,0 +1,337 @@
+package usecases
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/booking"
+	bookingMocks "github.com/booking_backend/internal/booking/mocks"
+	"github.com/booking_backend/internal/calendar"
+	"github.com/booking_backend/internal/calendar/mocks"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/ical"
+	"github.com/booking_backend/internal/helpers/safehttp"
+	"github.com/booking_backend/internal/models"
+	roomMocks "github.com/booking_backend/internal/room/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tenantID = models.DefaultTenantID
+
+var roomModel = &models.Room{
+	ID:       2,
+	Price:    500,
+	Property: 1,
+	Tenant:   tenantID,
+}
+
+func newBlock() *models.RoomBlock {
+	return &models.RoomBlock{
+		Room:      roomModel.ID,
+		DateStart: "2022-01-10",
+		DateEnd:   "2022-01-12",
+		Reason:    "Ремонт",
+	}
+}
+
+func TestCalendarUseCase_CreateBlock(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	block := newBlock()
+	calendarRep := mocks.NewMockCalendarRepository(ctrl)
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+	calendarRep.EXPECT().InsertBlock(block).Return(nil)
+
+	uc := NewCalendarUseCase(calendarRep, bookingMocks.NewMockBookingRepository(ctrl),
+		roomRep, http.DefaultClient)
+	err := uc.CreateBlock(tenantID, block)
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestCalendarUseCase_CreateBlock_RoomIsOccupied(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	block := newBlock()
+	calendarRep := mocks.NewMockCalendarRepository(ctrl)
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+	calendarRep.EXPECT().InsertBlock(block).Return(booking.ErrRoomIsOccupied)
+
+	uc := NewCalendarUseCase(calendarRep, bookingMocks.NewMockBookingRepository(ctrl),
+		roomRep, http.DefaultClient)
+	err := uc.CreateBlock(tenantID, block)
+	assert.Equal(t, errors.Get(consts.CodeRoomIsOccupied), err)
+}
+
+func TestCalendarUseCase_CreateBlock_IncorrectDates(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	block := newBlock()
+	block.DateStart, block.DateEnd = block.DateEnd, block.DateStart
+
+	uc := NewCalendarUseCase(mocks.NewMockCalendarRepository(ctrl),
+		bookingMocks.NewMockBookingRepository(ctrl), roomMocks.NewMockRoomRepository(ctrl), http.DefaultClient)
+	err := uc.CreateBlock(tenantID, block)
+	assert.Equal(t, errors.Get(consts.CodeIncorrectDates), err)
+}
+
+func TestCalendarUseCase_DeleteBlock_DoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	calendarRep := mocks.NewMockCalendarRepository(ctrl)
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+	calendarRep.EXPECT().DeleteBlock(roomModel.ID, uint64(7)).Return(calendar.ErrBlockDoesNotExist)
+
+	uc := NewCalendarUseCase(calendarRep, bookingMocks.NewMockBookingRepository(ctrl),
+		roomRep, http.DefaultClient)
+	err := uc.DeleteBlock(tenantID, roomModel.ID, 7)
+	assert.Equal(t, errors.Get(consts.CodeBlockDoesNotExist), err)
+}
+
+func TestCalendarUseCase_DeleteBlock_OtherTenant(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The room of another tenant is not found, its blocks are left untouched
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), uint64(2), roomModel.ID).Return(nil, sql.ErrNoRows)
+
+	uc := NewCalendarUseCase(mocks.NewMockCalendarRepository(ctrl),
+		bookingMocks.NewMockBookingRepository(ctrl), roomRep, http.DefaultClient)
+	err := uc.DeleteBlock(2, roomModel.ID, 7)
+	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), err)
+}
+
+func TestCalendarUseCase_GetRoomCalendar(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updated := time.Date(2021, 12, 20, 9, 0, 0, 0, time.UTC)
+	bookings := []*models.Booking{
+		{ID: 5, Room: roomModel.ID, DateStart: "2022-01-02T00:00:00Z", DateEnd: "2022-01-05T00:00:00Z",
+			Status: models.BookingStatusConfirmed, Guests: 2, Version: 1, Updated: updated},
+		{ID: 6, Room: roomModel.ID, DateStart: "2022-01-05T00:00:00Z", DateEnd: "2022-01-07T00:00:00Z",
+			Status: models.BookingStatusHeld, Guests: 1, Version: 1, Updated: updated},
+		{ID: 7, Room: roomModel.ID, DateStart: "2022-01-07T00:00:00Z", DateEnd: "2022-01-09T00:00:00Z",
+			Status: models.BookingStatusCancelled, Guests: 3, Version: 3, Updated: updated.Add(time.Hour)},
+	}
+	block := newBlock()
+	block.ID = 3
+
+	calendarRep := mocks.NewMockCalendarRepository(ctrl)
+	bookingRep := bookingMocks.NewMockBookingRepository(ctrl)
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+	bookingRep.EXPECT().SelectRoomCalendar(gomock.Any(), tenantID, roomModel.ID).Return(bookings, nil)
+	calendarRep.EXPECT().SelectRoomBlocks(roomModel.ID).Return([]*models.RoomBlock{block}, nil)
+
+	uc := NewCalendarUseCase(calendarRep, bookingRep, roomRep, http.DefaultClient)
+	roomCalendar, err := uc.GetRoomCalendar(tenantID, roomModel.ID)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, []*ical.Event{
+		{
+			UID:          "booking-5@booking_backend",
+			Start:        time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			End:          time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC),
+			Summary:      "Booking #5",
+			Description:  "Guests: 2",
+			Status:       ical.StatusConfirmed,
+			LastModified: updated,
+		},
+		{
+			UID:          "booking-6@booking_backend",
+			Start:        time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC),
+			End:          time.Date(2022, 1, 7, 0, 0, 0, 0, time.UTC),
+			Summary:      "Hold #6",
+			Description:  "Guests: 1",
+			Status:       ical.StatusTentative,
+			LastModified: updated,
+		},
+		{
+			UID:          "booking-7@booking_backend",
+			Start:        time.Date(2022, 1, 7, 0, 0, 0, 0, time.UTC),
+			End:          time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC),
+			Summary:      "Cancelled booking #7",
+			Description:  "Guests: 3",
+			Status:       ical.StatusCancelled,
+			Sequence:     2,
+			LastModified: updated.Add(time.Hour),
+		},
+		{
+			UID:         "block-3@booking_backend",
+			Start:       time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC),
+			End:         time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC),
+			Summary:     "Blocked",
+			Description: "Ремонт",
+			Status:      ical.StatusConfirmed,
+		},
+	}, roomCalendar.Events)
+}
+
+func TestCalendarUseCase_GetRoomCalendar_RoomDoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(nil, sql.ErrNoRows)
+
+	uc := NewCalendarUseCase(mocks.NewMockCalendarRepository(ctrl),
+		bookingMocks.NewMockBookingRepository(ctrl), roomRep, http.DefaultClient)
+	_, err := uc.GetRoomCalendar(tenantID, roomModel.ID)
+	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), err)
+}
+
+const channelFeed = "BEGIN:VCALENDAR\r\n" +
+	"PRODID:-//Channel//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:a1@channel\r\n" +
+	"DTSTART;VALUE=DATE:20220102\r\n" +
+	"DTEND;VALUE=DATE:20220105\r\n" +
+	"SUMMARY:Reserved\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:a2@channel\r\n" +
+	"DTSTART;VALUE=DATE:20220110\r\n" +
+	"DTEND;VALUE=DATE:20220112\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:booking-5@booking_backend\r\n" +
+	"DTSTART;VALUE=DATE:20220120\r\n" +
+	"DTEND;VALUE=DATE:20220122\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestCalendarUseCase_ImportRoomCalendarURL(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		_, _ = w.Write([]byte(channelFeed))
+	}))
+	defer server.Close()
+
+	result := &models.CalendarImport{Source: server.URL, Created: 1, Removed: 1}
+	calendarRep := mocks.NewMockCalendarRepository(ctrl)
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+	calendarRep.EXPECT().
+		ReplaceExternalBlocks(roomModel.ID, server.URL, gomock.Any()).
+		DoAndReturn(func(roomID uint64, source string,
+			blocks []*models.RoomBlock) (*models.CalendarImport, error) {
+			assert.Len(t, blocks, 1)
+			assert.Equal(t, "a1@channel", blocks[0].SourceUID)
+			assert.Equal(t, server.URL, blocks[0].Source)
+			assert.Equal(t, "2022-01-02", blocks[0].DateStart)
+			assert.Equal(t, "2022-01-05", blocks[0].DateEnd)
+			assert.Equal(t, "Reserved", blocks[0].Reason)
+			return result, nil
+		})
+	bookingRep := bookingMocks.NewMockBookingRepository(ctrl)
+	bookingRep.EXPECT().SelectRoomBookings(gomock.Any(), tenantID, roomModel.ID).Return(nil, nil)
+
+	uc := NewCalendarUseCase(calendarRep, bookingRep, roomRep, server.Client())
+	imported, err := uc.ImportRoomCalendarURL(tenantID, roomModel.ID, server.URL)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, result, imported)
+}
+
+func TestCalendarUseCase_ImportRoomCalendarURL_Unavailable(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+
+	uc := NewCalendarUseCase(mocks.NewMockCalendarRepository(ctrl),
+		bookingMocks.NewMockBookingRepository(ctrl), roomRep, server.Client())
+	_, err := uc.ImportRoomCalendarURL(tenantID, roomModel.ID, server.URL)
+	assert.Equal(t, consts.CodeCalendarUnavailable, err.Code)
+}
+
+func TestCalendarUseCase_ImportRoomCalendarURL_Forbidden(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the feed is downloaded from the loopback address")
+	}))
+	defer server.Close()
+
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+
+	uc := NewCalendarUseCase(mocks.NewMockCalendarRepository(ctrl),
+		bookingMocks.NewMockBookingRepository(ctrl), roomRep, safehttp.NewClient(time.Second, true))
+	_, err := uc.ImportRoomCalendarURL(tenantID, roomModel.ID, server.URL)
+	assert.Equal(t, consts.CodeURLForbidden, err.Code)
+}
+
+func TestCalendarUseCase_ImportRoomCalendar_Conflicts(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookings := []*models.Booking{
+		{ID: 5, Room: roomModel.ID, DateStart: "2022-01-04T00:00:00Z", DateEnd: "2022-01-06T00:00:00Z",
+			Status: models.BookingStatusConfirmed},
+		{ID: 6, Room: roomModel.ID, DateStart: "2022-01-01T00:00:00Z", DateEnd: "2022-01-03T00:00:00Z",
+			Status: models.BookingStatusHeld},
+		{ID: 7, Room: roomModel.ID, DateStart: "2022-01-05T00:00:00Z", DateEnd: "2022-01-08T00:00:00Z",
+			Status: models.BookingStatusConfirmed},
+	}
+	calendarRep := mocks.NewMockCalendarRepository(ctrl)
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	bookingRep := bookingMocks.NewMockBookingRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+	calendarRep.EXPECT().
+		ReplaceExternalBlocks(roomModel.ID, "channel.ics", gomock.Any()).
+		Return(&models.CalendarImport{Source: "channel.ics", Created: 1}, nil)
+	bookingRep.EXPECT().SelectRoomBookings(gomock.Any(), tenantID, roomModel.ID).Return(bookings, nil)
+
+	uc := NewCalendarUseCase(calendarRep, bookingRep, roomRep, http.DefaultClient)
+	imported, err := uc.ImportRoomCalendar(tenantID, roomModel.ID, "channel.ics", strings.NewReader(channelFeed))
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, []*models.CalendarConflict{
+		{BookingID: 5, SourceUID: "a1@channel", DateStart: "2022-01-02", DateEnd: "2022-01-05"},
+	}, imported.Conflicts)
+}
+
+func TestCalendarUseCase_ImportRoomCalendar_Invalid(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	roomRep := roomMocks.NewMockRoomRepository(ctrl)
+	roomRep.EXPECT().SelectByID(gomock.Any(), tenantID, roomModel.ID).Return(roomModel, nil)
+
+	uc := NewCalendarUseCase(mocks.NewMockCalendarRepository(ctrl),
+		bookingMocks.NewMockBookingRepository(ctrl), roomRep, http.DefaultClient)
+	_, err := uc.ImportRoomCalendar(tenantID, roomModel.ID, "channel.ics", strings.NewReader("<html></html>"))
+	assert.Equal(t, consts.CodeCalendarInvalid, err.Code)
+}
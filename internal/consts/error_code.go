@@ -6,4 +6,35 @@ const (
 	CodeRoomDoesNotExist
 	CodeBookingDoesNotExist
 	CodeIncorrectDates
+	CodeReservationDoesNotExist
+	CodeRoomIsOccupied
+	CodeHoldExpired
+	CodeBookingAlreadyCancelled
+	CodePaymentDeclined
+	CodePaymentGatewayError
+	CodePaymentNotAuthorized
+	CodeNothingToRefund
+	CodeInvoiceUnavailable
+	CodePropertyDoesNotExist
+	CodePromoCodeDoesNotExist
+	CodePromoCodeAlreadyExists
+	CodePromoCodeNotApplicable
+	CodePromoCodeExhausted
+	CodeBlockDoesNotExist
+	CodeCalendarInvalid
+	CodeCalendarUnavailable
+	CodeUnauthorized
+	CodeAPIKeyDoesNotExist
+	CodeForbidden
+	CodeWebhookDoesNotExist
+	CodeIdempotencyKeyReused
+	CodeIdempotencyKeyInProgress
+	CodeVersionMismatch
+	CodePreconditionRequired
+	CodeTimeout
+	CodeNotReady
+	CodeInvoiceNotIssued
+	CodeURLForbidden
+	CodeBookingNotConfirmed
+	CodePaymentAlreadyCaptured
 )
@@ -0,0 +1,46 @@
+package delivery
+
+import (
+	"github.com/booking_backend/internal/health"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// The probes are called by the container orchestrator without credentials
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+type HealthHandler struct {
+	healthUseCase health.HealthUseCase
+}
+
+func NewHealthHandler(useCase health.HealthUseCase) *HealthHandler {
+	return &HealthHandler{healthUseCase: useCase}
+}
+
+func (hh *HealthHandler) Configure(e *echo.Echo) {
+	e.GET(LivenessPath, hh.Live())
+	e.GET(ReadinessPath, hh.Ready())
+}
+
+// Live only tells the process is able to serve HTTP, the database is not
+// checked, so an unavailable database doesn't get the instance restarted
+func (hh *HealthHandler) Live() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		return context.JSON(http.StatusOK, response.Response{Message: "ok"})
+	}
+}
+
+func (hh *HealthHandler) Ready() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		if customErr := hh.healthUseCase.Ready(context.Request().Context()); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		return context.JSON(http.StatusOK, response.Response{Message: "ok"})
+	}
+}
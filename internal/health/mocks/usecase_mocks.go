@@ -0,0 +1,61 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_health is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	errors "github.com/booking_backend/internal/helpers/errors"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockHealthUseCase is a mock of HealthUseCase interface
+type MockHealthUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthUseCaseMockRecorder
+}
+
+// MockHealthUseCaseMockRecorder is the mock recorder for MockHealthUseCase
+type MockHealthUseCaseMockRecorder struct {
+	mock *MockHealthUseCase
+}
+
+// NewMockHealthUseCase creates a new mock instance
+func NewMockHealthUseCase(ctrl *gomock.Controller) *MockHealthUseCase {
+	mock := &MockHealthUseCase{ctrl: ctrl}
+	mock.recorder = &MockHealthUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHealthUseCase) EXPECT() *MockHealthUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Ready mocks base method
+func (m *MockHealthUseCase) Ready(ctx context.Context) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", ctx)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready
+func (mr *MockHealthUseCaseMockRecorder) Ready(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockHealthUseCase)(nil).Ready), ctx)
+}
+
+// Drain mocks base method
+func (m *MockHealthUseCase) Drain() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Drain")
+}
+
+// Drain indicates an expected call of Drain
+func (mr *MockHealthUseCaseMockRecorder) Drain() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockHealthUseCase)(nil).Drain))
+}
@@ -0,0 +1,13 @@
+package health
+
+import "context"
+
+// SchemaVersion is the last migration of scripts/migrations the application
+// needs, every new migration file increases it
+const SchemaVersion = 28
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	// SelectSchemaVersion returns the last migration applied to the database
+	SelectSchemaVersion(ctx context.Context) (int, error)
+}
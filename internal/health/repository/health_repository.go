@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/health"
+	"github.com/booking_backend/internal/helpers/query"
+	"time"
+)
+
+type HealthRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewHealthRepository bounds every call of the repository with the timeout
+func NewHealthRepository(db *sql.DB, timeout time.Duration) health.HealthRepository {
+	return &HealthRepository{db: db, timeout: timeout}
+}
+
+func (rep *HealthRepository) Ping(ctx context.Context) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	return rep.db.PingContext(ctx)
+}
+
+func (rep *HealthRepository) SelectSchemaVersion(ctx context.Context) (int, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	var version int
+	err := rep.db.QueryRowContext(ctx, `
+		SELECT COALESCE(max(version), 0)
+		FROM schema_migrations`).
+		Scan(&version)
+	return version, err
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/helpers/query"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHealthRepository_SelectSchemaVersion(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT COALESCE\(max\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+
+	rep := NewHealthRepository(db, query.DefaultTimeout)
+	version, err := rep.SelectSchemaVersion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,15 @@
+package health
+
+import (
+	"context"
+	"github.com/booking_backend/internal/helpers/errors"
+)
+
+type HealthUseCase interface {
+	// Ready tells whether the instance can serve requests: it is not shutting
+	// down, the database is reachable and the migrations are applied
+	Ready(ctx context.Context) *errors.Error
+	// Drain makes the instance not ready, so no new requests are routed to it
+	// while the ones in flight are finished
+	Drain()
+}
@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/health"
+	"github.com/booking_backend/internal/helpers/errors"
+	"sync/atomic"
+)
+
+type HealthUseCase struct {
+	healthRepo health.HealthRepository
+	draining   int32
+}
+
+func NewHealthUseCase(healthRepository health.HealthRepository) health.HealthUseCase {
+	return &HealthUseCase{healthRepo: healthRepository}
+}
+
+func (uc *HealthUseCase) Ready(ctx context.Context) *errors.Error {
+	if atomic.LoadInt32(&uc.draining) == 1 {
+		return errors.New(consts.CodeNotReady, fmt.Errorf("instance is shutting down"))
+	}
+	if err := uc.healthRepo.Ping(ctx); err != nil {
+		return errors.New(consts.CodeNotReady, err)
+	}
+
+	version, err := uc.healthRepo.SelectSchemaVersion(ctx)
+	if err != nil {
+		return errors.New(consts.CodeNotReady, err)
+	}
+	if version < health.SchemaVersion {
+		return errors.New(consts.CodeNotReady,
+			fmt.Errorf("schema version is %d, %d is required", version, health.SchemaVersion))
+	}
+	return nil
+}
+
+func (uc *HealthUseCase) Drain() {
+	atomic.StoreInt32(&uc.draining, 1)
+}
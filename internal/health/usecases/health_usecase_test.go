@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/health"
+	"github.com/booking_backend/internal/health/mocks"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+)
+
+var ctx = context.Background()
+
+func TestHealthUseCase_Ready(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	healthRep := mocks.NewMockHealthRepository(ctrl)
+
+	healthRep.EXPECT().Ping(gomock.Any()).Return(nil)
+	healthRep.EXPECT().SelectSchemaVersion(gomock.Any()).Return(health.SchemaVersion, nil)
+
+	err := NewHealthUseCase(healthRep).Ready(ctx)
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestHealthUseCase_Ready_DatabaseUnavailable(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	healthRep := mocks.NewMockHealthRepository(ctrl)
+
+	healthRep.EXPECT().Ping(gomock.Any()).Return(sql.ErrConnDone)
+
+	err := NewHealthUseCase(healthRep).Ready(ctx)
+	assert.Equal(t, errors.Get(consts.CodeNotReady), err)
+}
+
+func TestHealthUseCase_Ready_MigrationsNotApplied(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	healthRep := mocks.NewMockHealthRepository(ctrl)
+
+	healthRep.EXPECT().Ping(gomock.Any()).Return(nil)
+	healthRep.EXPECT().SelectSchemaVersion(gomock.Any()).Return(health.SchemaVersion-1, nil)
+
+	err := NewHealthUseCase(healthRep).Ready(ctx)
+	assert.Equal(t, errors.Get(consts.CodeNotReady), err)
+}
+
+func TestHealthUseCase_Drain(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	healthRep := mocks.NewMockHealthRepository(ctrl)
+
+	// The database is not queried once the instance is shutting down
+	useCase := NewHealthUseCase(healthRep)
+	useCase.Drain()
+	err := useCase.Ready(ctx)
+	assert.Equal(t, errors.Get(consts.CodeNotReady), err)
+}
@@ -0,0 +1,94 @@
+// Package csvio reads and writes CSV files whose first line names the columns
+package csvio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"strings"
+)
+
+const MIMETextCSV = "text/csv; charset=utf-8"
+
+// WantsCSV tells whether a list should be exported as CSV instead of JSON,
+// either by ?format=csv or by the Accept header
+func WantsCSV(context echo.Context) bool {
+	if format := context.QueryParam("format"); format != "" {
+		return format == "csv"
+	}
+	return strings.Contains(context.Request().Header.Get(echo.HeaderAccept), "text/csv")
+}
+
+type Reader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	rows    uint64
+}
+
+// Row is a line of the file, Number counts the rows after the header from 1
+type Row struct {
+	Number  uint64
+	values  []string
+	columns map[string]int
+}
+
+// NewReader reads the header. Column names are case insensitive, columns
+// which are not required are optional and unknown ones are ignored
+func NewReader(r io.Reader, required ...string) (*Reader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv: header is missing")
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Excel starts UTF-8 files with a byte order mark
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\uFEFF")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range required {
+		if _, has := columns[name]; !has {
+			return nil, fmt.Errorf("csv: column %s is missing", name)
+		}
+	}
+	return &Reader{reader: reader, columns: columns}, nil
+}
+
+// Read returns io.EOF after the last row
+func (r *Reader) Read() (*Row, error) {
+	values, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	r.rows++
+	return &Row{Number: r.rows, values: values, columns: r.columns}, nil
+}
+
+// Get returns the trimmed value of the column, empty for a missing one
+func (row *Row) Get(column string) string {
+	i, has := row.columns[column]
+	if !has || i >= len(row.values) {
+		return ""
+	}
+	return strings.TrimSpace(row.values[i])
+}
+
+func Write(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
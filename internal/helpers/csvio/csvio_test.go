@@ -0,0 +1,51 @@
+package csvio
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+	reader, err := NewReader(strings.NewReader("\uFEFFDescription, Price,created\n"+
+		"\"Sea view, balcony\",1500,2021-12-01\n"+
+		"Standard\n"), "description", "price")
+	assert.NoError(t, err)
+
+	row, err := reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), row.Number)
+	assert.Equal(t, "Sea view, balcony", row.Get("description"))
+	assert.Equal(t, "1500", row.Get("price"))
+	assert.Equal(t, "", row.Get("property_id"))
+
+	row, err = reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), row.Number)
+	assert.Equal(t, "Standard", row.Get("description"))
+	assert.Equal(t, "", row.Get("price"))
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReader_MissingColumn(t *testing.T) {
+	t.Parallel()
+	_, err := NewReader(strings.NewReader("description\nStandard\n"), "description", "price")
+	assert.EqualError(t, err, "csv: column price is missing")
+
+	_, err = NewReader(strings.NewReader(""), "description")
+	assert.EqualError(t, err, "csv: header is missing")
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	buf := &bytes.Buffer{}
+	err := Write(buf, []string{"room_id", "description"},
+		[][]string{{"1", "Sea view, balcony"}, {"2", "Standard"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "room_id,description\n1,\"Sea view, balcony\"\n2,Standard\n", buf.String())
+}
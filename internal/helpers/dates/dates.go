@@ -0,0 +1,56 @@
+package dates
+
+import (
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"time"
+)
+
+const Layout = `2006-01-02`
+
+// Parse accepts both request dates and dates scanned from the database
+func Parse(date string) (time.Time, error) {
+	t, err := time.Parse(Layout, date)
+	if err != nil {
+		return time.Parse(time.RFC3339, date)
+	}
+	return t, nil
+}
+
+// Nights returns the number of nights between arrival and departure
+func Nights(dateStart, dateEnd string) (uint64, error) {
+	start, err := Parse(dateStart)
+	if err != nil {
+		return 0, err
+	}
+	end, err := Parse(dateEnd)
+	if err != nil {
+		return 0, err
+	}
+	if end.Before(start) {
+		return 0, nil
+	}
+	return uint64(end.Sub(start).Hours() / 24), nil
+}
+
+func CheckDates(dateStart, dateEnd string) *errors.Error {
+	start, err := time.Parse(Layout, dateStart)
+	if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	end, err := time.Parse(Layout, dateEnd)
+	if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	if end.Before(start) {
+		return errors.Get(consts.CodeIncorrectDates)
+	}
+	return nil
+}
+
+// Today is the date at the location at the moment, at midnight UTC like the
+// parsed dates
+func Today(now time.Time, location *time.Location) time.Time {
+	local := now.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
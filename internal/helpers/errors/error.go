@@ -2,6 +2,7 @@ package errors
 
 import (
 	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/query"
 	"net/http"
 )
 
@@ -18,7 +19,12 @@ var WrongErrorCode = &Error{
 	UserMessage: "Что-то пошло не так",
 }
 
+// New reports the internal error caused by the deadline or the cancellation
+// of the query context as the timeout
 func New(code uint64, err error) *Error {
+	if code == CodeInternalError && query.IsTimeout(err) {
+		code = CodeTimeout
+	}
 	customErr, has := Errors[code]
 	if !has {
 		return WrongErrorCode
@@ -66,4 +72,190 @@ var Errors = map[uint64]*Error{
 		Message:     "dates are incorrect",
 		UserMessage: "Дата начала бронирования не может быть раньше даты окончания",
 	},
+	CodeReservationDoesNotExist: {
+		Code:        CodeReservationDoesNotExist,
+		HTTPCode:    http.StatusNotFound,
+		Message:     "reservation with this id doesn't exist",
+		UserMessage: "Группового бронирования с таким ID не существует",
+	},
+	CodeRoomIsOccupied: {
+		Code:        CodeRoomIsOccupied,
+		HTTPCode:    http.StatusConflict,
+		Message:     "room is occupied on these dates",
+		UserMessage: "Номер занят на эти даты",
+	},
+	CodeHoldExpired: {
+		Code:        CodeHoldExpired,
+		HTTPCode:    http.StatusGone,
+		Message:     "hold has expired",
+		UserMessage: "Время удержания брони истекло",
+	},
+	CodeBookingAlreadyCancelled: {
+		Code:        CodeBookingAlreadyCancelled,
+		HTTPCode:    http.StatusConflict,
+		Message:     "booking is already cancelled",
+		UserMessage: "Бронь уже отменена",
+	},
+	CodePaymentDeclined: {
+		Code:        CodePaymentDeclined,
+		HTTPCode:    http.StatusPaymentRequired,
+		Message:     "payment declined",
+		UserMessage: "Платеж отклонен",
+	},
+	CodePaymentGatewayError: {
+		Code:        CodePaymentGatewayError,
+		HTTPCode:    http.StatusBadGateway,
+		Message:     "payment gateway error",
+		UserMessage: "Платежная система недоступна, попробуйте позже",
+	},
+	CodePaymentNotAuthorized: {
+		Code:        CodePaymentNotAuthorized,
+		HTTPCode:    http.StatusConflict,
+		Message:     "booking has no payment authorization",
+		UserMessage: "Бронь не оплачена",
+	},
+	CodeNothingToRefund: {
+		Code:        CodeNothingToRefund,
+		HTTPCode:    http.StatusConflict,
+		Message:     "booking has no failed refund",
+		UserMessage: "По брони нет неудавшегося возврата",
+	},
+	CodeInvoiceUnavailable: {
+		Code:        CodeInvoiceUnavailable,
+		HTTPCode:    http.StatusConflict,
+		Message:     "booking has nothing to invoice",
+		UserMessage: "По брони нечего выставлять в счет",
+	},
+	CodePropertyDoesNotExist: {
+		Code:        CodePropertyDoesNotExist,
+		HTTPCode:    http.StatusNotFound,
+		Message:     "property with this id doesn't exist",
+		UserMessage: "Объекта размещения с таким ID не существует",
+	},
+	CodePromoCodeDoesNotExist: {
+		Code:        CodePromoCodeDoesNotExist,
+		HTTPCode:    http.StatusNotFound,
+		Message:     "promo code doesn't exist",
+		UserMessage: "Такого промокода не существует",
+	},
+	CodePromoCodeAlreadyExists: {
+		Code:        CodePromoCodeAlreadyExists,
+		HTTPCode:    http.StatusConflict,
+		Message:     "promo code already exists",
+		UserMessage: "Такой промокод уже существует",
+	},
+	CodePromoCodeNotApplicable: {
+		Code:        CodePromoCodeNotApplicable,
+		HTTPCode:    http.StatusUnprocessableEntity,
+		Message:     "promo code is not applicable to this booking",
+		UserMessage: "Промокод не действует для этой брони",
+	},
+	CodePromoCodeExhausted: {
+		Code:        CodePromoCodeExhausted,
+		HTTPCode:    http.StatusConflict,
+		Message:     "promo code usage limit is reached",
+		UserMessage: "Промокод больше нельзя использовать",
+	},
+	CodeBlockDoesNotExist: {
+		Code:        CodeBlockDoesNotExist,
+		HTTPCode:    http.StatusNotFound,
+		Message:     "block with this id doesn't exist",
+		UserMessage: "Блокировки с таким ID не существует",
+	},
+	CodeCalendarInvalid: {
+		Code:        CodeCalendarInvalid,
+		HTTPCode:    http.StatusUnprocessableEntity,
+		Message:     "calendar feed can't be parsed",
+		UserMessage: "Не удалось разобрать календарь",
+	},
+	CodeCalendarUnavailable: {
+		Code:        CodeCalendarUnavailable,
+		HTTPCode:    http.StatusBadGateway,
+		Message:     "calendar feed can't be downloaded",
+		UserMessage: "Не удалось загрузить календарь",
+	},
+	CodeUnauthorized: {
+		Code:        CodeUnauthorized,
+		HTTPCode:    http.StatusUnauthorized,
+		Message:     "authentication required",
+		UserMessage: "Требуется авторизация",
+	},
+	CodeAPIKeyDoesNotExist: {
+		Code:        CodeAPIKeyDoesNotExist,
+		HTTPCode:    http.StatusNotFound,
+		Message:     "api key with this id doesn't exist",
+		UserMessage: "API-ключа с таким ID не существует",
+	},
+	CodeForbidden: {
+		Code:        CodeForbidden,
+		HTTPCode:    http.StatusForbidden,
+		Message:     "operation is not permitted",
+		UserMessage: "Недостаточно прав",
+	},
+	CodeWebhookDoesNotExist: {
+		Code:        CodeWebhookDoesNotExist,
+		HTTPCode:    http.StatusNotFound,
+		Message:     "webhook with this id doesn't exist",
+		UserMessage: "Вебхука с таким ID не существует",
+	},
+	CodeIdempotencyKeyReused: {
+		Code:        CodeIdempotencyKeyReused,
+		HTTPCode:    http.StatusUnprocessableEntity,
+		Message:     "idempotency key is already used for another request",
+		UserMessage: "Ключ идемпотентности уже использован для другого запроса",
+	},
+	CodeIdempotencyKeyInProgress: {
+		Code:        CodeIdempotencyKeyInProgress,
+		HTTPCode:    http.StatusConflict,
+		Message:     "request with this idempotency key is in progress",
+		UserMessage: "Запрос с этим ключом идемпотентности еще выполняется",
+	},
+	CodeVersionMismatch: {
+		Code:        CodeVersionMismatch,
+		HTTPCode:    http.StatusPreconditionFailed,
+		Message:     "resource has been changed by another request",
+		UserMessage: "Данные изменены другим запросом, обновите их и повторите",
+	},
+	CodePreconditionRequired: {
+		Code:        CodePreconditionRequired,
+		HTTPCode:    http.StatusPreconditionRequired,
+		Message:     "If-Match header is required",
+		UserMessage: "Не передан заголовок If-Match",
+	},
+	CodeTimeout: {
+		Code:        CodeTimeout,
+		HTTPCode:    http.StatusGatewayTimeout,
+		Message:     "request has timed out",
+		UserMessage: "Запрос выполнялся слишком долго, повторите позже",
+	},
+	CodeNotReady: {
+		Code:        CodeNotReady,
+		HTTPCode:    http.StatusServiceUnavailable,
+		Message:     "service is not ready",
+		UserMessage: "Сервис временно недоступен, повторите позже",
+	},
+	CodeInvoiceNotIssued: {
+		Code:        CodeInvoiceNotIssued,
+		HTTPCode:    http.StatusConflict,
+		Message:     "invoice is not issued yet",
+		UserMessage: "Счет по брони еще не выставлен",
+	},
+	CodeURLForbidden: {
+		Code:        CodeURLForbidden,
+		HTTPCode:    http.StatusUnprocessableEntity,
+		Message:     "URL must be http or https and point to a public address",
+		UserMessage: "Ссылка должна вести на публичный адрес по http или https",
+	},
+	CodeBookingNotConfirmed: {
+		Code:        CodeBookingNotConfirmed,
+		HTTPCode:    http.StatusConflict,
+		Message:     "booking is not confirmed",
+		UserMessage: "Бронь не подтверждена",
+	},
+	CodePaymentAlreadyCaptured: {
+		Code:        CodePaymentAlreadyCaptured,
+		HTTPCode:    http.StatusConflict,
+		Message:     "payment is already captured",
+		UserMessage: "Оплата по брони уже списана",
+	},
 }
@@ -0,0 +1,121 @@
+// Package ical writes calendars in the iCalendar format (RFC 5545) limited to
+// what room calendars need: all-day events with a stable UID
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+// Event lasts whole days from Start to End, End is not included. Sequence
+// grows with every change of the event, so calendars take the latest one
+type Event struct {
+	UID          string
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Status       string
+	Sequence     uint64
+	LastModified time.Time
+}
+
+type Calendar struct {
+	ProductID string
+	Name      string
+	Events    []*Event
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(text string) string {
+	return escaper.Replace(text)
+}
+
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line folding it to 75 octets without splitting
+// multi-byte characters
+func (cw *writer) line(name string, value string) {
+	if cw.err != nil {
+		return
+	}
+	content := name + ":" + value
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		if _, cw.err = cw.w.WriteString(content[:cut] + "\r\n "); cw.err != nil {
+			return
+		}
+		content = content[cut:]
+		// The leading space of a continuation line counts towards its length
+		limit = maxLineOctets - 1
+	}
+	_, cw.err = cw.w.WriteString(content + "\r\n")
+}
+
+// Write serializes the calendar. stamp is the DTSTAMP of every event
+func Write(w io.Writer, calendar *Calendar, stamp time.Time) error {
+	cw := &writer{w: bufio.NewWriter(w)}
+	cw.line("BEGIN", "VCALENDAR")
+	cw.line("VERSION", "2.0")
+	cw.line("PRODID", escape(calendar.ProductID))
+	cw.line("CALSCALE", "GREGORIAN")
+	cw.line("METHOD", "PUBLISH")
+	if calendar.Name != "" {
+		cw.line("X-WR-CALNAME", escape(calendar.Name))
+	}
+	for _, event := range calendar.Events {
+		cw.line("BEGIN", "VEVENT")
+		cw.line("UID", escape(event.UID))
+		cw.line("DTSTAMP", stamp.UTC().Format(dateTimeLayout))
+		if !event.LastModified.IsZero() {
+			cw.line("LAST-MODIFIED", event.LastModified.UTC().Format(dateTimeLayout))
+		}
+		cw.line("SEQUENCE", strconv.FormatUint(event.Sequence, 10))
+		cw.line("DTSTART;VALUE=DATE", event.Start.Format(dateLayout))
+		cw.line("DTEND;VALUE=DATE", event.End.Format(dateLayout))
+		cw.line("SUMMARY", escape(event.Summary))
+		if event.Description != "" {
+			cw.line("DESCRIPTION", escape(event.Description))
+		}
+		if event.Status != "" {
+			cw.line("STATUS", event.Status)
+		}
+		// Cancelled events no longer take the time of the room
+		if event.Status == StatusCancelled {
+			cw.line("TRANSP", "TRANSPARENT")
+		} else {
+			cw.line("TRANSP", "OPAQUE")
+		}
+		cw.line("END", "VEVENT")
+	}
+	cw.line("END", "VCALENDAR")
+	if cw.err != nil {
+		return cw.err
+	}
+	if err := cw.w.Flush(); err != nil {
+		return fmt.Errorf("ical: %w", err)
+	}
+	return nil
+}
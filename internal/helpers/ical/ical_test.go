@@ -0,0 +1,90 @@
+package ical
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	calendar := &Calendar{
+		ProductID: "-//test//EN",
+		Name:      "Room 1",
+		Events: []*Event{{
+			UID:         "booking-1@test",
+			Start:       time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			End:         time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC),
+			Summary:     "Booking #1",
+			Description: "Late check-in; guests: 2, pets\nno",
+			Status:      StatusTentative,
+		}, {
+			UID:          "booking-2@test",
+			Start:        time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC),
+			End:          time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC),
+			Summary:      "Cancelled booking #2",
+			Status:       StatusCancelled,
+			Sequence:     2,
+			LastModified: time.Date(2021, 12, 31, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+		}},
+	}
+
+	feed := &bytes.Buffer{}
+	err := Write(feed, calendar, time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:-//test//EN\r\n"+
+		"CALSCALE:GREGORIAN\r\n"+
+		"METHOD:PUBLISH\r\n"+
+		"X-WR-CALNAME:Room 1\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:booking-1@test\r\n"+
+		"DTSTAMP:20220101T103000Z\r\n"+
+		"SEQUENCE:0\r\n"+
+		"DTSTART;VALUE=DATE:20220102\r\n"+
+		"DTEND;VALUE=DATE:20220105\r\n"+
+		"SUMMARY:Booking #1\r\n"+
+		`DESCRIPTION:Late check-in\; guests: 2\, pets\nno`+"\r\n"+
+		"STATUS:TENTATIVE\r\n"+
+		"TRANSP:OPAQUE\r\n"+
+		"END:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:booking-2@test\r\n"+
+		"DTSTAMP:20220101T103000Z\r\n"+
+		"LAST-MODIFIED:20211231T090000Z\r\n"+
+		"SEQUENCE:2\r\n"+
+		"DTSTART;VALUE=DATE:20220103\r\n"+
+		"DTEND;VALUE=DATE:20220104\r\n"+
+		"SUMMARY:Cancelled booking #2\r\n"+
+		"STATUS:CANCELLED\r\n"+
+		"TRANSP:TRANSPARENT\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n", feed.String())
+}
+
+func TestWrite_FoldsLongLines(t *testing.T) {
+	t.Parallel()
+	calendar := &Calendar{
+		Events: []*Event{{
+			UID:     "block-1@test",
+			Summary: strings.Repeat("Ремонт ", 30),
+		}},
+	}
+
+	feed := &bytes.Buffer{}
+	assert.NoError(t, Write(feed, calendar, time.Now()))
+
+	var summary string
+	for _, line := range strings.Split(strings.TrimSuffix(feed.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+		if strings.HasPrefix(line, "SUMMARY:") {
+			summary = line
+		} else if strings.HasPrefix(line, " ") {
+			summary += line[1:]
+		}
+	}
+	assert.Equal(t, "SUMMARY:"+calendar.Events[0].Summary, summary)
+}
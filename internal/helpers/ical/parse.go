@@ -0,0 +1,131 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoCalendar = errors.New("ical: VCALENDAR is missing")
+	ErrBadEvent   = errors.New("ical: event has no UID or DTSTART")
+)
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// Parse reads the events of a calendar. Times are cut to their dates in
+// the time zone they are written in, an event without DTEND lasts one day
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{}
+	var event *Event
+	// nested counts the components inside the event, e.g. VALARM, whose
+	// properties don't belong to the event
+	nested := 0
+	found := false
+	for _, line := range lines {
+		name, value := split(line)
+		switch {
+		case event != nil && name == "BEGIN":
+			nested++
+		case nested > 0 && name == "END":
+			nested--
+		case nested > 0:
+		case name == "BEGIN" && value == "VCALENDAR":
+			found = true
+		case name == "BEGIN" && value == "VEVENT":
+			event = &Event{}
+		case name == "END" && value == "VEVENT" && event != nil:
+			if event.UID == "" || event.Start.IsZero() {
+				return nil, ErrBadEvent
+			}
+			if !event.End.After(event.Start) {
+				event.End = event.Start.AddDate(0, 0, 1)
+			}
+			calendar.Events = append(calendar.Events, event)
+			event = nil
+		case event == nil:
+			switch name {
+			case "PRODID":
+				calendar.ProductID = unescaper.Replace(value)
+			case "X-WR-CALNAME":
+				calendar.Name = unescaper.Replace(value)
+			}
+		default:
+			if err := event.set(name, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !found {
+		return nil, ErrNoCalendar
+	}
+	return calendar, nil
+}
+
+func (event *Event) set(name string, value string) error {
+	var err error
+	switch name {
+	case "UID":
+		event.UID = value
+	case "DTSTART":
+		event.Start, err = parseDate(value)
+	case "DTEND":
+		event.End, err = parseDate(value)
+	case "SUMMARY":
+		event.Summary = unescaper.Replace(value)
+	case "DESCRIPTION":
+		event.Description = unescaper.Replace(value)
+	case "STATUS":
+		event.Status = strings.ToUpper(value)
+	}
+	return err
+}
+
+// parseDate accepts both DATE and DATE-TIME values
+func parseDate(value string) (time.Time, error) {
+	if len(value) > len(dateLayout) {
+		value = value[:len(dateLayout)]
+	}
+	return time.Parse(dateLayout, value)
+}
+
+// unfold joins continuation lines, which start with a space or a tab
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// split divides a content line into its name and value dropping the
+// parameters, DATE and DATE-TIME values are told apart by their length
+func split(line string) (string, string) {
+	colon := strings.IndexByte(line, ':')
+	if colon < 0 {
+		return strings.ToUpper(line), ""
+	}
+	name, value := line[:colon], line[colon+1:]
+	if semicolon := strings.IndexByte(name, ';'); semicolon >= 0 {
+		name = name[:semicolon]
+	}
+	return strings.ToUpper(name), value
+}
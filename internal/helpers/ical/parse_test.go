@@ -0,0 +1,89 @@
+package ical
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	feed := "BEGIN:VCALENDAR\n" +
+		"PRODID:-//Channel//EN\n" +
+		"BEGIN:VTIMEZONE\n" +
+		"TZID:Europe/Moscow\n" +
+		"BEGIN:STANDARD\n" +
+		"DTSTART:19700101T000000\n" +
+		"END:STANDARD\n" +
+		"END:VTIMEZONE\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:a1@channel\n" +
+		"DTSTART;VALUE=DATE:20220102\n" +
+		"DTEND;VALUE=DATE:20220105\n" +
+		"SUMMARY:Reserved\\, guest\n" +
+		"  arrives late\n" +
+		"BEGIN:VALARM\n" +
+		"DESCRIPTION:Reminder\n" +
+		"END:VALARM\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:a2@channel\n" +
+		"DTSTART;TZID=Europe/Moscow:20220110T140000\n" +
+		"DTEND;TZID=Europe/Moscow:20220112T120000\n" +
+		"STATUS:cancelled\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:a3@channel\n" +
+		"DTSTART;VALUE=DATE:20220120\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	calendar, err := Parse(strings.NewReader(feed))
+	assert.NoError(t, err)
+	assert.Equal(t, "-//Channel//EN", calendar.ProductID)
+	assert.Equal(t, []*Event{
+		{UID: "a1@channel", Start: date(2022, 1, 2), End: date(2022, 1, 5),
+			Summary: "Reserved, guest arrives late"},
+		{UID: "a2@channel", Start: date(2022, 1, 10), End: date(2022, 1, 12),
+			Status: StatusCancelled},
+		{UID: "a3@channel", Start: date(2022, 1, 20), End: date(2022, 1, 21)},
+	}, calendar.Events)
+}
+
+func TestParse_Written(t *testing.T) {
+	t.Parallel()
+	written := &Calendar{
+		ProductID: "-//test//EN",
+		Name:      "Room 1",
+		Events: []*Event{{
+			UID:         "booking-1@test",
+			Start:       date(2022, 1, 2),
+			End:         date(2022, 1, 5),
+			Summary:     strings.Repeat("Бронь; ", 20),
+			Description: "Guests: 2\nLate check-in",
+			Status:      StatusConfirmed,
+		}},
+	}
+	feed := &bytes.Buffer{}
+	assert.NoError(t, Write(feed, written, time.Now()))
+
+	calendar, err := Parse(feed)
+	assert.NoError(t, err)
+	assert.Equal(t, written, calendar)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := Parse(strings.NewReader("<html></html>"))
+	assert.Equal(t, ErrNoCalendar, err)
+
+	_, err = Parse(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20220120\nEND:VEVENT\nEND:VCALENDAR\n"))
+	assert.Equal(t, ErrBadEvent, err)
+}
@@ -0,0 +1,45 @@
+package jwt
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("secret")
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	claims := &Claims{Subject: "manager@hotel", ExpiresAt: now.Add(time.Hour).Unix()}
+	token, err := Sign(claims, secret)
+	assert.NoError(t, err)
+
+	parsed, err := Parse(token, secret, now)
+	assert.NoError(t, err)
+	assert.Equal(t, claims, parsed)
+
+	_, err = Parse(token, secret, now.Add(time.Hour))
+	assert.Equal(t, ErrExpired, err)
+
+	_, err = Parse(token, []byte("other"), now)
+	assert.Equal(t, ErrSignature, err)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	token, err := Sign(&Claims{Subject: "guest", NotBefore: now.Add(time.Minute).Unix()}, secret)
+	assert.NoError(t, err)
+	_, err = Parse(token, secret, now)
+	assert.Equal(t, ErrNotYet, err)
+
+	parts := strings.Split(token, ".")
+	// {"alg":"none"}
+	_, err = Parse("eyJhbGciOiJub25lIn0."+parts[1]+".", secret, now)
+	assert.Equal(t, ErrAlgorithm, err)
+
+	_, err = Parse("not a token", secret, now)
+	assert.Equal(t, ErrMalformed, err)
+}
@@ -0,0 +1,136 @@
+// Package migration brings the schema of a database to the last version by
+// applying the files of scripts/migrations the database has not got yet
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// lockKey is the key of the advisory lock taken by every migration, so the
+// instances started at once apply every migration only once
+const lockKey = 7305001
+
+// fileName is the version of the migration followed by its name
+var fileName = regexp.MustCompile(`^(\d+)_\w+\.sql$`)
+
+type Migration struct {
+	Version int
+	Path    string
+}
+
+// List returns the migrations of the directory ordered by version. The
+// versions go one after another starting with 1, so a missing or a duplicate
+// file is an error rather than a migration silently skipped
+func List(dir string) ([]Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, file := range files {
+		match := fileName.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Path: filepath.Join(dir, file.Name())})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration: %s is not version %d", migration.Path, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// Apply runs the migrations of the directory newer than the version of the
+// database and returns the ones applied. Every migration runs in a
+// transaction of its own and records its version there
+func Apply(ctx context.Context, db *sql.DB, dir string) ([]Migration, error) {
+	migrations, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version int         PRIMARY KEY,
+			applied timestamptz NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range migrations {
+		done, err := apply(ctx, db, migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration: %s: %w", migration.Path, err)
+		}
+		if done {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+// apply runs the migration unless the database already has its version
+func apply(ctx context.Context, db *sql.DB, migration Migration) (bool, error) {
+	script, err := ioutil.ReadFile(migration.Path)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return false, err
+	}
+	version, err := selectVersion(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if version >= migration.Version {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return false, err
+	}
+	version, err = selectVersion(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if version != migration.Version {
+		return false, fmt.Errorf("the migration recorded version %d", version)
+	}
+
+	return true, tx.Commit()
+}
+
+func selectVersion(ctx context.Context, tx *sql.Tx) (int, error) {
+	var version int
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(max(version), 0) FROM schema_migrations`).
+		Scan(&version)
+	return version, err
+}
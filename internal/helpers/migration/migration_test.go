@@ -0,0 +1,138 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/health"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const scripts = "../../../scripts"
+
+func writeMigrations(t *testing.T, names ...string) string {
+	dir, err := ioutil.TempDir("", "migrations")
+	assert.NoError(t, err)
+	for i, name := range names {
+		script := fmt.Sprintf("CREATE TABLE t%d (id int);\nINSERT INTO schema_migrations(version) VALUES (%d);\n", i, i+1)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0644))
+	}
+	return dir
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+	dir := writeMigrations(t, "0002_b.sql", "0001_a.sql", "README.md")
+	defer os.RemoveAll(dir)
+
+	migrations, err := List(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Path: filepath.Join(dir, "0001_a.sql")},
+		{Version: 2, Path: filepath.Join(dir, "0002_b.sql")},
+	}, migrations)
+}
+
+func TestList_Gap(t *testing.T) {
+	t.Parallel()
+	dir := writeMigrations(t, "0001_a.sql", "0003_c.sql")
+	defer os.RemoveAll(dir)
+
+	_, err := List(dir)
+	assert.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	dir := writeMigrations(t, "0001_a.sql", "0002_b.sql")
+	defer os.RemoveAll(dir)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COALESCE\(max\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COALESCE\(max\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+	mock.ExpectExec(`CREATE TABLE t1 \(id int\);`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COALESCE\(max\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectCommit()
+
+	applied, err := Apply(context.Background(), db, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{{Version: 2, Path: filepath.Join(dir, "0002_b.sql")}}, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApply_VersionNotRecorded(t *testing.T) {
+	t.Parallel()
+	dir := writeMigrations(t, "0001_a.sql")
+	defer os.RemoveAll(dir)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COALESCE\(max\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(0))
+	mock.ExpectExec(`CREATE TABLE t0 \(id int\);`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COALESCE\(max\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(0))
+	mock.ExpectRollback()
+
+	applied, err := Apply(context.Background(), db, dir)
+	assert.Error(t, err)
+	assert.Empty(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// The migrations of the repository go in order, every one records its own
+// version, init.sql runs all of them and /readyz waits for the last one
+func TestScripts(t *testing.T) {
+	t.Parallel()
+	migrations, err := List(filepath.Join(scripts, "migrations"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	assert.Equal(t, health.SchemaVersion, migrations[len(migrations)-1].Version)
+
+	var includes []string
+	for _, migration := range migrations {
+		script, err := ioutil.ReadFile(migration.Path)
+		assert.NoError(t, err)
+		record := fmt.Sprintf("INSERT INTO schema_migrations(version) VALUES (%d);", migration.Version)
+		assert.True(t, strings.HasSuffix(strings.TrimSpace(string(script)), record), migration.Path)
+		includes = append(includes, `\ir migrations/`+filepath.Base(migration.Path))
+	}
+
+	initScript, err := ioutil.ReadFile(filepath.Join(scripts, "init.sql"))
+	assert.NoError(t, err)
+	assert.Equal(t, includes, regexp.MustCompile(`(?m)^\\ir .+$`).FindAllString(string(initScript), -1))
+}
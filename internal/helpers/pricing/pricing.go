@@ -0,0 +1,59 @@
+package pricing
+
+import "github.com/booking_backend/internal/models"
+
+// Quote applies the promo code discount and the tax rules of the property
+// to a stay. Amounts are rounded half up to whole currency units
+func Quote(nightPrice uint64, nights uint64, guests uint64, rules *models.TaxRules,
+	promo *models.PromoCode) *models.Quote {
+	quote := &models.Quote{
+		Nights:        nights,
+		Guests:        guests,
+		NightPrice:    nightPrice,
+		Accommodation: nightPrice * nights,
+		CleaningFee:   rules.CleaningFee,
+		TouristTax:    rules.TouristTax * guests * nights,
+		VATPercent:    rules.VATPercent,
+		TaxMode:       rules.TaxMode,
+	}
+	if quote.TaxMode == "" {
+		quote.TaxMode = models.TaxModeExclusive
+	}
+
+	if promo != nil {
+		quote.PromoCode = promo.Code
+		quote.Discount = Discount(quote.Accommodation, promo)
+	}
+
+	taxable := quote.Accommodation - quote.Discount + quote.CleaningFee
+	quote.Total = taxable + quote.TouristTax
+	switch quote.TaxMode {
+	case models.TaxModeInclusive:
+		quote.VAT = divide(taxable*rules.VATPercent, 100+rules.VATPercent)
+	default:
+		quote.VAT = divide(taxable*rules.VATPercent, 100)
+		quote.Total += quote.VAT
+	}
+	return quote
+}
+
+// Discount never exceeds the accommodation price, so fees and taxes are always paid
+func Discount(accommodation uint64, promo *models.PromoCode) uint64 {
+	switch promo.DiscountType {
+	case models.DiscountTypePercent:
+		if promo.DiscountValue >= 100 {
+			return accommodation
+		}
+		return divide(accommodation*promo.DiscountValue, 100)
+	case models.DiscountTypeFixed:
+		if promo.DiscountValue >= accommodation {
+			return accommodation
+		}
+		return promo.DiscountValue
+	}
+	return 0
+}
+
+func divide(a uint64, b uint64) uint64 {
+	return (a + b/2) / b
+}
@@ -0,0 +1,97 @@
+package pricing
+
+import (
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQuote_NoTaxes(t *testing.T) {
+	t.Parallel()
+	quote := Quote(500, 3, 2, &models.TaxRules{}, nil)
+
+	assert.Equal(t, &models.Quote{
+		Nights:        3,
+		Guests:        2,
+		NightPrice:    500,
+		Accommodation: 1500,
+		TaxMode:       models.TaxModeExclusive,
+		Total:         1500,
+	}, quote)
+}
+
+func TestQuote_Exclusive(t *testing.T) {
+	t.Parallel()
+	rules := &models.TaxRules{
+		VATPercent:  20,
+		TaxMode:     models.TaxModeExclusive,
+		TouristTax:  10,
+		CleaningFee: 300,
+	}
+	quote := Quote(500, 3, 2, rules, nil)
+
+	assert.Equal(t, uint64(1500), quote.Accommodation)
+	assert.Equal(t, uint64(60), quote.TouristTax)
+	assert.Equal(t, uint64(360), quote.VAT)
+	assert.Equal(t, uint64(1500+300+360+60), quote.Total)
+}
+
+func TestQuote_Inclusive(t *testing.T) {
+	t.Parallel()
+	rules := &models.TaxRules{
+		VATPercent:  20,
+		TaxMode:     models.TaxModeInclusive,
+		TouristTax:  10,
+		CleaningFee: 300,
+	}
+	quote := Quote(500, 3, 2, rules, nil)
+
+	assert.Equal(t, uint64(300), quote.VAT)
+	assert.Equal(t, uint64(1500+300+60), quote.Total)
+}
+
+func TestQuote_Rounding(t *testing.T) {
+	t.Parallel()
+	quote := Quote(333, 1, 1, &models.TaxRules{VATPercent: 10, TaxMode: models.TaxModeInclusive}, nil)
+
+	assert.Equal(t, uint64(30), quote.VAT)
+	assert.Equal(t, uint64(333), quote.Total)
+}
+
+func TestQuote_PromoCode(t *testing.T) {
+	t.Parallel()
+	rules := &models.TaxRules{
+		VATPercent:  20,
+		TaxMode:     models.TaxModeExclusive,
+		CleaningFee: 300,
+	}
+	promo := &models.PromoCode{
+		Code:          "SUMMER",
+		DiscountType:  models.DiscountTypePercent,
+		DiscountValue: 10,
+	}
+	quote := Quote(500, 3, 1, rules, promo)
+
+	assert.Equal(t, "SUMMER", quote.PromoCode)
+	assert.Equal(t, uint64(150), quote.Discount)
+	assert.Equal(t, uint64(330), quote.VAT)
+	assert.Equal(t, uint64(1500-150+300+330), quote.Total)
+}
+
+func TestDiscount(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		promo *models.PromoCode
+		want  uint64
+	}{
+		{"percent", &models.PromoCode{DiscountType: models.DiscountTypePercent, DiscountValue: 15}, 150},
+		{"fixed", &models.PromoCode{DiscountType: models.DiscountTypeFixed, DiscountValue: 300}, 300},
+		{"fixed above price", &models.PromoCode{DiscountType: models.DiscountTypeFixed, DiscountValue: 5000}, 1000},
+		{"full percent", &models.PromoCode{DiscountType: models.DiscountTypePercent, DiscountValue: 100}, 1000},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Discount(1000, test.promo), test.name)
+	}
+}
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+// DefaultTimeout bounds a repository call when no timeout is configured
+const DefaultTimeout = 5 * time.Second
+
+// codeQueryCanceled is returned by Postgres for the statement cancelled on
+// the deadline of its context or on statement_timeout
+const codeQueryCanceled = "57014"
+
+// WithTimeout bounds the repository call made with the returned context,
+// a non-positive timeout falls back to DefaultTimeout
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// IsTimeout tells the query has been stopped because its context has passed
+// the deadline or has been cancelled with the request
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == codeQueryCanceled
+}
@@ -0,0 +1,37 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIsTimeout(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsTimeout(context.DeadlineExceeded))
+	assert.True(t, IsTimeout(context.Canceled))
+	assert.True(t, IsTimeout(fmt.Errorf("select rooms: %w", context.DeadlineExceeded)))
+	assert.True(t, IsTimeout(&pq.Error{Code: "57014"}))
+	assert.False(t, IsTimeout(&pq.Error{Code: "23505"}))
+	assert.False(t, IsTimeout(errors.New("connection refused")))
+	assert.False(t, IsTimeout(nil))
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	ctx, cancel = WithTimeout(context.Background(), 0)
+	defer cancel()
+	deadline, ok = ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(DefaultTimeout), deadline, time.Second)
+}
@@ -0,0 +1,53 @@
+// Package rbac tells which operations every role is permitted
+package rbac
+
+import "github.com/booking_backend/internal/models"
+
+type Permission string
+
+const (
+	ViewRooms   Permission = "rooms:view"
+	ManageRooms Permission = "rooms:manage"
+	DeleteRooms Permission = "rooms:delete"
+	// ViewBookings and CancelBookings cover all bookings, the Own
+	// permissions only the bookings of the caller
+	ViewBookings      Permission = "bookings:view"
+	ViewOwnBookings   Permission = "bookings:view_own"
+	CreateBookings    Permission = "bookings:create"
+	CancelBookings    Permission = "bookings:cancel"
+	CancelOwnBookings Permission = "bookings:cancel_own"
+	// ManageBookings covers reservations, payments and refunds
+	ManageBookings   Permission = "bookings:manage"
+	ManageProperties Permission = "properties:manage"
+	ImportData       Permission = "data:import"
+	ManageAPIKeys    Permission = "api_keys:manage"
+	ViewAudit        Permission = "audit:view"
+	ManageWebhooks   Permission = "webhooks:manage"
+)
+
+var roles = map[string][]Permission{
+	models.RoleAdmin: {ViewRooms, ManageRooms, DeleteRooms,
+		ViewBookings, CreateBookings, CancelBookings, ManageBookings,
+		ManageProperties, ImportData, ManageAPIKeys, ViewAudit, ManageWebhooks},
+	models.RoleManager: {ViewRooms, ManageRooms, DeleteRooms,
+		ViewBookings, CreateBookings, CancelBookings, ManageBookings,
+		ManageProperties, ImportData, ViewAudit},
+	models.RoleFrontDesk: {ViewRooms,
+		ViewBookings, CreateBookings, CancelBookings, ManageBookings},
+	models.RoleGuest: {ViewRooms,
+		ViewOwnBookings, CreateBookings, CancelOwnBookings},
+}
+
+func IsRole(role string) bool {
+	_, has := roles[role]
+	return has
+}
+
+func Can(role string, permission Permission) bool {
+	for _, granted := range roles[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package rbac
+
+import (
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		role       string
+		permission Permission
+		want       bool
+	}{
+		{models.RoleAdmin, ManageAPIKeys, true},
+		{models.RoleManager, ManageAPIKeys, false},
+		{models.RoleManager, DeleteRooms, true},
+		{models.RoleFrontDesk, DeleteRooms, false},
+		{models.RoleFrontDesk, CancelBookings, true},
+		{models.RoleGuest, CancelBookings, false},
+		{models.RoleGuest, CancelOwnBookings, true},
+		{models.RoleGuest, ViewBookings, false},
+		{models.RoleManager, ViewAudit, true},
+		{models.RoleFrontDesk, ViewAudit, false},
+		{models.RoleAdmin, ManageWebhooks, true},
+		{models.RoleManager, ManageWebhooks, false},
+		{"owner", ViewRooms, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Can(test.role, test.permission), test.role, test.permission)
+	}
+	assert.False(t, IsRole("owner"))
+	assert.True(t, IsRole(models.RoleGuest))
+}
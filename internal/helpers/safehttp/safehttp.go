@@ -0,0 +1,150 @@
+// Package safehttp makes requests to the URLs given by the tenants, such as
+// calendar feeds and webhooks, without letting them reach the internal network
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const maxRedirects = 10
+
+var (
+	ErrForbiddenAddress = errors.New("safehttp: address is not public")
+	ErrForbiddenScheme  = errors.New("safehttp: only http and https URLs are allowed")
+)
+
+// nonPublic are the loopback, private, link-local (cloud metadata included),
+// shared, multicast and reserved ranges
+var nonPublic = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsPublic tells whether the address is reachable on the internet.
+// IPv4-mapped IPv6 addresses are checked as IPv4 ones
+func IsPublic(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range nonPublic {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL makes sure the URL is http or https and every address of its host
+// is public. It is a check for the input, the addresses may change before the
+// request, so the requests are made with the client of NewClient
+func CheckURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ErrForbiddenScheme
+	}
+
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublic(ip) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublic(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// IsForbidden tells whether the request failed because its URL or one of the
+// redirects was not allowed
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbiddenAddress) || errors.Is(err, ErrForbiddenScheme)
+}
+
+// control runs once the address is resolved, right before the connection
+func control(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// NewClient returns the client that connects only to public addresses. The
+// address is checked at dial time, so neither a redirect nor a DNS record
+// changed after CheckURL lead to the internal network. Proxies are not used,
+// as they would connect on behalf of the client. Redirects are followed only
+// when followRedirects is set, otherwise the redirect response is returned
+func NewClient(timeout time.Duration, followRedirects bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if !followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("safehttp: stopped after %d redirects", maxRedirects)
+			}
+			if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+				return ErrForbiddenScheme
+			}
+			return nil
+		},
+	}
+}
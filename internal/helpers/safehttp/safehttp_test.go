@@ -0,0 +1,66 @@
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	t.Parallel()
+	for address, public := range map[string]bool{
+		"8.8.8.8":          true,
+		"2a00:1450::1":     true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		assert.Equal(t, public, IsPublic(net.ParseIP(address)), address)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	assert.NoError(t, CheckURL(ctx, "https://8.8.8.8/hooks"))
+	assert.Equal(t, ErrForbiddenAddress, CheckURL(ctx, "http://169.254.169.254/latest/meta-data"))
+	assert.Equal(t, ErrForbiddenAddress, CheckURL(ctx, "http://[::1]:9000/rooms"))
+	assert.Equal(t, ErrForbiddenAddress, CheckURL(ctx, "http://localhost:9000/rooms"))
+	assert.Equal(t, ErrForbiddenScheme, CheckURL(ctx, "file:///etc/passwd"))
+}
+
+func TestNewClient_RejectsLoopback(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request reached the server")
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second, true).Get(server.URL)
+	assert.True(t, errors.Is(err, ErrForbiddenAddress))
+}
+
+func TestNewClient_Redirects(t *testing.T) {
+	t.Parallel()
+	request := httptest.NewRequest(http.MethodGet, "https://8.8.8.8/next", nil)
+	via := []*http.Request{httptest.NewRequest(http.MethodGet, "https://8.8.8.8/", nil)}
+
+	assert.Equal(t, http.ErrUseLastResponse, NewClient(time.Second, false).CheckRedirect(request, via))
+	assert.NoError(t, NewClient(time.Second, true).CheckRedirect(request, via))
+
+	request.URL.Scheme = "file"
+	assert.Equal(t, ErrForbiddenScheme, NewClient(time.Second, true).CheckRedirect(request, via))
+}
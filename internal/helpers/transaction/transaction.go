@@ -0,0 +1,145 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/booking_backend/internal/helpers/query"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// RetryPolicy describes how many times a unit of work is attempted. The pause
+// between attempts starts with Backoff and doubles after every attempt
+type RetryPolicy struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+// Manager runs several repository calls as one unit of work. The repositories
+// join the transaction of the unit of work through the context given to them
+type Manager interface {
+	// Run commits the transaction once the work succeeds and rolls it back
+	// otherwise. The work is attempted again from the start when Postgres
+	// fails to serialize the transaction or detects a deadlock
+	Run(ctx context.Context, isolation sql.IsolationLevel, work func(ctx context.Context) error) error
+}
+
+type manager struct {
+	db      *sql.DB
+	timeout time.Duration
+	retry   RetryPolicy
+}
+
+// NewManager bounds every attempt of a unit of work with the timeout
+func NewManager(db *sql.DB, timeout time.Duration, retry RetryPolicy) Manager {
+	return &manager{db: db, timeout: timeout, retry: retry}
+}
+
+type txKey struct{}
+
+func fromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{}).(*sql.Tx)
+	return tx
+}
+
+func (m *manager) Run(ctx context.Context, isolation sql.IsolationLevel,
+	work func(ctx context.Context) error) error {
+	// A nested unit of work is a part of the outer one
+	if fromContext(ctx) != nil {
+		return work(ctx)
+	}
+
+	backoff := m.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		err := m.attempt(ctx, isolation, work)
+		if err == nil || !IsRetryable(err) || attempt >= m.retry.Attempts {
+			return err
+		}
+		logrus.Info("transaction is retried: ", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (m *manager) attempt(ctx context.Context, isolation sql.IsolationLevel,
+	work func(ctx context.Context) error) error {
+	ctx, cancel := query.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{Isolation: isolation})
+	if err != nil {
+		return err
+	}
+	if err := work(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logrus.Info(rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// IsRetryable tells the transaction has failed only because of the concurrent
+// ones, so it may succeed when run again
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == codeSerializationFailure || pqErr.Code == codeDeadlockDetected
+}
+
+// Conn is the part of the connection the repositories query with
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Querier returns the transaction of the unit of work running with ctx, the
+// database otherwise
+func Querier(ctx context.Context, db *sql.DB) Conn {
+	if tx := fromContext(ctx); tx != nil {
+		return tx
+	}
+	return db
+}
+
+// Begin joins the transaction of the unit of work running with ctx, otherwise
+// it starts a new transaction
+func Begin(ctx context.Context, db *sql.DB) (*sql.Tx, error) {
+	if tx := fromContext(ctx); tx != nil {
+		return tx, nil
+	}
+	return db.BeginTx(ctx, &sql.TxOptions{})
+}
+
+// Commit commits the transaction started by Begin. The transaction of the
+// unit of work is left to it
+func Commit(ctx context.Context, tx *sql.Tx) error {
+	if fromContext(ctx) == tx {
+		return nil
+	}
+	return tx.Commit()
+}
+
+// Rollback rolls back the transaction started by Begin. The transaction of
+// the unit of work is rolled back by it once the failed work returns
+func Rollback(ctx context.Context, tx *sql.Tx) error {
+	if fromContext(ctx) == tx {
+		return nil
+	}
+	return tx.Rollback()
+}
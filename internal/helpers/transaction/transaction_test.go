@@ -0,0 +1,158 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var retry = RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsRetryable(&pq.Error{Code: "40001"}))
+	assert.True(t, IsRetryable(&pq.Error{Code: "40P01"}))
+	assert.True(t, IsRetryable(fmt.Errorf("insert booking: %w", &pq.Error{Code: "40001"})))
+	assert.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, IsRetryable(sql.ErrNoRows))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestManager_Run_Commit(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE rooms").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE bookings").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = NewManager(db, time.Minute, retry).Run(context.Background(), sql.LevelSerializable,
+		func(ctx context.Context) error {
+			if _, err := Querier(ctx, db).ExecContext(ctx, "UPDATE rooms"); err != nil {
+				return err
+			}
+			// The transaction of the unit of work is joined and committed once
+			tx, err := Begin(ctx, db)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE bookings"); err != nil {
+				return err
+			}
+			return Commit(ctx, tx)
+		})
+	assert.NoError(t, err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestManager_Run_RetrySerializationFailure(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE rooms").WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE rooms").WillReturnError(&pq.Error{Code: "40P01"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE rooms").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts := 0
+	err = NewManager(db, time.Minute, retry).Run(context.Background(), sql.LevelSerializable,
+		func(ctx context.Context) error {
+			attempts++
+			_, err := Querier(ctx, db).ExecContext(ctx, "UPDATE rooms")
+			return err
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestManager_Run_AttemptsExhausted(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 0; i < retry.Attempts; i++ {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+	}
+
+	serializationFailure := &pq.Error{Code: "40001"}
+	err = NewManager(db, time.Minute, retry).Run(context.Background(), sql.LevelSerializable,
+		func(ctx context.Context) error {
+			return serializationFailure
+		})
+	assert.Equal(t, serializationFailure, err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestManager_Run_NoRetry(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	workErr := errors.New("room is occupied")
+	err = NewManager(db, time.Minute, retry).Run(context.Background(), sql.LevelSerializable,
+		func(ctx context.Context) error {
+			tx, err := Begin(ctx, db)
+			if err != nil {
+				return err
+			}
+			// The failed call leaves the rollback to the unit of work
+			assert.NoError(t, Rollback(ctx, tx))
+			return workErr
+		})
+	assert.Equal(t, workErr, err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestQuerier_WithoutUnitOfWork(t *testing.T) {
+	t.Parallel()
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	assert.Equal(t, db, Querier(context.Background(), db))
+}
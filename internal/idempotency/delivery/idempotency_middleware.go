@@ -0,0 +1,197 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/idempotency"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks the stored response to the first request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxKeyLength             = 255
+	// maxBodySize bounds the body kept in memory to be hashed
+	maxBodySize = 1 << 20
+)
+
+type IdempotencyHandler struct {
+	idempotencyUseCase idempotency.IdempotencyUseCase
+}
+
+func NewIdempotencyHandler(useCase idempotency.IdempotencyUseCase) *IdempotencyHandler {
+	return &IdempotencyHandler{idempotencyUseCase: useCase}
+}
+
+// requestHash tells apart the requests made with the same key. Forms are
+// hashed by their parsed values, so a retry encoded anew, e.g. with another
+// multipart boundary, matches the first request. A form that can't be parsed
+// is hashed as is, the handler rejects it anyway
+func requestHash(request *http.Request, body []byte) string {
+	contentType := request.Header.Get(echo.HeaderContentType)
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == echo.MIMEMultipartForm || mediaType == echo.MIMEApplicationForm) {
+		var form bytes.Buffer
+		if err := writeForm(&form, mediaType, params["boundary"], body); err == nil {
+			contentType, body = mediaType, form.Bytes()
+		}
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s?%s\n%s\n", request.Method, request.URL.Path,
+		request.URL.RawQuery, contentType)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeForm writes the form fields sorted by name, the values of a field keep
+// their order. Files are written as their names and the hash of the content
+func writeForm(w io.Writer, mediaType string, boundary string, body []byte) error {
+	type field struct {
+		name  string
+		value string
+	}
+	var fields []field
+
+	if mediaType == echo.MIMEApplicationForm {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return err
+		}
+		for name, list := range values {
+			for _, value := range list {
+				fields = append(fields, field{name: name, value: value})
+			}
+		}
+	} else {
+		reader := multipart.NewReader(bytes.NewReader(body), boundary)
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadAll(part)
+			if err != nil {
+				return err
+			}
+			value := string(content)
+			if part.FileName() != "" {
+				value = fmt.Sprintf("file %q %x", part.FileName(), sha256.Sum256(content))
+			}
+			fields = append(fields, field{name: part.FormName(), value: value})
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(w, "%q=%q\n", f.name, f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recorder keeps a copy of the response written through it
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// keepAlive holds the key in the background until the returned stop is called
+func (ih *IdempotencyHandler) keepAlive(record *models.IdempotencyRecord) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ih.idempotencyUseCase.KeepAlive(ctx, record)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Idempotent runs the request with the Idempotency-Key header once per
+// caller and key, its retries get the stored response. Requests without
+// the header are run as usual
+func (ih *IdempotencyHandler) Idempotent() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			key := context.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(context)
+			}
+
+			request := context.Request()
+			body, err := ioutil.ReadAll(http.MaxBytesReader(context.Response(), request.Body, maxBodySize))
+			if err == nil && len(key) > maxKeyLength {
+				err = fmt.Errorf("%s is longer than %d", HeaderIdempotencyKey, maxKeyLength)
+			}
+			if err != nil {
+				customErr := errors.New(CodeBadRequest, err)
+				logrus.Info(customErr)
+				return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+			}
+			request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			record := &models.IdempotencyRecord{
+				Tenant:      principal.Tenant(context),
+				Subject:     principal.Actor(context).Subject,
+				Key:         key,
+				RequestHash: requestHash(request, body),
+			}
+			stored, customErr := ih.idempotencyUseCase.Begin(record)
+			if customErr != nil {
+				logrus.Info(customErr)
+				return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+			}
+			if stored != nil {
+				context.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return context.JSONBlob(stored.StatusCode, stored.Response)
+			}
+
+			rec := &recorder{ResponseWriter: context.Response().Writer}
+			context.Response().Writer = rec
+			stopKeepAlive := ih.keepAlive(record)
+			err = next(context)
+			stopKeepAlive()
+
+			statusCode := context.Response().Status
+			if err != nil || !context.Response().Committed {
+				// The error is written after the middleware, so it isn't stored
+				statusCode = http.StatusInternalServerError
+			}
+			if customErr := ih.idempotencyUseCase.Complete(record, statusCode,
+				rec.body.Bytes()); customErr != nil {
+				logrus.Error(customErr)
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/idempotency/mocks"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/principal"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newServer counts the bookings made, the handler echoes the request body
+func newServer(ctrl *gomock.Controller, made *int) (*echo.Echo, *mocks.MockIdempotencyUseCase) {
+	useCase := mocks.NewMockIdempotencyUseCase(ctrl)
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			principal.Set(context, &models.Principal{Subject: "guest@mail.ru",
+				Role: models.RoleGuest, Tenant: models.DefaultTenantID})
+			return next(context)
+		}
+	})
+	e.POST("bookings/create", func(context echo.Context) error {
+		*made++
+		body, _ := ioutil.ReadAll(context.Request().Body)
+		return context.JSONBlob(http.StatusCreated, body)
+	}, NewIdempotencyHandler(useCase).Idempotent())
+	return e, useCase
+}
+
+func newRequest(key string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/bookings/create", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	return req
+}
+
+func TestIdempotent_NoKey(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	made := 0
+	e, _ := newServer(ctrl, &made)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newRequest("", `{"room_id":1}`))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, 1, made)
+}
+
+func TestIdempotent_First(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	made := 0
+	e, useCase := newServer(ctrl, &made)
+	var begun *models.IdempotencyRecord
+	useCase.EXPECT().Begin(gomock.Any()).
+		DoAndReturn(func(record *models.IdempotencyRecord) (*models.IdempotencyRecord, *errors.Error) {
+			assert.Equal(t, models.DefaultTenantID, record.Tenant)
+			assert.Equal(t, "guest@mail.ru", record.Subject)
+			assert.Equal(t, "b7c1", record.Key)
+			begun = record
+			return nil, nil
+		})
+	// The key is held until the handler is done
+	useCase.EXPECT().KeepAlive(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, record *models.IdempotencyRecord) {
+			assert.Equal(t, begun, record)
+			<-ctx.Done()
+			assert.Equal(t, 1, made)
+		})
+	useCase.EXPECT().Complete(gomock.Any(), http.StatusCreated, []byte(`{"room_id":1}`)).
+		DoAndReturn(func(record *models.IdempotencyRecord, _ int, _ []byte) *errors.Error {
+			assert.Equal(t, begun, record)
+			return nil
+		})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newRequest("b7c1", `{"room_id":1}`))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	// The handler still reads the whole body after it is hashed
+	assert.Equal(t, `{"room_id":1}`, rec.Body.String())
+	assert.Equal(t, 1, made)
+}
+
+func TestIdempotent_Replay(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	made := 0
+	e, useCase := newServer(ctrl, &made)
+	useCase.EXPECT().Begin(gomock.Any()).Return(&models.IdempotencyRecord{
+		StatusCode: http.StatusCreated, Response: []byte(`{"booking_id":5}`)}, nil)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newRequest("b7c1", `{"room_id":1}`))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"booking_id":5}`, rec.Body.String())
+	assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, 0, made)
+}
+
+func TestIdempotent_Reused(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	made := 0
+	e, useCase := newServer(ctrl, &made)
+	hashes := map[string]bool{}
+	useCase.EXPECT().Begin(gomock.Any()).
+		DoAndReturn(func(record *models.IdempotencyRecord) (*models.IdempotencyRecord, *errors.Error) {
+			hashes[record.RequestHash] = true
+			return nil, errors.Get(consts.CodeIdempotencyKeyReused)
+		}).Times(2)
+
+	for _, body := range []string{`{"room_id":1}`, `{"room_id":2}`} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, newRequest("b7c1", body))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	}
+	assert.Len(t, hashes, 2)
+	assert.Equal(t, 0, made)
+}
+
+func TestIdempotent_LongKey(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	made := 0
+	e, _ := newServer(ctrl, &made)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newRequest(strings.Repeat("k", maxKeyLength+1), `{"room_id":1}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 0, made)
+}
+
+func TestIdempotent_BodyTooLarge(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	made := 0
+	e, _ := newServer(ctrl, &made)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newRequest("b7c1", strings.Repeat(" ", maxBodySize+1)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 0, made)
+}
+
+// multipartRequest encodes the fields and the file with a random boundary
+func multipartRequest(t *testing.T, fields [][2]string, file string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, field := range fields {
+		assert.NoError(t, writer.WriteField(field[0], field[1]))
+	}
+	part, err := writer.CreateFormFile("file", "rooms.csv")
+	assert.NoError(t, err)
+	_, err = part.Write([]byte(file))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/bookings/create", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestRequestHash_Multipart(t *testing.T) {
+	t.Parallel()
+	hash := func(req *http.Request) string {
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		return requestHash(req, body)
+	}
+
+	first := hash(multipartRequest(t, [][2]string{{"property", "1"}, {"source", "pms"}}, "1,500\n"))
+	// A retry gets another boundary and may order the fields differently
+	retry := hash(multipartRequest(t, [][2]string{{"source", "pms"}, {"property", "1"}}, "1,500\n"))
+	assert.Equal(t, first, retry)
+
+	assert.NotEqual(t, first, hash(multipartRequest(t, [][2]string{{"property", "2"}, {"source", "pms"}}, "1,500\n")))
+	assert.NotEqual(t, first, hash(multipartRequest(t, [][2]string{{"property", "1"}, {"source", "pms"}}, "1,600\n")))
+}
+
+func TestRequestHash_URLEncoded(t *testing.T) {
+	t.Parallel()
+	newForm := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/bookings/create", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		return req
+	}
+
+	first := requestHash(newForm("room_id=1&guests=2"), []byte("room_id=1&guests=2"))
+	assert.Equal(t, first, requestHash(newForm("guests=2&room_id=1"), []byte("guests=2&room_id=1")))
+	assert.NotEqual(t, first, requestHash(newForm("room_id=1&guests=3"), []byte("room_id=1&guests=3")))
+}
@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_idempotency is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockIdempotencyUseCase is a mock of IdempotencyUseCase interface
+type MockIdempotencyUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyUseCaseMockRecorder
+}
+
+// MockIdempotencyUseCaseMockRecorder is the mock recorder for MockIdempotencyUseCase
+type MockIdempotencyUseCaseMockRecorder struct {
+	mock *MockIdempotencyUseCase
+}
+
+// NewMockIdempotencyUseCase creates a new mock instance
+func NewMockIdempotencyUseCase(ctrl *gomock.Controller) *MockIdempotencyUseCase {
+	mock := &MockIdempotencyUseCase{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockIdempotencyUseCase) EXPECT() *MockIdempotencyUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method
+func (m *MockIdempotencyUseCase) Begin(record *models.IdempotencyRecord) (*models.IdempotencyRecord, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", record)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin
+func (mr *MockIdempotencyUseCaseMockRecorder) Begin(record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotencyUseCase)(nil).Begin), record)
+}
+
+// KeepAlive mocks base method
+func (m *MockIdempotencyUseCase) KeepAlive(ctx context.Context, record *models.IdempotencyRecord) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "KeepAlive", ctx, record)
+}
+
+// KeepAlive indicates an expected call of KeepAlive
+func (mr *MockIdempotencyUseCaseMockRecorder) KeepAlive(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeepAlive", reflect.TypeOf((*MockIdempotencyUseCase)(nil).KeepAlive), ctx, record)
+}
+
+// Complete mocks base method
+func (m *MockIdempotencyUseCase) Complete(record *models.IdempotencyRecord, statusCode int, response []byte) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", record, statusCode, response)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete
+func (mr *MockIdempotencyUseCaseMockRecorder) Complete(record, statusCode, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyUseCase)(nil).Complete), record, statusCode, response)
+}
+
+// DeleteExpired mocks base method
+func (m *MockIdempotencyUseCase) DeleteExpired() (int64, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockIdempotencyUseCaseMockRecorder) DeleteExpired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockIdempotencyUseCase)(nil).DeleteExpired))
+}
@@ -0,0 +1,22 @@
+package idempotency
+
+import (
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+type IdempotencyRepository interface {
+	// Reserve stores the record of a new request or takes over the record
+	// created before expiredBefore, or left in progress before staleBefore.
+	// False means the key is taken
+	Reserve(record *models.IdempotencyRecord, expiredBefore time.Time,
+		staleBefore time.Time) (bool, error)
+	Select(tenant uint64, subject string, key string) (*models.IdempotencyRecord, error)
+	Complete(record *models.IdempotencyRecord) error
+	// Touch renews the record of the request in progress, so it isn't taken
+	// for a stale one while the request runs
+	Touch(record *models.IdempotencyRecord) error
+	// Release deletes the record of the request in progress
+	Release(record *models.IdempotencyRecord) error
+	DeleteExpired(before time.Time) (int64, error)
+}
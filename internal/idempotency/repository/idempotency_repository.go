@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/idempotency"
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) idempotency.IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (rep *IdempotencyRepository) Reserve(record *models.IdempotencyRecord,
+	expiredBefore time.Time, staleBefore time.Time) (bool, error) {
+	res, err := rep.db.Exec(`
+		INSERT INTO idempotency_keys(tenant, subject, key, request_hash)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, subject, key) DO UPDATE
+		SET request_hash=EXCLUDED.request_hash, status_code=0, response=NULL, created=now()
+		WHERE idempotency_keys.created < $5
+			OR (idempotency_keys.status_code = 0 AND idempotency_keys.created < $6)`,
+		record.Tenant, record.Subject, record.Key, record.RequestHash, expiredBefore, staleBefore)
+	if err != nil {
+		return false, err
+	}
+	reserved, err := res.RowsAffected()
+	return reserved == 1, err
+}
+
+func (rep *IdempotencyRepository) Select(tenant uint64, subject string,
+	key string) (*models.IdempotencyRecord, error) {
+	record := &models.IdempotencyRecord{}
+	err := rep.db.QueryRow(`
+		SELECT tenant, subject, key, request_hash, status_code, response, created
+		FROM idempotency_keys
+		WHERE tenant=$1 AND subject=$2 AND key=$3`, tenant, subject, key).
+		Scan(&record.Tenant, &record.Subject, &record.Key, &record.RequestHash,
+			&record.StatusCode, &record.Response, &record.Created)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (rep *IdempotencyRepository) Complete(record *models.IdempotencyRecord) error {
+	_, err := rep.db.Exec(`
+		UPDATE idempotency_keys
+		SET status_code=$1, response=$2
+		WHERE tenant=$3 AND subject=$4 AND key=$5 AND request_hash=$6`,
+		record.StatusCode, record.Response, record.Tenant, record.Subject, record.Key,
+		record.RequestHash)
+	return err
+}
+
+func (rep *IdempotencyRepository) Touch(record *models.IdempotencyRecord) error {
+	_, err := rep.db.Exec(`
+		UPDATE idempotency_keys
+		SET created=now()
+		WHERE tenant=$1 AND subject=$2 AND key=$3 AND request_hash=$4 AND status_code=0`,
+		record.Tenant, record.Subject, record.Key, record.RequestHash)
+	return err
+}
+
+func (rep *IdempotencyRepository) Release(record *models.IdempotencyRecord) error {
+	_, err := rep.db.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE tenant=$1 AND subject=$2 AND key=$3 AND request_hash=$4 AND status_code=0`,
+		record.Tenant, record.Subject, record.Key, record.RequestHash)
+	return err
+}
+
+func (rep *IdempotencyRepository) DeleteExpired(before time.Time) (int64, error) {
+	res, err := rep.db.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE created < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	record := &models.IdempotencyRecord{Tenant: models.DefaultTenantID, Subject: "guest@mail.ru",
+		Key: "b7c1", RequestHash: "hash"}
+	expiredBefore := time.Now().Add(-24 * time.Hour)
+	staleBefore := time.Now().Add(-time.Minute)
+	// The second time the key is taken, so the conflicting row is kept
+	mock.ExpectExec(`INSERT INTO idempotency_keys(.+) ON CONFLICT`).
+		WithArgs(record.Tenant, record.Subject, record.Key, record.RequestHash,
+			expiredBefore, staleBefore).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO idempotency_keys(.+) ON CONFLICT`).
+		WithArgs(record.Tenant, record.Subject, record.Key, record.RequestHash,
+			expiredBefore, staleBefore).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rep := NewIdempotencyRepository(db)
+	reserved, err := rep.Reserve(record, expiredBefore, staleBefore)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	reserved, err = rep.Reserve(record, expiredBefore, staleBefore)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Touch(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	record := &models.IdempotencyRecord{Tenant: models.DefaultTenantID, Subject: "guest@mail.ru",
+		Key: "b7c1", RequestHash: "hash"}
+	mock.ExpectExec(`UPDATE idempotency_keys SET created=now\(\)(.+)status_code=0`).
+		WithArgs(record.Tenant, record.Subject, record.Key, record.RequestHash).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rep := NewIdempotencyRepository(db)
+	assert.NoError(t, rep.Touch(record))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package sweeper
+
+import (
+	"context"
+	"github.com/booking_backend/internal/idempotency"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// IdempotencySweeper periodically deletes the expired idempotency keys
+type IdempotencySweeper struct {
+	idempotencyUseCase idempotency.IdempotencyUseCase
+	interval           time.Duration
+}
+
+func NewIdempotencySweeper(useCase idempotency.IdempotencyUseCase,
+	interval time.Duration) *IdempotencySweeper {
+	return &IdempotencySweeper{idempotencyUseCase: useCase, interval: interval}
+}
+
+// Run blocks until the context is cancelled
+func (is *IdempotencySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(is.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			is.Sweep()
+		}
+	}
+}
+
+func (is *IdempotencySweeper) Sweep() {
+	deleted, customErr := is.idempotencyUseCase.DeleteExpired()
+	if customErr != nil {
+		logrus.Error(customErr)
+		return
+	}
+	if deleted > 0 {
+		logrus.Infof("deleted %d expired idempotency keys", deleted)
+	}
+}
@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type IdempotencyUseCase interface {
+	// Begin returns the stored response if the request was already made with
+	// the key, and nil if the request is new and has to be run
+	Begin(record *models.IdempotencyRecord) (*models.IdempotencyRecord, *errors.Error)
+	// KeepAlive holds the key of the request in progress until ctx is done
+	KeepAlive(ctx context.Context, record *models.IdempotencyRecord)
+	Complete(record *models.IdempotencyRecord, statusCode int, response []byte) *errors.Error
+	DeleteExpired() (int64, *errors.Error)
+}
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/idempotency"
+	"github.com/booking_backend/internal/models"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// lockTimeout is how long the key of a request stays held without a sign of
+// life. The request renews it every keepAliveInterval while it runs, so only a
+// lost request, e.g. with the instance restarted, leaves the key to a retry
+const (
+	lockTimeout       = time.Minute
+	keepAliveInterval = lockTimeout / 3
+)
+
+type IdempotencyUseCase struct {
+	idempotencyRepo idempotency.IdempotencyRepository
+	ttl             time.Duration
+}
+
+// NewIdempotencyUseCase keeps the responses for ttl, then the key can be used again
+func NewIdempotencyUseCase(idempotencyRepository idempotency.IdempotencyRepository,
+	ttl time.Duration) idempotency.IdempotencyUseCase {
+	return &IdempotencyUseCase{idempotencyRepo: idempotencyRepository, ttl: ttl}
+}
+
+func (uc *IdempotencyUseCase) Begin(
+	record *models.IdempotencyRecord) (*models.IdempotencyRecord, *errors.Error) {
+	now := time.Now()
+	reserved, err := uc.idempotencyRepo.Reserve(record, now.Add(-uc.ttl), now.Add(-lockTimeout))
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	stored, err := uc.idempotencyRepo.Select(record.Tenant, record.Subject, record.Key)
+	if err == sql.ErrNoRows {
+		// The first request has just failed and released the key
+		return nil, errors.Get(consts.CodeIdempotencyKeyInProgress)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	if stored.RequestHash != record.RequestHash {
+		return nil, errors.Get(consts.CodeIdempotencyKeyReused)
+	}
+	if stored.StatusCode == 0 {
+		return nil, errors.Get(consts.CodeIdempotencyKeyInProgress)
+	}
+	return stored, nil
+}
+
+// KeepAlive renews the key until the request is done, however long it takes.
+// A failed renewal is retried at the next tick, the lock outlives a couple of them
+func (uc *IdempotencyUseCase) KeepAlive(ctx context.Context, record *models.IdempotencyRecord) {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := uc.idempotencyRepo.Touch(record); err != nil {
+				logrus.Error(err)
+			}
+		}
+	}
+}
+
+// Complete stores the response. A server error is not stored, the key is
+// released instead, so the retry runs the request again
+func (uc *IdempotencyUseCase) Complete(record *models.IdempotencyRecord, statusCode int,
+	response []byte) *errors.Error {
+	if statusCode >= http.StatusInternalServerError {
+		if err := uc.idempotencyRepo.Release(record); err != nil {
+			return errors.New(consts.CodeInternalError, err)
+		}
+		return nil
+	}
+
+	record.StatusCode = statusCode
+	record.Response = response
+	if err := uc.idempotencyRepo.Complete(record); err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+func (uc *IdempotencyUseCase) DeleteExpired() (int64, *errors.Error) {
+	deleted, err := uc.idempotencyRepo.DeleteExpired(time.Now().Add(-uc.ttl))
+	if err != nil {
+		return 0, errors.New(consts.CodeInternalError, err)
+	}
+	return deleted, nil
+}
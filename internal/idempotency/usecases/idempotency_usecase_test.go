@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/idempotency/mocks"
+	"github.com/booking_backend/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newRecord() *models.IdempotencyRecord {
+	return &models.IdempotencyRecord{Tenant: models.DefaultTenantID, Subject: "guest@mail.ru",
+		Key: "b7c1", RequestHash: "hash"}
+}
+
+func TestIdempotencyUseCase_Begin_New(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	idempotencyRep := mocks.NewMockIdempotencyRepository(ctrl)
+
+	record := newRecord()
+	idempotencyRep.EXPECT().Reserve(record, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ *models.IdempotencyRecord, expiredBefore time.Time,
+			staleBefore time.Time) (bool, error) {
+			assert.True(t, expiredBefore.Before(staleBefore))
+			return true, nil
+		})
+
+	useCase := NewIdempotencyUseCase(idempotencyRep, 24*time.Hour)
+	stored, err := useCase.Begin(record)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Nil(t, stored)
+}
+
+func TestIdempotencyUseCase_Begin_Replay(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	idempotencyRep := mocks.NewMockIdempotencyRepository(ctrl)
+
+	record := newRecord()
+	done := newRecord()
+	done.StatusCode = http.StatusCreated
+	done.Response = []byte(`{"booking_id":5}`)
+	idempotencyRep.EXPECT().Reserve(record, gomock.Any(), gomock.Any()).Return(false, nil)
+	idempotencyRep.EXPECT().Select(record.Tenant, record.Subject, record.Key).Return(done, nil)
+
+	useCase := NewIdempotencyUseCase(idempotencyRep, 24*time.Hour)
+	stored, err := useCase.Begin(record)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, done, stored)
+}
+
+func TestIdempotencyUseCase_Begin_Conflicts(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	idempotencyRep := mocks.NewMockIdempotencyRepository(ctrl)
+	useCase := NewIdempotencyUseCase(idempotencyRep, 24*time.Hour)
+
+	record := newRecord()
+	idempotencyRep.EXPECT().Reserve(record, gomock.Any(), gomock.Any()).Return(false, nil).Times(3)
+
+	other := newRecord()
+	other.RequestHash = "other"
+	other.StatusCode = http.StatusCreated
+	idempotencyRep.EXPECT().Select(record.Tenant, record.Subject, record.Key).Return(other, nil)
+	_, err := useCase.Begin(record)
+	assert.Equal(t, errors.Get(consts.CodeIdempotencyKeyReused), err)
+
+	inProgress := newRecord()
+	idempotencyRep.EXPECT().Select(record.Tenant, record.Subject, record.Key).Return(inProgress, nil)
+	_, err = useCase.Begin(record)
+	assert.Equal(t, errors.Get(consts.CodeIdempotencyKeyInProgress), err)
+
+	idempotencyRep.EXPECT().Select(record.Tenant, record.Subject, record.Key).Return(nil, sql.ErrNoRows)
+	_, err = useCase.Begin(record)
+	assert.Equal(t, errors.Get(consts.CodeIdempotencyKeyInProgress), err)
+}
+
+func TestIdempotencyUseCase_Complete(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	idempotencyRep := mocks.NewMockIdempotencyRepository(ctrl)
+	useCase := NewIdempotencyUseCase(idempotencyRep, 24*time.Hour)
+
+	// Client errors are as final as successes, server errors may pass on retry
+	record := newRecord()
+	idempotencyRep.EXPECT().Complete(record).Return(nil)
+	err := useCase.Complete(record, http.StatusConflict, []byte(`{"error":{}}`))
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, http.StatusConflict, record.StatusCode)
+
+	failed := newRecord()
+	idempotencyRep.EXPECT().Release(failed).Return(nil)
+	err = useCase.Complete(failed, http.StatusInternalServerError, nil)
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestIdempotencyUseCase_KeepAlive(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	idempotencyRep := mocks.NewMockIdempotencyRepository(ctrl)
+	useCase := NewIdempotencyUseCase(idempotencyRep, 24*time.Hour)
+
+	// The key is renewed more than once before it could be taken for a stale one
+	assert.True(t, 2*keepAliveInterval < lockTimeout)
+
+	// A request done before the first renewal doesn't touch the key
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	useCase.KeepAlive(ctx, newRecord())
+}
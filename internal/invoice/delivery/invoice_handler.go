@@ -0,0 +1,67 @@
+package delivery
+
+import (
+	"bytes"
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/invoice"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type InvoiceHandler struct {
+	invoiceUseCase invoice.InvoiceUseCase
+}
+
+func NewInvoiceHandler(useCase invoice.InvoiceUseCase) *InvoiceHandler {
+	return &InvoiceHandler{invoiceUseCase: useCase}
+}
+
+func (ih *InvoiceHandler) Configure(e *echo.Echo) {
+	e.GET("bookings/:id/invoice",
+		ih.GetInvoice(), principal.Require(rbac.ViewBookings))
+}
+
+// wantsHTML tells whether the invoice should be rendered as a document
+// instead of JSON, either by ?format=html or by the Accept header
+func wantsHTML(context echo.Context) bool {
+	if format := context.QueryParam("format"); format != "" {
+		return format == "html"
+	}
+	return strings.Contains(context.Request().Header.Get(echo.HeaderAccept), echo.MIMETextHTML)
+}
+
+func (ih *InvoiceHandler) GetInvoice() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		bookingID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		issued, customErr := ih.invoiceUseCase.GetInvoice(principal.Tenant(context), bookingID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		if !wantsHTML(context) {
+			return context.JSON(http.StatusOK, issued)
+		}
+
+		document := &bytes.Buffer{}
+		if err := invoiceTemplate.Execute(document, issued); err != nil {
+			customErr := errors.New(CodeInternalError, err)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		return context.HTMLBlob(http.StatusOK, document.Bytes())
+	}
+}
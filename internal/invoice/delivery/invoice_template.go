@@ -0,0 +1,32 @@
+package delivery
+
+import "html/template"
+
+var invoiceTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Счет № {{.Property}}-{{printf "%06d" .Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 4px 8px; }
+td.amount { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Счет № {{.Property}}-{{printf "%06d" .Number}}</h1>
+<p>Дата выставления: {{.Issued.Format "02.01.2006"}}</p>
+<p>Бронь {{.Booking}}, номер {{.Room}}, с {{.DateStart}} по {{.DateEnd}}</p>
+<table>
+<tr><th>Наименование</th><th>Количество</th><th>Цена</th><th>Сумма</th></tr>
+{{range .Lines}}<tr><td>{{.Description}}</td><td class="amount">{{.Quantity}}</td><td class="amount">{{.UnitPrice}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}{{if .Discount}}<tr><th colspan="3">Скидка по промокоду {{.PromoCode}}</th><td class="amount">-{{.Discount}}</td></tr>
+{{end}}<tr><th colspan="3">Итого</th><td class="amount">{{.Total}}</td></tr>
+{{if and (eq .TaxMode "inclusive") .VAT}}<tr><th colspan="3">В том числе НДС {{.VATPercent}}%</th><td class="amount">{{.VAT}}</td></tr>
+{{end}}<tr><th colspan="3">Оплачено</th><td class="amount">{{.Paid}}</td></tr>
+<tr><th colspan="3">Возвращено</th><td class="amount">{{.Refunded}}</td></tr>
+</table>
+</body>
+</html>
+`))
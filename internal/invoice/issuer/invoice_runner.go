@@ -0,0 +1,26 @@
+package issuer
+
+import (
+	"context"
+	"errors"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/invoice"
+	"github.com/booking_backend/internal/job"
+	"github.com/booking_backend/internal/models"
+)
+
+// NewInvoiceRunner issues the invoices in the scheduler jobs. The booking
+// deleted or left with nothing to invoice since the job was planned is skipped
+func NewInvoiceRunner(useCase invoice.InvoiceUseCase) job.Runner {
+	return func(_ context.Context, j *models.Job) error {
+		_, customErr := useCase.IssueInvoice(j.Tenant, j.Booking)
+		if customErr == nil {
+			return nil
+		}
+		switch customErr.Code {
+		case consts.CodeBookingDoesNotExist, consts.CodeInvoiceUnavailable:
+			return nil
+		}
+		return errors.New(customErr.Message)
+	}
+}
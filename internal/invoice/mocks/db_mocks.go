@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"encoding/json"
+	"github.com/booking_backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func MockInsertSuccess(mock sqlmock.Sqlmock, invoice *models.Invoice) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT property FROM rooms`).
+		WithArgs(invoice.Room).
+		WillReturnRows(sqlmock.NewRows([]string{"property"}).AddRow(invoice.Property))
+	mock.ExpectQuery(`UPDATE properties`).
+		WithArgs(invoice.Property).
+		WillReturnRows(sqlmock.NewRows([]string{"invoice_number"}).AddRow(invoice.Number))
+	mock.ExpectQuery(`INSERT INTO invoices`).
+		WithArgs(invoice.Booking, invoice.Property, invoice.Number,
+			invoice.Issued, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(invoice.ID))
+	mock.ExpectCommit()
+}
+
+func MockInsertAlreadyIssued(mock sqlmock.Sqlmock, invoice *models.Invoice) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT property FROM rooms`).
+		WithArgs(invoice.Room).
+		WillReturnRows(sqlmock.NewRows([]string{"property"}).AddRow(invoice.Property))
+	mock.ExpectQuery(`UPDATE properties`).
+		WithArgs(invoice.Property).
+		WillReturnRows(sqlmock.NewRows([]string{"invoice_number"}).AddRow(invoice.Number))
+	mock.ExpectQuery(`INSERT INTO invoices`).
+		WithArgs(invoice.Booking, invoice.Property, invoice.Number,
+			invoice.Issued, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+}
+
+func MockSelectByBooking(mock sqlmock.Sqlmock, invoice *models.Invoice) error {
+	document, err := json.Marshal(invoice)
+	if err != nil {
+		return err
+	}
+	mock.ExpectQuery(`SELECT id, document FROM invoices`).
+		WithArgs(invoice.Booking).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document"}).
+			AddRow(invoice.ID, document))
+	return nil
+}
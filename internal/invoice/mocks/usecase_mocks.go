@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_invoice is a generated GoMock package.
+package mocks
+
+import (
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockInvoiceUseCase is a mock of InvoiceUseCase interface
+type MockInvoiceUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvoiceUseCaseMockRecorder
+}
+
+// MockInvoiceUseCaseMockRecorder is the mock recorder for MockInvoiceUseCase
+type MockInvoiceUseCaseMockRecorder struct {
+	mock *MockInvoiceUseCase
+}
+
+// NewMockInvoiceUseCase creates a new mock instance
+func NewMockInvoiceUseCase(ctrl *gomock.Controller) *MockInvoiceUseCase {
+	mock := &MockInvoiceUseCase{ctrl: ctrl}
+	mock.recorder = &MockInvoiceUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockInvoiceUseCase) EXPECT() *MockInvoiceUseCaseMockRecorder {
+	return m.recorder
+}
+
+// GetInvoice mocks base method
+func (m *MockInvoiceUseCase) GetInvoice(tenant, bookingID uint64) (*models.Invoice, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvoice", tenant, bookingID)
+	ret0, _ := ret[0].(*models.Invoice)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetInvoice indicates an expected call of GetInvoice
+func (mr *MockInvoiceUseCaseMockRecorder) GetInvoice(tenant, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvoice", reflect.TypeOf((*MockInvoiceUseCase)(nil).GetInvoice), tenant, bookingID)
+}
+
+// IssueInvoice mocks base method
+func (m *MockInvoiceUseCase) IssueInvoice(tenant, bookingID uint64) (*models.Invoice, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueInvoice", tenant, bookingID)
+	ret0, _ := ret[0].(*models.Invoice)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// IssueInvoice indicates an expected call of IssueInvoice
+func (mr *MockInvoiceUseCaseMockRecorder) IssueInvoice(tenant, bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueInvoice", reflect.TypeOf((*MockInvoiceUseCase)(nil).IssueInvoice), tenant, bookingID)
+}
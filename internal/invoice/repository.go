@@ -0,0 +1,13 @@
+package invoice
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/models"
+)
+
+var ErrAlreadyIssued = errors.New("invoice is already issued")
+
+type InvoiceRepository interface {
+	Insert(invoice *models.Invoice) error
+	SelectByBooking(bookingID uint64) (*models.Invoice, error)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/booking_backend/internal/invoice"
+	"github.com/booking_backend/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+type InvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) invoice.InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+func rollback(tx *sql.Tx) {
+	if rollbackErr := tx.Rollback(); rollbackErr != nil {
+		logrus.Info(rollbackErr)
+	}
+}
+
+// Insert takes the next number of the room property and stores the invoice.
+// The property row stays locked until commit, so numbers have no gaps even
+// when the insert is rolled back
+func (rep *InvoiceRepository) Insert(issued *models.Invoice) error {
+	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		SELECT property
+		FROM rooms
+		WHERE id=$1`, issued.Room).
+		Scan(&issued.Property)
+	if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	err = tx.QueryRow(`
+		UPDATE properties
+		SET invoice_number=invoice_number+1
+		WHERE id=$1 RETURNING invoice_number`, issued.Property).
+		Scan(&issued.Number)
+	if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	document, err := json.Marshal(issued)
+	if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO invoices(booking, property, number, issued, document)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (booking) DO NOTHING RETURNING id`,
+		issued.Booking, issued.Property, issued.Number, issued.Issued, document).
+		Scan(&issued.ID)
+	if err == sql.ErrNoRows {
+		rollback(tx)
+		return invoice.ErrAlreadyIssued
+	} else if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (rep *InvoiceRepository) SelectByBooking(bookingID uint64) (*models.Invoice, error) {
+	var id uint64
+	var document []byte
+	err := rep.db.QueryRow(`
+		SELECT id, document
+		FROM invoices
+		WHERE booking=$1`, bookingID).
+		Scan(&id, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := &models.Invoice{}
+	if err := json.Unmarshal(document, issued); err != nil {
+		return nil, err
+	}
+	issued.ID = id
+	return issued, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/invoice"
+	"github.com/booking_backend/internal/invoice/mocks"
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newInvoiceModel() *models.Invoice {
+	return &models.Invoice{
+		ID:        1,
+		Property:  1,
+		Number:    42,
+		Booking:   3,
+		Room:      2,
+		DateStart: "2022-01-02",
+		DateEnd:   "2022-01-05",
+		Lines: []*models.InvoiceLine{
+			&models.InvoiceLine{
+				Description: "Проживание, номер 2",
+				Quantity:    3,
+				UnitPrice:   500,
+				Amount:      1500,
+			},
+		},
+		Total:  1500,
+		Paid:   1500,
+		Issued: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestInvoiceRepository_Insert(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	invoicePgRep := NewInvoiceRepository(db)
+	invoiceModel := newInvoiceModel()
+	mocks.MockInsertSuccess(mock, invoiceModel)
+
+	issued := newInvoiceModel()
+	issued.ID, issued.Property, issued.Number = 0, 0, 0
+	err = invoicePgRep.Insert(issued)
+	assert.NoError(t, err)
+	assert.Equal(t, invoiceModel, issued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvoiceRepository_Insert_AlreadyIssued(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	invoicePgRep := NewInvoiceRepository(db)
+	invoiceModel := newInvoiceModel()
+	mocks.MockInsertAlreadyIssued(mock, invoiceModel)
+
+	err = invoicePgRep.Insert(invoiceModel)
+	assert.Equal(t, invoice.ErrAlreadyIssued, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvoiceRepository_SelectByBooking(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	invoicePgRep := NewInvoiceRepository(db)
+	invoiceModel := newInvoiceModel()
+	if err := mocks.MockSelectByBooking(mock, invoiceModel); err != nil {
+		t.Fatal(err)
+	}
+
+	issued, err := invoicePgRep.SelectByBooking(invoiceModel.Booking)
+	assert.NoError(t, err)
+	assert.Equal(t, invoiceModel, issued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
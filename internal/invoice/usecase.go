@@ -0,0 +1,11 @@
+package invoice
+
+import (
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type InvoiceUseCase interface {
+	GetInvoice(tenant uint64, bookingID uint64) (*models.Invoice, *errors.Error)
+	IssueInvoice(tenant uint64, bookingID uint64) (*models.Invoice, *errors.Error)
+}
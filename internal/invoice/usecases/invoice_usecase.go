@@ -0,0 +1,213 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/dates"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/invoice"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/payment"
+	"time"
+)
+
+type InvoiceUseCase struct {
+	invoiceRepo    invoice.InvoiceRepository
+	bookingRepo    booking.BookingRepository
+	paymentUseCase payment.PaymentUseCase
+}
+
+func NewInvoiceUseCase(invoiceRepository invoice.InvoiceRepository,
+	bookingRepository booking.BookingRepository,
+	paymentUseCase payment.PaymentUseCase) invoice.InvoiceUseCase {
+	return &InvoiceUseCase{invoiceRepo: invoiceRepository,
+		bookingRepo: bookingRepository, paymentUseCase: paymentUseCase}
+}
+
+func (uc *InvoiceUseCase) getBooking(tenant uint64, bookingID uint64) (*models.Booking, *errors.Error) {
+	stay, err := uc.bookingRepo.SelectByID(context.Background(), tenant, bookingID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeBookingDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return stay, nil
+}
+
+// GetInvoice returns the invoice issued for the booking. The booking is looked
+// up first, as it tells whether the invoice belongs to the tenant
+func (uc *InvoiceUseCase) GetInvoice(tenant uint64, bookingID uint64) (*models.Invoice, *errors.Error) {
+	if _, customErr := uc.getBooking(tenant, bookingID); customErr != nil {
+		return nil, customErr
+	}
+
+	issued, err := uc.invoiceRepo.SelectByBooking(bookingID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeInvoiceNotIssued)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return issued, nil
+}
+
+// IssueInvoice is run by the scheduler once the guest has checked out or the
+// booking is cancelled with a fee, so the invoice has the final payments.
+// Once issued the invoice is never changed, issuing it again returns it
+func (uc *InvoiceUseCase) IssueInvoice(tenant uint64, bookingID uint64) (*models.Invoice, *errors.Error) {
+	stay, customErr := uc.getBooking(tenant, bookingID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	issued, err := uc.invoiceRepo.SelectByBooking(bookingID)
+	if err == nil {
+		return issued, nil
+	} else if err != sql.ErrNoRows {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	issued, customErr = uc.compose(stay)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	err = uc.invoiceRepo.Insert(issued)
+	if err == invoice.ErrAlreadyIssued {
+		// Issued by another scheduler, the stored one is the only valid invoice
+		issued, err = uc.invoiceRepo.SelectByBooking(bookingID)
+	}
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return issued, nil
+}
+
+func (uc *InvoiceUseCase) compose(stay *models.Booking) (*models.Invoice, *errors.Error) {
+	lines, err := invoiceLines(stay)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	if len(lines) == 0 {
+		return nil, errors.Get(consts.CodeInvoiceUnavailable)
+	}
+
+	dateStart, err := dates.Parse(stay.DateStart)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	dateEnd, err := dates.Parse(stay.DateEnd)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	issued := &models.Invoice{
+		Booking:   stay.ID,
+		Room:      stay.Room,
+		DateStart: dateStart.Format(dates.Layout),
+		DateEnd:   dateEnd.Format(dates.Layout),
+		Lines:     lines,
+		Issued:    time.Now(),
+	}
+	for _, line := range lines {
+		issued.Total += line.Amount
+	}
+	if stay.Status == models.BookingStatusConfirmed && stay.Quote != nil {
+		issued.PromoCode = stay.Quote.PromoCode
+		issued.Discount = stay.Quote.Discount
+		issued.Total -= stay.Quote.Discount
+		issued.VATPercent = stay.Quote.VATPercent
+		issued.TaxMode = stay.Quote.TaxMode
+		issued.VAT = stay.Quote.VAT
+	}
+
+	payments, customErr := uc.paymentUseCase.GetBookingPayments(stay.ID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	for _, p := range payments {
+		if p.Status != models.PaymentStatusSucceeded {
+			continue
+		}
+		switch p.Type {
+		case models.PaymentTypeCapture:
+			issued.Paid += p.Amount
+		case models.PaymentTypeRefund:
+			issued.Refunded += p.Amount
+		}
+	}
+	return issued, nil
+}
+
+// invoiceLines charges the stay of a confirmed booking or the fee of a cancelled one.
+// Holds and free cancellations have nothing to invoice
+func invoiceLines(stay *models.Booking) ([]*models.InvoiceLine, error) {
+	switch stay.Status {
+	case models.BookingStatusConfirmed:
+		if stay.Quote != nil {
+			return quoteLines(stay.Room, stay.Quote), nil
+		}
+		nights, err := dates.Nights(stay.DateStart, stay.DateEnd)
+		if err != nil {
+			return nil, err
+		}
+		line := &models.InvoiceLine{
+			Description: fmt.Sprintf("Проживание, номер %d", stay.Room),
+			Quantity:    nights,
+			Amount:      stay.Amount,
+		}
+		if nights != 0 {
+			line.UnitPrice = stay.Amount / nights
+		}
+		return []*models.InvoiceLine{line}, nil
+	case models.BookingStatusCancelled:
+		if stay.CancellationFee == 0 {
+			return nil, nil
+		}
+		return []*models.InvoiceLine{{
+			Description: "Штраф за отмену брони",
+			Quantity:    1,
+			UnitPrice:   stay.CancellationFee,
+			Amount:      stay.CancellationFee,
+		}}, nil
+	}
+	return nil, nil
+}
+
+// quoteLines itemizes the price breakdown the booking was charged with
+func quoteLines(room uint64, quote *models.Quote) []*models.InvoiceLine {
+	lines := []*models.InvoiceLine{{
+		Description: fmt.Sprintf("Проживание, номер %d", room),
+		Quantity:    quote.Nights,
+		UnitPrice:   quote.NightPrice,
+		Amount:      quote.Accommodation,
+	}}
+	if quote.CleaningFee != 0 {
+		lines = append(lines, &models.InvoiceLine{
+			Description: "Уборка",
+			Quantity:    1,
+			UnitPrice:   quote.CleaningFee,
+			Amount:      quote.CleaningFee,
+		})
+	}
+	if quote.TouristTax != 0 {
+		personNights := quote.Guests * quote.Nights
+		lines = append(lines, &models.InvoiceLine{
+			Description: "Туристический налог",
+			Quantity:    personNights,
+			UnitPrice:   quote.TouristTax / personNights,
+			Amount:      quote.TouristTax,
+		})
+	}
+	if quote.TaxMode == models.TaxModeExclusive && quote.VAT != 0 {
+		lines = append(lines, &models.InvoiceLine{
+			Description: fmt.Sprintf("НДС %d%%", quote.VATPercent),
+			Quantity:    1,
+			UnitPrice:   quote.VAT,
+			Amount:      quote.VAT,
+		})
+	}
+	return lines
+}
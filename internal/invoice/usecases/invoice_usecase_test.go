@@ -0,0 +1,314 @@
+package usecases
+
+import (
+	"database/sql"
+	mockBooking "github.com/booking_backend/internal/booking/mocks"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/invoice"
+	"github.com/booking_backend/internal/invoice/mocks"
+	"github.com/booking_backend/internal/models"
+	mockPayment "github.com/booking_backend/internal/payment/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newBookingModel() *models.Booking {
+	return &models.Booking{
+		ID:        3,
+		DateStart: "2022-01-02T00:00:00Z",
+		DateEnd:   "2022-01-05T00:00:00Z",
+		Room:      2,
+		Status:    models.BookingStatusConfirmed,
+		Amount:    1500,
+	}
+}
+
+var bookingPayments = []*models.Payment{
+	&models.Payment{Booking: 3, Type: models.PaymentTypeAuthorization,
+		Amount: 1500, Status: models.PaymentStatusSucceeded},
+	&models.Payment{Booking: 3, Type: models.PaymentTypeCapture,
+		Amount: 1500, Status: models.PaymentStatusFailed},
+	&models.Payment{Booking: 3, Type: models.PaymentTypeCapture,
+		Amount: 1500, Status: models.PaymentStatusSucceeded},
+}
+
+func TestInvoiceUseCase_GetInvoice_AlreadyIssued(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+	stored := &models.Invoice{ID: 1, Number: 7, Booking: 3, Total: 1000}
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(newBookingModel(), nil)
+	invoiceRep.
+		EXPECT().
+		SelectByBooking(uint64(3)).
+		Return(stored, nil)
+
+	issued, err := invoiceUseCase.GetInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, stored, issued)
+}
+
+func TestInvoiceUseCase_GetInvoice_NotIssued(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+
+	// Reading the invoice never issues it, the guest hasn't checked out yet
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(newBookingModel(), nil)
+	invoiceRep.
+		EXPECT().
+		SelectByBooking(uint64(3)).
+		Return(nil, sql.ErrNoRows)
+
+	_, err := invoiceUseCase.GetInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, errors.Get(consts.CodeInvoiceNotIssued), err)
+}
+
+func TestInvoiceUseCase_IssueInvoice_IssueConfirmed(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+
+	invoiceRep.
+		EXPECT().
+		SelectByBooking(uint64(3)).
+		Return(nil, sql.ErrNoRows)
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(newBookingModel(), nil)
+	paymentUseCase.
+		EXPECT().
+		GetBookingPayments(uint64(3)).
+		Return(bookingPayments, nil)
+	invoiceRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	issued, err := invoiceUseCase.IssueInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, "2022-01-02", issued.DateStart)
+	assert.Equal(t, "2022-01-05", issued.DateEnd)
+	assert.Equal(t, []*models.InvoiceLine{
+		&models.InvoiceLine{
+			Description: "Проживание, номер 2",
+			Quantity:    3,
+			UnitPrice:   500,
+			Amount:      1500,
+		},
+	}, issued.Lines)
+	assert.Equal(t, uint64(1500), issued.Total)
+	assert.Equal(t, uint64(1500), issued.Paid)
+}
+
+func TestInvoiceUseCase_IssueInvoice_TaxBreakdown(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+	quoted := newBookingModel()
+	quoted.Guests = 2
+	quoted.Amount = 2220
+	quoted.Quote = &models.Quote{
+		Nights:        3,
+		Guests:        2,
+		NightPrice:    500,
+		Accommodation: 1500,
+		CleaningFee:   300,
+		TouristTax:    60,
+		VATPercent:    20,
+		TaxMode:       models.TaxModeExclusive,
+		VAT:           360,
+		Total:         2220,
+	}
+
+	invoiceRep.
+		EXPECT().
+		SelectByBooking(uint64(3)).
+		Return(nil, sql.ErrNoRows)
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(quoted, nil)
+	paymentUseCase.
+		EXPECT().
+		GetBookingPayments(uint64(3)).
+		Return(nil, nil)
+	invoiceRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	issued, err := invoiceUseCase.IssueInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, []*models.InvoiceLine{
+		&models.InvoiceLine{Description: "Проживание, номер 2", Quantity: 3, UnitPrice: 500, Amount: 1500},
+		&models.InvoiceLine{Description: "Уборка", Quantity: 1, UnitPrice: 300, Amount: 300},
+		&models.InvoiceLine{Description: "Туристический налог", Quantity: 6, UnitPrice: 10, Amount: 60},
+		&models.InvoiceLine{Description: "НДС 20%", Quantity: 1, UnitPrice: 360, Amount: 360},
+	}, issued.Lines)
+	assert.Equal(t, uint64(2220), issued.Total)
+	assert.Equal(t, uint64(360), issued.VAT)
+}
+
+func TestInvoiceUseCase_IssueInvoice_CancellationFee(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+	cancelled := newBookingModel()
+	cancelled.Status = models.BookingStatusCancelled
+	cancelled.CancellationFee = 500
+	payments := append(bookingPayments, &models.Payment{Booking: 3,
+		Type: models.PaymentTypeRefund, Amount: 1000, Status: models.PaymentStatusSucceeded})
+
+	invoiceRep.
+		EXPECT().
+		SelectByBooking(uint64(3)).
+		Return(nil, sql.ErrNoRows)
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(cancelled, nil)
+	paymentUseCase.
+		EXPECT().
+		GetBookingPayments(uint64(3)).
+		Return(payments, nil)
+	invoiceRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	issued, err := invoiceUseCase.IssueInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, uint64(500), issued.Total)
+	assert.Equal(t, uint64(1500), issued.Paid)
+	assert.Equal(t, uint64(1000), issued.Refunded)
+}
+
+func TestInvoiceUseCase_IssueInvoice_HoldIsNotInvoiced(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+	held := newBookingModel()
+	held.Status = models.BookingStatusHeld
+
+	invoiceRep.
+		EXPECT().
+		SelectByBooking(uint64(3)).
+		Return(nil, sql.ErrNoRows)
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(held, nil)
+
+	_, err := invoiceUseCase.IssueInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, errors.Get(consts.CodeInvoiceUnavailable), err)
+}
+
+func TestInvoiceUseCase_IssueInvoice_IssuedConcurrently(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+	stored := &models.Invoice{ID: 1, Number: 7, Booking: 3, Total: 1500}
+
+	gomock.InOrder(
+		invoiceRep.
+			EXPECT().
+			SelectByBooking(uint64(3)).
+			Return(nil, sql.ErrNoRows),
+		invoiceRep.
+			EXPECT().
+			SelectByBooking(uint64(3)).
+			Return(stored, nil),
+	)
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(newBookingModel(), nil)
+	paymentUseCase.
+		EXPECT().
+		GetBookingPayments(uint64(3)).
+		Return(bookingPayments, nil)
+	invoiceRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(invoice.ErrAlreadyIssued)
+
+	issued, err := invoiceUseCase.IssueInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, stored, issued)
+}
+
+func TestInvoiceUseCase_GetInvoice_BookingDoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(models.DefaultTenantID), uint64(3)).
+		Return(nil, sql.ErrNoRows)
+
+	_, err := invoiceUseCase.GetInvoice(models.DefaultTenantID, 3)
+	assert.Equal(t, errors.Get(consts.CodeBookingDoesNotExist), err)
+}
+
+func TestInvoiceUseCase_GetInvoice_OtherTenant(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoiceRep := mocks.NewMockInvoiceRepository(ctrl)
+	bookingRep := mockBooking.NewMockBookingRepository(ctrl)
+	paymentUseCase := mockPayment.NewMockPaymentUseCase(ctrl)
+	invoiceUseCase := NewInvoiceUseCase(invoiceRep, bookingRep, paymentUseCase)
+
+	// The invoice of a booking of another tenant is never looked up
+	bookingRep.
+		EXPECT().
+		SelectByID(gomock.Any(), uint64(2), uint64(3)).
+		Return(nil, sql.ErrNoRows)
+
+	_, err := invoiceUseCase.GetInvoice(2, 3)
+	assert.Equal(t, errors.Get(consts.CodeBookingDoesNotExist), err)
+}
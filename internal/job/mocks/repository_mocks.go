@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mock_job is a generated GoMock package.
+package mocks
+
+import (
+	job "github.com/booking_backend/internal/job"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockJobRepository is a mock of JobRepository interface
+type MockJobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobRepositoryMockRecorder
+}
+
+// MockJobRepositoryMockRecorder is the mock recorder for MockJobRepository
+type MockJobRepositoryMockRecorder struct {
+	mock *MockJobRepository
+}
+
+// NewMockJobRepository creates a new mock instance
+func NewMockJobRepository(ctrl *gomock.Controller) *MockJobRepository {
+	mock := &MockJobRepository{ctrl: ctrl}
+	mock.recorder = &MockJobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockJobRepository) EXPECT() *MockJobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// PlanReminders mocks base method
+func (m *MockJobRepository) PlanReminders(plan job.Plan) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlanReminders", plan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlanReminders indicates an expected call of PlanReminders
+func (mr *MockJobRepositoryMockRecorder) PlanReminders(plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlanReminders", reflect.TypeOf((*MockJobRepository)(nil).PlanReminders), plan)
+}
+
+// PlanArrivals mocks base method
+func (m *MockJobRepository) PlanArrivals(plan job.Plan) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlanArrivals", plan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlanArrivals indicates an expected call of PlanArrivals
+func (mr *MockJobRepositoryMockRecorder) PlanArrivals(plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlanArrivals", reflect.TypeOf((*MockJobRepository)(nil).PlanArrivals), plan)
+}
+
+// PlanRefunds mocks base method
+func (m *MockJobRepository) PlanRefunds() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlanRefunds")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlanRefunds indicates an expected call of PlanRefunds
+func (mr *MockJobRepositoryMockRecorder) PlanRefunds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlanRefunds", reflect.TypeOf((*MockJobRepository)(nil).PlanRefunds))
+}
+
+// PlanInvoices mocks base method
+func (m *MockJobRepository) PlanInvoices() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlanInvoices")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlanInvoices indicates an expected call of PlanInvoices
+func (mr *MockJobRepositoryMockRecorder) PlanInvoices() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlanInvoices", reflect.TypeOf((*MockJobRepository)(nil).PlanInvoices))
+}
+
+// RunNext mocks base method
+func (m *MockJobRepository) RunNext(retry job.RetryPolicy, runners map[string]job.Runner) (*models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunNext", retry, runners)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunNext indicates an expected call of RunNext
+func (mr *MockJobRepositoryMockRecorder) RunNext(retry, runners interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunNext", reflect.TypeOf((*MockJobRepository)(nil).RunNext), retry, runners)
+}
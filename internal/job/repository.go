@@ -0,0 +1,37 @@
+package job
+
+import (
+	"context"
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+// RetryPolicy describes how many times a failed job is run. The pause
+// between runs starts with Backoff and doubles after every run
+type RetryPolicy struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+// Plan tells when the jobs run. The reminders run LeadDays before the
+// check-in at ReminderTime, the arrivals run on the day at ArrivalsTime.
+// Times are offsets from midnight in the time zone of the property
+type Plan struct {
+	LeadDays     int
+	ReminderTime time.Duration
+	ArrivalsTime time.Duration
+}
+
+// Runner runs the jobs of its type which call other services, like the
+// refunds. It runs outside the transaction of the job, the job stays locked
+// meanwhile. A job is run again if the transaction fails, so a runner must
+// do nothing the second time
+type Runner func(ctx context.Context, j *models.Job) error
+
+type JobRepository interface {
+	PlanReminders(plan Plan) (int64, error)
+	PlanArrivals(plan Plan) (int64, error)
+	PlanRefunds() (int64, error)
+	PlanInvoices() (int64, error)
+	RunNext(retry RetryPolicy, runners map[string]Runner) (*models.Job, error)
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	bookingRepository "github.com/booking_backend/internal/booking/repository"
+	"github.com/booking_backend/internal/job"
+	"github.com/booking_backend/internal/models"
+	outboxRepository "github.com/booking_backend/internal/outbox/repository"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) job.JobRepository {
+	return &JobRepository{db: db}
+}
+
+func rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		logrus.Error(err)
+	}
+}
+
+// PlanReminders plans a reminder for every confirmed booking starting within
+// LeadDays. The bookings made later than that are reminded right away, and a
+// rescheduled booking gets a new reminder for its new date. The days and the
+// reminder time are those at the property of the room
+func (rep *JobRepository) PlanReminders(plan job.Plan) (int64, error) {
+	res, err := rep.db.Exec(`
+		INSERT INTO jobs(type, tenant, booking, date, run_at)
+		SELECT $1, b.tenant, b.id, b.date_start,
+			GREATEST(now(), ((b.date_start - $3::int) + make_interval(secs => $4)) AT TIME ZONE p.time_zone)
+		FROM bookings b
+		JOIN rooms r ON r.id=b.room
+		JOIN properties p ON p.id=r.property
+		WHERE b.status=$2 AND b.deleted_at IS NULL
+			AND b.date_start > (now() AT TIME ZONE p.time_zone)::date
+			AND b.date_start <= (now() AT TIME ZONE p.time_zone)::date + $3::int
+		ON CONFLICT (type, tenant, booking, date) DO NOTHING`,
+		models.JobBookingReminder, models.BookingStatusConfirmed,
+		plan.LeadDays, plan.ReminderTime.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PlanArrivals plans today's arrivals for every tenant somebody arrives to.
+// Today is the date at the property, the arrivals of the tenant's properties
+// on the same date are published together at the earliest ArrivalsTime
+func (rep *JobRepository) PlanArrivals(plan job.Plan) (int64, error) {
+	res, err := rep.db.Exec(`
+		INSERT INTO jobs(type, tenant, date, run_at)
+		SELECT $1, b.tenant, b.date_start,
+			min((b.date_start + make_interval(secs => $3)) AT TIME ZONE p.time_zone)
+		FROM bookings b
+		JOIN rooms r ON r.id=b.room
+		JOIN properties p ON p.id=r.property
+		WHERE b.status=$2 AND b.deleted_at IS NULL
+			AND b.date_start = (now() AT TIME ZONE p.time_zone)::date
+		GROUP BY b.tenant, b.date_start
+		ON CONFLICT (type, tenant, booking, date) DO NOTHING`,
+		models.JobDailyArrivals, models.BookingStatusConfirmed, plan.ArrivalsTime.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PlanRefunds plans a retry for every cancelled booking whose refund has
+// failed. The retry is planned once for the day of the cancellation, the
+// refund that fails all its attempts is left to be retried by hand
+func (rep *JobRepository) PlanRefunds() (int64, error) {
+	res, err := rep.db.Exec(`
+		INSERT INTO jobs(type, tenant, booking, date, run_at)
+		SELECT $1, tenant, id, cancelled::date, now()
+		FROM bookings
+		WHERE status=$2 AND refund_status=$3 AND deleted_at IS NULL
+		ON CONFLICT (type, tenant, booking, date) DO NOTHING`,
+		models.JobRefund, models.BookingStatusCancelled, models.RefundStatusFailed)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PlanInvoices plans the invoice of every confirmed booking the guest has
+// checked out of by the date at the property, and of every booking cancelled
+// with a fee. The bookings already invoiced are skipped
+func (rep *JobRepository) PlanInvoices() (int64, error) {
+	res, err := rep.db.Exec(`
+		INSERT INTO jobs(type, tenant, booking, date, run_at)
+		SELECT $1, b.tenant, b.id, CASE WHEN b.status=$2 THEN b.date_end ELSE b.cancelled::date END, now()
+		FROM bookings b
+		JOIN rooms r ON r.id=b.room
+		JOIN properties p ON p.id=r.property
+		WHERE b.deleted_at IS NULL
+			AND ((b.status=$2 AND b.date_end <= (now() AT TIME ZONE p.time_zone)::date)
+				OR (b.status=$3 AND b.cancellation_fee > 0))
+			AND NOT EXISTS (SELECT 1 FROM invoices i WHERE i.booking=b.id)
+		ON CONFLICT (type, tenant, booking, date) DO NOTHING`,
+		models.JobInvoice, models.BookingStatusConfirmed, models.BookingStatusCancelled)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// run publishes the event of the job, the job is done without an event when
+// there is nothing to tell anymore, e.g. the booking is cancelled. The jobs
+// of the runners' types are given to the runners
+func run(ctx context.Context, tx *sql.Tx, j *models.Job, runners map[string]job.Runner) error {
+	if runner, ok := runners[j.Type]; ok {
+		return runner(ctx, j)
+	}
+
+	bookings, err := bookingRepository.SelectArrivals(ctx, tx,
+		j.Tenant, j.Date, j.Booking)
+	if err != nil {
+		return err
+	}
+	if len(bookings) == 0 {
+		return nil
+	}
+
+	var event *models.Event
+	switch j.Type {
+	case models.JobBookingReminder:
+		event = models.NewEvent(j.Tenant, models.EventBookingReminder, bookings[0])
+	case models.JobDailyArrivals:
+		event = models.NewEvent(j.Tenant, models.EventDailyArrivals,
+			&models.Arrivals{Date: j.Date, Bookings: bookings})
+	default:
+		return fmt.Errorf("unknown job type %q", j.Type)
+	}
+	return outboxRepository.InsertEvent(ctx, tx, event)
+}
+
+// RunNext claims the earliest due job and runs it within the same transaction.
+// The job stays locked until it is finished, other schedulers skip it, so it
+// runs once however many instances there are. Nil means no job is due
+func (rep *JobRepository) RunNext(retry job.RetryPolicy,
+	runners map[string]job.Runner) (*models.Job, error) {
+	ctx := context.Background()
+	tx, err := rep.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	j := &models.Job{}
+	err = tx.QueryRow(`
+		SELECT id, type, tenant, booking, to_char(date, 'YYYY-MM-DD'), run_at, status, attempts,
+			last_error, created
+		FROM jobs
+		WHERE status=$1 AND run_at <= now()
+		ORDER BY run_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`, models.JobStatusPending).
+		Scan(&j.ID, &j.Type, &j.Tenant, &j.Booking, &j.Date, &j.RunAt, &j.Status,
+			&j.Attempts, &j.LastError, &j.Created)
+	if err == sql.ErrNoRows {
+		rollback(tx)
+		return nil, nil
+	} else if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+
+	// The savepoint undoes a failed run but keeps the lock to record the failure
+	if _, err := tx.Exec(`SAVEPOINT job`); err != nil {
+		rollback(tx)
+		return nil, err
+	}
+	runErr := run(ctx, tx, j, runners)
+	if runErr != nil {
+		if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT job`); err != nil {
+			rollback(tx)
+			return nil, err
+		}
+	}
+
+	finish(j, runErr, retry, time.Now())
+	_, err = tx.Exec(`
+		UPDATE jobs
+		SET status=$1, attempts=$2, run_at=$3, last_error=$4, finished=$5
+		WHERE id=$6`,
+		j.Status, j.Attempts, j.RunAt, j.LastError, j.Finished, j.ID)
+	if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// finish records the run. A failed job is run again after the backoff until
+// it runs out of attempts
+func finish(j *models.Job, runErr error, retry job.RetryPolicy, now time.Time) {
+	j.Attempts++
+	if runErr == nil {
+		j.Status = models.JobStatusDone
+		j.LastError = ""
+		j.Finished = &now
+		return
+	}
+
+	j.LastError = runErr.Error()
+	if j.Attempts >= retry.Attempts {
+		j.Status = models.JobStatusFailed
+		j.Finished = &now
+		return
+	}
+	j.RunAt = now.Add(retry.Backoff << uint(j.Attempts-1))
+}
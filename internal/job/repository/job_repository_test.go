@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	bookingMocks "github.com/booking_backend/internal/booking/mocks"
+	"github.com/booking_backend/internal/job"
+	"github.com/booking_backend/internal/models"
+	outboxMocks "github.com/booking_backend/internal/outbox/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var jobColumns = []string{"id", "type", "tenant", "booking", "date", "run_at", "status",
+	"attempts", "last_error", "created"}
+
+var retry = job.RetryPolicy{Attempts: 3, Backoff: time.Minute}
+
+func mockClaim(mock sqlmock.Sqlmock, j *models.Job) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM jobs (.+) FOR UPDATE SKIP LOCKED`).
+		WithArgs(models.JobStatusPending).
+		WillReturnRows(sqlmock.NewRows(jobColumns).AddRow(j.ID, j.Type, j.Tenant, j.Booking,
+			j.Date, j.RunAt, j.Status, j.Attempts, j.LastError, j.Created))
+	mock.ExpectExec(`SAVEPOINT job`).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func pendingJob(jobType string, booking uint64, attempts int) *models.Job {
+	return &models.Job{ID: 4, Type: jobType, Tenant: models.DefaultTenantID, Booking: booking,
+		Date: "2022-01-10", RunAt: time.Now(), Status: models.JobStatusPending, Attempts: attempts}
+}
+
+func TestJobRepository_RunNext_Reminder(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	j := pendingJob(models.JobBookingReminder, 5, 0)
+	booking := &models.Booking{ID: 5, DateStart: "2022-01-10", DateEnd: "2022-01-12",
+		Status: models.BookingStatusConfirmed, Tenant: j.Tenant}
+	mockClaim(mock, j)
+	bookingMocks.MockSelectArrivals(mock, j.Tenant, j.Date, j.Booking, []*models.Booking{booking})
+	outboxMocks.MockInsertEvent(mock, models.NewEvent(j.Tenant, models.EventBookingReminder, booking))
+	mock.ExpectExec(`UPDATE jobs`).
+		WithArgs(models.JobStatusDone, 1, sqlmock.AnyArg(), "", sqlmock.AnyArg(), j.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rep := NewJobRepository(db)
+	done, err := rep.RunNext(retry, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobStatusDone, done.Status)
+	assert.NotNil(t, done.Finished)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_RunNext_Cancelled(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The booking is cancelled since, so the job is done without a reminder
+	j := pendingJob(models.JobBookingReminder, 5, 0)
+	mockClaim(mock, j)
+	bookingMocks.MockSelectArrivals(mock, j.Tenant, j.Date, j.Booking, nil)
+	mock.ExpectExec(`UPDATE jobs`).
+		WithArgs(models.JobStatusDone, 1, sqlmock.AnyArg(), "", sqlmock.AnyArg(), j.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rep := NewJobRepository(db)
+	done, err := rep.RunNext(retry, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobStatusDone, done.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_RunNext_Failure(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	j := pendingJob(models.JobDailyArrivals, 0, 1)
+	mockClaim(mock, j)
+	mock.ExpectQuery(`SELECT (.+) FROM bookings`).
+		WillReturnError(errors.New("canceling statement due to statement timeout"))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT job`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE jobs`).
+		WithArgs(models.JobStatusPending, 2, sqlmock.AnyArg(),
+			"canceling statement due to statement timeout", nil, j.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	before := time.Now()
+	rep := NewJobRepository(db)
+	failed, err := rep.RunNext(retry, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobStatusPending, failed.Status)
+	// The second attempt waits twice the backoff
+	assert.False(t, failed.RunAt.Before(before.Add(2*retry.Backoff)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_RunNext_Runner(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The refund is made by its runner, no event is published for it
+	j := pendingJob(models.JobRefund, 5, 0)
+	mockClaim(mock, j)
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT job`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE jobs`).
+		WithArgs(models.JobStatusPending, 1, sqlmock.AnyArg(), "payment gateway is unavailable",
+			nil, j.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var ran *models.Job
+	runners := map[string]job.Runner{
+		models.JobRefund: func(_ context.Context, j *models.Job) error {
+			ran = j
+			return errors.New("payment gateway is unavailable")
+		},
+	}
+	rep := NewJobRepository(db)
+	failed, err := rep.RunNext(retry, runners)
+	assert.NoError(t, err)
+	assert.Equal(t, j.ID, ran.ID)
+	assert.Equal(t, models.JobStatusPending, failed.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_PlanRefunds(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO jobs`).
+		WithArgs(models.JobRefund, models.BookingStatusCancelled, models.RefundStatusFailed).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	rep := NewJobRepository(db)
+	planned, err := rep.PlanRefunds()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), planned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_PlanInvoices(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO jobs(.+)AT TIME ZONE p.time_zone`).
+		WithArgs(models.JobInvoice, models.BookingStatusConfirmed, models.BookingStatusCancelled).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rep := NewJobRepository(db)
+	planned, err := rep.PlanInvoices()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), planned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_PlanReminders(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO jobs(.+)AT TIME ZONE p.time_zone(.+)JOIN properties p`).
+		WithArgs(models.JobBookingReminder, models.BookingStatusConfirmed, 2, float64(36000)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	rep := NewJobRepository(db)
+	planned, err := rep.PlanReminders(job.Plan{LeadDays: 2, ReminderTime: 10 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), planned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_PlanArrivals(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO jobs(.+)AT TIME ZONE p.time_zone(.+)GROUP BY b.tenant, b.date_start`).
+		WithArgs(models.JobDailyArrivals, models.BookingStatusConfirmed, float64(25200)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rep := NewJobRepository(db)
+	planned, err := rep.PlanArrivals(job.Plan{ArrivalsTime: 7 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), planned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_RunNext_NoJob(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM jobs`).
+		WithArgs(models.JobStatusPending).
+		WillReturnRows(sqlmock.NewRows(jobColumns))
+	mock.ExpectRollback()
+
+	rep := NewJobRepository(db)
+	j, err := rep.RunNext(retry, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, j)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFinish_OutOfAttempts(t *testing.T) {
+	t.Parallel()
+	j := pendingJob(models.JobDailyArrivals, 0, retry.Attempts-1)
+	now := time.Now()
+
+	finish(j, errors.New("database is down"), retry, now)
+	assert.Equal(t, models.JobStatusFailed, j.Status)
+	assert.Equal(t, retry.Attempts, j.Attempts)
+	assert.Equal(t, &now, j.Finished)
+}
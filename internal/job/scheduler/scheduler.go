@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/booking_backend/internal/job"
+	"github.com/booking_backend/internal/models"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// Scheduler periodically plans the jobs and runs the due ones
+type Scheduler struct {
+	jobRepo   job.JobRepository
+	plan      job.Plan
+	retry     job.RetryPolicy
+	runners   map[string]job.Runner
+	interval  time.Duration
+	batchSize int
+}
+
+// NewScheduler takes the runners by the job types they run, the other jobs
+// publish their events
+func NewScheduler(jobRepository job.JobRepository, plan job.Plan, retry job.RetryPolicy,
+	runners map[string]job.Runner, interval time.Duration, batchSize int) *Scheduler {
+	return &Scheduler{jobRepo: jobRepository, plan: plan, retry: retry, runners: runners,
+		interval: interval, batchSize: batchSize}
+}
+
+// Run blocks until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Plan()
+			s.RunDue()
+		}
+	}
+}
+
+// Plan adds the jobs that are not planned yet. Planning is repeated on every
+// run, so the jobs of the bookings made meanwhile are not missed
+func (s *Scheduler) Plan() {
+	if planned, err := s.jobRepo.PlanReminders(s.plan); err != nil {
+		logrus.Error(err)
+	} else if planned > 0 {
+		logrus.Infof("%d reminders are planned", planned)
+	}
+
+	if planned, err := s.jobRepo.PlanArrivals(s.plan); err != nil {
+		logrus.Error(err)
+	} else if planned > 0 {
+		logrus.Infof("%d arrivals are planned", planned)
+	}
+
+	if planned, err := s.jobRepo.PlanRefunds(); err != nil {
+		logrus.Error(err)
+	} else if planned > 0 {
+		logrus.Infof("%d refunds are planned", planned)
+	}
+
+	if planned, err := s.jobRepo.PlanInvoices(); err != nil {
+		logrus.Error(err)
+	} else if planned > 0 {
+		logrus.Infof("%d invoices are planned", planned)
+	}
+}
+
+// RunDue runs at most a batch of due jobs and returns how many were done
+func (s *Scheduler) RunDue() int {
+	done := 0
+	for i := 0; i < s.batchSize; i++ {
+		j, err := s.jobRepo.RunNext(s.retry, s.runners)
+		if err != nil {
+			logrus.Error(err)
+			return done
+		}
+		if j == nil {
+			return done
+		}
+
+		if j.Status == models.JobStatusDone {
+			done++
+		} else {
+			logrus.Errorf("job %d %s failed %d times: %s", j.ID, j.Type, j.Attempts, j.LastError)
+		}
+	}
+	return done
+}
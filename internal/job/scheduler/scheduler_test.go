@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/job"
+	"github.com/booking_backend/internal/job/mocks"
+	"github.com/booking_backend/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	plan  = job.Plan{LeadDays: 2, ReminderTime: 10 * time.Hour, ArrivalsTime: 7 * time.Hour}
+	retry = job.RetryPolicy{Attempts: 3, Backoff: time.Minute}
+)
+
+func TestScheduler_Plan(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	jobRep := mocks.NewMockJobRepository(ctrl)
+
+	// A failed planning doesn't stop the other one
+	jobRep.EXPECT().PlanReminders(plan).Return(int64(0), errors.New("database is down"))
+	jobRep.EXPECT().PlanArrivals(plan).Return(int64(1), nil)
+	jobRep.EXPECT().PlanRefunds().Return(int64(0), nil)
+	jobRep.EXPECT().PlanInvoices().Return(int64(2), nil)
+
+	s := NewScheduler(jobRep, plan, retry, nil, time.Minute, 10)
+	s.Plan()
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	jobRep := mocks.NewMockJobRepository(ctrl)
+
+	gomock.InOrder(
+		jobRep.EXPECT().RunNext(retry, nil).Return(&models.Job{ID: 1, Status: models.JobStatusDone}, nil),
+		jobRep.EXPECT().RunNext(retry, nil).Return(&models.Job{ID: 2, Status: models.JobStatusPending,
+			Attempts: 1, LastError: "database is down"}, nil),
+		jobRep.EXPECT().RunNext(retry, nil).Return(&models.Job{ID: 3, Status: models.JobStatusDone}, nil),
+		jobRep.EXPECT().RunNext(retry, nil).Return(nil, nil),
+	)
+
+	s := NewScheduler(jobRep, plan, retry, nil, time.Minute, 10)
+	assert.Equal(t, 2, s.RunDue())
+}
+
+func TestScheduler_RunDue_Batch(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	jobRep := mocks.NewMockJobRepository(ctrl)
+
+	// The rest of the due jobs wait for the next run
+	jobRep.EXPECT().RunNext(retry, nil).Return(&models.Job{Status: models.JobStatusDone}, nil).Times(2)
+
+	s := NewScheduler(jobRep, plan, retry, nil, time.Minute, 2)
+	assert.Equal(t, 2, s.RunDue())
+}
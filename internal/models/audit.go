@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	// Rooms are deleted softly, restored and then purged for good
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+const (
+	AuditEntityRoom               = "room"
+	AuditEntityBooking            = "booking"
+	AuditEntityCancellationPolicy = "cancellation_policy"
+)
+
+// Actor is whoever makes the change, RequestID ties it to the request log
+type Actor struct {
+	Subject   string
+	RequestID string
+}
+
+// SystemActor makes the changes nobody asked for, e.g. releases expired holds
+var SystemActor = &Actor{Subject: "system"}
+
+// AuditEntry is an append-only record of a change. Before and After are
+// stored as JSON when the change is written, Before is null for creates and
+// After is null for deletes
+type AuditEntry struct {
+	ID        uint64      `json:"audit_id"`
+	Actor     string      `json:"actor"`
+	Action    string      `json:"action"`
+	Entity    string      `json:"entity"`
+	EntityID  uint64      `json:"entity_id"`
+	Before    interface{} `json:"before"`
+	After     interface{} `json:"after"`
+	RequestID string      `json:"request_id,omitempty"`
+	Created   time.Time   `json:"created"`
+	Tenant    uint64      `json:"-"`
+}
+
+// Entry starts the audit entry of the change made by the actor
+func (actor *Actor) Entry(tenant uint64, action string, entity string,
+	entityID uint64, before interface{}, after interface{}) *AuditEntry {
+	return &AuditEntry{
+		Actor:     actor.Subject,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		Before:    before,
+		After:     after,
+		RequestID: actor.RequestID,
+		Tenant:    tenant,
+	}
+}
+
+// AuditFilter selects the entries of the tenant, zero fields match everything
+type AuditFilter struct {
+	Tenant   uint64
+	Entity   string
+	EntityID uint64
+	From     *time.Time
+	To       *time.Time
+}
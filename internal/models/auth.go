@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	RoleAdmin     = "admin"
+	RoleManager   = "manager"
+	RoleFrontDesk = "front_desk"
+	RoleGuest     = "guest"
+)
+
+// APIKey is stored as a hash, the key itself is shown only once on creation.
+// Prefix is the beginning of the key telling the keys apart
+type APIKey struct {
+	ID       uint64     `json:"key_id"`
+	Name     string     `json:"name"`
+	Prefix   string     `json:"prefix"`
+	Role     string     `json:"role"`
+	Created  time.Time  `json:"created"`
+	LastUsed *time.Time `json:"last_used,omitempty"`
+	Revoked  *time.Time `json:"revoked,omitempty"`
+	Tenant   uint64     `json:"-"`
+}
+
+// Principal is the authenticated caller. KeyID is zero for bearer tokens
+type Principal struct {
+	Subject string `json:"subject"`
+	Role    string `json:"role"`
+	Tenant  uint64 `json:"tenant"`
+	KeyID   uint64 `json:"key_id,omitempty"`
+}
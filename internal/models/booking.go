@@ -15,9 +15,42 @@ func (ct *CustomDate) UnmarshalParam(param string) error {
 	return nil
 }
 
+const (
+	BookingStatusConfirmed = "confirmed"
+	BookingStatusHeld      = "held"
+	BookingStatusCancelled = "cancelled"
+)
+
 type Booking struct {
 	ID        uint64 `json:"booking_id"`
 	DateStart string `json:"date_start"`
 	DateEnd   string `json:"date_end"`
 	Room      uint64 `json:"room"`
+	// Reservation is zero for bookings made outside of a group reservation
+	Reservation uint64 `json:"reservation,omitempty"`
+	Status      string `json:"status"`
+	Guests      uint64 `json:"guests"`
+	// Guest is the subject of the guest the booking was made for
+	Guest string `json:"guest,omitempty"`
+	// Email and Language are where and how the guest is notified, no email
+	// means no notifications
+	Email    string `json:"email,omitempty"`
+	Language string `json:"language,omitempty"`
+	Amount   uint64 `json:"amount"`
+	// Quote is the breakdown of Amount by the tax rules in force at booking time
+	Quote       *Quote     `json:"quote,omitempty"`
+	PromoCode   string     `json:"promo_code,omitempty"`
+	HoldExpires *time.Time `json:"hold_expires,omitempty"`
+	// CancellationFee is charged according to the room cancellation policy
+	CancellationFee uint64     `json:"cancellation_fee,omitempty"`
+	Cancelled       *time.Time `json:"cancelled,omitempty"`
+	// RefundAmount is what is due back to the guest after the cancellation fee
+	RefundStatus string `json:"refund_status,omitempty"`
+	RefundAmount uint64 `json:"refund_amount,omitempty"`
+	// Version grows with every change and is returned as the ETag
+	Version uint64 `json:"version"`
+	// Updated is the time of the last change, the calendar feed publishes it
+	Updated time.Time `json:"-"`
+	// Tenant is always the tenant of the room
+	Tenant uint64 `json:"-"`
 }
@@ -0,0 +1,15 @@
+package models
+
+const (
+	PenaltyTypePercent    = "percent"
+	PenaltyTypeFirstNight = "first_night"
+)
+
+// CancellationPolicy lets guests cancel for free until FreeDays days before
+// arrival, later cancellations are charged according to the penalty type
+type CancellationPolicy struct {
+	Room           uint64 `json:"room"`
+	FreeDays       uint64 `json:"free_days"`
+	PenaltyType    string `json:"penalty_type"`
+	PenaltyPercent uint64 `json:"penalty_percent"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	EventRoomCreated        = "RoomCreated"
+	EventRoomDeleted        = "RoomDeleted"
+	EventBookingCreated     = "BookingCreated"
+	EventBookingConfirmed   = "BookingConfirmed"
+	EventBookingCancelled   = "BookingCancelled"
+	EventBookingRescheduled = "BookingRescheduled"
+	// The scheduled events remind the guest of the coming stay and tell the
+	// front desk who arrives today
+	EventBookingReminder = "BookingReminder"
+	EventDailyArrivals   = "DailyArrivals"
+)
+
+// Event is a domain event stored in the outbox within the transaction of the
+// change. Payload is stored as JSON when the change is written and read back
+// as raw JSON
+type Event struct {
+	ID      uint64      `json:"event_id"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload"`
+	Created time.Time   `json:"created"`
+	Tenant  uint64      `json:"-"`
+}
+
+func NewEvent(tenant uint64, eventType string, payload interface{}) *Event {
+	return &Event{Type: eventType, Payload: payload, Tenant: tenant}
+}
+
+// Arrivals is the payload of EventDailyArrivals. It is meant for the front
+// desk, which gets it only by subscribing a webhook to the event
+type Arrivals struct {
+	Date     string     `json:"date"`
+	Bookings []*Booking `json:"bookings"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers the response to the request made with the key
+// by the caller. StatusCode is zero while the request is in progress
+type IdempotencyRecord struct {
+	Tenant      uint64
+	Subject     string
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Response    []byte
+	Created     time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+// ImportReport tells which rows of a CSV file were imported. In the
+// all-or-nothing mode a single error rejects the whole file
+type ImportReport struct {
+	Rows     uint64         `json:"rows"`
+	Imported uint64         `json:"imported"`
+	IDs      []uint64       `json:"ids"`
+	Errors   []*ImportError `json:"errors"`
+}
+
+// ImportError points to the data row counting from 1 after the header
+type ImportError struct {
+	Row     uint64 `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type InvoiceLine struct {
+	Description string `json:"description"`
+	Quantity    uint64 `json:"quantity"`
+	UnitPrice   uint64 `json:"unit_price"`
+	Amount      uint64 `json:"amount"`
+}
+
+// Invoice is a snapshot of the stay taken when the invoice is issued.
+// It is never recalculated afterwards
+type Invoice struct {
+	ID        uint64         `json:"invoice_id"`
+	Property  uint64         `json:"property"`
+	Number    uint64         `json:"number"`
+	Booking   uint64         `json:"booking"`
+	Room      uint64         `json:"room"`
+	DateStart string         `json:"date_start"`
+	DateEnd   string         `json:"date_end"`
+	Lines     []*InvoiceLine `json:"lines"`
+	// Discount of the promo code is subtracted from the sum of the lines
+	PromoCode string `json:"promo_code,omitempty"`
+	Discount  uint64 `json:"discount,omitempty"`
+	Total     uint64 `json:"total"`
+	// VAT is either one of the lines or, in the inclusive mode, part of their amounts
+	VATPercent uint64    `json:"vat_percent,omitempty"`
+	TaxMode    string    `json:"tax_mode,omitempty"`
+	VAT        uint64    `json:"vat,omitempty"`
+	Paid       uint64    `json:"paid"`
+	Refunded   uint64    `json:"refunded"`
+	Issued     time.Time `json:"issued"`
+}
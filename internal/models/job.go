@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	JobBookingReminder = "booking_reminder"
+	JobDailyArrivals   = "daily_arrivals"
+	JobRefund          = "refund"
+	JobInvoice         = "invoice"
+)
+
+const (
+	JobStatusPending = "pending"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+// Job is a persisted task run by the scheduler at RunAt. A job is planned
+// once for its type, tenant, booking and date, Booking is zero for the jobs
+// about the whole tenant
+type Job struct {
+	ID        uint64
+	Type      string
+	Tenant    uint64
+	Booking   uint64
+	Date      string
+	RunAt     time.Time
+	Status    string
+	Attempts  int
+	LastError string
+	Created   time.Time
+	Finished  *time.Time
+}
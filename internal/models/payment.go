@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	PaymentTypeAuthorization = "authorization"
+	PaymentTypeCapture       = "capture"
+	PaymentTypeRefund        = "refund"
+	PaymentTypeVoid          = "void"
+)
+
+const (
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+)
+
+const (
+	RefundStatusNone     = ""
+	RefundStatusPartial  = "partial"
+	RefundStatusRefunded = "refunded"
+	RefundStatusFailed   = "failed"
+)
+
+// Payment is a single transaction with the payment gateway
+type Payment struct {
+	ID        uint64    `json:"payment_id"`
+	Booking   uint64    `json:"booking"`
+	Type      string    `json:"type"`
+	Amount    uint64    `json:"amount"`
+	Reference string    `json:"reference"`
+	Status    string    `json:"status"`
+	Created   time.Time `json:"created"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	DiscountTypePercent = "percent"
+	DiscountTypeFixed   = "fixed"
+)
+
+// PromoCode gives a discount on the accommodation. Zero UsageLimit, Room and
+// Property mean that the code is not restricted by them
+type PromoCode struct {
+	Code          string    `json:"code"`
+	DiscountType  string    `json:"discount_type"`
+	DiscountValue uint64    `json:"discount_value"`
+	ValidFrom     string    `json:"valid_from"`
+	ValidTo       string    `json:"valid_to"`
+	UsageLimit    uint64    `json:"usage_limit"`
+	Used          uint64    `json:"used"`
+	Room          uint64    `json:"room,omitempty"`
+	Property      uint64    `json:"property,omitempty"`
+	MinNights     uint64    `json:"min_nights"`
+	Created       time.Time `json:"created"`
+	Tenant        uint64    `json:"-"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// DefaultPropertyID is the property every room belongs to unless stated otherwise
+const DefaultPropertyID = 1
+
+const (
+	TaxModeExclusive = "exclusive"
+	TaxModeInclusive = "inclusive"
+)
+
+type Property struct {
+	ID       uint64 `json:"property_id"`
+	Name     string `json:"name"`
+	TimeZone string `json:"time_zone"`
+	Tenant   uint64 `json:"-"`
+}
+
+// Location is the time zone of the property, the time zone is checked when
+// the property is created
+func (p *Property) Location() (*time.Location, error) {
+	return time.LoadLocation(p.TimeZone)
+}
+
+// TaxRules are the taxes and fees charged by a property. In the inclusive mode
+// room prices and the cleaning fee already contain VAT, in the exclusive mode
+// VAT is added on top. Tourist tax is charged per guest per night and is not
+// subject to VAT
+type TaxRules struct {
+	Property    uint64 `json:"property"`
+	VATPercent  uint64 `json:"vat_percent"`
+	TaxMode     string `json:"tax_mode"`
+	TouristTax  uint64 `json:"tourist_tax"`
+	CleaningFee uint64 `json:"cleaning_fee"`
+}
+
+// Quote is the price breakdown of a stay
+type Quote struct {
+	Nights        uint64 `json:"nights"`
+	Guests        uint64 `json:"guests"`
+	NightPrice    uint64 `json:"night_price"`
+	Accommodation uint64 `json:"accommodation"`
+	PromoCode     string `json:"promo_code,omitempty"`
+	Discount      uint64 `json:"discount,omitempty"`
+	CleaningFee   uint64 `json:"cleaning_fee"`
+	TouristTax    uint64 `json:"tourist_tax"`
+	VATPercent    uint64 `json:"vat_percent"`
+	TaxMode       string `json:"tax_mode"`
+	VAT           uint64 `json:"vat"`
+	Total         uint64 `json:"total"`
+}
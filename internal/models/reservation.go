@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Reservation.Version changes with the reservation and with every of its lines
+type Reservation struct {
+	ID       uint64     `json:"reservation_id"`
+	Created  time.Time  `json:"created"`
+	Version  uint64     `json:"version"`
+	Bookings []*Booking `json:"bookings"`
+}
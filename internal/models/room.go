@@ -2,9 +2,16 @@ package models
 
 import "time"
 
+// AnyVersion is matched by every version of a room or a booking
+const AnyVersion uint64 = 0
+
 type Room struct {
 	ID          uint64    `json:"room_id"`
 	Description string    `json:"description"`
 	Price       uint64    `json:"price"`
 	Created     time.Time `json:"created"`
+	Property    uint64    `json:"property"`
+	// Version grows with every change and is returned as the ETag
+	Version uint64 `json:"version"`
+	Tenant  uint64 `json:"-"`
 }
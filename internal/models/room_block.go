@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// RoomBlock closes the room from DateStart to DateEnd without a booking.
+// Blocks imported from a calendar feed keep the feed in Source and the
+// event UID in SourceUID
+type RoomBlock struct {
+	ID        uint64    `json:"block_id"`
+	Room      uint64    `json:"room"`
+	DateStart string    `json:"date_start"`
+	DateEnd   string    `json:"date_end"`
+	Reason    string    `json:"reason,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	SourceUID string    `json:"source_uid,omitempty"`
+	Created   time.Time `json:"created"`
+}
+
+// CalendarImport counts how the blocks of the feed changed on import.
+// Conflicts are the confirmed bookings the imported events overlap
+type CalendarImport struct {
+	Source    string              `json:"source"`
+	Created   uint64              `json:"created"`
+	Updated   uint64              `json:"updated"`
+	Removed   uint64              `json:"removed"`
+	Conflicts []*CalendarConflict `json:"conflicts,omitempty"`
+}
+
+// CalendarConflict is the room sold twice: on another channel as the event
+// SourceUID and here as the booking, one of them has to be moved
+type CalendarConflict struct {
+	BookingID uint64 `json:"booking_id"`
+	SourceUID string `json:"source_uid"`
+	DateStart string `json:"date_start"`
+	DateEnd   string `json:"date_end"`
+}
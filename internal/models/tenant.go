@@ -0,0 +1,10 @@
+package models
+
+// DefaultTenantID is the tenant of the callers whose token names none,
+// so a single hotel chain deployment keeps working without tenants
+const DefaultTenantID uint64 = 1
+
+type Tenant struct {
+	ID   uint64 `json:"tenant_id"`
+	Name string `json:"name"`
+}
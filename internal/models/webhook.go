@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	// DeliveryStatusDead is the dead letter state of the deliveries which
+	// failed every attempt
+	DeliveryStatusDead = "dead"
+)
+
+// Webhook subscribes the URL to the events of the tenant. Secret signs the
+// payloads and is shown only on creation
+type Webhook struct {
+	ID         uint64    `json:"webhook_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"-"`
+	Created    time.Time `json:"created"`
+	Tenant     uint64    `json:"-"`
+}
+
+// WebhookDelivery is the event on its way to the webhook. Body is the event
+// as it is sent, URL and Secret are the ones of the webhook
+type WebhookDelivery struct {
+	ID           uint64     `json:"delivery_id"`
+	Webhook      uint64     `json:"webhook_id"`
+	Event        uint64     `json:"event_id"`
+	EventType    string     `json:"event_type"`
+	Status       string     `json:"status"`
+	Attempts     int        `json:"attempts"`
+	NextAttempt  *time.Time `json:"next_attempt,omitempty"`
+	ResponseCode int        `json:"response_code,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	Created      time.Time  `json:"created"`
+	Delivered    *time.Time `json:"delivered,omitempty"`
+	Body         []byte     `json:"-"`
+	URL          string     `json:"-"`
+	Secret       string     `json:"-"`
+}
@@ -0,0 +1,9 @@
+package notification
+
+import "github.com/booking_backend/internal/models"
+
+// Mailer is implemented by the ways to send an email: the SMTP client and
+// the stand-in for local runs that keeps the emails in files
+type Mailer interface {
+	Send(email *models.Email) error
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"fmt"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/notification"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer stands in for the SMTP server in local runs: every email is
+// logged and, if the directory is set, saved there as an .eml file
+type FileMailer struct {
+	dir  string
+	from string
+	sent uint64
+}
+
+func NewFileMailer(dir string, from string) notification.Mailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (fm *FileMailer) Send(email *models.Email) error {
+	logger := logrus.WithField("to", email.To)
+	if fm.dir == "" {
+		logger.Info(email.Subject)
+		return nil
+	}
+
+	now := time.Now()
+	message, err := compose(fm.from, email, now)
+	if err != nil {
+		return err
+	}
+	// The counter keeps the names unique within the same nanosecond
+	name := fmt.Sprintf("%d-%d.eml", now.UnixNano(), atomic.AddUint64(&fm.sent, 1))
+	path := filepath.Join(fm.dir, name)
+	if err := ioutil.WriteFile(path, message, 0644); err != nil {
+		return err
+	}
+
+	logger.WithField("file", path).Info(email.Subject)
+	return nil
+}
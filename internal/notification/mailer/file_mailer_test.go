@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mails")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	email := &models.Email{To: "guest@example.com", Subject: "Бронь № 5 создана",
+		Body: "Заезд: 2022-01-10\n"}
+	mailer := NewFileMailer(dir, "booking@example.com")
+	assert.NoError(t, mailer.Send(email))
+	assert.NoError(t, mailer.Send(email))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	file, err := os.Open(files[0])
+	assert.NoError(t, err)
+	defer file.Close()
+	message, err := mail.ReadMessage(file)
+	assert.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, email.Subject, subject)
+	assert.Equal(t, email.To, message.Header.Get("To"))
+	body, err := ioutil.ReadAll(quotedprintable.NewReader(message.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, email.Body, strings.ReplaceAll(string(body), "\r\n", "\n"))
+}
+
+func TestFileMailer_Send_LogOnly(t *testing.T) {
+	t.Parallel()
+	mailer := NewFileMailer("", "booking@example.com")
+	assert.NoError(t, mailer.Send(&models.Email{To: "guest@example.com", Subject: "Test"}))
+}
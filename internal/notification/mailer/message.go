@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/booking_backend/internal/models"
+	"mime"
+	"mime/quotedprintable"
+	"time"
+)
+
+// compose renders the email as an RFC 5322 message with a UTF-8 text body
+func compose(from string, email *models.Email, date time.Time) ([]byte, error) {
+	message := &bytes.Buffer{}
+	fmt.Fprintf(message, "From: %s\r\n", from)
+	fmt.Fprintf(message, "To: %s\r\n", email.To)
+	fmt.Fprintf(message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(message)
+	if _, err := body.Write([]byte(email.Body)); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
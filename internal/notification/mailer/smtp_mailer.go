@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/notification"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer sends the emails through the SMTP server, STARTTLS is used when
+// the server offers it
+type SMTPMailer struct {
+	host    string
+	addr    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+}
+
+// NewSMTPMailer authenticates only when the username is set. The whole
+// session with the server, the connection included, is bounded by the timeout
+func NewSMTPMailer(host string, port int, username string, password string,
+	from string, timeout time.Duration) notification.Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{host: host, addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth, from: from, timeout: timeout}
+}
+
+// Send does what smtp.SendMail does, but a server that stops responding
+// fails the email instead of blocking the sink forever. The errors of the
+// server are returned as they are, so the rejected emails are told apart
+func (sm *SMTPMailer) Send(email *models.Email) error {
+	message, err := compose(sm.from, email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	conn, err := (&net.Dialer{Timeout: sm.timeout}).Dial("tcp", sm.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(sm.timeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, sm.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: sm.host}); err != nil {
+			return err
+		}
+	}
+	if sm.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(sm.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(sm.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(email.To); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"bufio"
+	"github.com/booking_backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve answers the SMTP session with the replies in order, one for the
+// greeting and one for every command
+func serve(t *testing.T, replies ...string) (string, int, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := make(chan string, 16)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer close(commands)
+
+		// The reply after DATA answers the message, not a command
+		reader := bufio.NewReader(conn)
+		data := false
+		for i, reply := range replies {
+			if data {
+				for line := ""; line != ".\r\n"; {
+					if line, err = reader.ReadString('\n'); err != nil {
+						return
+					}
+				}
+				data = false
+			} else if i > 0 {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				commands <- strings.TrimSpace(line)
+				data = line == "DATA\r\n"
+			}
+			conn.Write([]byte(reply + "\r\n"))
+		}
+		// The server stops responding until the client hangs up
+		io.Copy(ioutil.Discard, reader)
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return host, portNumber, commands
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	t.Parallel()
+	host, port, commands := serve(t,
+		"220 localhost ESMTP",
+		"250 localhost",
+		"250 OK",
+		"250 OK",
+		"354 Go ahead",
+		"250 OK",
+		"221 Bye")
+
+	mailer := NewSMTPMailer(host, port, "", "", "booking@example.com", time.Second)
+	assert.NoError(t, mailer.Send(&models.Email{To: "guest@example.com", Subject: "Бронь № 5 создана"}))
+
+	var got []string
+	for command := range commands {
+		got = append(got, command)
+	}
+	assert.Equal(t, []string{"EHLO localhost", "MAIL FROM:<booking@example.com>",
+		"RCPT TO:<guest@example.com>", "DATA", "QUIT"}, got)
+}
+
+func TestSMTPMailer_Send_ServerStopsResponding(t *testing.T) {
+	t.Parallel()
+	host, port, _ := serve(t, "220 localhost ESMTP", "250 localhost")
+
+	mailer := NewSMTPMailer(host, port, "", "", "booking@example.com", 200*time.Millisecond)
+	started := time.Now()
+	err := mailer.Send(&models.Email{To: "guest@example.com", Subject: "Бронь № 5 создана"})
+
+	netErr, ok := err.(net.Error)
+	assert.True(t, ok && netErr.Timeout(), "%v", err)
+	assert.Less(t, int64(time.Since(started)), int64(time.Second))
+}
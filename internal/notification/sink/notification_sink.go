@@ -0,0 +1,93 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/notification"
+	"github.com/booking_backend/internal/outbox"
+	"github.com/sirupsen/logrus"
+	"net/textproto"
+)
+
+// NotificationSink emails the guests about their bookings. It runs in the
+// outbox dispatcher, so the emails never slow the requests down
+type NotificationSink struct {
+	mailer notification.Mailer
+}
+
+func NewNotificationSink(mailer notification.Mailer) outbox.Sink {
+	return &NotificationSink{mailer: mailer}
+}
+
+// Render makes the email about the booking event, nil for the events guests
+// are not notified about
+func Render(eventType string, booking *models.Booking) (*models.Email, error) {
+	language, ok := templates[booking.Language]
+	if !ok {
+		language = templates[DefaultLanguage]
+	}
+	tmpl, ok := language[eventType]
+	if !ok {
+		return nil, nil
+	}
+
+	subject, body := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := tmpl.subject.Execute(subject, booking); err != nil {
+		return nil, err
+	}
+	if err := tmpl.body.Execute(body, booking); err != nil {
+		return nil, err
+	}
+	return &models.Email{To: booking.Email, Subject: subject.String(), Body: body.String()}, nil
+}
+
+// decodeBooking reads the payload whether it is a booking or its JSON from the outbox
+func decodeBooking(payload interface{}) (*models.Booking, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	booking := &models.Booking{}
+	if err := json.Unmarshal(data, booking); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// isPermanent tells the rejections that a retry won't help, like an unknown mailbox
+func isPermanent(err error) bool {
+	protoErr, ok := err.(*textproto.Error)
+	return ok && protoErr.Code >= 500
+}
+
+// Deliver returns an error only when sending can succeed later, otherwise
+// the email is dropped so that it doesn't hold the other events back
+func (ns *NotificationSink) Deliver(event *models.Event) error {
+	if _, ok := templates[DefaultLanguage][event.Type]; !ok {
+		return nil
+	}
+
+	logger := logrus.WithFields(logrus.Fields{"event_id": event.ID, "type": event.Type})
+	booking, err := decodeBooking(event.Payload)
+	if err != nil {
+		logger.Error(err)
+		return nil
+	}
+	if booking.Email == "" {
+		return nil
+	}
+
+	email, err := Render(event.Type, booking)
+	if err != nil {
+		logger.Error(err)
+		return nil
+	}
+
+	err = ns.mailer.Send(email)
+	if err != nil && isPermanent(err) {
+		logger.Warn(err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/notification/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBooking() *models.Booking {
+	return &models.Booking{
+		ID:        5,
+		DateStart: "2022-01-10",
+		DateEnd:   "2022-01-12",
+		Room:      3,
+		Status:    models.BookingStatusConfirmed,
+		Guests:    2,
+		Email:     "guest@example.com",
+		Amount:    4000,
+		Tenant:    models.DefaultTenantID,
+	}
+}
+
+// outboxEvent is the event as the dispatcher reads it from the outbox
+func outboxEvent(t *testing.T, eventType string, booking *models.Booking) *models.Event {
+	payload, err := json.Marshal(booking)
+	assert.NoError(t, err)
+	return &models.Event{ID: 1, Type: eventType, Payload: json.RawMessage(payload),
+		Tenant: booking.Tenant}
+}
+
+func TestRender_Languages(t *testing.T) {
+	t.Parallel()
+	booking := testBooking()
+
+	email, err := Render(models.EventBookingCreated, booking)
+	assert.NoError(t, err)
+	assert.Equal(t, "guest@example.com", email.To)
+	assert.Equal(t, "Бронь № 5 создана", email.Subject)
+	assert.Contains(t, email.Body, "Заезд: 2022-01-10")
+
+	booking.Language = "en"
+	email, err = Render(models.EventBookingRescheduled, booking)
+	assert.NoError(t, err)
+	assert.Equal(t, "Booking #5 dates are changed", email.Subject)
+	assert.Contains(t, email.Body, "Check-out: 2022-01-12")
+
+	// Unknown languages fall back to the default one
+	booking.Language = "de"
+	email, err = Render(models.EventBookingCancelled, booking)
+	assert.NoError(t, err)
+	assert.Equal(t, "Бронь № 5 отменена", email.Subject)
+}
+
+func TestRender_Reminder(t *testing.T) {
+	t.Parallel()
+	booking := testBooking()
+	// The bookings read from the database have the dates with time
+	booking.DateStart = "2022-01-10T00:00:00Z"
+	booking.DateEnd = "2022-01-12T00:00:00Z"
+
+	email, err := Render(models.EventBookingReminder, booking)
+	assert.NoError(t, err)
+	assert.Equal(t, "Напоминание о брони № 5", email.Subject)
+	assert.Contains(t, email.Body, "Заезд: 2022-01-10\n")
+	assert.Contains(t, email.Body, "Выезд: 2022-01-12\n")
+}
+
+func TestRender_Hold(t *testing.T) {
+	t.Parallel()
+	booking := testBooking()
+	expires := time.Date(2022, 1, 3, 10, 15, 0, 0, time.UTC)
+	booking.Status = models.BookingStatusHeld
+	booking.HoldExpires = &expires
+	booking.Language = "en"
+
+	email, err := Render(models.EventBookingCreated, booking)
+	assert.NoError(t, err)
+	assert.Contains(t, email.Body, "held until 2022-01-03 10:15")
+}
+
+func TestRender_Confirmed(t *testing.T) {
+	t.Parallel()
+	booking := testBooking()
+	// The confirmed booking is read from the database, with the dates with time
+	booking.DateStart = "2022-01-10T00:00:00Z"
+	booking.DateEnd = "2022-01-12T00:00:00Z"
+
+	email, err := Render(models.EventBookingConfirmed, booking)
+	assert.NoError(t, err)
+	assert.Equal(t, "Бронь № 5 подтверждена", email.Subject)
+	assert.Contains(t, email.Body, "Заезд: 2022-01-10\n")
+	assert.Contains(t, email.Body, "Выезд: 2022-01-12\n")
+	assert.NotContains(t, email.Body, "удерживается")
+
+	email, err = Render(models.EventBookingCancelled, booking)
+	assert.NoError(t, err)
+	assert.Contains(t, email.Body, "с 2022-01-10 по 2022-01-12 отменена")
+}
+
+func TestRender_CancellationFee(t *testing.T) {
+	t.Parallel()
+	booking := testBooking()
+	booking.Status = models.BookingStatusCancelled
+	booking.CancellationFee = 1000
+	booking.RefundAmount = 3000
+
+	email, err := Render(models.EventBookingCancelled, booking)
+	assert.NoError(t, err)
+	assert.Contains(t, email.Body, "Штраф за отмену: 1000")
+	assert.Contains(t, email.Body, "К возврату: 3000")
+}
+
+func TestNotificationSink_Deliver(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mailer := mocks.NewMockMailer(ctrl)
+
+	booking := testBooking()
+	mailer.EXPECT().Send(gomock.Any()).DoAndReturn(func(email *models.Email) error {
+		assert.Equal(t, booking.Email, email.To)
+		assert.True(t, strings.HasPrefix(email.Subject, "Бронь № 5"))
+		return nil
+	})
+
+	sink := NewNotificationSink(mailer)
+	assert.NoError(t, sink.Deliver(outboxEvent(t, models.EventBookingCreated, booking)))
+}
+
+func TestNotificationSink_Deliver_Skipped(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mailer := mocks.NewMockMailer(ctrl)
+	sink := NewNotificationSink(mailer)
+
+	// Nobody is notified about rooms or about bookings without an email
+	room := &models.Event{ID: 1, Type: models.EventRoomCreated, Payload: &models.Room{ID: 3}}
+	assert.NoError(t, sink.Deliver(room))
+
+	booking := testBooking()
+	booking.Email = ""
+	assert.NoError(t, sink.Deliver(outboxEvent(t, models.EventBookingCreated, booking)))
+}
+
+func TestNotificationSink_Deliver_Failures(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mailer := mocks.NewMockMailer(ctrl)
+	sink := NewNotificationSink(mailer)
+	event := outboxEvent(t, models.EventBookingCancelled, testBooking())
+
+	// The event is retried while the server is unavailable, a rejected
+	// mailbox is not
+	unavailable := errors.New("connection refused")
+	mailer.EXPECT().Send(gomock.Any()).Return(unavailable)
+	assert.Equal(t, unavailable, sink.Deliver(event))
+
+	mailer.EXPECT().Send(gomock.Any()).Return(&textproto.Error{Code: 550, Msg: "no such user"})
+	assert.NoError(t, sink.Deliver(event))
+}
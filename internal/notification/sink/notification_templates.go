@@ -0,0 +1,138 @@
+package sink
+
+import (
+	"github.com/booking_backend/internal/models"
+	"text/template"
+)
+
+// DefaultLanguage is used for the bookings made without a language
+const DefaultLanguage = "ru"
+
+type emailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// funcs cut the time off the dates read from the database
+var funcs = template.FuncMap{
+	"date": func(date string) string {
+		if len(date) > len("2006-01-02") {
+			return date[:len("2006-01-02")]
+		}
+		return date
+	},
+}
+
+func newTemplate(name string, subject string, body string) *emailTemplate {
+	return &emailTemplate{
+		subject: template.Must(template.New(name + "_subject").Funcs(funcs).Parse(subject)),
+		body:    template.Must(template.New(name).Funcs(funcs).Parse(body)),
+	}
+}
+
+// templates are chosen by the language of the booking and by the event type,
+// the data is the booking from the event
+var templates = map[string]map[string]*emailTemplate{
+	"ru": {
+		models.EventBookingCreated: newTemplate("created_ru", `Бронь № {{.ID}} создана`,
+			`Здравствуйте!
+
+Бронь № {{.ID}} создана{{if and (eq .Status "held") .HoldExpires}} и удерживается до {{.HoldExpires.Format "02.01.2006 15:04"}}, подтвердите ее до этого времени{{end}}.
+
+Номер: {{.Room}}
+Заезд: {{date .DateStart}}
+Выезд: {{date .DateEnd}}
+Гостей: {{.Guests}}
+Сумма: {{.Amount}}
+`),
+		models.EventBookingConfirmed: newTemplate("confirmed_ru", `Бронь № {{.ID}} подтверждена`,
+			`Здравствуйте!
+
+Бронь № {{.ID}} подтверждена.
+
+Номер: {{.Room}}
+Заезд: {{date .DateStart}}
+Выезд: {{date .DateEnd}}
+Гостей: {{.Guests}}
+Сумма: {{.Amount}}
+`),
+		models.EventBookingRescheduled: newTemplate("rescheduled_ru", `Даты брони № {{.ID}} изменены`,
+			`Здравствуйте!
+
+Даты брони № {{.ID}} изменены.
+
+Номер: {{.Room}}
+Заезд: {{date .DateStart}}
+Выезд: {{date .DateEnd}}
+`),
+		models.EventBookingReminder: newTemplate("reminder_ru", `Напоминание о брони № {{.ID}}`,
+			`Здравствуйте!
+
+Напоминаем, что {{date .DateStart}} вас ждет заселение по брони № {{.ID}}.
+
+Номер: {{.Room}}
+Заезд: {{date .DateStart}}
+Выезд: {{date .DateEnd}}
+Гостей: {{.Guests}}
+`),
+		models.EventBookingCancelled: newTemplate("cancelled_ru", `Бронь № {{.ID}} отменена`,
+			`Здравствуйте!
+
+Бронь № {{.ID}} на даты с {{date .DateStart}} по {{date .DateEnd}} отменена.
+{{if .CancellationFee}}
+Штраф за отмену: {{.CancellationFee}}
+{{end}}{{if .RefundAmount}}К возврату: {{.RefundAmount}}
+{{end}}`),
+	},
+	"en": {
+		models.EventBookingCreated: newTemplate("created_en", `Booking #{{.ID}} is created`,
+			`Hello!
+
+Booking #{{.ID}} is created{{if and (eq .Status "held") .HoldExpires}} and held until {{.HoldExpires.Format "2006-01-02 15:04"}}, please confirm it by then{{end}}.
+
+Room: {{.Room}}
+Check-in: {{date .DateStart}}
+Check-out: {{date .DateEnd}}
+Guests: {{.Guests}}
+Amount: {{.Amount}}
+`),
+		models.EventBookingConfirmed: newTemplate("confirmed_en", `Booking #{{.ID}} is confirmed`,
+			`Hello!
+
+Booking #{{.ID}} is confirmed.
+
+Room: {{.Room}}
+Check-in: {{date .DateStart}}
+Check-out: {{date .DateEnd}}
+Guests: {{.Guests}}
+Amount: {{.Amount}}
+`),
+		models.EventBookingRescheduled: newTemplate("rescheduled_en", `Booking #{{.ID}} dates are changed`,
+			`Hello!
+
+The dates of booking #{{.ID}} are changed.
+
+Room: {{.Room}}
+Check-in: {{date .DateStart}}
+Check-out: {{date .DateEnd}}
+`),
+		models.EventBookingReminder: newTemplate("reminder_en", `Reminder of booking #{{.ID}}`,
+			`Hello!
+
+This is a reminder that your stay under booking #{{.ID}} begins on {{date .DateStart}}.
+
+Room: {{.Room}}
+Check-in: {{date .DateStart}}
+Check-out: {{date .DateEnd}}
+Guests: {{.Guests}}
+`),
+		models.EventBookingCancelled: newTemplate("cancelled_en", `Booking #{{.ID}} is cancelled`,
+			`Hello!
+
+Booking #{{.ID}} from {{date .DateStart}} to {{date .DateEnd}} is cancelled.
+{{if .CancellationFee}}
+Cancellation fee: {{.CancellationFee}}
+{{end}}{{if .RefundAmount}}Refund: {{.RefundAmount}}
+{{end}}`),
+	},
+}
@@ -0,0 +1,85 @@
+package dispatcher
+
+import (
+	"context"
+	"github.com/booking_backend/internal/outbox"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"time"
+)
+
+// Dispatcher periodically delivers the events of the outbox to the sinks.
+// Every sink goes through the events on its own, by the name it is given
+type Dispatcher struct {
+	outboxRepo outbox.OutboxRepository
+	sinks      map[string]outbox.Sink
+	names      []string
+	interval   time.Duration
+	batchSize  int
+	lease      time.Duration
+}
+
+// NewDispatcher claims a batch of a sink for the lease, which has to cover
+// delivering the whole batch, so that the instances don't deliver it twice
+func NewDispatcher(outboxRepository outbox.OutboxRepository, interval time.Duration,
+	batchSize int, lease time.Duration, sinks map[string]outbox.Sink) *Dispatcher {
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &Dispatcher{outboxRepo: outboxRepository, sinks: sinks, names: names,
+		interval: interval, batchSize: batchSize, lease: lease}
+}
+
+// Run blocks until the context is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Dispatch()
+		}
+	}
+}
+
+// Dispatch delivers a batch of events to every sink and returns how many
+// events all the sinks have got by now
+func (d *Dispatcher) Dispatch() int {
+	for _, name := range d.names {
+		d.deliver(name, d.sinks[name])
+	}
+
+	sent, err := d.outboxRepo.MarkSent(d.names)
+	if err != nil {
+		logrus.Error(err)
+		return 0
+	}
+	return int(sent)
+}
+
+// deliver passes the events the sink has not got yet in the order they were
+// written. It stops at the first failed delivery, the rest of the batch stays
+// claimed and is delivered once the lease expires, while the other sinks go on
+func (d *Dispatcher) deliver(name string, sink outbox.Sink) {
+	events, err := d.outboxRepo.ClaimUndelivered(name, d.batchSize, d.lease)
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	for _, event := range events {
+		if err := sink.Deliver(event); err != nil {
+			logrus.Errorf("event %d %s is not delivered to %s: %v", event.ID, event.Type, name, err)
+			return
+		}
+		if err := d.outboxRepo.MarkDelivered(name, event.ID); err != nil {
+			logrus.Error(err)
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package dispatcher
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/outbox"
+	"github.com/booking_backend/internal/outbox/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var unsentEvents = []*models.Event{
+	{ID: 1, Type: models.EventBookingCreated, Tenant: models.DefaultTenantID},
+	{ID: 2, Type: models.EventBookingCancelled, Tenant: models.DefaultTenantID},
+	{ID: 3, Type: models.EventRoomDeleted, Tenant: models.DefaultTenantID},
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	outboxRep := mocks.NewMockOutboxRepository(ctrl)
+	first := mocks.NewMockSink(ctrl)
+	second := mocks.NewMockSink(ctrl)
+
+	calls := []*gomock.Call{outboxRep.EXPECT().ClaimUndelivered("first", 10, time.Minute).Return(unsentEvents, nil)}
+	for _, event := range unsentEvents {
+		calls = append(calls,
+			first.EXPECT().Deliver(event).Return(nil),
+			outboxRep.EXPECT().MarkDelivered("first", event.ID).Return(nil))
+	}
+	calls = append(calls, outboxRep.EXPECT().ClaimUndelivered("second", 10, time.Minute).Return(unsentEvents, nil))
+	for _, event := range unsentEvents {
+		calls = append(calls,
+			second.EXPECT().Deliver(event).Return(nil),
+			outboxRep.EXPECT().MarkDelivered("second", event.ID).Return(nil))
+	}
+	calls = append(calls, outboxRep.EXPECT().MarkSent([]string{"first", "second"}).Return(int64(3), nil))
+	gomock.InOrder(calls...)
+
+	d := NewDispatcher(outboxRep, time.Second, 10, time.Minute, map[string]outbox.Sink{"first": first, "second": second})
+	assert.Equal(t, 3, d.Dispatch())
+}
+
+func TestDispatcher_Dispatch_FailingSinkStopsAlone(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	outboxRep := mocks.NewMockOutboxRepository(ctrl)
+	failing := mocks.NewMockSink(ctrl)
+	working := mocks.NewMockSink(ctrl)
+
+	// The failing sink doesn't get the third event before the second one,
+	// both wait for the lease to expire, while the working sink gets all of them
+	outboxRep.EXPECT().ClaimUndelivered("failing", 10, time.Minute).Return(unsentEvents, nil)
+	gomock.InOrder(
+		failing.EXPECT().Deliver(unsentEvents[0]).Return(nil),
+		outboxRep.EXPECT().MarkDelivered("failing", unsentEvents[0].ID).Return(nil),
+		failing.EXPECT().Deliver(unsentEvents[1]).Return(errors.New("sink is down")),
+	)
+	outboxRep.EXPECT().ClaimUndelivered("working", 10, time.Minute).Return(unsentEvents, nil)
+	for _, event := range unsentEvents {
+		working.EXPECT().Deliver(event).Return(nil)
+		outboxRep.EXPECT().MarkDelivered("working", event.ID).Return(nil)
+	}
+	outboxRep.EXPECT().MarkSent([]string{"failing", "working"}).Return(int64(1), nil)
+
+	d := NewDispatcher(outboxRep, time.Second, 10, time.Minute, map[string]outbox.Sink{"failing": failing, "working": working})
+	assert.Equal(t, 1, d.Dispatch())
+}
+
+func TestDispatcher_Dispatch_SelectFails(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	outboxRep := mocks.NewMockOutboxRepository(ctrl)
+
+	outboxRep.EXPECT().ClaimUndelivered("log", 10, time.Minute).Return(nil, errors.New("connection refused"))
+	outboxRep.EXPECT().MarkSent([]string{"log"}).Return(int64(0), errors.New("connection refused"))
+
+	d := NewDispatcher(outboxRep, time.Second, 10, time.Minute, map[string]outbox.Sink{"log": mocks.NewMockSink(ctrl)})
+	assert.Equal(t, 0, d.Dispatch())
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"github.com/booking_backend/internal/models"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var eventColumns = []string{"id", "type", "payload", "created", "tenant"}
+
+func MockInsertEvent(mock sqlmock.Sqlmock, event *models.Event) {
+	mock.ExpectQuery(`INSERT INTO outbox`).
+		WithArgs(event.Tenant, event.Type, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(event.ID, time.Now()))
+}
+
+func MockClaimUndelivered(mock sqlmock.Sqlmock, sink string, limit int, lease time.Duration,
+	events []*models.Event) {
+	mock.ExpectExec(`INSERT INTO outbox_deliveries`).
+		WithArgs(sink).
+		WillReturnResult(sqlmock.NewResult(0, int64(len(events))))
+	rows := sqlmock.NewRows(eventColumns)
+	for _, event := range events {
+		rows.AddRow(event.ID, event.Type, event.Payload, event.Created, event.Tenant)
+	}
+	mock.ExpectQuery(`UPDATE outbox_deliveries d SET lease_until(.+)FOR UPDATE SKIP LOCKED\) RETURNING`).
+		WithArgs(sink, limit, lease.Seconds()).
+		WillReturnRows(rows)
+}
@@ -0,0 +1,14 @@
+package outbox
+
+import (
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+type OutboxRepository interface {
+	// ClaimUndelivered takes the unsent events the sink has not got yet for
+	// the lease, other dispatchers skip them until the lease expires
+	ClaimUndelivered(sink string, limit int, lease time.Duration) ([]*models.Event, error)
+	MarkDelivered(sink string, id uint64) error
+	MarkSent(sinks []string) (int64, error)
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/outbox"
+	"github.com/lib/pq"
+	"sort"
+	"time"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) outbox.OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// InsertEvent stores the event within the transaction making the change, so
+// the event is sent if and only if the change is stored
+func InsertEvent(ctx context.Context, tx *sql.Tx, event *models.Event) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO outbox(tenant, type, payload)
+		VALUES ($1, $2, $3) RETURNING id, created`,
+		event.Tenant, event.Type, payload).
+		Scan(&event.ID, &event.Created)
+}
+
+// ClaimUndelivered returns the oldest events the sink has not got yet. Every
+// unsent event gets a delivery row for the sink first, whatever its id, so
+// the events committed after the ones with greater ids are not missed. The
+// deliveries are claimed until the end of the lease, the ones locked or
+// leased by another dispatcher are skipped
+func (rep *OutboxRepository) ClaimUndelivered(sink string, limit int,
+	lease time.Duration) ([]*models.Event, error) {
+	_, err := rep.db.Exec(`
+		INSERT INTO outbox_deliveries(event, sink)
+		SELECT id, $1
+		FROM outbox
+		WHERE sent IS NULL
+		ON CONFLICT (event, sink) DO NOTHING`, sink)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rep.db.Query(`
+		UPDATE outbox_deliveries d
+		SET lease_until=now() + make_interval(secs => $3)
+		FROM outbox o
+		WHERE o.id = d.event AND d.sink=$1 AND d.event IN (
+			SELECT event
+			FROM outbox_deliveries
+			WHERE sink=$1 AND delivered IS NULL AND (lease_until IS NULL OR lease_until <= now())
+			ORDER BY event
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED)
+		RETURNING o.id, o.type, o.payload, o.created, o.tenant`, sink, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.Event
+	for rows.Next() {
+		event := &models.Event{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.Type, &payload,
+			&event.Created, &event.Tenant); err != nil {
+			return nil, err
+		}
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING keeps no order
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
+
+// MarkDelivered records that the sink got the event
+func (rep *OutboxRepository) MarkDelivered(sink string, id uint64) error {
+	_, err := rep.db.Exec(`
+		UPDATE outbox_deliveries
+		SET delivered=now(), lease_until=NULL
+		WHERE event=$2 AND sink=$1`, sink, id)
+	return err
+}
+
+// MarkSent marks sent the events every one of the sinks has got and returns
+// how many there were
+func (rep *OutboxRepository) MarkSent(sinks []string) (int64, error) {
+	res, err := rep.db.Exec(`
+		UPDATE outbox o
+		SET sent=now()
+		WHERE sent IS NULL AND (
+			SELECT count(*)
+			FROM outbox_deliveries d
+			WHERE d.event = o.id AND d.sink=ANY($1) AND d.delivered IS NOT NULL)=$2`,
+		pq.Array(sinks), len(sinks))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/outbox/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInsertEvent(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	event := models.NewEvent(models.DefaultTenantID, models.EventRoomCreated,
+		&models.Room{ID: 3, Price: 500})
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO outbox`).
+		WithArgs(event.Tenant, event.Type,
+			[]byte(`{"room_id":3,"description":"","price":500,"created":"0001-01-01T00:00:00Z","property":0,"version":0}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(7, time.Now()))
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, InsertEvent(context.Background(), tx, event))
+	assert.NoError(t, tx.Commit())
+	assert.Equal(t, uint64(7), event.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ClaimUndelivered(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	events := []*models.Event{
+		{
+			ID:      1,
+			Type:    models.EventBookingCreated,
+			Payload: json.RawMessage(`{"booking_id":5}`),
+			Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			Tenant:  models.DefaultTenantID,
+		},
+		{
+			ID:      2,
+			Type:    models.EventBookingCancelled,
+			Payload: json.RawMessage(`{"booking_id":5}`),
+			Created: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC),
+			Tenant:  models.DefaultTenantID,
+		},
+	}
+	// RETURNING keeps no order, the events are sorted by id
+	mocks.MockClaimUndelivered(mock, "webhooks", 10, time.Minute, []*models.Event{events[1], events[0]})
+
+	outboxPgRep := NewOutboxRepository(db)
+	actual, err := outboxPgRep.ClaimUndelivered("webhooks", 10, time.Minute)
+
+	assert.NoError(t, err)
+	assert.Equal(t, events, actual)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_MarkDelivered(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE outbox_deliveries SET delivered=now\(\)`).
+		WithArgs("webhooks", uint64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	outboxPgRep := NewOutboxRepository(db)
+	assert.NoError(t, outboxPgRep.MarkDelivered("webhooks", 4))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_MarkSent(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE outbox o SET sent=now\(\)(.+)FROM outbox_deliveries d`).
+		WithArgs(sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	outboxPgRep := NewOutboxRepository(db)
+	sent, err := outboxPgRep.MarkSent([]string{"log", "webhooks"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), sent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,10 @@
+package outbox
+
+import "github.com/booking_backend/internal/models"
+
+// Sink delivers events outside the service. Delivery is at least once: an
+// event is delivered again if recording its delivery fails. Every sink gets
+// the events in order on its own, so a failing sink only delays itself
+type Sink interface {
+	Deliver(event *models.Event) error
+}
@@ -0,0 +1,29 @@
+package sinks
+
+import (
+	"encoding/json"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/outbox"
+	"github.com/sirupsen/logrus"
+)
+
+// LogSink writes the events to the service log
+type LogSink struct{}
+
+func NewLogSink() outbox.Sink {
+	return &LogSink{}
+}
+
+func (ls *LogSink) Deliver(event *models.Event) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event_id": event.ID,
+		"type":     event.Type,
+		"tenant":   event.Tenant,
+	}).Info(string(payload))
+	return nil
+}
@@ -0,0 +1,92 @@
+package delivery
+
+import (
+	"github.com/booking_backend/internal/booking"
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/payment"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+type PaymentHandler struct {
+	paymentUseCase payment.PaymentUseCase
+	bookingUseCase booking.BookingUseCase
+}
+
+// NewPaymentHandler takes the booking use case to make sure the booking
+// belongs to the tenant of the caller, payments themselves know nothing of tenants
+func NewPaymentHandler(useCase payment.PaymentUseCase,
+	bookingUseCase booking.BookingUseCase) *PaymentHandler {
+	return &PaymentHandler{paymentUseCase: useCase, bookingUseCase: bookingUseCase}
+}
+
+func (ph *PaymentHandler) Configure(e *echo.Echo) {
+	e.GET("bookings/:id/payments",
+		ph.GetBookingPayments(), principal.Require(rbac.ViewBookings))
+	e.POST("bookings/:id/payments/capture",
+		ph.Capture(), principal.Require(rbac.ManageBookings))
+}
+
+func (ph *PaymentHandler) GetBookingPayments() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		bookingID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		_, customErr := ph.bookingUseCase.GetBooking(context.Request().Context(),
+			principal.Tenant(context), bookingID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		payments, customErr := ph.paymentUseCase.GetBookingPayments(bookingID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, payments)
+	}
+}
+
+func (ph *PaymentHandler) Capture() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		bookingID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		booking, customErr := ph.bookingUseCase.GetBooking(context.Request().Context(),
+			principal.Tenant(context), bookingID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		// A hold is not paid for yet and a cancelled booking is settled on cancellation
+		if booking.Status != models.BookingStatusConfirmed {
+			customErr := errors.Get(CodeBookingNotConfirmed)
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		if customErr := ph.paymentUseCase.Capture(bookingID); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{Message: "success"})
+	}
+}
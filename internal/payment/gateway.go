@@ -0,0 +1,18 @@
+package payment
+
+import "errors"
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrUnknownReference = errors.New("unknown authorization reference")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Gateway is implemented by payment provider adapters. Amounts are in the
+// smallest currency unit, reference identifies the authorization at the provider
+type Gateway interface {
+	Authorize(amount uint64, token string) (string, error)
+	Capture(reference string, amount uint64) error
+	Refund(reference string, amount uint64) error
+	Void(reference string) error
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"fmt"
+	"github.com/booking_backend/internal/payment"
+	"strings"
+	"sync"
+)
+
+// DeclinedTokenPrefix makes FakeGateway decline the authorization
+const DeclinedTokenPrefix = "decline"
+
+type authorization struct {
+	amount   uint64
+	captured uint64
+	refunded uint64
+	voided   bool
+}
+
+// FakeGateway keeps authorizations in memory and is meant for local runs and
+// tests until a real provider adapter is plugged in
+type FakeGateway struct {
+	mu             sync.Mutex
+	lastID         uint64
+	authorizations map[string]*authorization
+}
+
+func NewFakeGateway() payment.Gateway {
+	return &FakeGateway{authorizations: map[string]*authorization{}}
+}
+
+func (fg *FakeGateway) Authorize(amount uint64, token string) (string, error) {
+	if strings.HasPrefix(token, DeclinedTokenPrefix) {
+		return "", payment.ErrDeclined
+	}
+
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	fg.lastID++
+	reference := fmt.Sprintf("fake_auth_%d", fg.lastID)
+	fg.authorizations[reference] = &authorization{amount: amount}
+	return reference, nil
+}
+
+func (fg *FakeGateway) Capture(reference string, amount uint64) error {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	auth, has := fg.authorizations[reference]
+	if !has || auth.voided {
+		return payment.ErrUnknownReference
+	}
+	if auth.captured+amount > auth.amount {
+		return payment.ErrInvalidAmount
+	}
+	auth.captured += amount
+	return nil
+}
+
+func (fg *FakeGateway) Refund(reference string, amount uint64) error {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	auth, has := fg.authorizations[reference]
+	if !has {
+		return payment.ErrUnknownReference
+	}
+	if auth.refunded+amount > auth.captured {
+		return payment.ErrInvalidAmount
+	}
+	auth.refunded += amount
+	return nil
+}
+
+func (fg *FakeGateway) Void(reference string) error {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	auth, has := fg.authorizations[reference]
+	if !has || auth.captured > 0 {
+		return payment.ErrUnknownReference
+	}
+	auth.voided = true
+	return nil
+}
@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"github.com/booking_backend/internal/payment"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFakeGateway_Declined(t *testing.T) {
+	t.Parallel()
+	gateway := NewFakeGateway()
+
+	_, err := gateway.Authorize(1000, DeclinedTokenPrefix+"_insufficient_funds")
+	assert.Equal(t, payment.ErrDeclined, err)
+}
+
+func TestFakeGateway_CaptureAndRefund(t *testing.T) {
+	t.Parallel()
+	gateway := NewFakeGateway()
+
+	reference, err := gateway.Authorize(1000, "tok_visa")
+	assert.NoError(t, err)
+
+	assert.NoError(t, gateway.Capture(reference, 1000))
+	assert.Equal(t, payment.ErrInvalidAmount, gateway.Capture(reference, 1))
+	assert.NoError(t, gateway.Refund(reference, 400))
+	assert.Equal(t, payment.ErrInvalidAmount, gateway.Refund(reference, 700))
+	assert.Equal(t, payment.ErrUnknownReference, gateway.Void(reference))
+}
@@ -0,0 +1,90 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gateway.go
+
+// Package mock_payment is a generated GoMock package.
+package mocks
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockGateway is a mock of Gateway interface
+type MockGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockGatewayMockRecorder
+}
+
+// MockGatewayMockRecorder is the mock recorder for MockGateway
+type MockGatewayMockRecorder struct {
+	mock *MockGateway
+}
+
+// NewMockGateway creates a new mock instance
+func NewMockGateway(ctrl *gomock.Controller) *MockGateway {
+	mock := &MockGateway{ctrl: ctrl}
+	mock.recorder = &MockGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGateway) EXPECT() *MockGatewayMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method
+func (m *MockGateway) Authorize(amount uint64, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", amount, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockGatewayMockRecorder) Authorize(amount, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockGateway)(nil).Authorize), amount, token)
+}
+
+// Capture mocks base method
+func (m *MockGateway) Capture(reference string, amount uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", reference, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture
+func (mr *MockGatewayMockRecorder) Capture(reference, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockGateway)(nil).Capture), reference, amount)
+}
+
+// Refund mocks base method
+func (m *MockGateway) Refund(reference string, amount uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", reference, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refund indicates an expected call of Refund
+func (mr *MockGatewayMockRecorder) Refund(reference, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockGateway)(nil).Refund), reference, amount)
+}
+
+// Void mocks base method
+func (m *MockGateway) Void(reference string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", reference)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void
+func (mr *MockGatewayMockRecorder) Void(reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockGateway)(nil).Void), reference)
+}
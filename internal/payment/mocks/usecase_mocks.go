@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_payment is a generated GoMock package.
+package mocks
+
+import (
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPaymentUseCase is a mock of PaymentUseCase interface
+type MockPaymentUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentUseCaseMockRecorder
+}
+
+// MockPaymentUseCaseMockRecorder is the mock recorder for MockPaymentUseCase
+type MockPaymentUseCaseMockRecorder struct {
+	mock *MockPaymentUseCase
+}
+
+// NewMockPaymentUseCase creates a new mock instance
+func NewMockPaymentUseCase(ctrl *gomock.Controller) *MockPaymentUseCase {
+	mock := &MockPaymentUseCase{ctrl: ctrl}
+	mock.recorder = &MockPaymentUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentUseCase) EXPECT() *MockPaymentUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method
+func (m *MockPaymentUseCase) Authorize(booking *models.Booking, token string) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", booking, token)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockPaymentUseCaseMockRecorder) Authorize(booking, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockPaymentUseCase)(nil).Authorize), booking, token)
+}
+
+// Capture mocks base method
+func (m *MockPaymentUseCase) Capture(bookingID uint64) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", bookingID)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// Capture indicates an expected call of Capture
+func (mr *MockPaymentUseCaseMockRecorder) Capture(bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockPaymentUseCase)(nil).Capture), bookingID)
+}
+
+// Void mocks base method
+func (m *MockPaymentUseCase) Void(bookingID uint64) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", bookingID)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// Void indicates an expected call of Void
+func (mr *MockPaymentUseCaseMockRecorder) Void(bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockPaymentUseCase)(nil).Void), bookingID)
+}
+
+// Settle mocks base method
+func (m *MockPaymentUseCase) Settle(booking *models.Booking) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Settle", booking)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// Settle indicates an expected call of Settle
+func (mr *MockPaymentUseCaseMockRecorder) Settle(booking interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settle", reflect.TypeOf((*MockPaymentUseCase)(nil).Settle), booking)
+}
+
+// GetBookingPayments mocks base method
+func (m *MockPaymentUseCase) GetBookingPayments(bookingID uint64) ([]*models.Payment, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingPayments", bookingID)
+	ret0, _ := ret[0].([]*models.Payment)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetBookingPayments indicates an expected call of GetBookingPayments
+func (mr *MockPaymentUseCaseMockRecorder) GetBookingPayments(bookingID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingPayments", reflect.TypeOf((*MockPaymentUseCase)(nil).GetBookingPayments), bookingID)
+}
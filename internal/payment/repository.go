@@ -0,0 +1,8 @@
+package payment
+
+import "github.com/booking_backend/internal/models"
+
+type PaymentRepository interface {
+	Insert(payment *models.Payment) error
+	SelectBookingPayments(bookingID uint64) ([]*models.Payment, error)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/payment"
+)
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) payment.PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func (rep *PaymentRepository) Insert(payment *models.Payment) error {
+	return rep.db.QueryRow(`
+		INSERT INTO payments(booking, type, amount, reference, status, created)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		payment.Booking, payment.Type, payment.Amount,
+		payment.Reference, payment.Status, payment.Created).
+		Scan(&payment.ID)
+}
+
+func (rep *PaymentRepository) SelectBookingPayments(bookingID uint64) ([]*models.Payment, error) {
+	rows, err := rep.db.Query(`
+		SELECT id, booking, type, amount, reference, status, created
+		FROM payments
+		WHERE booking=$1
+		ORDER BY id`, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*models.Payment
+	for rows.Next() {
+		payment := &models.Payment{}
+		if err := rows.Scan(&payment.ID, &payment.Booking, &payment.Type,
+			&payment.Amount, &payment.Reference, &payment.Status,
+			&payment.Created); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
@@ -0,0 +1,14 @@
+package payment
+
+import (
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type PaymentUseCase interface {
+	Authorize(booking *models.Booking, token string) *errors.Error
+	Capture(bookingID uint64) *errors.Error
+	Void(bookingID uint64) *errors.Error
+	Settle(booking *models.Booking) *errors.Error
+	GetBookingPayments(bookingID uint64) ([]*models.Payment, *errors.Error)
+}
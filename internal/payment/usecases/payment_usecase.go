@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/payment"
+	"time"
+)
+
+type PaymentUseCase struct {
+	paymentRepo payment.PaymentRepository
+	gateway     payment.Gateway
+}
+
+func NewPaymentUseCase(paymentRepository payment.PaymentRepository,
+	gateway payment.Gateway) payment.PaymentUseCase {
+	return &PaymentUseCase{paymentRepo: paymentRepository, gateway: gateway}
+}
+
+// paymentState is what the transactions of one booking add up to
+type paymentState struct {
+	reference  string
+	authorized uint64
+	captured   uint64
+	refunded   uint64
+	voided     bool
+}
+
+func summarize(payments []*models.Payment) *paymentState {
+	state := &paymentState{}
+	for _, p := range payments {
+		if p.Status != models.PaymentStatusSucceeded {
+			continue
+		}
+		switch p.Type {
+		case models.PaymentTypeAuthorization:
+			state.reference = p.Reference
+			state.authorized = p.Amount
+			state.voided = false
+		case models.PaymentTypeCapture:
+			state.captured += p.Amount
+		case models.PaymentTypeRefund:
+			state.refunded += p.Amount
+		case models.PaymentTypeVoid:
+			state.voided = true
+		}
+	}
+	return state
+}
+
+func (uc *PaymentUseCase) state(bookingID uint64) (*paymentState, *errors.Error) {
+	payments, err := uc.paymentRepo.SelectBookingPayments(bookingID)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return summarize(payments), nil
+}
+
+// record stores the transaction whatever the gateway answered, so failed
+// attempts are visible in the booking payment history
+func (uc *PaymentUseCase) record(bookingID uint64, paymentType string, amount uint64,
+	reference string, gatewayErr error) *errors.Error {
+	transaction := &models.Payment{
+		Booking:   bookingID,
+		Type:      paymentType,
+		Amount:    amount,
+		Reference: reference,
+		Status:    models.PaymentStatusSucceeded,
+		Created:   time.Now(),
+	}
+	if gatewayErr != nil {
+		transaction.Status = models.PaymentStatusFailed
+	}
+
+	if err := uc.paymentRepo.Insert(transaction); err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+
+	switch gatewayErr {
+	case nil:
+		return nil
+	case payment.ErrDeclined:
+		return errors.Get(consts.CodePaymentDeclined)
+	default:
+		return errors.New(consts.CodePaymentGatewayError, gatewayErr)
+	}
+}
+
+func (uc *PaymentUseCase) Authorize(booking *models.Booking, token string) *errors.Error {
+	reference, err := uc.gateway.Authorize(booking.Amount, token)
+	return uc.record(booking.ID, models.PaymentTypeAuthorization, booking.Amount, reference, err)
+}
+
+// Capture takes the whole authorized amount once, an authorization that is
+// voided or already captured can't be captured again
+func (uc *PaymentUseCase) Capture(bookingID uint64) *errors.Error {
+	state, customErr := uc.state(bookingID)
+	if customErr != nil {
+		return customErr
+	}
+	if state.reference == "" || state.voided {
+		return errors.Get(consts.CodePaymentNotAuthorized)
+	}
+	if state.captured > 0 {
+		return errors.Get(consts.CodePaymentAlreadyCaptured)
+	}
+
+	err := uc.gateway.Capture(state.reference, state.authorized)
+	return uc.record(bookingID, models.PaymentTypeCapture, state.authorized, state.reference, err)
+}
+
+// Void releases the authorization if nothing has been captured yet
+func (uc *PaymentUseCase) Void(bookingID uint64) *errors.Error {
+	state, customErr := uc.state(bookingID)
+	if customErr != nil {
+		return customErr
+	}
+	if state.reference == "" || state.voided || state.captured > 0 {
+		return nil
+	}
+
+	err := uc.gateway.Void(state.reference)
+	return uc.record(bookingID, models.PaymentTypeVoid, 0, state.reference, err)
+}
+
+// Settle is called for a cancelled booking. The cancellation fee is kept
+// and everything else the guest has paid is refunded. When nothing has been
+// captured yet, only the fee is captured and the authorization is released.
+// The outcome is written to booking.RefundStatus and booking.RefundAmount,
+// the amount counts only the refunds made. A failed refund, fee capture or
+// void is tried once and marked failed, the scheduler retries it later
+func (uc *PaymentUseCase) Settle(booking *models.Booking) *errors.Error {
+	state, customErr := uc.state(booking.ID)
+	if customErr != nil {
+		return customErr
+	}
+	if state.reference == "" || state.voided {
+		booking.RefundStatus = models.RefundStatusNone
+		return nil
+	}
+
+	fee := booking.CancellationFee
+	if state.captured == 0 {
+		if fee == 0 {
+			err := uc.gateway.Void(state.reference)
+			customErr = uc.record(booking.ID, models.PaymentTypeVoid, 0, state.reference, err)
+		} else {
+			if fee > state.authorized {
+				fee = state.authorized
+			}
+			err := uc.gateway.Capture(state.reference, fee)
+			customErr = uc.record(booking.ID, models.PaymentTypeCapture, fee, state.reference, err)
+		}
+		booking.RefundStatus = models.RefundStatusNone
+		if customErr != nil {
+			booking.RefundStatus = models.RefundStatusFailed
+		}
+		return customErr
+	}
+
+	var refundable uint64
+	if state.captured > fee+state.refunded {
+		refundable = state.captured - fee - state.refunded
+	}
+	booking.RefundAmount = state.refunded
+	if refundable > 0 {
+		err := uc.gateway.Refund(state.reference, refundable)
+		customErr := uc.record(booking.ID, models.PaymentTypeRefund, refundable, state.reference, err)
+		if customErr != nil {
+			booking.RefundStatus = models.RefundStatusFailed
+			return customErr
+		}
+		booking.RefundAmount += refundable
+	}
+
+	switch {
+	case booking.RefundAmount == 0:
+		booking.RefundStatus = models.RefundStatusNone
+	case booking.RefundAmount == state.captured:
+		booking.RefundStatus = models.RefundStatusRefunded
+	default:
+		booking.RefundStatus = models.RefundStatusPartial
+	}
+	return nil
+}
+
+func (uc *PaymentUseCase) GetBookingPayments(bookingID uint64) ([]*models.Payment, *errors.Error) {
+	payments, err := uc.paymentRepo.SelectBookingPayments(bookingID)
+	if err == nil && payments == nil {
+		return []*models.Payment{}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return payments, nil
+}
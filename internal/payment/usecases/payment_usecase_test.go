@@ -0,0 +1,333 @@
+package usecases
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/payment"
+	"github.com/booking_backend/internal/payment/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var bookingModel = &models.Booking{
+	ID:     3,
+	Amount: 1500,
+}
+
+var authorization = &models.Payment{
+	ID:        1,
+	Booking:   3,
+	Type:      models.PaymentTypeAuthorization,
+	Amount:    1500,
+	Reference: "fake_auth_1",
+	Status:    models.PaymentStatusSucceeded,
+}
+
+func TestPaymentUseCase_Authorize_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+
+	gateway.
+		EXPECT().
+		Authorize(bookingModel.Amount, "tok_visa").
+		Return("fake_auth_1", nil)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		DoAndReturn(func(p *models.Payment) error {
+			assert.Equal(t, models.PaymentStatusSucceeded, p.Status)
+			assert.Equal(t, "fake_auth_1", p.Reference)
+			return nil
+		})
+
+	err := paymentUseCase.Authorize(bookingModel, "tok_visa")
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestPaymentUseCase_Authorize_Declined(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+
+	gateway.
+		EXPECT().
+		Authorize(bookingModel.Amount, "decline").
+		Return("", payment.ErrDeclined)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		DoAndReturn(func(p *models.Payment) error {
+			assert.Equal(t, models.PaymentStatusFailed, p.Status)
+			return nil
+		})
+
+	err := paymentUseCase.Authorize(bookingModel, "decline")
+	assert.Equal(t, errors.Get(consts.CodePaymentDeclined), err)
+}
+
+func TestPaymentUseCase_Capture_NotAuthorized(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(bookingModel.ID).
+		Return(nil, nil)
+
+	err := paymentUseCase.Capture(bookingModel.ID)
+	assert.Equal(t, errors.Get(consts.CodePaymentNotAuthorized), err)
+}
+
+func TestPaymentUseCase_Capture_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(bookingModel.ID).
+		Return([]*models.Payment{authorization}, nil)
+	gateway.
+		EXPECT().
+		Capture(authorization.Reference, authorization.Amount).
+		Return(nil)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	err := paymentUseCase.Capture(bookingModel.ID)
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestPaymentUseCase_Capture_AlreadyCaptured(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+	capture := &models.Payment{Booking: 3, Type: models.PaymentTypeCapture, Amount: 500,
+		Reference: authorization.Reference, Status: models.PaymentStatusSucceeded}
+
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(bookingModel.ID).
+		Return([]*models.Payment{authorization, capture}, nil)
+
+	err := paymentUseCase.Capture(bookingModel.ID)
+	assert.Equal(t, errors.Get(consts.CodePaymentAlreadyCaptured), err)
+}
+
+func TestPaymentUseCase_Capture_Voided(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+	void := &models.Payment{Booking: 3, Type: models.PaymentTypeVoid,
+		Reference: authorization.Reference, Status: models.PaymentStatusSucceeded}
+
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(bookingModel.ID).
+		Return([]*models.Payment{authorization, void}, nil)
+
+	err := paymentUseCase.Capture(bookingModel.ID)
+	assert.Equal(t, errors.Get(consts.CodePaymentNotAuthorized), err)
+}
+
+func TestPaymentUseCase_Void_AfterCapture(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+
+	capture := &models.Payment{
+		Booking:   3,
+		Type:      models.PaymentTypeCapture,
+		Amount:    1500,
+		Reference: authorization.Reference,
+		Status:    models.PaymentStatusSucceeded,
+	}
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(bookingModel.ID).
+		Return([]*models.Payment{authorization, capture}, nil)
+
+	err := paymentUseCase.Void(bookingModel.ID)
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func newCapture(amount uint64) *models.Payment {
+	return &models.Payment{
+		Booking:   3,
+		Type:      models.PaymentTypeCapture,
+		Amount:    amount,
+		Reference: authorization.Reference,
+		Status:    models.PaymentStatusSucceeded,
+	}
+}
+
+func TestPaymentUseCase_Settle_PartialRefund(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+	cancelled := &models.Booking{ID: 3, CancellationFee: 500}
+
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(cancelled.ID).
+		Return([]*models.Payment{authorization, newCapture(1500)}, nil)
+	gateway.
+		EXPECT().
+		Refund(authorization.Reference, uint64(1000)).
+		Return(nil)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	err := paymentUseCase.Settle(cancelled)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.RefundStatusPartial, cancelled.RefundStatus)
+	assert.Equal(t, uint64(1000), cancelled.RefundAmount)
+}
+
+func TestPaymentUseCase_Settle_RetryAfterFailure(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+	cancelled := &models.Booking{ID: 3}
+
+	// The failed refund is not counted, so the retry refunds all of it
+	failedRefund := &models.Payment{Booking: 3, Type: models.PaymentTypeRefund, Amount: 1500,
+		Reference: authorization.Reference, Status: models.PaymentStatusFailed}
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(cancelled.ID).
+		Return([]*models.Payment{authorization, newCapture(1500), failedRefund}, nil)
+	gateway.
+		EXPECT().
+		Refund(authorization.Reference, uint64(1500)).
+		Return(nil)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	err := paymentUseCase.Settle(cancelled)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.RefundStatusRefunded, cancelled.RefundStatus)
+	assert.Equal(t, uint64(1500), cancelled.RefundAmount)
+}
+
+func TestPaymentUseCase_Settle_RefundFailed(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+	cancelled := &models.Booking{ID: 3}
+
+	// The refund is tried once, the request doesn't wait for the retries
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(cancelled.ID).
+		Return([]*models.Payment{authorization, newCapture(1500)}, nil)
+	gateway.
+		EXPECT().
+		Refund(authorization.Reference, uint64(1500)).
+		Return(sql.ErrConnDone)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	err := paymentUseCase.Settle(cancelled)
+	assert.Equal(t, consts.CodePaymentGatewayError, err.Code)
+	assert.Equal(t, models.RefundStatusFailed, cancelled.RefundStatus)
+	assert.Equal(t, uint64(0), cancelled.RefundAmount)
+}
+
+func TestPaymentUseCase_Settle_CaptureFeeFromAuthorization(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+	cancelled := &models.Booking{ID: 3, CancellationFee: 500}
+
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(cancelled.ID).
+		Return([]*models.Payment{authorization}, nil)
+	gateway.
+		EXPECT().
+		Capture(authorization.Reference, uint64(500)).
+		Return(nil)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	err := paymentUseCase.Settle(cancelled)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.RefundStatusNone, cancelled.RefundStatus)
+}
+
+func TestPaymentUseCase_Settle_CaptureFeeFailed(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	paymentRep := mocks.NewMockPaymentRepository(ctrl)
+	gateway := mocks.NewMockGateway(ctrl)
+	paymentUseCase := NewPaymentUseCase(paymentRep, gateway)
+	cancelled := &models.Booking{ID: 3, CancellationFee: 500}
+
+	// The fee is captured again by the refund job
+	paymentRep.
+		EXPECT().
+		SelectBookingPayments(cancelled.ID).
+		Return([]*models.Payment{authorization}, nil)
+	gateway.
+		EXPECT().
+		Capture(authorization.Reference, uint64(500)).
+		Return(sql.ErrConnDone)
+	paymentRep.
+		EXPECT().
+		Insert(gomock.Any()).
+		Return(nil)
+
+	err := paymentUseCase.Settle(cancelled)
+	assert.Equal(t, consts.CodePaymentGatewayError, err.Code)
+	assert.Equal(t, models.RefundStatusFailed, cancelled.RefundStatus)
+}
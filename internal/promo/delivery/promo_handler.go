@@ -0,0 +1,99 @@
+package delivery
+
+import (
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/promo"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/request_reader"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+type PromoHandler struct {
+	promoUseCase promo.PromoUseCase
+}
+
+func NewPromoHandler(useCase promo.PromoUseCase) *PromoHandler {
+	return &PromoHandler{promoUseCase: useCase}
+}
+
+func (ph *PromoHandler) Configure(e *echo.Echo) {
+	e.POST("promo_codes/create",
+		ph.CreatePromoCode(), principal.Require(rbac.ManageProperties))
+	e.GET("promo_codes/list",
+		ph.GetPromoCodes(), principal.Require(rbac.ManageProperties))
+	e.GET("promo_codes/:code",
+		ph.GetPromoCode(), principal.Require(rbac.ManageProperties))
+}
+
+func (ph *PromoHandler) CreatePromoCode() echo.HandlerFunc {
+	type Request struct {
+		Code          string            `form:"code" validate:"required,max=64"`
+		DiscountType  string            `form:"discount_type" validate:"required,oneof=percent fixed"`
+		DiscountValue uint64            `form:"discount_value" validate:"required"`
+		ValidFrom     models.CustomDate `form:"valid_from" validate:"required"`
+		ValidTo       models.CustomDate `form:"valid_to" validate:"required"`
+		UsageLimit    uint64            `form:"usage_limit"`
+		RoomID        uint64            `form:"room_id"`
+		PropertyID    uint64            `form:"property_id"`
+		MinNights     uint64            `form:"min_nights"`
+	}
+
+	return func(context echo.Context) error {
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		promoCode := &models.PromoCode{
+			Code:          req.Code,
+			DiscountType:  req.DiscountType,
+			DiscountValue: req.DiscountValue,
+			ValidFrom:     req.ValidFrom.Date,
+			ValidTo:       req.ValidTo.Date,
+			UsageLimit:    req.UsageLimit,
+			Room:          req.RoomID,
+			Property:      req.PropertyID,
+			MinNights:     req.MinNights,
+			Created:       time.Now(),
+			Tenant:        principal.Tenant(context),
+		}
+
+		if customErr := ph.promoUseCase.CreatePromoCode(promoCode); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusCreated, promoCode)
+	}
+}
+
+func (ph *PromoHandler) GetPromoCodes() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		codes, customErr := ph.promoUseCase.GetPromoCodes(principal.Tenant(context))
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, codes)
+	}
+}
+
+func (ph *PromoHandler) GetPromoCode() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		promoCode, customErr := ph.promoUseCase.GetPromoCode(principal.Tenant(context),
+			context.Param("code"))
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, promoCode)
+	}
+}
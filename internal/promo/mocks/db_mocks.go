@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"github.com/booking_backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var promoCodeColumns = []string{"code", "discount_type", "discount_value",
+	"valid_from", "valid_to", "usage_limit", "used", "room", "property",
+	"min_nights", "created", "tenant"}
+
+// MockRedeem expects the usage check of a code limited to usageLimit uses
+func MockRedeem(mock sqlmock.Sqlmock, tenant uint64, code string, usageLimit uint64, used uint64) {
+	mock.ExpectQuery(`SELECT usage_limit FROM promo_codes`).
+		WithArgs(tenant, code).
+		WillReturnRows(sqlmock.NewRows([]string{"usage_limit"}).AddRow(usageLimit))
+	if usageLimit == 0 {
+		return
+	}
+	mock.ExpectQuery(`SELECT count`).
+		WithArgs(tenant, code, models.BookingStatusConfirmed, models.BookingStatusHeld).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(used))
+}
+
+func MockInsertAlreadyExists(mock sqlmock.Sqlmock, promoCode *models.PromoCode) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO promo_codes`).
+		WithArgs(promoCode.Code, promoCode.DiscountType, promoCode.DiscountValue,
+			promoCode.ValidFrom, promoCode.ValidTo, promoCode.UsageLimit,
+			promoCode.Room, promoCode.Property, promoCode.MinNights, promoCode.Created,
+			promoCode.Tenant).
+		WillReturnRows(sqlmock.NewRows([]string{"code"}))
+	mock.ExpectRollback()
+}
+
+func MockSelectByCode(mock sqlmock.Sqlmock, promoCode *models.PromoCode) {
+	mock.ExpectQuery(`SELECT (.+) FROM promo_codes`).
+		WithArgs(models.BookingStatusConfirmed, models.BookingStatusHeld, promoCode.Tenant,
+			promoCode.Code).
+		WillReturnRows(sqlmock.NewRows(promoCodeColumns).
+			AddRow(promoCode.Code, promoCode.DiscountType, promoCode.DiscountValue,
+				promoCode.ValidFrom, promoCode.ValidTo, promoCode.UsageLimit,
+				promoCode.Used, promoCode.Room, promoCode.Property,
+				promoCode.MinNights, promoCode.Created, promoCode.Tenant))
+}
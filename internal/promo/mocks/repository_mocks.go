@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mock_promo is a generated GoMock package.
+package mocks
+
+import (
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPromoRepository is a mock of PromoRepository interface
+type MockPromoRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromoRepositoryMockRecorder
+}
+
+// MockPromoRepositoryMockRecorder is the mock recorder for MockPromoRepository
+type MockPromoRepositoryMockRecorder struct {
+	mock *MockPromoRepository
+}
+
+// NewMockPromoRepository creates a new mock instance
+func NewMockPromoRepository(ctrl *gomock.Controller) *MockPromoRepository {
+	mock := &MockPromoRepository{ctrl: ctrl}
+	mock.recorder = &MockPromoRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPromoRepository) EXPECT() *MockPromoRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method
+func (m *MockPromoRepository) Insert(promo *models.PromoCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", promo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert
+func (mr *MockPromoRepositoryMockRecorder) Insert(promo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockPromoRepository)(nil).Insert), promo)
+}
+
+// SelectByCode mocks base method
+func (m *MockPromoRepository) SelectByCode(tenant uint64, code string) (*models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectByCode", tenant, code)
+	ret0, _ := ret[0].(*models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectByCode indicates an expected call of SelectByCode
+func (mr *MockPromoRepositoryMockRecorder) SelectByCode(tenant, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByCode", reflect.TypeOf((*MockPromoRepository)(nil).SelectByCode), tenant, code)
+}
+
+// SelectPromoCodes mocks base method
+func (m *MockPromoRepository) SelectPromoCodes(tenant uint64) ([]*models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectPromoCodes", tenant)
+	ret0, _ := ret[0].([]*models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectPromoCodes indicates an expected call of SelectPromoCodes
+func (mr *MockPromoRepositoryMockRecorder) SelectPromoCodes(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectPromoCodes", reflect.TypeOf((*MockPromoRepository)(nil).SelectPromoCodes), tenant)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_promo is a generated GoMock package.
+package mocks
+
+import (
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPromoUseCase is a mock of PromoUseCase interface
+type MockPromoUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromoUseCaseMockRecorder
+}
+
+// MockPromoUseCaseMockRecorder is the mock recorder for MockPromoUseCase
+type MockPromoUseCaseMockRecorder struct {
+	mock *MockPromoUseCase
+}
+
+// NewMockPromoUseCase creates a new mock instance
+func NewMockPromoUseCase(ctrl *gomock.Controller) *MockPromoUseCase {
+	mock := &MockPromoUseCase{ctrl: ctrl}
+	mock.recorder = &MockPromoUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPromoUseCase) EXPECT() *MockPromoUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreatePromoCode mocks base method
+func (m *MockPromoUseCase) CreatePromoCode(promo *models.PromoCode) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromoCode", promo)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// CreatePromoCode indicates an expected call of CreatePromoCode
+func (mr *MockPromoUseCaseMockRecorder) CreatePromoCode(promo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromoCode", reflect.TypeOf((*MockPromoUseCase)(nil).CreatePromoCode), promo)
+}
+
+// GetPromoCode mocks base method
+func (m *MockPromoUseCase) GetPromoCode(tenant uint64, code string) (*models.PromoCode, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoCode", tenant, code)
+	ret0, _ := ret[0].(*models.PromoCode)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetPromoCode indicates an expected call of GetPromoCode
+func (mr *MockPromoUseCaseMockRecorder) GetPromoCode(tenant, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoCode", reflect.TypeOf((*MockPromoUseCase)(nil).GetPromoCode), tenant, code)
+}
+
+// GetPromoCodes mocks base method
+func (m *MockPromoUseCase) GetPromoCodes(tenant uint64) ([]*models.PromoCode, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoCodes", tenant)
+	ret0, _ := ret[0].([]*models.PromoCode)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetPromoCodes indicates an expected call of GetPromoCodes
+func (mr *MockPromoUseCaseMockRecorder) GetPromoCodes(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoCodes", reflect.TypeOf((*MockPromoUseCase)(nil).GetPromoCodes), tenant)
+}
+
+// CheckPromoCode mocks base method
+func (m *MockPromoUseCase) CheckPromoCode(code string, room *models.Room, dateStart, dateEnd string) (*models.PromoCode, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPromoCode", code, room, dateStart, dateEnd)
+	ret0, _ := ret[0].(*models.PromoCode)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// CheckPromoCode indicates an expected call of CheckPromoCode
+func (mr *MockPromoUseCaseMockRecorder) CheckPromoCode(code, room, dateStart, dateEnd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPromoCode", reflect.TypeOf((*MockPromoUseCase)(nil).CheckPromoCode), code, room, dateStart, dateEnd)
+}
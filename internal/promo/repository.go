@@ -0,0 +1,18 @@
+package promo
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/models"
+)
+
+var (
+	ErrAlreadyExists = errors.New("promo code already exists")
+	ErrExhausted     = errors.New("promo code usage limit is reached")
+)
+
+// PromoRepository finds only the codes of the tenant
+type PromoRepository interface {
+	Insert(promo *models.PromoCode) error
+	SelectByCode(tenant uint64, code string) (*models.PromoCode, error)
+	SelectPromoCodes(tenant uint64) ([]*models.PromoCode, error)
+}
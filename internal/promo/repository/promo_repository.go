@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/promo"
+	"github.com/sirupsen/logrus"
+)
+
+type PromoRepository struct {
+	db *sql.DB
+}
+
+func NewPromoRepository(db *sql.DB) promo.PromoRepository {
+	return &PromoRepository{db: db}
+}
+
+// A code is used by every confirmed booking and unexpired hold made with it,
+// so cancelled bookings and expired holds give the use back
+const selectPromoCodes = `
+	SELECT p.code, p.discount_type, p.discount_value,
+		to_char(p.valid_from, 'YYYY-MM-DD'), to_char(p.valid_to, 'YYYY-MM-DD'),
+		p.usage_limit, (
+			SELECT count(*)
+			FROM bookings b
+			WHERE b.tenant=p.tenant AND b.promo_code=p.code
+				AND (b.status=$1 OR (b.status=$2 AND b.hold_expires > now()))),
+		COALESCE(p.room, 0), COALESCE(p.property, 0), p.min_nights, p.created, p.tenant
+	FROM promo_codes p`
+
+// Redeem makes sure that the code of the tenant still has uses left. The code
+// row stays locked until the end of the transaction inserting the booking, so
+// concurrent bookings can't exceed the usage limit
+func Redeem(tx *sql.Tx, tenant uint64, code string) error {
+	var usageLimit uint64
+	err := tx.QueryRow(`
+		SELECT usage_limit
+		FROM promo_codes
+		WHERE tenant=$1 AND code=$2
+		FOR UPDATE`, tenant, code).
+		Scan(&usageLimit)
+	if err != nil {
+		return err
+	}
+	if usageLimit == 0 {
+		return nil
+	}
+
+	var used uint64
+	err = tx.QueryRow(`
+		SELECT count(*)
+		FROM bookings
+		WHERE tenant=$1 AND promo_code=$2
+			AND (status=$3 OR (status=$4 AND hold_expires > now()))`,
+		tenant, code, models.BookingStatusConfirmed, models.BookingStatusHeld).
+		Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used >= usageLimit {
+		return promo.ErrExhausted
+	}
+	return nil
+}
+
+func (rep *PromoRepository) Insert(code *models.PromoCode) error {
+	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO promo_codes(code, discount_type, discount_value, valid_from, valid_to,
+			usage_limit, room, property, min_nights, created, tenant)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), NULLIF($8, 0), $9, $10, $11)
+		ON CONFLICT (tenant, code) DO NOTHING RETURNING code`,
+		code.Code, code.DiscountType, code.DiscountValue, code.ValidFrom, code.ValidTo,
+		code.UsageLimit, code.Room, code.Property, code.MinNights, code.Created, code.Tenant).
+		Scan(&code.Code)
+	if err == sql.ErrNoRows {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logrus.Info(rollbackErr)
+		}
+		return promo.ErrAlreadyExists
+	} else if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logrus.Info(rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromoCode(row scanner) (*models.PromoCode, error) {
+	code := &models.PromoCode{}
+	if err := row.Scan(&code.Code, &code.DiscountType, &code.DiscountValue,
+		&code.ValidFrom, &code.ValidTo, &code.UsageLimit, &code.Used,
+		&code.Room, &code.Property, &code.MinNights, &code.Created, &code.Tenant); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+func (rep *PromoRepository) SelectByCode(tenant uint64, code string) (*models.PromoCode, error) {
+	row := rep.db.QueryRow(selectPromoCodes+`
+		WHERE p.tenant=$3 AND p.code=$4`,
+		models.BookingStatusConfirmed, models.BookingStatusHeld, tenant, code)
+	return scanPromoCode(row)
+}
+
+func (rep *PromoRepository) SelectPromoCodes(tenant uint64) ([]*models.PromoCode, error) {
+	rows, err := rep.db.Query(selectPromoCodes+`
+		WHERE p.tenant=$3
+		ORDER BY p.created`,
+		models.BookingStatusConfirmed, models.BookingStatusHeld, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []*models.PromoCode
+	for rows.Next() {
+		code, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
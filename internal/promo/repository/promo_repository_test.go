@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/promo"
+	"github.com/booking_backend/internal/promo/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var promoCodeModel = &models.PromoCode{
+	Code:          "WINTER",
+	DiscountType:  models.DiscountTypePercent,
+	DiscountValue: 10,
+	ValidFrom:     "2022-01-01",
+	ValidTo:       "2022-02-28",
+	UsageLimit:    100,
+	Used:          3,
+	Property:      1,
+	MinNights:     2,
+	Created:       time.Date(2021, 12, 1, 12, 0, 0, 0, time.UTC),
+	Tenant:        models.DefaultTenantID,
+}
+
+func TestRedeem(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		usageLimit uint64
+		used       uint64
+		err        error
+	}{
+		{"unlimited", 0, 0, nil},
+		{"uses left", 5, 4, nil},
+		{"exhausted", 5, 5, promo.ErrExhausted},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mock.ExpectBegin()
+		mocks.MockRedeem(mock, promoCodeModel.Tenant, promoCodeModel.Code, test.usageLimit, test.used)
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = Redeem(tx, promoCodeModel.Tenant, promoCodeModel.Code)
+		assert.Equal(t, test.err, err, test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		db.Close()
+	}
+}
+
+func TestPromoRepository_Insert_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	promoPgRep := NewPromoRepository(db)
+	mocks.MockInsertAlreadyExists(mock, promoCodeModel)
+
+	err = promoPgRep.Insert(promoCodeModel)
+	assert.Equal(t, promo.ErrAlreadyExists, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoRepository_SelectByCode(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	promoPgRep := NewPromoRepository(db)
+	mocks.MockSelectByCode(mock, promoCodeModel)
+
+	promoCode, err := promoPgRep.SelectByCode(promoCodeModel.Tenant, promoCodeModel.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, promoCodeModel, promoCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,15 @@
+package promo
+
+import (
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type PromoUseCase interface {
+	CreatePromoCode(promo *models.PromoCode) *errors.Error
+	GetPromoCode(tenant uint64, code string) (*models.PromoCode, *errors.Error)
+	GetPromoCodes(tenant uint64) ([]*models.PromoCode, *errors.Error)
+	// The code is looked for among the codes of the tenant of the room
+	CheckPromoCode(code string, room *models.Room,
+		dateStart string, dateEnd string) (*models.PromoCode, *errors.Error)
+}
@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/dates"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/promo"
+	"time"
+)
+
+type PromoUseCase struct {
+	promoRepo promo.PromoRepository
+}
+
+func NewPromoUseCase(promoRepository promo.PromoRepository) promo.PromoUseCase {
+	return &PromoUseCase{promoRepo: promoRepository}
+}
+
+func (uc *PromoUseCase) CreatePromoCode(code *models.PromoCode) *errors.Error {
+	if err := dates.CheckDates(code.ValidFrom, code.ValidTo); err != nil {
+		return err
+	}
+
+	err := uc.promoRepo.Insert(code)
+	if err == promo.ErrAlreadyExists {
+		return errors.Get(consts.CodePromoCodeAlreadyExists)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+func (uc *PromoUseCase) GetPromoCode(tenant uint64, code string) (*models.PromoCode, *errors.Error) {
+	promoCode, err := uc.promoRepo.SelectByCode(tenant, code)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodePromoCodeDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return promoCode, nil
+}
+
+func (uc *PromoUseCase) GetPromoCodes(tenant uint64) ([]*models.PromoCode, *errors.Error) {
+	codes, err := uc.promoRepo.SelectPromoCodes(tenant)
+	if err == nil && codes == nil {
+		return []*models.PromoCode{}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return codes, nil
+}
+
+// CheckPromoCode tells whether the code can be applied to the stay today.
+// Uses left are checked once more when the booking is stored
+func (uc *PromoUseCase) CheckPromoCode(code string, room *models.Room,
+	dateStart string, dateEnd string) (*models.PromoCode, *errors.Error) {
+	promoCode, customErr := uc.GetPromoCode(room.Tenant, code)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	applicable, err := isApplicable(promoCode, room, dateStart, dateEnd, time.Now())
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	if !applicable {
+		return nil, errors.Get(consts.CodePromoCodeNotApplicable)
+	}
+
+	if promoCode.UsageLimit != 0 && promoCode.Used >= promoCode.UsageLimit {
+		return nil, errors.Get(consts.CodePromoCodeExhausted)
+	}
+	return promoCode, nil
+}
+
+func isApplicable(promoCode *models.PromoCode, room *models.Room,
+	dateStart string, dateEnd string, now time.Time) (bool, error) {
+	validFrom, err := dates.Parse(promoCode.ValidFrom)
+	if err != nil {
+		return false, err
+	}
+	validTo, err := dates.Parse(promoCode.ValidTo)
+	if err != nil {
+		return false, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if today.Before(validFrom) || today.After(validTo) {
+		return false, nil
+	}
+
+	if promoCode.Room != 0 && promoCode.Room != room.ID {
+		return false, nil
+	}
+	if promoCode.Property != 0 && promoCode.Property != room.Property {
+		return false, nil
+	}
+
+	nights, err := dates.Nights(dateStart, dateEnd)
+	if err != nil {
+		return false, err
+	}
+	return nights >= promoCode.MinNights, nil
+}
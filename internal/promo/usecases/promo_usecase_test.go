@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/promo"
+	"github.com/booking_backend/internal/promo/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var room = &models.Room{
+	ID:       2,
+	Price:    500,
+	Property: 1,
+	Tenant:   models.DefaultTenantID,
+}
+
+func newPromoCode() *models.PromoCode {
+	return &models.PromoCode{
+		Code:          "WINTER",
+		DiscountType:  models.DiscountTypePercent,
+		DiscountValue: 10,
+		ValidFrom:     "2022-01-01",
+		ValidTo:       "2022-02-28",
+		Property:      1,
+		MinNights:     2,
+	}
+}
+
+func TestIsApplicable(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2022, 1, 15, 18, 0, 0, 0, time.UTC)
+	otherRoom := newPromoCode()
+	otherRoom.Room = 3
+	otherProperty := newPromoCode()
+	otherProperty.Property = 2
+	tests := []struct {
+		name      string
+		promoCode *models.PromoCode
+		dateEnd   string
+		now       time.Time
+		want      bool
+	}{
+		{"applicable", newPromoCode(), "2022-01-04", now, true},
+		{"last valid day", newPromoCode(), "2022-01-04",
+			time.Date(2022, 2, 28, 23, 0, 0, 0, time.UTC), true},
+		{"not started", newPromoCode(), "2022-01-04",
+			time.Date(2021, 12, 31, 12, 0, 0, 0, time.UTC), false},
+		{"expired", newPromoCode(), "2022-01-04",
+			time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"too short", newPromoCode(), "2022-01-03", now, false},
+		{"other room", otherRoom, "2022-01-04", now, false},
+		{"other property", otherProperty, "2022-01-04", now, false},
+	}
+
+	for _, test := range tests {
+		applicable, err := isApplicable(test.promoCode, room, "2022-01-02", test.dateEnd, test.now)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.want, applicable, test.name)
+	}
+}
+
+func TestPromoUseCase_CheckPromoCode_DoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	promoRep := mocks.NewMockPromoRepository(ctrl)
+	promoUseCase := NewPromoUseCase(promoRep)
+
+	promoRep.
+		EXPECT().
+		SelectByCode(room.Tenant, "SPRING").
+		Return(nil, sql.ErrNoRows)
+
+	_, err := promoUseCase.CheckPromoCode("SPRING", room, "2022-01-02", "2022-01-04")
+	assert.Equal(t, errors.Get(consts.CodePromoCodeDoesNotExist), err)
+}
+
+func TestPromoUseCase_CheckPromoCode_Exhausted(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	promoRep := mocks.NewMockPromoRepository(ctrl)
+	promoUseCase := NewPromoUseCase(promoRep)
+	promoCode := newPromoCode()
+	promoCode.ValidTo = time.Now().AddDate(1, 0, 0).Format("2006-01-02")
+	promoCode.UsageLimit = 10
+	promoCode.Used = 10
+
+	promoRep.
+		EXPECT().
+		SelectByCode(room.Tenant, promoCode.Code).
+		Return(promoCode, nil)
+
+	_, err := promoUseCase.CheckPromoCode(promoCode.Code, room, "2022-01-02", "2022-01-04")
+	assert.Equal(t, errors.Get(consts.CodePromoCodeExhausted), err)
+}
+
+func TestPromoUseCase_CreatePromoCode_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	promoRep := mocks.NewMockPromoRepository(ctrl)
+	promoUseCase := NewPromoUseCase(promoRep)
+	promoCode := newPromoCode()
+
+	promoRep.
+		EXPECT().
+		Insert(promoCode).
+		Return(promo.ErrAlreadyExists)
+
+	err := promoUseCase.CreatePromoCode(promoCode)
+	assert.Equal(t, errors.Get(consts.CodePromoCodeAlreadyExists), err)
+}
+
+func TestPromoUseCase_CreatePromoCode_IncorrectDates(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	promoRep := mocks.NewMockPromoRepository(ctrl)
+	promoUseCase := NewPromoUseCase(promoRep)
+	promoCode := newPromoCode()
+	promoCode.ValidFrom, promoCode.ValidTo = promoCode.ValidTo, promoCode.ValidFrom
+
+	err := promoUseCase.CreatePromoCode(promoCode)
+	assert.Equal(t, errors.Get(consts.CodeIncorrectDates), err)
+}
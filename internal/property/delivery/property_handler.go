@@ -0,0 +1,115 @@
+package delivery
+
+import (
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/property"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/request_reader"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+type PropertyHandler struct {
+	propertyUseCase property.PropertyUseCase
+}
+
+func NewPropertyHandler(useCase property.PropertyUseCase) *PropertyHandler {
+	return &PropertyHandler{propertyUseCase: useCase}
+}
+
+func (ph *PropertyHandler) Configure(e *echo.Echo) {
+	e.POST("properties/create",
+		ph.CreateProperty(), principal.Require(rbac.ManageProperties))
+	e.GET("properties/:id/tax_rules",
+		ph.GetTaxRules(), principal.Require(rbac.ViewRooms))
+	e.PUT("properties/:id/tax_rules",
+		ph.SetTaxRules(), principal.Require(rbac.ManageProperties))
+}
+
+func (ph *PropertyHandler) CreateProperty() echo.HandlerFunc {
+	type Request struct {
+		Name     string `form:"name" validate:"required"`
+		TimeZone string `form:"time_zone"`
+	}
+
+	return func(context echo.Context) error {
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		property := &models.Property{Name: req.Name, TimeZone: req.TimeZone,
+			Tenant: principal.Tenant(context)}
+		if customErr := ph.propertyUseCase.CreateProperty(property); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusCreated, property)
+	}
+}
+
+func (ph *PropertyHandler) GetTaxRules() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		propertyID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		rules, customErr := ph.propertyUseCase.GetTaxRules(principal.Tenant(context), propertyID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, rules)
+	}
+}
+
+func (ph *PropertyHandler) SetTaxRules() echo.HandlerFunc {
+	type Request struct {
+		VATPercent  uint64 `form:"vat_percent" validate:"max=100"`
+		TaxMode     string `form:"tax_mode" validate:"required,oneof=inclusive exclusive"`
+		TouristTax  uint64 `form:"tourist_tax"`
+		CleaningFee uint64 `form:"cleaning_fee"`
+	}
+
+	return func(context echo.Context) error {
+		propertyID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		rules := &models.TaxRules{
+			Property:    propertyID,
+			VATPercent:  req.VATPercent,
+			TaxMode:     req.TaxMode,
+			TouristTax:  req.TouristTax,
+			CleaningFee: req.CleaningFee,
+		}
+
+		if customErr := ph.propertyUseCase.SetTaxRules(principal.Tenant(context), rules); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, rules)
+	}
+}
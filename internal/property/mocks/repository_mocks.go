@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mock_property is a generated GoMock package.
+package mocks
+
+import (
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPropertyRepository is a mock of PropertyRepository interface
+type MockPropertyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPropertyRepositoryMockRecorder
+}
+
+// MockPropertyRepositoryMockRecorder is the mock recorder for MockPropertyRepository
+type MockPropertyRepositoryMockRecorder struct {
+	mock *MockPropertyRepository
+}
+
+// NewMockPropertyRepository creates a new mock instance
+func NewMockPropertyRepository(ctrl *gomock.Controller) *MockPropertyRepository {
+	mock := &MockPropertyRepository{ctrl: ctrl}
+	mock.recorder = &MockPropertyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPropertyRepository) EXPECT() *MockPropertyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method
+func (m *MockPropertyRepository) Insert(property *models.Property) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", property)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert
+func (mr *MockPropertyRepositoryMockRecorder) Insert(property interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockPropertyRepository)(nil).Insert), property)
+}
+
+// SelectByID mocks base method
+func (m *MockPropertyRepository) SelectByID(tenant, id uint64) (*models.Property, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectByID", tenant, id)
+	ret0, _ := ret[0].(*models.Property)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectByID indicates an expected call of SelectByID
+func (mr *MockPropertyRepositoryMockRecorder) SelectByID(tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByID", reflect.TypeOf((*MockPropertyRepository)(nil).SelectByID), tenant, id)
+}
+
+// SelectTaxRules mocks base method
+func (m *MockPropertyRepository) SelectTaxRules(tenant, propertyID uint64) (*models.TaxRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectTaxRules", tenant, propertyID)
+	ret0, _ := ret[0].(*models.TaxRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectTaxRules indicates an expected call of SelectTaxRules
+func (mr *MockPropertyRepositoryMockRecorder) SelectTaxRules(tenant, propertyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectTaxRules", reflect.TypeOf((*MockPropertyRepository)(nil).SelectTaxRules), tenant, propertyID)
+}
+
+// SelectRoomTaxRules mocks base method
+func (m *MockPropertyRepository) SelectRoomTaxRules(roomID uint64) (*models.TaxRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectRoomTaxRules", roomID)
+	ret0, _ := ret[0].(*models.TaxRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectRoomTaxRules indicates an expected call of SelectRoomTaxRules
+func (mr *MockPropertyRepositoryMockRecorder) SelectRoomTaxRules(roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRoomTaxRules", reflect.TypeOf((*MockPropertyRepository)(nil).SelectRoomTaxRules), roomID)
+}
+
+// UpsertTaxRules mocks base method
+func (m *MockPropertyRepository) UpsertTaxRules(tenant uint64, rules *models.TaxRules) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTaxRules", tenant, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertTaxRules indicates an expected call of UpsertTaxRules
+func (mr *MockPropertyRepositoryMockRecorder) UpsertTaxRules(tenant, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTaxRules", reflect.TypeOf((*MockPropertyRepository)(nil).UpsertTaxRules), tenant, rules)
+}
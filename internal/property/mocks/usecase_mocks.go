@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_property is a generated GoMock package.
+package mocks
+
+import (
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPropertyUseCase is a mock of PropertyUseCase interface
+type MockPropertyUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPropertyUseCaseMockRecorder
+}
+
+// MockPropertyUseCaseMockRecorder is the mock recorder for MockPropertyUseCase
+type MockPropertyUseCaseMockRecorder struct {
+	mock *MockPropertyUseCase
+}
+
+// NewMockPropertyUseCase creates a new mock instance
+func NewMockPropertyUseCase(ctrl *gomock.Controller) *MockPropertyUseCase {
+	mock := &MockPropertyUseCase{ctrl: ctrl}
+	mock.recorder = &MockPropertyUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPropertyUseCase) EXPECT() *MockPropertyUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateProperty mocks base method
+func (m *MockPropertyUseCase) CreateProperty(property *models.Property) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProperty", property)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// CreateProperty indicates an expected call of CreateProperty
+func (mr *MockPropertyUseCaseMockRecorder) CreateProperty(property interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProperty", reflect.TypeOf((*MockPropertyUseCase)(nil).CreateProperty), property)
+}
+
+// GetProperty mocks base method
+func (m *MockPropertyUseCase) GetProperty(tenant, id uint64) (*models.Property, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProperty", tenant, id)
+	ret0, _ := ret[0].(*models.Property)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetProperty indicates an expected call of GetProperty
+func (mr *MockPropertyUseCaseMockRecorder) GetProperty(tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProperty", reflect.TypeOf((*MockPropertyUseCase)(nil).GetProperty), tenant, id)
+}
+
+// GetTaxRules mocks base method
+func (m *MockPropertyUseCase) GetTaxRules(tenant, propertyID uint64) (*models.TaxRules, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxRules", tenant, propertyID)
+	ret0, _ := ret[0].(*models.TaxRules)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetTaxRules indicates an expected call of GetTaxRules
+func (mr *MockPropertyUseCaseMockRecorder) GetTaxRules(tenant, propertyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxRules", reflect.TypeOf((*MockPropertyUseCase)(nil).GetTaxRules), tenant, propertyID)
+}
+
+// SetTaxRules mocks base method
+func (m *MockPropertyUseCase) SetTaxRules(tenant uint64, rules *models.TaxRules) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTaxRules", tenant, rules)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// SetTaxRules indicates an expected call of SetTaxRules
+func (mr *MockPropertyUseCaseMockRecorder) SetTaxRules(tenant, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTaxRules", reflect.TypeOf((*MockPropertyUseCase)(nil).SetTaxRules), tenant, rules)
+}
+
+// QuoteStay mocks base method
+func (m *MockPropertyUseCase) QuoteStay(room *models.Room, dateStart, dateEnd string, guests uint64, promo *models.PromoCode) (*models.Quote, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteStay", room, dateStart, dateEnd, guests, promo)
+	ret0, _ := ret[0].(*models.Quote)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// QuoteStay indicates an expected call of QuoteStay
+func (mr *MockPropertyUseCaseMockRecorder) QuoteStay(room, dateStart, dateEnd, guests, promo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteStay", reflect.TypeOf((*MockPropertyUseCase)(nil).QuoteStay), room, dateStart, dateEnd, guests, promo)
+}
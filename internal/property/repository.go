@@ -0,0 +1,14 @@
+package property
+
+import "github.com/booking_backend/internal/models"
+
+// PropertyRepository finds properties and their tax rules only within the
+// tenant, a property of another tenant is not found
+type PropertyRepository interface {
+	Insert(property *models.Property) error
+	SelectByID(tenant uint64, id uint64) (*models.Property, error)
+	SelectTaxRules(tenant uint64, propertyID uint64) (*models.TaxRules, error)
+	SelectRoomTaxRules(roomID uint64) (*models.TaxRules, error)
+	// No rows means the property is not found
+	UpsertTaxRules(tenant uint64, rules *models.TaxRules) error
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/property"
+	"github.com/sirupsen/logrus"
+)
+
+type PropertyRepository struct {
+	db *sql.DB
+}
+
+func NewPropertyRepository(db *sql.DB) property.PropertyRepository {
+	return &PropertyRepository{db: db}
+}
+
+func (rep *PropertyRepository) Insert(property *models.Property) error {
+	return rep.db.QueryRow(`
+		INSERT INTO properties(name, time_zone, tenant)
+		VALUES ($1, $2, $3) RETURNING id`,
+		property.Name, property.TimeZone, property.Tenant).
+		Scan(&property.ID)
+}
+
+func (rep *PropertyRepository) SelectByID(tenant uint64, id uint64) (*models.Property, error) {
+	property := &models.Property{}
+	err := rep.db.QueryRow(`
+		SELECT id, name, time_zone, tenant
+		FROM properties
+		WHERE id=$1 AND tenant=$2`, id, tenant).
+		Scan(&property.ID, &property.Name, &property.TimeZone, &property.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	return property, nil
+}
+
+func (rep *PropertyRepository) SelectTaxRules(tenant uint64, propertyID uint64) (*models.TaxRules, error) {
+	rules := &models.TaxRules{}
+	err := rep.db.QueryRow(`
+		SELECT t.property, t.vat_percent, t.tax_mode, t.tourist_tax, t.cleaning_fee
+		FROM tax_rules t
+		JOIN properties p ON p.id=t.property
+		WHERE t.property=$1 AND p.tenant=$2`, propertyID, tenant).
+		Scan(&rules.Property, &rules.VATPercent, &rules.TaxMode,
+			&rules.TouristTax, &rules.CleaningFee)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SelectRoomTaxRules returns the tax rules of the property the room belongs to
+func (rep *PropertyRepository) SelectRoomTaxRules(roomID uint64) (*models.TaxRules, error) {
+	rules := &models.TaxRules{}
+	err := rep.db.QueryRow(`
+		SELECT t.property, t.vat_percent, t.tax_mode, t.tourist_tax, t.cleaning_fee
+		FROM tax_rules t
+		JOIN rooms r ON r.property=t.property
+		WHERE r.id=$1`, roomID).
+		Scan(&rules.Property, &rules.VATPercent, &rules.TaxMode,
+			&rules.TouristTax, &rules.CleaningFee)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UpsertTaxRules writes the rules only when the property belongs to the tenant
+func (rep *PropertyRepository) UpsertTaxRules(tenant uint64, rules *models.TaxRules) error {
+	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO tax_rules(property, vat_percent, tax_mode, tourist_tax, cleaning_fee)
+		SELECT id, $2, $3, $4, $5
+		FROM properties
+		WHERE id=$1 AND tenant=$6
+		ON CONFLICT (property) DO UPDATE
+		SET vat_percent=excluded.vat_percent,
+			tax_mode=excluded.tax_mode,
+			tourist_tax=excluded.tourist_tax,
+			cleaning_fee=excluded.cleaning_fee`,
+		rules.Property, rules.VATPercent, rules.TaxMode, rules.TouristTax, rules.CleaningFee, tenant)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logrus.Info(rollbackErr)
+		}
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err == nil && affected == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logrus.Info(rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
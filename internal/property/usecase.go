@@ -0,0 +1,15 @@
+package property
+
+import (
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type PropertyUseCase interface {
+	CreateProperty(property *models.Property) *errors.Error
+	GetProperty(tenant uint64, id uint64) (*models.Property, *errors.Error)
+	GetTaxRules(tenant uint64, propertyID uint64) (*models.TaxRules, *errors.Error)
+	SetTaxRules(tenant uint64, rules *models.TaxRules) *errors.Error
+	QuoteStay(room *models.Room, dateStart string, dateEnd string,
+		guests uint64, promo *models.PromoCode) (*models.Quote, *errors.Error)
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/dates"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/pricing"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/property"
+)
+
+type PropertyUseCase struct {
+	propertyRepo property.PropertyRepository
+}
+
+func NewPropertyUseCase(propertyRepository property.PropertyRepository) property.PropertyUseCase {
+	return &PropertyUseCase{propertyRepo: propertyRepository}
+}
+
+// CreateProperty places the property in UTC unless a time zone is given
+func (uc *PropertyUseCase) CreateProperty(property *models.Property) *errors.Error {
+	if property.TimeZone == "" {
+		property.TimeZone = "UTC"
+	}
+	if _, err := property.Location(); err != nil {
+		return errors.New(consts.CodeBadRequest, err)
+	}
+
+	if err := uc.propertyRepo.Insert(property); err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+func (uc *PropertyUseCase) GetProperty(tenant uint64, id uint64) (*models.Property, *errors.Error) {
+	property, err := uc.propertyRepo.SelectByID(tenant, id)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodePropertyDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return property, nil
+}
+
+func (uc *PropertyUseCase) GetTaxRules(tenant uint64, propertyID uint64) (*models.TaxRules, *errors.Error) {
+	_, err := uc.propertyRepo.SelectByID(tenant, propertyID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodePropertyDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	rules, err := uc.propertyRepo.SelectTaxRules(tenant, propertyID)
+	if err == sql.ErrNoRows {
+		return &models.TaxRules{Property: propertyID, TaxMode: models.TaxModeExclusive}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return rules, nil
+}
+
+func (uc *PropertyUseCase) SetTaxRules(tenant uint64, rules *models.TaxRules) *errors.Error {
+	err := uc.propertyRepo.UpsertTaxRules(tenant, rules)
+	if err == sql.ErrNoRows {
+		return errors.Get(consts.CodePropertyDoesNotExist)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+// QuoteStay prices the stay in the room with the taxes and fees of its property.
+// Properties without tax rules charge the bare room price. When the number of
+// guests is not given, the room is priced for one. The promo code, if any,
+// must be checked by the caller
+func (uc *PropertyUseCase) QuoteStay(room *models.Room, dateStart string, dateEnd string,
+	guests uint64, promo *models.PromoCode) (*models.Quote, *errors.Error) {
+	if guests == 0 {
+		guests = 1
+	}
+
+	nights, err := dates.Nights(dateStart, dateEnd)
+	if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	rules, err := uc.propertyRepo.SelectRoomTaxRules(room.ID)
+	if err == sql.ErrNoRows {
+		rules = &models.TaxRules{TaxMode: models.TaxModeExclusive}
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	return pricing.Quote(room.Price, nights, guests, rules, promo), nil
+}
@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/property/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const tenantID = models.DefaultTenantID
+
+var defaultProperty = &models.Property{
+	ID:     models.DefaultPropertyID,
+	Name:   "default",
+	Tenant: tenantID,
+}
+
+var room = &models.Room{
+	ID:    2,
+	Price: 500,
+}
+
+func TestPropertyUseCase_GetTaxRules_Default(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	propertyRep := mocks.NewMockPropertyRepository(ctrl)
+	propertyUseCase := NewPropertyUseCase(propertyRep)
+
+	propertyRep.
+		EXPECT().
+		SelectByID(tenantID, defaultProperty.ID).
+		Return(defaultProperty, nil)
+	propertyRep.
+		EXPECT().
+		SelectTaxRules(tenantID, defaultProperty.ID).
+		Return(nil, sql.ErrNoRows)
+
+	rules, err := propertyUseCase.GetTaxRules(tenantID, defaultProperty.ID)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, &models.TaxRules{Property: defaultProperty.ID,
+		TaxMode: models.TaxModeExclusive}, rules)
+}
+
+func TestPropertyUseCase_SetTaxRules_PropertyDoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	propertyRep := mocks.NewMockPropertyRepository(ctrl)
+	propertyUseCase := NewPropertyUseCase(propertyRep)
+
+	propertyRep.
+		EXPECT().
+		UpsertTaxRules(tenantID, &models.TaxRules{Property: 5}).
+		Return(sql.ErrNoRows)
+
+	err := propertyUseCase.SetTaxRules(tenantID, &models.TaxRules{Property: 5})
+	assert.Equal(t, errors.Get(consts.CodePropertyDoesNotExist), err)
+}
+
+func TestPropertyUseCase_GetTaxRules_OtherTenant(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	propertyRep := mocks.NewMockPropertyRepository(ctrl)
+	propertyUseCase := NewPropertyUseCase(propertyRep)
+
+	// The property of another tenant is not found, so its rules are not read
+	const otherTenant uint64 = 2
+	propertyRep.
+		EXPECT().
+		SelectByID(otherTenant, defaultProperty.ID).
+		Return(nil, sql.ErrNoRows)
+
+	_, err := propertyUseCase.GetTaxRules(otherTenant, defaultProperty.ID)
+	assert.Equal(t, errors.Get(consts.CodePropertyDoesNotExist), err)
+}
+
+func TestPropertyUseCase_QuoteStay(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	propertyRep := mocks.NewMockPropertyRepository(ctrl)
+	propertyUseCase := NewPropertyUseCase(propertyRep)
+
+	propertyRep.
+		EXPECT().
+		SelectRoomTaxRules(room.ID).
+		Return(&models.TaxRules{
+			Property:    defaultProperty.ID,
+			VATPercent:  20,
+			TaxMode:     models.TaxModeInclusive,
+			TouristTax:  10,
+			CleaningFee: 100,
+		}, nil)
+
+	quote, err := propertyUseCase.QuoteStay(room, "2022-01-02", "2022-01-04", 0, nil)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, &models.Quote{
+		Nights:        2,
+		Guests:        1,
+		NightPrice:    500,
+		Accommodation: 1000,
+		CleaningFee:   100,
+		TouristTax:    20,
+		VATPercent:    20,
+		TaxMode:       models.TaxModeInclusive,
+		VAT:           183,
+		Total:         1120,
+	}, quote)
+}
+
+func TestPropertyUseCase_QuoteStay_NoTaxRules(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	propertyRep := mocks.NewMockPropertyRepository(ctrl)
+	propertyUseCase := NewPropertyUseCase(propertyRep)
+
+	propertyRep.
+		EXPECT().
+		SelectRoomTaxRules(room.ID).
+		Return(nil, sql.ErrNoRows)
+
+	quote, err := propertyUseCase.QuoteStay(room, "2022-01-02", "2022-01-04", 2, nil)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, uint64(1000), quote.Total)
+}
@@ -0,0 +1,249 @@
+package delivery
+
+import (
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/reservation"
+	"github.com/booking_backend/tools/etag"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/request_reader"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ReservationHandler struct {
+	reservationUseCase reservation.ReservationUseCase
+}
+
+func NewReservationHandler(useCase reservation.ReservationUseCase) *ReservationHandler {
+	return &ReservationHandler{reservationUseCase: useCase}
+}
+
+func (rh *ReservationHandler) Configure(e *echo.Echo) {
+	e.POST("reservations/create",
+		rh.CreateReservation(), principal.Require(rbac.ManageBookings))
+	e.GET("reservations/:id",
+		rh.GetReservation(), principal.Require(rbac.ViewBookings))
+	e.PUT("reservations/:id",
+		rh.RescheduleReservation(), principal.Require(rbac.ManageBookings))
+	e.PUT("reservations/:id/bookings/:booking_id",
+		rh.RescheduleReservationBooking(), principal.Require(rbac.ManageBookings))
+	e.DELETE("reservations/:id",
+		rh.CancelReservation(), principal.Require(rbac.ManageBookings))
+	e.DELETE("reservations/:id/bookings/:booking_id",
+		rh.CancelReservationBooking(), principal.Require(rbac.ManageBookings))
+}
+
+type Dates struct {
+	DateStart models.CustomDate `form:"date_start" validate:"required"`
+	DateEnd   models.CustomDate `form:"date_end" validate:"required"`
+}
+
+func (rh *ReservationHandler) CreateReservation() echo.HandlerFunc {
+	type Request struct {
+		RoomIDs []uint64 `form:"room_id" validate:"required,min=1,unique"`
+		// Guests is the number of guests in each room
+		Guests uint64 `form:"guests"`
+		Hold   bool   `form:"hold"`
+		// PaymentToken pays for every room of the reservation
+		PaymentToken string `form:"payment_token"`
+		Dates
+	}
+
+	return func(context echo.Context) error {
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		reservation := &models.Reservation{Created: time.Now()}
+		for _, roomID := range req.RoomIDs {
+			booking := &models.Booking{
+				DateStart: req.DateStart.Date,
+				DateEnd:   req.DateEnd.Date,
+				Room:      roomID,
+				Guests:    req.Guests,
+			}
+			if req.Hold {
+				booking.Status = models.BookingStatusHeld
+			}
+			reservation.Bookings = append(reservation.Bookings, booking)
+		}
+
+		if customErr := rh.reservationUseCase.CreateReservation(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), reservation,
+			req.PaymentToken); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusCreated, reservation)
+	}
+}
+
+func (rh *ReservationHandler) GetReservation() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		reservationID, customErr := parseID(context, "id")
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		reservation, customErr := rh.reservationUseCase.GetReservation(context.Request().Context(),
+			principal.Tenant(context), reservationID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		etag.Set(context, reservation.Version)
+		return context.JSON(http.StatusOK, reservation)
+	}
+}
+
+func (rh *ReservationHandler) RescheduleReservation() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		reservationID, customErr := parseID(context, "id")
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		req := &Dates{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		customErr = rh.reservationUseCase.RescheduleReservation(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), reservationID, version,
+			req.DateStart.Date, req.DateEnd.Date)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{Message: "success"})
+	}
+}
+
+func (rh *ReservationHandler) RescheduleReservationBooking() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		reservationID, customErr := parseID(context, "id")
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		bookingID, customErr := parseID(context, "booking_id")
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		req := &Dates{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		customErr = rh.reservationUseCase.RescheduleReservationBooking(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), reservationID, bookingID, version,
+			req.DateStart.Date, req.DateEnd.Date)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{Message: "success"})
+	}
+}
+
+func (rh *ReservationHandler) CancelReservation() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		reservationID, customErr := parseID(context, "id")
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		cancelled, customErr := rh.reservationUseCase.CancelReservation(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), reservationID, version)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{
+			Message: "success",
+			Body:    &response.Body{"bookings": cancelled},
+		})
+	}
+}
+
+func (rh *ReservationHandler) CancelReservationBooking() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		reservationID, customErr := parseID(context, "id")
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		bookingID, customErr := parseID(context, "booking_id")
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		cancelled, customErr := rh.reservationUseCase.CancelReservationBooking(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), reservationID, bookingID, version)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{
+			Message: "success",
+			Body: &response.Body{
+				"cancellation_fee": cancelled.CancellationFee,
+				"refund_status":    cancelled.RefundStatus,
+				"refund_amount":    cancelled.RefundAmount,
+			},
+		})
+	}
+}
+
+func parseID(context echo.Context, param string) (uint64, *errors.Error) {
+	id, parseErr := strconv.ParseUint(context.Param(param), 10, 64)
+	if parseErr != nil {
+		return 0, errors.New(CodeInternalError, parseErr)
+	}
+	return id, nil
+}
@@ -0,0 +1,111 @@
+package mocks
+
+import (
+	"database/sql"
+	auditMocks "github.com/booking_backend/internal/audit/mocks"
+	bookingMocks "github.com/booking_backend/internal/booking/mocks"
+	"github.com/booking_backend/internal/models"
+	outboxMocks "github.com/booking_backend/internal/outbox/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func MockInsertSuccess(mock sqlmock.Sqlmock, reservation *models.Reservation,
+	entries []*models.AuditEntry, events []*models.Event) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO reservations`).
+		WithArgs(reservation.Created).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).
+			AddRow(reservation.ID, reservation.Version))
+	for _, booking := range reservation.Bookings {
+		bookingMocks.MockCheckRoomIsFree(mock, booking, false)
+		mock.ExpectQuery(`INSERT INTO bookings`).
+			WithArgs(booking.DateStart, booking.DateEnd, booking.Room,
+				reservation.ID, booking.Status, booking.Guests,
+				booking.Amount, sqlmock.AnyArg(), booking.HoldExpires).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "version", "tenant"}).
+				AddRow(booking.ID, booking.Version, booking.Tenant))
+	}
+	for _, entry := range entries {
+		auditMocks.MockInsertEntry(mock, entry)
+	}
+	for _, event := range events {
+		outboxMocks.MockInsertEvent(mock, event)
+	}
+	mock.ExpectCommit()
+}
+
+func MockInsertBookingFails(mock sqlmock.Sqlmock, reservation *models.Reservation, err error) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO reservations`).
+		WithArgs(reservation.Created).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).
+			AddRow(reservation.ID, reservation.Version))
+	bookingMocks.MockCheckRoomIsFree(mock, reservation.Bookings[0], false)
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WillReturnError(err)
+	mock.ExpectRollback()
+}
+
+func MockInsertRoomIsOccupied(mock sqlmock.Sqlmock, reservation *models.Reservation) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO reservations`).
+		WithArgs(reservation.Created).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).
+			AddRow(reservation.ID, reservation.Version))
+	bookingMocks.MockCheckRoomIsFree(mock, reservation.Bookings[0], false)
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "tenant"}).
+			AddRow(reservation.Bookings[0].ID, reservation.Bookings[0].Version,
+				reservation.Bookings[0].Tenant))
+	bookingMocks.MockCheckRoomIsFree(mock, reservation.Bookings[1], true)
+	mock.ExpectRollback()
+}
+
+func MockSelectReturnRows(mock sqlmock.Sqlmock, tenant uint64, reservation *models.Reservation) {
+	mock.ExpectQuery(`SELECT (.+) FROM reservations`).
+		WithArgs(reservation.ID, tenant).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created", "version"}).
+			AddRow(reservation.ID, reservation.Created, reservation.Version))
+
+	rows := sqlmock.NewRows([]string{"id", "date_start", "date_end", "room", "status", "guests",
+		"amount", "version"})
+	for _, booking := range reservation.Bookings {
+		rows.AddRow(booking.ID, booking.DateStart, booking.DateEnd,
+			booking.Room, booking.Status, booking.Guests, booking.Amount, booking.Version)
+	}
+	mock.ExpectQuery(`SELECT (.+) FROM bookings`).
+		WithArgs(reservation.ID, tenant).
+		WillReturnRows(rows)
+}
+
+func MockSelectReturnErrNoRows(mock sqlmock.Sqlmock, tenant uint64, id uint64) {
+	mock.ExpectQuery(`SELECT (.+) FROM reservations`).
+		WithArgs(id, tenant).
+		WillReturnError(sql.ErrNoRows)
+}
+
+// MockUpdateDatesSuccess expects the rooms of the lines to be locked in the order given
+func MockUpdateDatesSuccess(mock sqlmock.Sqlmock, id uint64, version uint64, locked []*models.Booking,
+	lines []*models.Booking, entries []*models.AuditEntry, events []*models.Event) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE reservations`).
+		WithArgs(id, version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, line := range locked {
+		bookingMocks.MockCheckRoomIsFree(mock, line, false)
+	}
+	for _, line := range lines {
+		mock.ExpectExec(`UPDATE bookings SET date_start=\$1, date_end=\$2, amount=\$3, quote=\$4`).
+			WithArgs(line.DateStart, line.DateEnd, line.Amount, sqlmock.AnyArg(), line.ID, id,
+				models.AnyVersion, models.BookingStatusCancelled).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	for _, entry := range entries {
+		auditMocks.MockInsertEntry(mock, entry)
+	}
+	for _, event := range events {
+		outboxMocks.MockInsertEvent(mock, event)
+	}
+	mock.ExpectCommit()
+}
@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mock_reservation is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockReservationRepository is a mock of ReservationRepository interface
+type MockReservationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReservationRepositoryMockRecorder
+}
+
+// MockReservationRepositoryMockRecorder is the mock recorder for MockReservationRepository
+type MockReservationRepositoryMockRecorder struct {
+	mock *MockReservationRepository
+}
+
+// NewMockReservationRepository creates a new mock instance
+func NewMockReservationRepository(ctrl *gomock.Controller) *MockReservationRepository {
+	mock := &MockReservationRepository{ctrl: ctrl}
+	mock.recorder = &MockReservationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReservationRepository) EXPECT() *MockReservationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method
+func (m *MockReservationRepository) Insert(ctx context.Context, reservation *models.Reservation, entries []*models.AuditEntry, events []*models.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, reservation, entries, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert
+func (mr *MockReservationRepositoryMockRecorder) Insert(ctx, reservation, entries, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockReservationRepository)(nil).Insert), ctx, reservation, entries, events)
+}
+
+// SelectByID mocks base method
+func (m *MockReservationRepository) SelectByID(ctx context.Context, tenant, id uint64) (*models.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectByID", ctx, tenant, id)
+	ret0, _ := ret[0].(*models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectByID indicates an expected call of SelectByID
+func (mr *MockReservationRepositoryMockRecorder) SelectByID(ctx, tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByID", reflect.TypeOf((*MockReservationRepository)(nil).SelectByID), ctx, tenant, id)
+}
+
+// UpdateDates mocks base method
+func (m *MockReservationRepository) UpdateDates(ctx context.Context, id, version uint64, lines []*models.Booking, entries []*models.AuditEntry, events []*models.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDates", ctx, id, version, lines, entries, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDates indicates an expected call of UpdateDates
+func (mr *MockReservationRepositoryMockRecorder) UpdateDates(ctx, id, version, lines, entries, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDates", reflect.TypeOf((*MockReservationRepository)(nil).UpdateDates), ctx, id, version, lines, entries, events)
+}
+
+// UpdateBookingDates mocks base method
+func (m *MockReservationRepository) UpdateBookingDates(ctx context.Context, id uint64, line *models.Booking, version uint64, entries []*models.AuditEntry, events []*models.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBookingDates", ctx, id, line, version, entries, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBookingDates indicates an expected call of UpdateBookingDates
+func (mr *MockReservationRepositoryMockRecorder) UpdateBookingDates(ctx, id, line, version, entries, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookingDates", reflect.TypeOf((*MockReservationRepository)(nil).UpdateBookingDates), ctx, id, line, version, entries, events)
+}
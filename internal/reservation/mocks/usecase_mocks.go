@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_reservation is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockReservationUseCase is a mock of ReservationUseCase interface
+type MockReservationUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockReservationUseCaseMockRecorder
+}
+
+// MockReservationUseCaseMockRecorder is the mock recorder for MockReservationUseCase
+type MockReservationUseCaseMockRecorder struct {
+	mock *MockReservationUseCase
+}
+
+// NewMockReservationUseCase creates a new mock instance
+func NewMockReservationUseCase(ctrl *gomock.Controller) *MockReservationUseCase {
+	mock := &MockReservationUseCase{ctrl: ctrl}
+	mock.recorder = &MockReservationUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReservationUseCase) EXPECT() *MockReservationUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateReservation mocks base method
+func (m *MockReservationUseCase) CreateReservation(ctx context.Context, tenant uint64, actor *models.Actor, reservation *models.Reservation, paymentToken string) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReservation", ctx, tenant, actor, reservation, paymentToken)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// CreateReservation indicates an expected call of CreateReservation
+func (mr *MockReservationUseCaseMockRecorder) CreateReservation(ctx, tenant, actor, reservation, paymentToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReservation", reflect.TypeOf((*MockReservationUseCase)(nil).CreateReservation), ctx, tenant, actor, reservation, paymentToken)
+}
+
+// GetReservation mocks base method
+func (m *MockReservationUseCase) GetReservation(ctx context.Context, tenant, id uint64) (*models.Reservation, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservation", ctx, tenant, id)
+	ret0, _ := ret[0].(*models.Reservation)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetReservation indicates an expected call of GetReservation
+func (mr *MockReservationUseCaseMockRecorder) GetReservation(ctx, tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservation", reflect.TypeOf((*MockReservationUseCase)(nil).GetReservation), ctx, tenant, id)
+}
+
+// CancelReservation mocks base method
+func (m *MockReservationUseCase) CancelReservation(ctx context.Context, tenant uint64, actor *models.Actor, id, version uint64) ([]*models.Booking, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReservation", ctx, tenant, actor, id, version)
+	ret0, _ := ret[0].([]*models.Booking)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// CancelReservation indicates an expected call of CancelReservation
+func (mr *MockReservationUseCaseMockRecorder) CancelReservation(ctx, tenant, actor, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReservation", reflect.TypeOf((*MockReservationUseCase)(nil).CancelReservation), ctx, tenant, actor, id, version)
+}
+
+// CancelReservationBooking mocks base method
+func (m *MockReservationUseCase) CancelReservationBooking(ctx context.Context, tenant uint64, actor *models.Actor, id, bookingID, version uint64) (*models.Booking, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReservationBooking", ctx, tenant, actor, id, bookingID, version)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// CancelReservationBooking indicates an expected call of CancelReservationBooking
+func (mr *MockReservationUseCaseMockRecorder) CancelReservationBooking(ctx, tenant, actor, id, bookingID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReservationBooking", reflect.TypeOf((*MockReservationUseCase)(nil).CancelReservationBooking), ctx, tenant, actor, id, bookingID, version)
+}
+
+// RescheduleReservation mocks base method
+func (m *MockReservationUseCase) RescheduleReservation(ctx context.Context, tenant uint64, actor *models.Actor, id, version uint64, dateStart, dateEnd string) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleReservation", ctx, tenant, actor, id, version, dateStart, dateEnd)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// RescheduleReservation indicates an expected call of RescheduleReservation
+func (mr *MockReservationUseCaseMockRecorder) RescheduleReservation(ctx, tenant, actor, id, version, dateStart, dateEnd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleReservation", reflect.TypeOf((*MockReservationUseCase)(nil).RescheduleReservation), ctx, tenant, actor, id, version, dateStart, dateEnd)
+}
+
+// RescheduleReservationBooking mocks base method
+func (m *MockReservationUseCase) RescheduleReservationBooking(ctx context.Context, tenant uint64, actor *models.Actor, id, bookingID, version uint64, dateStart, dateEnd string) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleReservationBooking", ctx, tenant, actor, id, bookingID, version, dateStart, dateEnd)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// RescheduleReservationBooking indicates an expected call of RescheduleReservationBooking
+func (mr *MockReservationUseCaseMockRecorder) RescheduleReservationBooking(ctx, tenant, actor, id, bookingID, version, dateStart, dateEnd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleReservationBooking", reflect.TypeOf((*MockReservationUseCase)(nil).RescheduleReservationBooking), ctx, tenant, actor, id, bookingID, version, dateStart, dateEnd)
+}
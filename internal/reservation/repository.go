@@ -0,0 +1,23 @@
+package reservation
+
+import (
+	"context"
+	"github.com/booking_backend/internal/models"
+)
+
+// ReservationRepository writes the audit entries and the events of every
+// change in the transaction of the change
+type ReservationRepository interface {
+	// The entries follow the bookings of the reservation, they are given the
+	// ids of the bookings once inserted
+	Insert(ctx context.Context, reservation *models.Reservation, entries []*models.AuditEntry,
+		events []*models.Event) error
+	SelectByID(ctx context.Context, tenant uint64, id uint64) (*models.Reservation, error)
+	// The lines are stored with their dates and prices. The reservation is
+	// changed as a whole only in the version, lines of it only in the version
+	// of the line. Cancelled lines are left as they are
+	UpdateDates(ctx context.Context, id uint64, version uint64, lines []*models.Booking,
+		entries []*models.AuditEntry, events []*models.Event) error
+	UpdateBookingDates(ctx context.Context, id uint64, line *models.Booking, version uint64,
+		entries []*models.AuditEntry, events []*models.Event) error
+}
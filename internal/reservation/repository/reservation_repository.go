@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	auditRepository "github.com/booking_backend/internal/audit/repository"
+	"github.com/booking_backend/internal/booking"
+	bookingRepository "github.com/booking_backend/internal/booking/repository"
+	"github.com/booking_backend/internal/helpers/query"
+	"github.com/booking_backend/internal/helpers/transaction"
+	"github.com/booking_backend/internal/models"
+	outboxRepository "github.com/booking_backend/internal/outbox/repository"
+	"github.com/booking_backend/internal/reservation"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"time"
+)
+
+type ReservationRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewReservationRepository bounds every call of the repository with the timeout
+func NewReservationRepository(db *sql.DB, timeout time.Duration) reservation.ReservationRepository {
+	return &ReservationRepository{db: db, timeout: timeout}
+}
+
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if rollbackErr := transaction.Rollback(ctx, tx); rollbackErr != nil {
+		logrus.Info(rollbackErr)
+	}
+}
+
+// commit writes the audit entries and the events of the change made within
+// the transaction and commits them all
+func commit(ctx context.Context, tx *sql.Tx, entries []*models.AuditEntry, events []*models.Event) error {
+	for _, entry := range entries {
+		if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+	}
+	for _, event := range events {
+		if err := outboxRepository.InsertEvent(ctx, tx, event); err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+	}
+	return transaction.Commit(ctx, tx)
+}
+
+// Insert creates the reservation and all of its bookings in one transaction,
+// so either every room is held or none of them is
+func (rep *ReservationRepository) Insert(ctx context.Context, reservation *models.Reservation,
+	entries []*models.AuditEntry, events []*models.Event) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO reservations(created)
+		VALUES ($1) RETURNING id, version`,
+		reservation.Created).
+		Scan(&reservation.ID, &reservation.Version)
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	for i, booking := range reservation.Bookings {
+		err = bookingRepository.CheckRoomIsFree(ctx, tx, booking.Room,
+			booking.DateStart, booking.DateEnd, 0)
+		if err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+
+		quote, err := bookingRepository.EncodeQuote(booking.Quote)
+		if err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+
+		booking.Reservation = reservation.ID
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO bookings(date_start, date_end, room, reservation, status,
+				guests, amount, quote, hold_expires, tenant)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9,
+				(SELECT tenant FROM rooms WHERE id=$3)) RETURNING id, version, tenant`,
+			booking.DateStart, booking.DateEnd, booking.Room, booking.Reservation,
+			booking.Status, booking.Guests, booking.Amount, quote, booking.HoldExpires).
+			Scan(&booking.ID, &booking.Version, &booking.Tenant)
+		if err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+		entries[i].EntityID = booking.ID
+	}
+
+	return commit(ctx, tx, entries, events)
+}
+
+// SelectByID finds the reservation by the tenant of its bookings
+func (rep *ReservationRepository) SelectByID(ctx context.Context, tenant uint64,
+	id uint64) (*models.Reservation, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	conn := transaction.Querier(ctx, rep.db)
+	reservation := &models.Reservation{}
+	err := conn.QueryRowContext(ctx, `
+		SELECT id, created, version
+		FROM reservations
+		WHERE id=$1 AND EXISTS(
+			SELECT 1 FROM bookings WHERE reservation=$1 AND tenant=$2 AND deleted_at IS NULL)`, id, tenant).
+		Scan(&reservation.ID, &reservation.Created, &reservation.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, `
+		SELECT id, date_start, date_end, room, status, guests, amount, version
+		FROM bookings
+		WHERE reservation=$1 AND tenant=$2 AND deleted_at IS NULL
+		ORDER BY id`, id, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservation.Bookings = []*models.Booking{}
+	for rows.Next() {
+		booking := &models.Booking{Reservation: reservation.ID, Tenant: tenant}
+		if err := rows.Scan(&booking.ID, &booking.DateStart,
+			&booking.DateEnd, &booking.Room, &booking.Status, &booking.Guests, &booking.Amount,
+			&booking.Version); err != nil {
+			return nil, err
+		}
+		reservation.Bookings = append(reservation.Bookings, booking)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// touch moves the reservation to the next version along with its line
+func touch(ctx context.Context, tx *sql.Tx, id uint64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE reservations
+		SET version=version+1
+		WHERE id=$1`, id)
+	return err
+}
+
+// checkVersion tells the reservation or its line changed since it was read
+// from the one found
+func checkVersion(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return booking.ErrVersionMismatch
+	}
+	return nil
+}
+
+// moveLine stores the dates and the price of the line in the version,
+// AnyVersion matches any. Cancelled lines are never moved
+func moveLine(ctx context.Context, tx *sql.Tx, id uint64, line *models.Booking, version uint64) error {
+	quote, err := bookingRepository.EncodeQuote(line.Quote)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE bookings
+		SET date_start=$1, date_end=$2, amount=$3, quote=$4, version=version+1
+		WHERE id=$5 AND reservation=$6 AND ($7=0 OR version=$7) AND status<>$8 AND deleted_at IS NULL`,
+		line.DateStart, line.DateEnd, line.Amount, quote, line.ID, id, version,
+		models.BookingStatusCancelled)
+	if err != nil {
+		return err
+	}
+	return checkVersion(res)
+}
+
+// UpdateDates locks the rooms in the order of their ids, so the changes of
+// the reservations sharing rooms don't deadlock. The lines are moved in the
+// version of the reservation, every change of a line changes it as well
+func (rep *ReservationRepository) UpdateDates(ctx context.Context, id uint64, version uint64,
+	lines []*models.Booking, entries []*models.AuditEntry, events []*models.Event) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE reservations
+		SET version=version+1
+		WHERE id=$1 AND ($2=0 OR version=$2)`, id, version)
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if err = checkVersion(res); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	byRoom := append([]*models.Booking{}, lines...)
+	sort.Slice(byRoom, func(i, j int) bool {
+		return byRoom[i].Room < byRoom[j].Room
+	})
+	for _, line := range byRoom {
+		err = bookingRepository.CheckRoomIsFree(ctx, tx, line.Room,
+			line.DateStart, line.DateEnd, line.ID)
+		if err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+	}
+
+	for _, line := range lines {
+		if err = moveLine(ctx, tx, id, line, models.AnyVersion); err != nil {
+			rollback(ctx, tx)
+			return err
+		}
+	}
+
+	return commit(ctx, tx, entries, events)
+}
+
+// UpdateBookingDates moves one line of the reservation in the version
+func (rep *ReservationRepository) UpdateBookingDates(ctx context.Context, id uint64,
+	line *models.Booking, version uint64, entries []*models.AuditEntry, events []*models.Event) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return err
+	}
+
+	err = bookingRepository.CheckRoomIsFree(ctx, tx, line.Room, line.DateStart, line.DateEnd, line.ID)
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if err = moveLine(ctx, tx, id, line, version); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if err = touch(ctx, tx, id); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	return commit(ctx, tx, entries, events)
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/helpers/query"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/reservation/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var ctx = context.Background()
+
+func newReservationModel() *models.Reservation {
+	return &models.Reservation{
+		ID:      1,
+		Created: time.Date(2021, 1, 8, 19, 37, 51, 0, time.UTC),
+		Version: 1,
+		Bookings: []*models.Booking{
+			&models.Booking{
+				ID:        10,
+				DateStart: "2021-12-10",
+				DateEnd:   "2021-12-15",
+				Room:      1,
+			},
+			&models.Booking{
+				ID:        11,
+				DateStart: "2021-12-10",
+				DateEnd:   "2021-12-15",
+				Room:      2,
+			},
+		},
+	}
+}
+
+func TestReservationRepository_Insert(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	reservationRep := NewReservationRepository(db, query.DefaultTimeout)
+	reservationModel := newReservationModel()
+
+	actor := &models.Actor{Subject: "manager@hotel", RequestID: "request"}
+	entries := make([]*models.AuditEntry, len(reservationModel.Bookings))
+	events := make([]*models.Event, len(reservationModel.Bookings))
+	for i, booking := range reservationModel.Bookings {
+		entries[i] = actor.Entry(models.DefaultTenantID, models.AuditActionCreate,
+			models.AuditEntityBooking, booking.ID, nil, booking)
+		events[i] = models.NewEvent(models.DefaultTenantID, models.EventBookingCreated, booking)
+	}
+	mocks.MockInsertSuccess(mock, reservationModel, entries, events)
+	err = reservationRep.Insert(ctx, reservationModel, entries, events)
+
+	assert.NoError(t, err)
+	for i, booking := range reservationModel.Bookings {
+		assert.Equal(t, reservationModel.ID, booking.Reservation)
+		assert.Equal(t, booking.ID, entries[i].EntityID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReservationRepository_Insert_RollbackOnBookingError(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	reservationRep := NewReservationRepository(db, query.DefaultTimeout)
+	reservationModel := newReservationModel()
+
+	mocks.MockInsertBookingFails(mock, reservationModel, sql.ErrConnDone)
+	err = reservationRep.Insert(ctx, reservationModel, nil, nil)
+
+	assert.Equal(t, sql.ErrConnDone, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReservationRepository_SelectByID(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	reservationRep := NewReservationRepository(db, query.DefaultTimeout)
+	reservationModel := newReservationModel()
+	for _, booking := range reservationModel.Bookings {
+		booking.Reservation = reservationModel.ID
+		booking.Tenant = models.DefaultTenantID
+	}
+
+	mocks.MockSelectReturnRows(mock, models.DefaultTenantID, reservationModel)
+	resultReservation, err := reservationRep.SelectByID(ctx, models.DefaultTenantID, reservationModel.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reservationModel, resultReservation)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReservationRepository_SelectByID_OtherTenant(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	reservationRep := NewReservationRepository(db, query.DefaultTimeout)
+
+	mocks.MockSelectReturnErrNoRows(mock, 2, 1)
+	_, err = reservationRep.SelectByID(ctx, 2, 1)
+
+	assert.Equal(t, sql.ErrNoRows, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReservationRepository_Insert_RoomIsOccupied(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	reservationRep := NewReservationRepository(db, query.DefaultTimeout)
+	reservationModel := newReservationModel()
+
+	mocks.MockInsertRoomIsOccupied(mock, reservationModel)
+	entries := []*models.AuditEntry{{}, {}}
+	err = reservationRep.Insert(ctx, reservationModel, entries, nil)
+
+	assert.Equal(t, booking.ErrRoomIsOccupied, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReservationRepository_UpdateDates_LocksRoomsInOrder(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	reservationRep := NewReservationRepository(db, query.DefaultTimeout)
+	lines := []*models.Booking{
+		{ID: 10, DateStart: "2022-02-01", DateEnd: "2022-02-03", Room: 7, Amount: 1400},
+		{ID: 11, DateStart: "2022-02-01", DateEnd: "2022-02-03", Room: 3, Amount: 1000},
+	}
+
+	mocks.MockUpdateDatesSuccess(mock, 1, 4, []*models.Booking{lines[1], lines[0]}, lines, nil, nil)
+	err = reservationRep.UpdateDates(ctx, 1, 4, lines, nil, nil)
+
+	assert.NoError(t, err)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
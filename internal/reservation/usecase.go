@@ -0,0 +1,26 @@
+package reservation
+
+import (
+	"context"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+)
+
+type ReservationUseCase interface {
+	// The reservation is held only when its bookings are given in the held status
+	CreateReservation(ctx context.Context, tenant uint64, actor *models.Actor,
+		reservation *models.Reservation, paymentToken string) *errors.Error
+	GetReservation(ctx context.Context, tenant uint64, id uint64) (*models.Reservation, *errors.Error)
+	// The reservation is changed only in the version, AnyVersion matches any.
+	// The cancelled lines are returned with their fees and refunds
+	CancelReservation(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		version uint64) ([]*models.Booking, *errors.Error)
+	// The line of the reservation is changed only in the version, AnyVersion matches any
+	CancelReservationBooking(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		bookingID uint64, version uint64) (*models.Booking, *errors.Error)
+	// The rescheduled lines are priced anew for the dates
+	RescheduleReservation(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		version uint64, dateStart string, dateEnd string) *errors.Error
+	RescheduleReservationBooking(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		bookingID uint64, version uint64, dateStart string, dateEnd string) *errors.Error
+}
@@ -0,0 +1,293 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/booking"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/dates"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/transaction"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/property"
+	"github.com/booking_backend/internal/reservation"
+	"github.com/booking_backend/internal/room"
+	"time"
+)
+
+type ReservationUseCase struct {
+	transactions    transaction.Manager
+	reservationRepo reservation.ReservationRepository
+	roomRepo        room.RoomRepository
+	propertyUseCase property.PropertyUseCase
+	bookingUseCase  booking.BookingUseCase
+	holdTTL         time.Duration
+}
+
+// NewReservationUseCase confirms and cancels the lines of reservations like
+// any other booking: through the payment, with the fee of the cancellation
+// policy and the refund
+func NewReservationUseCase(transactions transaction.Manager,
+	reservationRepository reservation.ReservationRepository,
+	roomRepository room.RoomRepository, propertyUseCase property.PropertyUseCase,
+	bookingUseCase booking.BookingUseCase, holdTTL time.Duration) reservation.ReservationUseCase {
+	return &ReservationUseCase{transactions: transactions, reservationRepo: reservationRepository,
+		roomRepo: roomRepository, propertyUseCase: propertyUseCase, bookingUseCase: bookingUseCase,
+		holdTTL: holdTTL}
+}
+
+// CreateReservation holds every room of the reservation. Unless the caller
+// asked only for holds, all of them are confirmed right away with the given
+// payment token
+func (uc *ReservationUseCase) CreateReservation(ctx context.Context, tenant uint64, actor *models.Actor,
+	reservation *models.Reservation, paymentToken string) *errors.Error {
+	holdOnly := len(reservation.Bookings) > 0 &&
+		reservation.Bookings[0].Status == models.BookingStatusHeld
+	holdExpires := time.Now().Add(uc.holdTTL)
+	for _, booking := range reservation.Bookings {
+		if err := dates.CheckDates(booking.DateStart, booking.DateEnd); err != nil {
+			return err
+		}
+
+		room, err := uc.roomRepo.SelectByID(ctx, tenant, booking.Room)
+		if err == sql.ErrNoRows {
+			return errors.Get(consts.CodeRoomDoesNotExist)
+		} else if err != nil {
+			return errors.New(consts.CodeInternalError, err)
+		}
+
+		quote, customErr := uc.propertyUseCase.QuoteStay(room,
+			booking.DateStart, booking.DateEnd, booking.Guests, nil)
+		if customErr != nil {
+			return customErr
+		}
+		booking.Quote = quote
+		booking.Guests = quote.Guests
+		booking.Amount = quote.Total
+		booking.Status = models.BookingStatusHeld
+		booking.HoldExpires = &holdExpires
+	}
+
+	entries := make([]*models.AuditEntry, len(reservation.Bookings))
+	for i, booking := range reservation.Bookings {
+		entries[i] = actor.Entry(tenant, models.AuditActionCreate, models.AuditEntityBooking,
+			0, nil, booking)
+	}
+
+	// The bookings paid right away are announced once they are confirmed
+	var events []*models.Event
+	if holdOnly {
+		events = bookingEvents(tenant, models.EventBookingCreated, reservation.Bookings)
+	}
+	err := uc.reservationRepo.Insert(ctx, reservation, entries, events)
+	if err != nil {
+		return writeError(err)
+	}
+	if holdOnly {
+		return nil
+	}
+
+	return uc.bookingUseCase.ConfirmHolds(ctx, actor, reservation.Bookings, paymentToken)
+}
+
+func writeError(err error) *errors.Error {
+	switch err {
+	case sql.ErrNoRows:
+		return errors.Get(consts.CodeRoomDoesNotExist)
+	case booking.ErrRoomIsOccupied:
+		return errors.Get(consts.CodeRoomIsOccupied)
+	case booking.ErrVersionMismatch:
+		return errors.Get(consts.CodeVersionMismatch)
+	default:
+		return errors.New(consts.CodeInternalError, err)
+	}
+}
+
+func (uc *ReservationUseCase) GetReservation(ctx context.Context, tenant uint64,
+	id uint64) (*models.Reservation, *errors.Error) {
+	reservation, err := uc.reservationRepo.SelectByID(ctx, tenant, id)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeReservationDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return reservation, nil
+}
+
+// reschedule moves the lines to the dates and prices them anew by the rooms
+// and the tax rules in force, the audit entries of the change keep the lines
+// as they were
+func (uc *ReservationUseCase) reschedule(ctx context.Context, tenant uint64, actor *models.Actor,
+	lines []*models.Booking, dateStart string, dateEnd string) ([]*models.AuditEntry, *errors.Error) {
+	entries := make([]*models.AuditEntry, len(lines))
+	for i, line := range lines {
+		room, err := uc.roomRepo.SelectByID(ctx, tenant, line.Room)
+		if err == sql.ErrNoRows {
+			return nil, errors.Get(consts.CodeRoomDoesNotExist)
+		} else if err != nil {
+			return nil, errors.New(consts.CodeInternalError, err)
+		}
+		quote, customErr := uc.propertyUseCase.QuoteStay(room, dateStart, dateEnd, line.Guests, nil)
+		if customErr != nil {
+			return nil, customErr
+		}
+
+		before := *line
+		line.DateStart, line.DateEnd = dateStart, dateEnd
+		line.Quote = quote
+		line.Amount = quote.Total
+		line.Version++
+		entries[i] = actor.Entry(tenant, models.AuditActionUpdate, models.AuditEntityBooking,
+			line.ID, &before, line)
+	}
+	return entries, nil
+}
+
+// bookingEvents announces the same change of every booking of the reservation
+func bookingEvents(tenant uint64, eventType string, bookings []*models.Booking) []*models.Event {
+	events := make([]*models.Event, len(bookings))
+	for i, booking := range bookings {
+		events[i] = models.NewEvent(tenant, eventType, booking)
+	}
+	return events
+}
+
+// CancelReservation cancels the lines which are not cancelled yet. Every line
+// is cancelled in the version read along with the reservation, and every
+// change of a line moves the reservation to the next version, so the lines
+// are cancelled only while the reservation is in the version
+func (uc *ReservationUseCase) CancelReservation(ctx context.Context, tenant uint64,
+	actor *models.Actor, id uint64, version uint64) ([]*models.Booking, *errors.Error) {
+	reservation, customErr := uc.selectVersion(ctx, tenant, id, version)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	var active []*models.Booking
+	for _, booking := range reservation.Bookings {
+		if booking.Status != models.BookingStatusCancelled {
+			active = append(active, booking)
+		}
+	}
+	if len(active) == 0 {
+		return nil, errors.Get(consts.CodeBookingAlreadyCancelled)
+	}
+	return uc.bookingUseCase.CancelBookings(ctx, tenant, actor, active)
+}
+
+func (uc *ReservationUseCase) CancelReservationBooking(ctx context.Context, tenant uint64,
+	actor *models.Actor, id uint64, bookingID uint64, version uint64) (*models.Booking, *errors.Error) {
+	line, customErr := uc.selectReservationBooking(ctx, tenant, id, bookingID, version)
+	if customErr != nil {
+		return nil, customErr
+	}
+	return uc.bookingUseCase.CancelBooking(ctx, tenant, actor, line.ID, line.Version)
+}
+
+// RescheduleReservation prices the lines in the transaction of the change,
+// so the rooms can be neither repriced nor deleted in between
+func (uc *ReservationUseCase) RescheduleReservation(ctx context.Context, tenant uint64,
+	actor *models.Actor, id uint64, version uint64, dateStart string, dateEnd string) *errors.Error {
+	if err := dates.CheckDates(dateStart, dateEnd); err != nil {
+		return err
+	}
+
+	var customErr *errors.Error
+	err := uc.transactions.Run(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		var reservation *models.Reservation
+		reservation, customErr = uc.selectVersion(ctx, tenant, id, version)
+		if customErr != nil {
+			return nil
+		}
+		// The cancelled lines stay on their dates
+		var active []*models.Booking
+		for _, booking := range reservation.Bookings {
+			if booking.Status != models.BookingStatusCancelled {
+				active = append(active, booking)
+			}
+		}
+
+		var entries []*models.AuditEntry
+		entries, customErr = uc.reschedule(ctx, tenant, actor, active, dateStart, dateEnd)
+		if customErr != nil {
+			// Nothing has been written yet
+			return nil
+		}
+		return uc.reservationRepo.UpdateDates(ctx, id, reservation.Version, active, entries,
+			bookingEvents(tenant, models.EventBookingRescheduled, active))
+	})
+	if err != nil {
+		return writeError(err)
+	}
+	return customErr
+}
+
+func (uc *ReservationUseCase) RescheduleReservationBooking(ctx context.Context, tenant uint64,
+	actor *models.Actor, id uint64, bookingID uint64, version uint64, dateStart string,
+	dateEnd string) *errors.Error {
+	if err := dates.CheckDates(dateStart, dateEnd); err != nil {
+		return err
+	}
+
+	var customErr *errors.Error
+	err := uc.transactions.Run(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		var rescheduled *models.Booking
+		rescheduled, customErr = uc.selectReservationBooking(ctx, tenant, id, bookingID, version)
+		if customErr != nil {
+			return nil
+		}
+		if rescheduled.Status == models.BookingStatusCancelled {
+			customErr = errors.Get(consts.CodeBookingAlreadyCancelled)
+			return nil
+		}
+
+		read := rescheduled.Version
+		lines := []*models.Booking{rescheduled}
+		var entries []*models.AuditEntry
+		entries, customErr = uc.reschedule(ctx, tenant, actor, lines, dateStart, dateEnd)
+		if customErr != nil {
+			return nil
+		}
+		return uc.reservationRepo.UpdateBookingDates(ctx, id, rescheduled, read, entries,
+			bookingEvents(tenant, models.EventBookingRescheduled, lines))
+	})
+	if err != nil {
+		return writeError(err)
+	}
+	return customErr
+}
+
+// selectVersion returns the reservation only while it is in the version the
+// caller has seen, the repository checks the version once more on the change
+func (uc *ReservationUseCase) selectVersion(ctx context.Context, tenant uint64, id uint64,
+	version uint64) (*models.Reservation, *errors.Error) {
+	reservation, customErr := uc.GetReservation(ctx, tenant, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if version != models.AnyVersion && version != reservation.Version {
+		return nil, errors.Get(consts.CodeVersionMismatch)
+	}
+	return reservation, nil
+}
+
+// selectReservationBooking returns the line of the reservation only while it
+// is in the version the caller has seen
+func (uc *ReservationUseCase) selectReservationBooking(ctx context.Context, tenant uint64,
+	id uint64, bookingID uint64, version uint64) (*models.Booking, *errors.Error) {
+	reservation, customErr := uc.GetReservation(ctx, tenant, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	for _, booking := range reservation.Bookings {
+		if booking.ID != bookingID {
+			continue
+		}
+		if version != models.AnyVersion && version != booking.Version {
+			return nil, errors.Get(consts.CodeVersionMismatch)
+		}
+		return booking, nil
+	}
+	return nil, errors.Get(consts.CodeBookingDoesNotExist)
+}
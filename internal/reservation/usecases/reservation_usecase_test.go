@@ -0,0 +1,607 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"github.com/booking_backend/internal/booking"
+	mockBooking "github.com/booking_backend/internal/booking/mocks"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	mockTransaction "github.com/booking_backend/internal/helpers/transaction/mocks"
+	"github.com/booking_backend/internal/models"
+	mockProperty "github.com/booking_backend/internal/property/mocks"
+	"github.com/booking_backend/internal/reservation/mocks"
+	mockRoom "github.com/booking_backend/internal/room/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const (
+	tenantID     uint64 = models.DefaultTenantID
+	holdTTL             = 15 * time.Minute
+	paymentToken        = "tok_visa"
+)
+
+var ctx = context.Background()
+
+var actor = &models.Actor{Subject: "manager@hotel", RequestID: "request"}
+
+var firstRoom = &models.Room{
+	ID:          1,
+	Description: "some description",
+	Price:       500,
+	Created:     time.Time{},
+}
+
+var secondRoom = &models.Room{
+	ID:          2,
+	Description: "another description",
+	Price:       700,
+	Created:     time.Time{},
+}
+
+// runInPlace runs the unit of work right away, as if within a transaction
+func runInPlace(ctrl *gomock.Controller) *mockTransaction.MockManager {
+	transactions := mockTransaction.NewMockManager(ctrl)
+	transactions.
+		EXPECT().
+		Run(gomock.Any(), sql.LevelSerializable, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel,
+			work func(ctx context.Context) error) error {
+			return work(ctx)
+		}).
+		AnyTimes()
+	return transactions
+}
+
+func newReservationModel() *models.Reservation {
+	return &models.Reservation{
+		ID:      1,
+		Version: 3,
+		Bookings: []*models.Booking{
+			&models.Booking{
+				ID:          10,
+				DateStart:   "2022-01-02",
+				DateEnd:     "2022-01-05",
+				Room:        firstRoom.ID,
+				Reservation: 1,
+				Version:     1,
+			},
+			&models.Booking{
+				ID:          11,
+				DateStart:   "2022-01-02",
+				DateEnd:     "2022-01-05",
+				Room:        secondRoom.ID,
+				Reservation: 1,
+				Version:     1,
+			},
+		},
+	}
+}
+
+func TestReservationUseCase_CreateReservation_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(firstRoom, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, secondRoom.ID).
+		Return(secondRoom, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, "2022-01-02", "2022-01-05", uint64(0), nil).
+		Return(&models.Quote{Guests: 1, Total: 1500}, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(secondRoom, "2022-01-02", "2022-01-05", uint64(0), nil).
+		Return(&models.Quote{Guests: 1, Total: 2100}, nil)
+	reservationRep.
+		EXPECT().
+		Insert(gomock.Any(), reservationModel, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, reservation *models.Reservation, entries []*models.AuditEntry,
+			events []*models.Event) error {
+			// Every booking of the reservation is recorded, and announced only
+			// once it is confirmed
+			assert.Len(t, entries, 2)
+			assert.Empty(t, events)
+			for i := range entries {
+				assert.Equal(t, actor.Entry(tenantID, models.AuditActionCreate,
+					models.AuditEntityBooking, 0, nil, reservation.Bookings[i]), entries[i])
+			}
+			return nil
+		})
+	// The rooms are held first and paid all together
+	bookingUseCase.
+		EXPECT().
+		ConfirmHolds(ctx, actor, reservationModel.Bookings, paymentToken).
+		DoAndReturn(func(_ context.Context, _ *models.Actor, held []*models.Booking, _ string) *errors.Error {
+			for _, hold := range held {
+				assert.Equal(t, models.BookingStatusHeld, hold.Status)
+				assert.NotNil(t, hold.HoldExpires)
+			}
+			return nil
+		})
+
+	err := reservationUseCase.CreateReservation(ctx, tenantID, actor, reservationModel, paymentToken)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, uint64(1500), reservationModel.Bookings[0].Amount)
+	assert.Equal(t, uint64(2100), reservationModel.Bookings[1].Amount)
+}
+
+func TestReservationUseCase_CreateReservation_PaymentDeclined(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, gomock.Any()).
+		Return(firstRoom, nil).
+		Times(2)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, "2022-01-02", "2022-01-05", uint64(0), nil).
+		Return(&models.Quote{Guests: 1, Total: 1500}, nil).
+		Times(2)
+	reservationRep.
+		EXPECT().
+		Insert(gomock.Any(), reservationModel, gomock.Any(), gomock.Any()).
+		Return(nil)
+	bookingUseCase.
+		EXPECT().
+		ConfirmHolds(ctx, actor, reservationModel.Bookings, "decline").
+		Return(errors.Get(consts.CodePaymentDeclined))
+
+	err := reservationUseCase.CreateReservation(ctx, tenantID, actor, reservationModel, "decline")
+	assert.Equal(t, errors.Get(consts.CodePaymentDeclined), err)
+}
+
+func TestReservationUseCase_CreateReservation_HoldOnly(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+	for _, line := range reservationModel.Bookings {
+		line.Status = models.BookingStatusHeld
+	}
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, gomock.Any()).
+		Return(firstRoom, nil).
+		Times(2)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, "2022-01-02", "2022-01-05", uint64(0), nil).
+		Return(&models.Quote{Guests: 1, Total: 1500}, nil).
+		Times(2)
+	// Nothing is paid until the holds are confirmed one by one, so the
+	// holds themselves are announced
+	reservationRep.
+		EXPECT().
+		Insert(gomock.Any(), reservationModel, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, reservation *models.Reservation, _ []*models.AuditEntry,
+			events []*models.Event) error {
+			assert.Len(t, events, 2)
+			for i, event := range events {
+				assert.Equal(t, models.EventBookingCreated, event.Type)
+				assert.Equal(t, reservation.Bookings[i], event.Payload)
+			}
+			return nil
+		})
+
+	err := reservationUseCase.CreateReservation(ctx, tenantID, actor, reservationModel, "")
+	assert.Equal(t, (*errors.Error)(nil), err)
+	for _, line := range reservationModel.Bookings {
+		assert.Equal(t, models.BookingStatusHeld, line.Status)
+		assert.NotNil(t, line.HoldExpires)
+	}
+}
+
+func TestReservationUseCase_CreateReservation_RoomDoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(firstRoom, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(firstRoom, "2022-01-02", "2022-01-05", uint64(0), nil).
+		Return(&models.Quote{Guests: 1, Total: 1500}, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, secondRoom.ID).
+		Return(nil, sql.ErrNoRows)
+
+	err := reservationUseCase.CreateReservation(ctx, tenantID, actor, reservationModel, paymentToken)
+	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), err)
+}
+
+func TestReservationUseCase_CancelReservationBooking_Success(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+	cancelled := &models.Booking{ID: 11, Status: models.BookingStatusCancelled, CancellationFee: 700}
+	bookingUseCase.
+		EXPECT().
+		CancelBooking(ctx, tenantID, actor, uint64(11), uint64(1)).
+		Return(cancelled, nil)
+
+	booking, err := reservationUseCase.CancelReservationBooking(ctx, tenantID, actor,
+		reservationModel.ID, 11, 1)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, cancelled, booking)
+}
+
+func TestReservationUseCase_CancelReservationBooking_VersionMismatch(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+
+	_, err := reservationUseCase.CancelReservationBooking(ctx, tenantID, actor, reservationModel.ID, 11, 2)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), err)
+}
+
+func TestReservationUseCase_CancelReservationBooking_NotInReservation(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+
+	_, err := reservationUseCase.CancelReservationBooking(ctx, tenantID, actor, reservationModel.ID, 12,
+		models.AnyVersion)
+	assert.Equal(t, errors.Get(consts.CodeBookingDoesNotExist), err)
+}
+
+func TestReservationUseCase_RescheduleReservation_IncorrectDates(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+
+	err := reservationUseCase.RescheduleReservation(ctx, tenantID, actor, 1, models.AnyVersion, "2022-01-05",
+		"2022-01-02")
+	assert.Equal(t, errors.Get(consts.CodeIncorrectDates), err)
+}
+
+func TestReservationUseCase_CancelReservation_DoesNotExist(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, uint64(1)).
+		Return(nil, sql.ErrNoRows)
+
+	_, err := reservationUseCase.CancelReservation(ctx, tenantID, actor, 1, models.AnyVersion)
+	assert.Equal(t, errors.Get(consts.CodeReservationDoesNotExist), err)
+}
+
+func TestReservationUseCase_CancelReservation_VersionMismatch(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	// A line of the reservation has been changed since the caller read it
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+
+	_, err := reservationUseCase.CancelReservation(ctx, tenantID, actor, reservationModel.ID,
+		reservationModel.Version-1)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), err)
+}
+
+func TestReservationUseCase_CancelReservation_ActiveLines(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+	reservationModel.Bookings[0].Status = models.BookingStatusCancelled
+	reservationModel.Bookings[1].Status = models.BookingStatusConfirmed
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+	// The line cancelled before is left as it is
+	bookingUseCase.
+		EXPECT().
+		CancelBookings(ctx, tenantID, actor, []*models.Booking{reservationModel.Bookings[1]}).
+		Return([]*models.Booking{reservationModel.Bookings[1]}, nil)
+
+	cancelled, err := reservationUseCase.CancelReservation(ctx, tenantID, actor, reservationModel.ID,
+		reservationModel.Version)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Len(t, cancelled, 1)
+}
+
+func TestReservationUseCase_CancelReservation_AlreadyCancelled(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+	for _, line := range reservationModel.Bookings {
+		line.Status = models.BookingStatusCancelled
+	}
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+
+	_, err := reservationUseCase.CancelReservation(ctx, tenantID, actor, reservationModel.ID,
+		models.AnyVersion)
+	assert.Equal(t, errors.Get(consts.CodeBookingAlreadyCancelled), err)
+}
+
+func TestReservationUseCase_RescheduleReservationBooking_Event(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+	quote := &models.Quote{Nights: 2, Guests: 1, NightPrice: 700, Accommodation: 1400, Total: 1400}
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, secondRoom.ID).
+		Return(secondRoom, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(secondRoom, "2022-02-01", "2022-02-03", uint64(0), nil).
+		Return(quote, nil)
+	reservationRep.
+		EXPECT().
+		UpdateBookingDates(gomock.Any(), reservationModel.ID, reservationModel.Bookings[1], uint64(1),
+			gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint64, line *models.Booking, version uint64,
+			entries []*models.AuditEntry, events []*models.Event) error {
+			// The line is priced for the new dates
+			assert.Equal(t, quote, line.Quote)
+			assert.Equal(t, quote.Total, line.Amount)
+
+			// The entry keeps the line as it was before the change
+			assert.Len(t, entries, 1)
+			assert.Equal(t, models.AuditActionUpdate, entries[0].Action)
+			assert.Equal(t, line.ID, entries[0].EntityID)
+			assert.Equal(t, "2022-01-02", entries[0].Before.(*models.Booking).DateStart)
+			assert.Equal(t, events[0].Payload, entries[0].After)
+
+			assert.Len(t, events, 1)
+			assert.Equal(t, models.EventBookingRescheduled, events[0].Type)
+			assert.Equal(t, tenantID, events[0].Tenant)
+			rescheduled := events[0].Payload.(*models.Booking)
+			assert.Equal(t, line.ID, rescheduled.ID)
+			assert.Equal(t, "2022-02-01", rescheduled.DateStart)
+			assert.Equal(t, "2022-02-03", rescheduled.DateEnd)
+			assert.Equal(t, version+1, rescheduled.Version)
+			return nil
+		})
+
+	err := reservationUseCase.RescheduleReservationBooking(ctx, tenantID, actor, reservationModel.ID, 11,
+		models.AnyVersion, "2022-02-01", "2022-02-03")
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestReservationUseCase_RescheduleReservationBooking_ChangedConcurrently(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, secondRoom.ID).
+		Return(secondRoom, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(secondRoom, "2022-02-01", "2022-02-03", uint64(0), nil).
+		Return(&models.Quote{Guests: 1, Total: 1400}, nil)
+	reservationRep.
+		EXPECT().
+		UpdateBookingDates(gomock.Any(), reservationModel.ID, reservationModel.Bookings[1], uint64(1),
+			gomock.Any(), gomock.Any()).
+		Return(booking.ErrVersionMismatch)
+
+	err := reservationUseCase.RescheduleReservationBooking(ctx, tenantID, actor, reservationModel.ID, 11,
+		1, "2022-02-01", "2022-02-03")
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), err)
+}
+
+func TestReservationUseCase_RescheduleReservation_ActiveLines(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+	reservationModel.Bookings[0].Status = models.BookingStatusCancelled
+
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, secondRoom.ID).
+		Return(secondRoom, nil)
+	propertyUseCase.
+		EXPECT().
+		QuoteStay(secondRoom, "2022-02-01", "2022-02-03", uint64(0), nil).
+		Return(&models.Quote{Guests: 1, Total: 1400}, nil)
+	reservationRep.
+		EXPECT().
+		UpdateDates(gomock.Any(), reservationModel.ID, reservationModel.Version, gomock.Any(),
+			gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint64, _ uint64, lines []*models.Booking,
+			entries []*models.AuditEntry, events []*models.Event) error {
+			// The cancelled line is neither moved nor recorded
+			assert.Equal(t, []*models.Booking{reservationModel.Bookings[1]}, lines)
+			assert.Equal(t, uint64(1400), lines[0].Amount)
+			assert.Len(t, entries, 1)
+			assert.Len(t, events, 1)
+			assert.Equal(t, reservationModel.Bookings[1].ID, entries[0].EntityID)
+			assert.Equal(t, actor.Subject, entries[0].Actor)
+			return nil
+		})
+
+	err := reservationUseCase.RescheduleReservation(ctx, tenantID, actor, reservationModel.ID,
+		reservationModel.Version, "2022-02-01", "2022-02-03")
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, "2022-01-02", reservationModel.Bookings[0].DateStart)
+}
+
+func TestReservationUseCase_RescheduleReservation_RoomDeleted(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reservationRep := mocks.NewMockReservationRepository(ctrl)
+	roomRep := mockRoom.NewMockRoomRepository(ctrl)
+	propertyUseCase := mockProperty.NewMockPropertyUseCase(ctrl)
+	bookingUseCase := mockBooking.NewMockBookingUseCase(ctrl)
+	reservationUseCase := NewReservationUseCase(runInPlace(ctrl), reservationRep, roomRep, propertyUseCase,
+		bookingUseCase, holdTTL)
+	reservationModel := newReservationModel()
+
+	// Nothing is moved when a line can't be priced
+	reservationRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, reservationModel.ID).
+		Return(reservationModel, nil)
+	roomRep.
+		EXPECT().
+		SelectByID(gomock.Any(), tenantID, firstRoom.ID).
+		Return(nil, sql.ErrNoRows)
+
+	err := reservationUseCase.RescheduleReservation(ctx, tenantID, actor, reservationModel.ID,
+		reservationModel.Version, "2022-02-01", "2022-02-03")
+	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), err)
+}
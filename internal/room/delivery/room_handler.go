@@ -1,14 +1,20 @@
 package delivery
 
 import (
+	"bytes"
 	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/csvio"
 	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
 	"github.com/booking_backend/internal/models"
 	"github.com/booking_backend/internal/room"
+	"github.com/booking_backend/tools/etag"
+	"github.com/booking_backend/tools/principal"
 	"github.com/booking_backend/tools/request_reader"
 	"github.com/booking_backend/tools/response"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,16 +22,29 @@ import (
 
 type RoomHandler struct {
 	roomUseCase room.RoomUseCase
+	idempotent  echo.MiddlewareFunc
 }
 
-func NewRoomHandler(useCase room.RoomUseCase) *RoomHandler {
-	return &RoomHandler{roomUseCase: useCase}
+// NewRoomHandler lets the room creation be retried with the idempotent middleware
+func NewRoomHandler(useCase room.RoomUseCase, idempotent echo.MiddlewareFunc) *RoomHandler {
+	return &RoomHandler{roomUseCase: useCase, idempotent: idempotent}
 }
 
 func (rh *RoomHandler) Configure(e *echo.Echo) {
-	e.POST("rooms/create", rh.CreateRoom())
-	e.GET("rooms/list", rh.GetRooms())
-	e.DELETE("rooms/:id", rh.DeleteRoom())
+	e.POST("rooms/create",
+		rh.CreateRoom(), principal.Require(rbac.ManageRooms), rh.idempotent)
+	e.GET("rooms/list",
+		rh.GetRooms(), principal.Require(rbac.ViewRooms))
+	e.GET("rooms/:id",
+		rh.GetRoom(), principal.Require(rbac.ViewRooms))
+	e.DELETE("rooms/:id",
+		rh.DeleteRoom(), principal.Require(rbac.DeleteRooms))
+	e.POST("rooms/:id/restore",
+		rh.RestoreRoom(), principal.Require(rbac.DeleteRooms))
+	e.GET("rooms/:id/cancellation_policy",
+		rh.GetCancellationPolicy(), principal.Require(rbac.ViewRooms))
+	e.PUT("rooms/:id/cancellation_policy",
+		rh.SetCancellationPolicy(), principal.Require(rbac.ManageRooms))
 }
 
 type RoomID struct {
@@ -38,6 +57,7 @@ func (rh *RoomHandler) CreateRoom() echo.HandlerFunc {
 		// TODO: валидация цены
 		Description string `form:"description" validate:"required"`
 		Price       uint64 `form:"price" validate:"required"`
+		PropertyID  uint64 `form:"property_id"`
 	}
 
 	return func(context echo.Context) error {
@@ -51,9 +71,15 @@ func (rh *RoomHandler) CreateRoom() echo.HandlerFunc {
 			Description: req.Description,
 			Price:       req.Price,
 			Created:     time.Now(),
+			Property:    req.PropertyID,
+			Tenant:      principal.Tenant(context),
+		}
+		if room.Property == 0 {
+			room.Property = models.DefaultPropertyID
 		}
 
-		if customErr := rh.roomUseCase.CreateRoom(room); customErr != nil {
+		if customErr := rh.roomUseCase.CreateRoom(context.Request().Context(),
+			principal.Actor(context), room); customErr != nil {
 			logrus.Error(customErr)
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
@@ -78,14 +104,64 @@ func (rh *RoomHandler) GetRooms() echo.HandlerFunc {
 			req.Sort.OrderBy = "created"
 		}
 
-		rooms, customErr := rh.roomUseCase.GetRoomsList(&req.Sort)
+		rooms, customErr := rh.roomUseCase.GetRoomsList(context.Request().Context(),
+			principal.Tenant(context), &req.Sort)
 		if customErr != nil {
 			logrus.Error(customErr)
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
 
-		return context.JSON(http.StatusOK, rooms)
+		if !csvio.WantsCSV(context) {
+			return context.JSON(http.StatusOK, rooms)
+		}
+
+		file := &bytes.Buffer{}
+		if err := writeRoomsCSV(file, rooms); err != nil {
+			customErr := errors.New(CodeInternalError, err)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+		return context.Blob(http.StatusOK, csvio.MIMETextCSV, file.Bytes())
+	}
+}
+
+// GetRoom returns the version of the room in ETag, it is sent back in
+// If-Match to change the room
+func (rh *RoomHandler) GetRoom() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		room, customErr := rh.roomUseCase.GetRoom(context.Request().Context(),
+			principal.Tenant(context), roomID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		etag.Set(context, room.Version)
+		return context.JSON(http.StatusOK, room)
+	}
+}
+
+// writeRoomsCSV uses the columns of the import, so the file can be
+// imported into another deployment
+func writeRoomsCSV(w io.Writer, rooms []*models.Room) error {
+	rows := make([][]string, 0, len(rooms))
+	for _, room := range rooms {
+		rows = append(rows, []string{
+			strconv.FormatUint(room.ID, 10),
+			room.Description,
+			strconv.FormatUint(room.Price, 10),
+			strconv.FormatUint(room.Property, 10),
+			room.Created.Format(time.RFC3339),
+		})
 	}
+	return csvio.Write(w, []string{"room_id", "description", "price", "property_id", "created"}, rows)
 }
 
 func (rh *RoomHandler) DeleteRoom() echo.HandlerFunc {
@@ -97,14 +173,116 @@ func (rh *RoomHandler) DeleteRoom() echo.HandlerFunc {
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
 
-		customErr := rh.roomUseCase.DeleteRoomAndBookings(roomID)
+		version, customErr := etag.Match(context)
 		if customErr != nil {
 			logrus.Error(customErr)
 			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
 		}
 
+		deletedVersion, customErr := rh.roomUseCase.DeleteRoomAndBookings(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), roomID, version)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		// The room is restored in this version
+		etag.Set(context, deletedVersion)
 		return context.JSON(http.StatusOK, response.Response{
 			Message: "success",
 		})
 	}
 }
+
+// RestoreRoom undoes the deletion of the room and its bookings until the
+// room is purged
+func (rh *RoomHandler) RestoreRoom() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		room, customErr := rh.roomUseCase.RestoreRoom(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), roomID, version)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		etag.Set(context, room.Version)
+		return context.JSON(http.StatusOK, room)
+	}
+}
+
+func (rh *RoomHandler) GetCancellationPolicy() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		policy, customErr := rh.roomUseCase.GetCancellationPolicy(context.Request().Context(),
+			principal.Tenant(context), roomID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, policy)
+	}
+}
+
+func (rh *RoomHandler) SetCancellationPolicy() echo.HandlerFunc {
+	type Request struct {
+		FreeDays       uint64 `form:"free_days"`
+		PenaltyType    string `form:"penalty_type" validate:"required,oneof=percent first_night"`
+		PenaltyPercent uint64 `form:"penalty_percent" validate:"max=100"`
+	}
+
+	return func(context echo.Context) error {
+		roomID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		version, customErr := etag.Match(context)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		policy := &models.CancellationPolicy{
+			Room:           roomID,
+			FreeDays:       req.FreeDays,
+			PenaltyType:    req.PenaltyType,
+			PenaltyPercent: req.PenaltyPercent,
+		}
+
+		if customErr := rh.roomUseCase.SetCancellationPolicy(context.Request().Context(),
+			principal.Tenant(context), principal.Actor(context), policy, version); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, policy)
+	}
+}
@@ -19,6 +19,8 @@ func (db *DataBuilder) CreateNewRoomModel() *models.Room {
 		Description: "Just a new room",
 		Price:       100,
 		Created:     time.Now(),
+		Property:    models.DefaultPropertyID,
+		Tenant:      models.DefaultTenantID,
 	}
 }
 
@@ -32,6 +34,9 @@ func(db *DataBuilder)  CreateFirstRoom() *models.Room {
 		Description: "room at the Hotel California",
 		Price:       500,
 		Created:     time.Date(2021, 1, 8, 19, 37, 51, 0, loc),
+		Property:    models.DefaultPropertyID,
+		Version:     1,
+		Tenant:      models.DefaultTenantID,
 	}
 	return existedRoom
 }
@@ -56,23 +61,51 @@ func (db *DataBuilder) CreateAllExistedRooms() []*models.Room {
 			Description: "room at the Hotel California",
 			Price:       500,
 			Created:     time.Date(2021, 1, 8, 19, 37, 51, 0, loc),
+			Property:    models.DefaultPropertyID,
+			Version:     1,
+			Tenant:      models.DefaultTenantID,
 		},
 		&models.Room{
 			ID:          2,
 			Description: "room at the Grand Budapest Hotel",
 			Price:       11500,
 			Created:     time.Date(2021, 1, 9, 19, 37, 51, 0, loc),
+			Property:    models.DefaultPropertyID,
+			Version:     1,
+			Tenant:      models.DefaultTenantID,
 		}, &models.Room{
 			ID:          3,
 			Description: "room at the Hostel Teriba",
 			Price:       750,
 			Created:     time.Date(2021, 1, 7, 19, 37, 51, 0, loc),
+			Property:    models.DefaultPropertyID,
+			Version:     1,
+			Tenant:      models.DefaultTenantID,
 		}, &models.Room{
 			ID:          4,
 			Description: "room at the Hostel Friends",
 			Price:       300,
 			Created:     time.Date(2021, 1, 6, 19, 37, 51, 0, loc),
+			Property:    models.DefaultPropertyID,
+			Version:     1,
+			Tenant:      models.DefaultTenantID,
 		},
 	}
 	return existedRooms
 }
+
+func (db *DataBuilder) CreateOtherTenantRoom() *models.Room {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return &models.Room{
+		ID:          5,
+		Description: "room at the Hotel Transylvania",
+		Price:       900,
+		Created:     time.Date(2021, 1, 5, 19, 37, 51, 0, loc),
+		Property:    models.DefaultPropertyID,
+		Version:     1,
+		Tenant:      2,
+	}
+}
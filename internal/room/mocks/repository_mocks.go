@@ -5,9 +5,11 @@
 package mocks
 
 import (
+	context "context"
 	models "github.com/booking_backend/internal/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRoomRepository is a mock of RoomRepository interface
@@ -34,59 +36,119 @@ func (m *MockRoomRepository) EXPECT() *MockRoomRepositoryMockRecorder {
 }
 
 // Insert mocks base method
-func (m *MockRoomRepository) Insert(room *models.Room) error {
+func (m *MockRoomRepository) Insert(ctx context.Context, room *models.Room, entry *models.AuditEntry, event *models.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Insert", room)
+	ret := m.ctrl.Call(m, "Insert", ctx, room, entry, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Insert indicates an expected call of Insert
-func (mr *MockRoomRepositoryMockRecorder) Insert(room interface{}) *gomock.Call {
+func (mr *MockRoomRepositoryMockRecorder) Insert(ctx, room, entry, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockRoomRepository)(nil).Insert), room)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockRoomRepository)(nil).Insert), ctx, room, entry, event)
 }
 
 // DeleteRoomAndBookings mocks base method
-func (m *MockRoomRepository) DeleteRoomAndBookings(id uint64) error {
+func (m *MockRoomRepository) DeleteRoomAndBookings(ctx context.Context, tenant, id, version uint64, entry *models.AuditEntry, event *models.Event) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRoomAndBookings", id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "DeleteRoomAndBookings", ctx, tenant, id, version, entry, event)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteRoomAndBookings indicates an expected call of DeleteRoomAndBookings
-func (mr *MockRoomRepositoryMockRecorder) DeleteRoomAndBookings(id interface{}) *gomock.Call {
+func (mr *MockRoomRepositoryMockRecorder) DeleteRoomAndBookings(ctx, tenant, id, version, entry, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoomAndBookings", reflect.TypeOf((*MockRoomRepository)(nil).DeleteRoomAndBookings), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoomAndBookings", reflect.TypeOf((*MockRoomRepository)(nil).DeleteRoomAndBookings), ctx, tenant, id, version, entry, event)
+}
+
+// RestoreRoom mocks base method
+func (m *MockRoomRepository) RestoreRoom(ctx context.Context, tenant, id, version uint64, entry *models.AuditEntry) (*models.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRoom", ctx, tenant, id, version, entry)
+	ret0, _ := ret[0].(*models.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreRoom indicates an expected call of RestoreRoom
+func (mr *MockRoomRepositoryMockRecorder) RestoreRoom(ctx, tenant, id, version, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRoom", reflect.TypeOf((*MockRoomRepository)(nil).RestoreRoom), ctx, tenant, id, version, entry)
+}
+
+// PurgeDeletedRooms mocks base method
+func (m *MockRoomRepository) PurgeDeletedRooms(ctx context.Context, deletedBefore time.Time, actor *models.Actor) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedRooms", ctx, deletedBefore, actor)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedRooms indicates an expected call of PurgeDeletedRooms
+func (mr *MockRoomRepositoryMockRecorder) PurgeDeletedRooms(ctx, deletedBefore, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedRooms", reflect.TypeOf((*MockRoomRepository)(nil).PurgeDeletedRooms), ctx, deletedBefore, actor)
 }
 
 // SelectByID mocks base method
-func (m *MockRoomRepository) SelectByID(id uint64) (*models.Room, error) {
+func (m *MockRoomRepository) SelectByID(ctx context.Context, tenant, id uint64) (*models.Room, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectByID", id)
+	ret := m.ctrl.Call(m, "SelectByID", ctx, tenant, id)
 	ret0, _ := ret[0].(*models.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SelectByID indicates an expected call of SelectByID
-func (mr *MockRoomRepositoryMockRecorder) SelectByID(id interface{}) *gomock.Call {
+func (mr *MockRoomRepositoryMockRecorder) SelectByID(ctx, tenant, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByID", reflect.TypeOf((*MockRoomRepository)(nil).SelectByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByID", reflect.TypeOf((*MockRoomRepository)(nil).SelectByID), ctx, tenant, id)
 }
 
 // SelectRooms mocks base method
-func (m *MockRoomRepository) SelectRooms(sort *models.Sort) ([]*models.Room, error) {
+func (m *MockRoomRepository) SelectRooms(ctx context.Context, tenant uint64, sort *models.Sort) ([]*models.Room, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectRooms", sort)
+	ret := m.ctrl.Call(m, "SelectRooms", ctx, tenant, sort)
 	ret0, _ := ret[0].([]*models.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SelectRooms indicates an expected call of SelectRooms
-func (mr *MockRoomRepositoryMockRecorder) SelectRooms(sort interface{}) *gomock.Call {
+func (mr *MockRoomRepositoryMockRecorder) SelectRooms(ctx, tenant, sort interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRooms", reflect.TypeOf((*MockRoomRepository)(nil).SelectRooms), ctx, tenant, sort)
+}
+
+// SelectCancellationPolicy mocks base method
+func (m *MockRoomRepository) SelectCancellationPolicy(ctx context.Context, tenant, roomID uint64) (*models.CancellationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectCancellationPolicy", ctx, tenant, roomID)
+	ret0, _ := ret[0].(*models.CancellationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectCancellationPolicy indicates an expected call of SelectCancellationPolicy
+func (mr *MockRoomRepositoryMockRecorder) SelectCancellationPolicy(ctx, tenant, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectCancellationPolicy", reflect.TypeOf((*MockRoomRepository)(nil).SelectCancellationPolicy), ctx, tenant, roomID)
+}
+
+// UpsertCancellationPolicy mocks base method
+func (m *MockRoomRepository) UpsertCancellationPolicy(ctx context.Context, tenant uint64, policy *models.CancellationPolicy, version uint64, entry *models.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCancellationPolicy", ctx, tenant, policy, version, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCancellationPolicy indicates an expected call of UpsertCancellationPolicy
+func (mr *MockRoomRepositoryMockRecorder) UpsertCancellationPolicy(ctx, tenant, policy, version, entry interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRooms", reflect.TypeOf((*MockRoomRepository)(nil).SelectRooms), sort)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCancellationPolicy", reflect.TypeOf((*MockRoomRepository)(nil).UpsertCancellationPolicy), ctx, tenant, policy, version, entry)
 }
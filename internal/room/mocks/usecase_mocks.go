@@ -5,10 +5,12 @@
 package mocks
 
 import (
+	context "context"
 	errors "github.com/booking_backend/internal/helpers/errors"
 	models "github.com/booking_backend/internal/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRoomUseCase is a mock of RoomUseCase interface
@@ -35,44 +37,119 @@ func (m *MockRoomUseCase) EXPECT() *MockRoomUseCaseMockRecorder {
 }
 
 // CreateRoom mocks base method
-func (m *MockRoomUseCase) CreateRoom(room *models.Room) *errors.Error {
+func (m *MockRoomUseCase) CreateRoom(ctx context.Context, actor *models.Actor, room *models.Room) *errors.Error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRoom", room)
+	ret := m.ctrl.Call(m, "CreateRoom", ctx, actor, room)
 	ret0, _ := ret[0].(*errors.Error)
 	return ret0
 }
 
 // CreateRoom indicates an expected call of CreateRoom
-func (mr *MockRoomUseCaseMockRecorder) CreateRoom(room interface{}) *gomock.Call {
+func (mr *MockRoomUseCaseMockRecorder) CreateRoom(ctx, actor, room interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockRoomUseCase)(nil).CreateRoom), room)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockRoomUseCase)(nil).CreateRoom), ctx, actor, room)
 }
 
 // DeleteRoomAndBookings mocks base method
-func (m *MockRoomUseCase) DeleteRoomAndBookings(id uint64) *errors.Error {
+func (m *MockRoomUseCase) DeleteRoomAndBookings(ctx context.Context, tenant uint64, actor *models.Actor, id, version uint64) (uint64, *errors.Error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRoomAndBookings", id)
-	ret0, _ := ret[0].(*errors.Error)
-	return ret0
+	ret := m.ctrl.Call(m, "DeleteRoomAndBookings", ctx, tenant, actor, id, version)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
 }
 
 // DeleteRoomAndBookings indicates an expected call of DeleteRoomAndBookings
-func (mr *MockRoomUseCaseMockRecorder) DeleteRoomAndBookings(id interface{}) *gomock.Call {
+func (mr *MockRoomUseCaseMockRecorder) DeleteRoomAndBookings(ctx, tenant, actor, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoomAndBookings", reflect.TypeOf((*MockRoomUseCase)(nil).DeleteRoomAndBookings), ctx, tenant, actor, id, version)
+}
+
+// RestoreRoom mocks base method
+func (m *MockRoomUseCase) RestoreRoom(ctx context.Context, tenant uint64, actor *models.Actor, id, version uint64) (*models.Room, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRoom", ctx, tenant, actor, id, version)
+	ret0, _ := ret[0].(*models.Room)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// RestoreRoom indicates an expected call of RestoreRoom
+func (mr *MockRoomUseCaseMockRecorder) RestoreRoom(ctx, tenant, actor, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRoom", reflect.TypeOf((*MockRoomUseCase)(nil).RestoreRoom), ctx, tenant, actor, id, version)
+}
+
+// PurgeDeletedRooms mocks base method
+func (m *MockRoomUseCase) PurgeDeletedRooms(ctx context.Context, deletedBefore time.Time) (int64, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedRooms", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// PurgeDeletedRooms indicates an expected call of PurgeDeletedRooms
+func (mr *MockRoomUseCaseMockRecorder) PurgeDeletedRooms(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedRooms", reflect.TypeOf((*MockRoomUseCase)(nil).PurgeDeletedRooms), ctx, deletedBefore)
+}
+
+// GetRoom mocks base method
+func (m *MockRoomUseCase) GetRoom(ctx context.Context, tenant, id uint64) (*models.Room, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoom", ctx, tenant, id)
+	ret0, _ := ret[0].(*models.Room)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetRoom indicates an expected call of GetRoom
+func (mr *MockRoomUseCaseMockRecorder) GetRoom(ctx, tenant, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoomAndBookings", reflect.TypeOf((*MockRoomUseCase)(nil).DeleteRoomAndBookings), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoom", reflect.TypeOf((*MockRoomUseCase)(nil).GetRoom), ctx, tenant, id)
 }
 
 // GetRoomsList mocks base method
-func (m *MockRoomUseCase) GetRoomsList(sort *models.Sort) ([]*models.Room, *errors.Error) {
+func (m *MockRoomUseCase) GetRoomsList(ctx context.Context, tenant uint64, sort *models.Sort) ([]*models.Room, *errors.Error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRoomsList", sort)
+	ret := m.ctrl.Call(m, "GetRoomsList", ctx, tenant, sort)
 	ret0, _ := ret[0].([]*models.Room)
 	ret1, _ := ret[1].(*errors.Error)
 	return ret0, ret1
 }
 
 // GetRoomsList indicates an expected call of GetRoomsList
-func (mr *MockRoomUseCaseMockRecorder) GetRoomsList(sort interface{}) *gomock.Call {
+func (mr *MockRoomUseCaseMockRecorder) GetRoomsList(ctx, tenant, sort interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomsList", reflect.TypeOf((*MockRoomUseCase)(nil).GetRoomsList), ctx, tenant, sort)
+}
+
+// GetCancellationPolicy mocks base method
+func (m *MockRoomUseCase) GetCancellationPolicy(ctx context.Context, tenant, roomID uint64) (*models.CancellationPolicy, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCancellationPolicy", ctx, tenant, roomID)
+	ret0, _ := ret[0].(*models.CancellationPolicy)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetCancellationPolicy indicates an expected call of GetCancellationPolicy
+func (mr *MockRoomUseCaseMockRecorder) GetCancellationPolicy(ctx, tenant, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCancellationPolicy", reflect.TypeOf((*MockRoomUseCase)(nil).GetCancellationPolicy), ctx, tenant, roomID)
+}
+
+// SetCancellationPolicy mocks base method
+func (m *MockRoomUseCase) SetCancellationPolicy(ctx context.Context, tenant uint64, actor *models.Actor, policy *models.CancellationPolicy, version uint64) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCancellationPolicy", ctx, tenant, actor, policy, version)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// SetCancellationPolicy indicates an expected call of SetCancellationPolicy
+func (mr *MockRoomUseCaseMockRecorder) SetCancellationPolicy(ctx, tenant, actor, policy, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomsList", reflect.TypeOf((*MockRoomUseCase)(nil).GetRoomsList), sort)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCancellationPolicy", reflect.TypeOf((*MockRoomUseCase)(nil).SetCancellationPolicy), ctx, tenant, actor, policy, version)
 }
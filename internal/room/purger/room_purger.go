@@ -0,0 +1,48 @@
+package purger
+
+import (
+	"context"
+	"github.com/booking_backend/internal/room"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// RoomPurger periodically removes for good the rooms deleted longer than
+// the retention period ago
+type RoomPurger struct {
+	roomUseCase room.RoomUseCase
+	interval    time.Duration
+	retention   time.Duration
+}
+
+func NewRoomPurger(useCase room.RoomUseCase, interval time.Duration,
+	retention time.Duration) *RoomPurger {
+	return &RoomPurger{roomUseCase: useCase, interval: interval, retention: retention}
+}
+
+// Run blocks until the context is cancelled. A purge already started is
+// completed, the cancellation only keeps the next one from starting
+func (rp *RoomPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(rp.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rp.Purge(context.Background())
+		}
+	}
+}
+
+func (rp *RoomPurger) Purge(ctx context.Context) {
+	purged, customErr := rp.roomUseCase.PurgeDeletedRooms(ctx, time.Now().Add(-rp.retention))
+	if customErr != nil {
+		logrus.Error(customErr)
+		return
+	}
+	if purged > 0 {
+		logrus.Infof("purged %d deleted rooms", purged)
+	}
+}
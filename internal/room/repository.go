@@ -1,10 +1,26 @@
 package room
 
-import "github.com/booking_backend/internal/models"
+import (
+	"context"
+	"errors"
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+// ErrVersionMismatch means the room has been changed since it was read
+var ErrVersionMismatch = errors.New("room has been changed")
 
 type RoomRepository interface {
-	Insert(room *models.Room) error
-	DeleteRoomAndBookings(id uint64) error
-	SelectByID(id uint64) (*models.Room, error)
-	SelectRooms(sort *models.Sort) ([]*models.Room, error)
+	Insert(ctx context.Context, room *models.Room, entry *models.AuditEntry, event *models.Event) error
+	DeleteRoomAndBookings(ctx context.Context, tenant uint64, id uint64, version uint64,
+		entry *models.AuditEntry, event *models.Event) (uint64, error)
+	RestoreRoom(ctx context.Context, tenant uint64, id uint64, version uint64,
+		entry *models.AuditEntry) (*models.Room, error)
+	PurgeDeletedRooms(ctx context.Context, deletedBefore time.Time, actor *models.Actor) (int64, error)
+	SelectByID(ctx context.Context, tenant uint64, id uint64) (*models.Room, error)
+	SelectRooms(ctx context.Context, tenant uint64, sort *models.Sort) ([]*models.Room, error)
+	SelectCancellationPolicy(ctx context.Context, tenant uint64,
+		roomID uint64) (*models.CancellationPolicy, error)
+	UpsertCancellationPolicy(ctx context.Context, tenant uint64, policy *models.CancellationPolicy,
+		version uint64, entry *models.AuditEntry) error
 }
@@ -3,85 +3,283 @@ package repository
 import (
 	"context"
 	"database/sql"
+	auditRepository "github.com/booking_backend/internal/audit/repository"
+	bookingRepository "github.com/booking_backend/internal/booking/repository"
+	"github.com/booking_backend/internal/helpers/query"
+	"github.com/booking_backend/internal/helpers/transaction"
 	"github.com/booking_backend/internal/models"
+	outboxRepository "github.com/booking_backend/internal/outbox/repository"
 	"github.com/booking_backend/internal/room"
 	"github.com/sirupsen/logrus"
 	"strings"
+	"time"
 )
 
 type RoomRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
 }
 
-func NewRoomRepository(db *sql.DB) room.RoomRepository {
-	return &RoomRepository{db: db}
+// NewRoomRepository bounds every call of the repository with the timeout
+func NewRoomRepository(db *sql.DB, timeout time.Duration) room.RoomRepository {
+	return &RoomRepository{db: db, timeout: timeout}
 }
 
-func (rep *RoomRepository) Insert(room *models.Room) error {
-	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+// InsertRoom stores the room within the transaction
+func InsertRoom(ctx context.Context, tx *sql.Tx, room *models.Room) error {
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO rooms(description, price, created, property, tenant)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, version`,
+		room.Description, room.Price, room.Created, room.Property, room.Tenant).
+		Scan(&room.ID, &room.Version)
+}
+
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if rollbackErr := transaction.Rollback(ctx, tx); rollbackErr != nil {
+		logrus.Info(rollbackErr)
+	}
+}
+
+func (rep *RoomRepository) Insert(ctx context.Context, room *models.Room,
+	entry *models.AuditEntry, event *models.Event) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
 	if err != nil {
 		return err
 	}
 
-	err = tx.QueryRow(`
-		INSERT INTO rooms(description, price, created) 
-		VALUES ($1, $2, $3) RETURNING id`,
-		room.Description, room.Price, room.Created).
-		Scan(&room.ID)
+	err = InsertRoom(ctx, tx, room)
 	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			logrus.Info(rollbackErr)
-		}
+		rollback(ctx, tx)
+		return err
+	}
+
+	entry.EntityID = room.ID
+	if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if err := outboxRepository.InsertEvent(ctx, tx, event); err != nil {
+		rollback(ctx, tx)
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := transaction.Commit(ctx, tx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (rep *RoomRepository) SelectByID(id uint64) (*models.Room, error) {
+func (rep *RoomRepository) SelectByID(ctx context.Context, tenant uint64,
+	id uint64) (*models.Room, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
 	room := &models.Room{}
-	err := rep.db.QueryRow(`
-		SELECT id, description, price, created
+	err := transaction.Querier(ctx, rep.db).QueryRowContext(ctx, `
+		SELECT id, description, price, created, property, version, tenant
 		FROM rooms
-		WHERE id=$1`, id).
-		Scan(&room.ID, &room.Description, &room.Price, &room.Created)
+		WHERE id=$1 AND tenant=$2 AND deleted_at IS NULL`, id, tenant).
+		Scan(&room.ID, &room.Description, &room.Price, &room.Created, &room.Property,
+			&room.Version, &room.Tenant)
 	if err != nil {
 		return nil, err
 	}
 	return room, nil
 }
 
-func (rep *RoomRepository) DeleteRoomAndBookings(id uint64) error {
-	tx, err := rep.db.BeginTx(context.Background(), &sql.TxOptions{})
+// recordBookings writes an entry for every booking changed along with the
+// room on behalf of the actor of the room entry
+func recordBookings(ctx context.Context, tx *sql.Tx, entry *models.AuditEntry, action string,
+	bookings []*models.Booking) error {
+	for _, booking := range bookings {
+		bookingEntry := &models.AuditEntry{
+			Actor:     entry.Actor,
+			Action:    action,
+			Entity:    models.AuditEntityBooking,
+			EntityID:  booking.ID,
+			RequestID: entry.RequestID,
+			Tenant:    entry.Tenant,
+		}
+		if action == models.AuditActionDelete {
+			bookingEntry.Before = booking
+		} else {
+			bookingEntry.After = booking
+		}
+		if err := auditRepository.InsertEntry(ctx, tx, bookingEntry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRoomAndBookings only marks the room and its bookings deleted, they
+// are removed for good by PurgeDeletedRooms after the retention period. No
+// rows means the room is not found in the version. The version of the deleted
+// room is returned
+func (rep *RoomRepository) DeleteRoomAndBookings(ctx context.Context, tenant uint64, id uint64,
+	version uint64, entry *models.AuditEntry, event *models.Event) (uint64, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	var deletedAt time.Time
+	var deletedVersion uint64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE rooms
+		SET deleted_at=now(), version=version+1
+		WHERE id=$1 AND tenant=$2 AND deleted_at IS NULL AND ($3=0 OR version=$3)
+		RETURNING deleted_at, version`, id, tenant, version).
+		Scan(&deletedAt, &deletedVersion)
+	if err != nil {
+		rollback(ctx, tx)
+		return 0, err
+	}
+
+	bookings, err := bookingRepository.DeleteRoomBookings(ctx, tx, tenant, id, deletedAt)
+	if err != nil {
+		rollback(ctx, tx)
+		return 0, err
+	}
+	if err := recordBookings(ctx, tx, entry, models.AuditActionDelete, bookings); err != nil {
+		rollback(ctx, tx)
+		return 0, err
+	}
+
+	if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+		rollback(ctx, tx)
+		return 0, err
+	}
+	if err := outboxRepository.InsertEvent(ctx, tx, event); err != nil {
+		rollback(ctx, tx)
+		return 0, err
+	}
+
+	if err := transaction.Commit(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	return deletedVersion, nil
+}
+
+// RestoreRoom brings back the deleted room together with the bookings deleted
+// along with it, only while it is in the version. The restored room is the
+// After state of the entry
+func (rep *RoomRepository) RestoreRoom(ctx context.Context, tenant uint64, id uint64,
+	version uint64, entry *models.AuditEntry) (*models.Room, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletedAt time.Time
+	var deletedVersion uint64
+	err = tx.QueryRowContext(ctx, `
+		SELECT deleted_at, version
+		FROM rooms
+		WHERE id=$1 AND tenant=$2 AND deleted_at IS NOT NULL
+		FOR UPDATE`, id, tenant).
+		Scan(&deletedAt, &deletedVersion)
+	if err != nil {
+		rollback(ctx, tx)
+		return nil, err
+	}
+	if version != models.AnyVersion && version != deletedVersion {
+		rollback(ctx, tx)
+		return nil, room.ErrVersionMismatch
+	}
+
+	room := &models.Room{}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE rooms
+		SET deleted_at=NULL, version=version+1
+		WHERE id=$1
+		RETURNING id, description, price, created, property, version, tenant`, id).
+		Scan(&room.ID, &room.Description, &room.Price, &room.Created, &room.Property,
+			&room.Version, &room.Tenant)
+	if err != nil {
+		rollback(ctx, tx)
+		return nil, err
+	}
+
+	bookings, err := bookingRepository.RestoreRoomBookings(ctx, tx, tenant, id, deletedAt)
+	if err != nil {
+		rollback(ctx, tx)
+		return nil, err
+	}
+	if err := recordBookings(ctx, tx, entry, models.AuditActionRestore, bookings); err != nil {
+		rollback(ctx, tx)
+		return nil, err
+	}
+
+	entry.After = room
+	if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+		rollback(ctx, tx)
+		return nil, err
 	}
 
-	// Bookings will be deleted cascade
-	_, err = tx.Exec(`
+	if err := transaction.Commit(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// PurgeDeletedRooms removes the rooms deleted before deletedBefore for good,
+// their bookings are removed cascade. Every room is recorded as purged by the actor
+func (rep *RoomRepository) PurgeDeletedRooms(ctx context.Context, deletedBefore time.Time,
+	actor *models.Actor) (int64, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
 		DELETE
 		FROM rooms
-		WHERE id=$1`, id)
+		WHERE deleted_at < $1
+		RETURNING id, description, price, created, property, version, tenant`, deletedBefore)
 	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			logrus.Info(rollbackErr)
-		}
-		return err
+		rollback(ctx, tx)
+		return 0, err
+	}
+	rooms, err := scanRooms(rows)
+	if err != nil {
+		rollback(ctx, tx)
+		return 0, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return err
+	for _, room := range rooms {
+		entry := actor.Entry(room.Tenant, models.AuditActionPurge, models.AuditEntityRoom,
+			room.ID, room, nil)
+		if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+			rollback(ctx, tx)
+			return 0, err
+		}
 	}
 
-	return nil
+	if err := transaction.Commit(ctx, tx); err != nil {
+		return 0, err
+	}
+	return int64(len(rooms)), nil
 }
 
 func createSelectQuery(sort *models.Sort) string {
-	query := "SELECT id, description, price, created FROM rooms"
+	query := "SELECT id, description, price, created, property, version, tenant FROM rooms " +
+		"WHERE tenant=$1 AND deleted_at IS NULL"
 	switch sort.OrderBy {
 	case "price":
 		query = strings.Join([]string{query, "ORDER BY price"}, " ")
@@ -94,20 +292,14 @@ func createSelectQuery(sort *models.Sort) string {
 	return query
 }
 
-func (rep *RoomRepository) SelectRooms(sort *models.Sort) ([]*models.Room, error) {
-	query := createSelectQuery(sort)
-
-	rows, err := rep.db.Query(query)
-	if err != nil {
-		return nil, err
-	}
+func scanRooms(rows *sql.Rows) ([]*models.Room, error) {
 	defer rows.Close()
 
 	var rooms []*models.Room
 	for rows.Next() {
 		room := &models.Room{}
-		if err := rows.Scan(&room.ID, &room.Description,
-			&room.Price, &room.Created); err != nil {
+		if err := rows.Scan(&room.ID, &room.Description, &room.Price, &room.Created,
+			&room.Property, &room.Version, &room.Tenant); err != nil {
 			return nil, err
 		}
 		rooms = append(rooms, room)
@@ -118,3 +310,92 @@ func (rep *RoomRepository) SelectRooms(sort *models.Sort) ([]*models.Room, error
 	}
 	return rooms, nil
 }
+
+func (rep *RoomRepository) SelectRooms(ctx context.Context, tenant uint64,
+	sort *models.Sort) ([]*models.Room, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	rows, err := transaction.Querier(ctx, rep.db).QueryContext(ctx, createSelectQuery(sort), tenant)
+	if err != nil {
+		return nil, err
+	}
+	return scanRooms(rows)
+}
+
+func (rep *RoomRepository) SelectCancellationPolicy(ctx context.Context, tenant uint64,
+	roomID uint64) (*models.CancellationPolicy, error) {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	policy := &models.CancellationPolicy{}
+	err := transaction.Querier(ctx, rep.db).QueryRowContext(ctx, `
+		SELECT p.room, p.free_days, p.penalty_type, p.penalty_percent
+		FROM cancellation_policies p
+		JOIN rooms r ON r.id = p.room
+		WHERE p.room=$1 AND r.tenant=$2 AND r.deleted_at IS NULL`, roomID, tenant).
+		Scan(&policy.Room, &policy.FreeDays, &policy.PenaltyType, &policy.PenaltyPercent)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpsertCancellationPolicy changes the room along with its policy, sql.ErrNoRows
+// means the room belongs to another tenant or is not found in the version
+func (rep *RoomRepository) UpsertCancellationPolicy(ctx context.Context, tenant uint64,
+	policy *models.CancellationPolicy, version uint64, entry *models.AuditEntry) error {
+	ctx, cancel := query.WithTimeout(ctx, rep.timeout)
+	defer cancel()
+
+	tx, err := transaction.Begin(ctx, rep.db)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE rooms
+		SET version=version+1
+		WHERE id=$1 AND tenant=$2 AND deleted_at IS NULL AND ($3=0 OR version=$3)`,
+		policy.Room, tenant, version)
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		rollback(ctx, tx)
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+
+	var roomID uint64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO cancellation_policies(room, free_days, penalty_type, penalty_percent)
+		SELECT id, $2::int, $3::text, $4::int
+		FROM rooms
+		WHERE id=$1 AND tenant=$5 AND deleted_at IS NULL
+		ON CONFLICT (room) DO UPDATE
+		SET free_days=excluded.free_days,
+			penalty_type=excluded.penalty_type,
+			penalty_percent=excluded.penalty_percent
+		RETURNING room`,
+		policy.Room, policy.FreeDays, policy.PenaltyType, policy.PenaltyPercent, tenant).
+		Scan(&roomID)
+	if err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	if err := auditRepository.InsertEntry(ctx, tx, entry); err != nil {
+		rollback(ctx, tx)
+		return err
+	}
+
+	if err := transaction.Commit(ctx, tx); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/booking_backend/internal/helpers/query"
 	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/room"
 	fixtureModels "github.com/booking_backend/internal/room/fixtures"
 	"github.com/go-testfixtures/testfixtures/v3"
 	_ "github.com/lib/pq"
@@ -12,11 +15,13 @@ import (
 	"os"
 	sortPackage "sort"
 	"testing"
+	"time"
 )
 
 var (
 	db       *sql.DB
 	fixtures *testfixtures.Loader
+	ctx      = context.Background()
 )
 
 func GetTestDBConnString() string {
@@ -49,25 +54,38 @@ func prepareTestDatabase() {
 	}
 }
 
+func deleteEvent(room *models.Room) *models.Event {
+	return models.NewEvent(room.Tenant, models.EventRoomDeleted, room)
+}
+
+func deleteEntry(tenant uint64, id uint64) *models.AuditEntry {
+	return &models.AuditEntry{Tenant: tenant, Action: models.AuditActionDelete,
+		Entity: models.AuditEntityRoom, EntityID: id}
+}
+
 func TestRoomRepository_Insert_OK(t *testing.T) {
 	prepareTestDatabase()
 
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 	roomModel := fixtureModels.NewDataBuilder().CreateNewRoomModel()
 
-	err := roomRep.Insert(roomModel)
+	event := models.NewEvent(roomModel.Tenant, models.EventRoomCreated, roomModel)
+	err := roomRep.Insert(ctx, roomModel, &models.AuditEntry{Tenant: roomModel.Tenant,
+		Action: models.AuditActionCreate, Entity: models.AuditEntityRoom}, event)
 
 	// fixture id logic
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(10001), roomModel.ID)
+	assert.Equal(t, uint64(1), roomModel.Version)
+	assert.NotZero(t, event.ID)
 }
 
 func TestRoomRepository_SelectByID(t *testing.T) {
 	prepareTestDatabase()
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 	existedRoom := fixtureModels.NewDataBuilder().CreateFirstRoom()
 
-	actualRoom, err := roomRep.SelectByID(existedRoom.ID)
+	actualRoom, err := roomRep.SelectByID(ctx, models.DefaultTenantID, existedRoom.ID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, existedRoom, actualRoom)
@@ -75,17 +93,17 @@ func TestRoomRepository_SelectByID(t *testing.T) {
 
 func TestRoomRepository_SelectByID_NoRows(t *testing.T) {
 	prepareTestDatabase()
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 
 	roomModel := fixtureModels.NewDataBuilder().CreateNewRoomModel()
-	_, err := roomRep.SelectByID(roomModel.ID)
+	_, err := roomRep.SelectByID(ctx, models.DefaultTenantID, roomModel.ID)
 
 	assert.Error(t, err)
 }
 
 func TestRoomRepository_SelectRooms_Created_ASC(t *testing.T) {
 	prepareTestDatabase()
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 
 	sort := &models.Sort{
 		OrderBy: "created",
@@ -97,7 +115,7 @@ func TestRoomRepository_SelectRooms_Created_ASC(t *testing.T) {
 		return existedRooms[i].Created.Before(existedRooms[j].Created)
 	})
 
-	actualRooms, err := roomRep.SelectRooms(sort)
+	actualRooms, err := roomRep.SelectRooms(ctx, models.DefaultTenantID, sort)
 
 	assert.NoError(t, err)
 	assert.Equal(t, existedRooms, actualRooms)
@@ -105,7 +123,7 @@ func TestRoomRepository_SelectRooms_Created_ASC(t *testing.T) {
 
 func TestRoomRepository_SelectRooms_Price_ASC(t *testing.T) {
 	prepareTestDatabase()
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 
 	sort := &models.Sort{
 		OrderBy: "price",
@@ -117,7 +135,7 @@ func TestRoomRepository_SelectRooms_Price_ASC(t *testing.T) {
 		return existedRooms[i].Price < existedRooms[j].Price
 	})
 
-	actualRooms, err := roomRep.SelectRooms(sort)
+	actualRooms, err := roomRep.SelectRooms(ctx, models.DefaultTenantID, sort)
 
 	assert.NoError(t, err)
 	assert.Equal(t, existedRooms, actualRooms)
@@ -125,7 +143,7 @@ func TestRoomRepository_SelectRooms_Price_ASC(t *testing.T) {
 
 func TestRoomRepository_SelectRooms_Price_DESC(t *testing.T) {
 	prepareTestDatabase()
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 
 	sort := &models.Sort{
 		OrderBy: "price",
@@ -137,7 +155,7 @@ func TestRoomRepository_SelectRooms_Price_DESC(t *testing.T) {
 		return existedRooms[i].Price > existedRooms[j].Price
 	})
 
-	actualRooms, err := roomRep.SelectRooms(sort)
+	actualRooms, err := roomRep.SelectRooms(ctx, models.DefaultTenantID, sort)
 
 	assert.NoError(t, err)
 	assert.Equal(t, existedRooms, actualRooms)
@@ -145,7 +163,7 @@ func TestRoomRepository_SelectRooms_Price_DESC(t *testing.T) {
 
 func TestRoomRepository_SelectRooms_Created_DESC(t *testing.T) {
 	prepareTestDatabase()
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 
 	sort := &models.Sort{
 		OrderBy: "created",
@@ -157,7 +175,7 @@ func TestRoomRepository_SelectRooms_Created_DESC(t *testing.T) {
 		return existedRooms[i].Created.After(existedRooms[j].Created)
 	})
 
-	actualRooms, err := roomRep.SelectRooms(sort)
+	actualRooms, err := roomRep.SelectRooms(ctx, models.DefaultTenantID, sort)
 
 	assert.NoError(t, err)
 	assert.Equal(t, existedRooms, actualRooms)
@@ -165,13 +183,110 @@ func TestRoomRepository_SelectRooms_Created_DESC(t *testing.T) {
 
 func TestRoomRepository_DeleteRoomAndBookings(t *testing.T) {
 	prepareTestDatabase()
-	roomRep := NewRoomRepository(db)
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
 	existedRoom := fixtureModels.NewDataBuilder().CreateFirstRoom()
 
-	err := roomRep.DeleteRoomAndBookings(existedRoom.ID)
+	_, err := roomRep.DeleteRoomAndBookings(ctx, models.DefaultTenantID, existedRoom.ID, existedRoom.Version,
+		deleteEntry(models.DefaultTenantID, existedRoom.ID), deleteEvent(existedRoom))
 
 	assert.NoError(t, err)
 
-	_, err = roomRep.SelectByID(existedRoom.ID)
+	_, err = roomRep.SelectByID(ctx, models.DefaultTenantID, existedRoom.ID)
 	assert.Error(t, err)
 }
+
+func TestRoomRepository_DeleteRoomAndBookings_VersionMismatch(t *testing.T) {
+	prepareTestDatabase()
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
+	existedRoom := fixtureModels.NewDataBuilder().CreateFirstRoom()
+
+	_, err := roomRep.DeleteRoomAndBookings(ctx, models.DefaultTenantID, existedRoom.ID, existedRoom.Version+1,
+		deleteEntry(models.DefaultTenantID, existedRoom.ID), deleteEvent(existedRoom))
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	actualRoom, err := roomRep.SelectByID(ctx, models.DefaultTenantID, existedRoom.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, existedRoom, actualRoom)
+}
+
+func TestRoomRepository_OtherTenant(t *testing.T) {
+	prepareTestDatabase()
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
+	otherRoom := fixtureModels.NewDataBuilder().CreateOtherTenantRoom()
+
+	_, err := roomRep.SelectByID(ctx, models.DefaultTenantID, otherRoom.ID)
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	_, err = roomRep.DeleteRoomAndBookings(ctx, models.DefaultTenantID, otherRoom.ID, models.AnyVersion,
+		deleteEntry(models.DefaultTenantID, otherRoom.ID), deleteEvent(otherRoom))
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	actualRoom, err := roomRep.SelectByID(ctx, otherRoom.Tenant, otherRoom.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, otherRoom, actualRoom)
+
+	actualRooms, err := roomRep.SelectRooms(ctx, otherRoom.Tenant, &models.Sort{OrderBy: "created"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Room{otherRoom}, actualRooms)
+}
+
+func TestRoomRepository_RestoreRoom(t *testing.T) {
+	prepareTestDatabase()
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
+	existedRoom := fixtureModels.NewDataBuilder().CreateFirstRoom()
+
+	_, err := roomRep.RestoreRoom(ctx, models.DefaultTenantID, existedRoom.ID, models.AnyVersion,
+		&models.AuditEntry{Tenant: models.DefaultTenantID, Action: models.AuditActionRestore,
+			Entity: models.AuditEntityRoom, EntityID: existedRoom.ID})
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	deletedVersion, err := roomRep.DeleteRoomAndBookings(ctx, models.DefaultTenantID, existedRoom.ID,
+		existedRoom.Version, deleteEntry(models.DefaultTenantID, existedRoom.ID), deleteEvent(existedRoom))
+	assert.NoError(t, err)
+	assert.Equal(t, existedRoom.Version+1, deletedVersion)
+
+	actualRooms, err := roomRep.SelectRooms(ctx, models.DefaultTenantID, &models.Sort{OrderBy: "created"})
+	assert.NoError(t, err)
+	assert.NotContains(t, actualRooms, existedRoom)
+
+	_, err = roomRep.RestoreRoom(ctx, models.DefaultTenantID, existedRoom.ID, existedRoom.Version,
+		&models.AuditEntry{Tenant: models.DefaultTenantID, Action: models.AuditActionRestore,
+			Entity: models.AuditEntityRoom, EntityID: existedRoom.ID})
+	assert.Equal(t, room.ErrVersionMismatch, err)
+
+	restoredRoom, err := roomRep.RestoreRoom(ctx, models.DefaultTenantID, existedRoom.ID, deletedVersion,
+		&models.AuditEntry{Tenant: models.DefaultTenantID, Action: models.AuditActionRestore,
+			Entity: models.AuditEntityRoom, EntityID: existedRoom.ID})
+	assert.NoError(t, err)
+	// Both the deletion and the restoration change the room
+	existedRoom.Version += 2
+	assert.Equal(t, existedRoom, restoredRoom)
+
+	actualRoom, err := roomRep.SelectByID(ctx, models.DefaultTenantID, existedRoom.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, existedRoom, actualRoom)
+}
+
+func TestRoomRepository_PurgeDeletedRooms(t *testing.T) {
+	prepareTestDatabase()
+	roomRep := NewRoomRepository(db, query.DefaultTimeout)
+	existedRoom := fixtureModels.NewDataBuilder().CreateFirstRoom()
+
+	_, err := roomRep.DeleteRoomAndBookings(ctx, models.DefaultTenantID, existedRoom.ID, existedRoom.Version,
+		deleteEntry(models.DefaultTenantID, existedRoom.ID), deleteEvent(existedRoom))
+	assert.NoError(t, err)
+
+	// The room is kept for the retention period
+	purged, err := roomRep.PurgeDeletedRooms(ctx, time.Now().Add(-time.Hour), models.SystemActor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = roomRep.PurgeDeletedRooms(ctx, time.Now().Add(time.Minute), models.SystemActor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	_, err = roomRep.RestoreRoom(ctx, models.DefaultTenantID, existedRoom.ID, models.AnyVersion,
+		&models.AuditEntry{Tenant: models.DefaultTenantID, Action: models.AuditActionRestore,
+			Entity: models.AuditEntityRoom, EntityID: existedRoom.ID})
+	assert.Equal(t, sql.ErrNoRows, err)
+}
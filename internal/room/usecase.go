@@ -1,12 +1,27 @@
 package room
 
 import (
+	"context"
 	"github.com/booking_backend/internal/helpers/errors"
 	"github.com/booking_backend/internal/models"
+	"time"
 )
 
+// RoomUseCase runs the queries with the context of the request, so they stop
+// along with it
 type RoomUseCase interface {
-	CreateRoom(room *models.Room) *errors.Error
-	DeleteRoomAndBookings(id uint64) *errors.Error
-	GetRoomsList(sort *models.Sort) ([]*models.Room, *errors.Error)
+	CreateRoom(ctx context.Context, actor *models.Actor, room *models.Room) *errors.Error
+	// The changes are made only to the version of the room, AnyVersion matches any.
+	// The room is deleted in a new version, the one RestoreRoom expects
+	DeleteRoomAndBookings(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		version uint64) (uint64, *errors.Error)
+	RestoreRoom(ctx context.Context, tenant uint64, actor *models.Actor, id uint64,
+		version uint64) (*models.Room, *errors.Error)
+	PurgeDeletedRooms(ctx context.Context, deletedBefore time.Time) (int64, *errors.Error)
+	GetRoom(ctx context.Context, tenant uint64, id uint64) (*models.Room, *errors.Error)
+	GetRoomsList(ctx context.Context, tenant uint64, sort *models.Sort) ([]*models.Room, *errors.Error)
+	GetCancellationPolicy(ctx context.Context, tenant uint64,
+		roomID uint64) (*models.CancellationPolicy, *errors.Error)
+	SetCancellationPolicy(ctx context.Context, tenant uint64, actor *models.Actor,
+		policy *models.CancellationPolicy, version uint64) *errors.Error
 }
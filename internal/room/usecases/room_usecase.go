@@ -1,46 +1,127 @@
 package usecases
 
 import (
+	"context"
 	"database/sql"
 	"github.com/booking_backend/internal/consts"
 	"github.com/booking_backend/internal/helpers/errors"
 	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/property"
 	"github.com/booking_backend/internal/room"
+	"time"
 )
 
 type RoomUseCase struct {
-	roomsRep room.RoomRepository
+	roomsRep     room.RoomRepository
+	propertyRepo property.PropertyRepository
 }
 
-func NewRoomUseCase(rep room.RoomRepository) room.RoomUseCase {
-	return &RoomUseCase{roomsRep: rep}
+func NewRoomUseCase(rep room.RoomRepository,
+	propertyRepository property.PropertyRepository) room.RoomUseCase {
+	return &RoomUseCase{roomsRep: rep, propertyRepo: propertyRepository}
 }
 
-func (uc *RoomUseCase) CreateRoom(room *models.Room) *errors.Error {
-	err := uc.roomsRep.Insert(room)
+func (uc *RoomUseCase) CreateRoom(ctx context.Context, actor *models.Actor,
+	room *models.Room) *errors.Error {
+	_, err := uc.propertyRepo.SelectByID(room.Tenant, room.Property)
+	if err == sql.ErrNoRows {
+		return errors.Get(consts.CodePropertyDoesNotExist)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+
+	entry := actor.Entry(room.Tenant, models.AuditActionCreate, models.AuditEntityRoom,
+		0, nil, room)
+	event := models.NewEvent(room.Tenant, models.EventRoomCreated, room)
+	err = uc.roomsRep.Insert(ctx, room, entry, event)
 	if err != nil {
 		return errors.New(consts.CodeInternalError, err)
 	}
 	return nil
 }
 
-func (uc *RoomUseCase) DeleteRoomAndBookings(id uint64) *errors.Error {
-	_, err := uc.roomsRep.SelectByID(id)
+func (uc *RoomUseCase) DeleteRoomAndBookings(ctx context.Context, tenant uint64, actor *models.Actor,
+	id uint64, version uint64) (uint64, *errors.Error) {
+	deleted, customErr := uc.selectVersion(ctx, tenant, id, version)
+	if customErr != nil {
+		return 0, customErr
+	}
+
+	entry := actor.Entry(tenant, models.AuditActionDelete, models.AuditEntityRoom,
+		id, deleted, nil)
+	event := models.NewEvent(tenant, models.EventRoomDeleted, deleted)
+	deletedVersion, err := uc.roomsRep.DeleteRoomAndBookings(ctx, tenant, id, version, entry, event)
 	if err == sql.ErrNoRows {
-		return errors.Get(consts.CodeRoomDoesNotExist)
+		return 0, errors.Get(consts.CodeVersionMismatch)
 	} else if err != nil {
-		return errors.New(consts.CodeInternalError, err)
+		return 0, errors.New(consts.CodeInternalError, err)
 	}
+	return deletedVersion, nil
+}
 
-	err = uc.roomsRep.DeleteRoomAndBookings(id)
+// RestoreRoom brings back the deleted room with its bookings until it is
+// purged. Restoring a room which is not deleted just returns it, so a repeated
+// restore succeeds whatever the version
+func (uc *RoomUseCase) RestoreRoom(ctx context.Context, tenant uint64, actor *models.Actor,
+	id uint64, version uint64) (*models.Room, *errors.Error) {
+	active, err := uc.roomsRep.SelectByID(ctx, tenant, id)
+	if err == nil {
+		return active, nil
+	} else if err != sql.ErrNoRows {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	entry := actor.Entry(tenant, models.AuditActionRestore, models.AuditEntityRoom,
+		id, nil, nil)
+	restored, err := uc.roomsRep.RestoreRoom(ctx, tenant, id, version, entry)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeRoomDoesNotExist)
+	} else if err == room.ErrVersionMismatch {
+		return nil, errors.Get(consts.CodeVersionMismatch)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return restored, nil
+}
+
+// PurgeDeletedRooms removes the rooms deleted before deletedBefore for good
+func (uc *RoomUseCase) PurgeDeletedRooms(ctx context.Context,
+	deletedBefore time.Time) (int64, *errors.Error) {
+	purged, err := uc.roomsRep.PurgeDeletedRooms(ctx, deletedBefore, models.SystemActor)
 	if err != nil {
-		return errors.New(consts.CodeInternalError, err)
+		return 0, errors.New(consts.CodeInternalError, err)
 	}
-	return nil
+	return purged, nil
 }
 
-func (uc *RoomUseCase) GetRoomsList(sort *models.Sort) ([]*models.Room, *errors.Error) {
-	rooms, err := uc.roomsRep.SelectRooms(sort)
+func (uc *RoomUseCase) GetRoom(ctx context.Context, tenant uint64,
+	id uint64) (*models.Room, *errors.Error) {
+	room, err := uc.roomsRep.SelectByID(ctx, tenant, id)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeRoomDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return room, nil
+}
+
+// selectVersion returns the room only while it is in the version the caller
+// has seen, the repository checks the version once more on the change
+func (uc *RoomUseCase) selectVersion(ctx context.Context, tenant uint64, id uint64,
+	version uint64) (*models.Room, *errors.Error) {
+	room, customErr := uc.GetRoom(ctx, tenant, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if version != models.AnyVersion && version != room.Version {
+		return nil, errors.Get(consts.CodeVersionMismatch)
+	}
+	return room, nil
+}
+
+func (uc *RoomUseCase) GetRoomsList(ctx context.Context, tenant uint64,
+	sort *models.Sort) ([]*models.Room, *errors.Error) {
+	rooms, err := uc.roomsRep.SelectRooms(ctx, tenant, sort)
 	if err == nil && rooms == nil {
 		return []*models.Room{}, nil
 	} else if err != nil {
@@ -48,3 +129,48 @@ func (uc *RoomUseCase) GetRoomsList(sort *models.Sort) ([]*models.Room, *errors.
 	}
 	return rooms, nil
 }
+
+// GetCancellationPolicy returns free cancellation policy for rooms without
+// a configured one
+func (uc *RoomUseCase) GetCancellationPolicy(ctx context.Context, tenant uint64,
+	roomID uint64) (*models.CancellationPolicy, *errors.Error) {
+	_, err := uc.roomsRep.SelectByID(ctx, tenant, roomID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeRoomDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	policy, err := uc.roomsRep.SelectCancellationPolicy(ctx, tenant, roomID)
+	if err == sql.ErrNoRows {
+		return &models.CancellationPolicy{Room: roomID, PenaltyType: models.PenaltyTypePercent}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return policy, nil
+}
+
+func (uc *RoomUseCase) SetCancellationPolicy(ctx context.Context, tenant uint64, actor *models.Actor,
+	policy *models.CancellationPolicy, version uint64) *errors.Error {
+	if _, customErr := uc.selectVersion(ctx, tenant, policy.Room, version); customErr != nil {
+		return customErr
+	}
+
+	entry := actor.Entry(tenant, models.AuditActionCreate, models.AuditEntityCancellationPolicy,
+		policy.Room, nil, policy)
+	previous, err := uc.roomsRep.SelectCancellationPolicy(ctx, tenant, policy.Room)
+	if err == nil {
+		entry.Action = models.AuditActionUpdate
+		entry.Before = previous
+	} else if err != sql.ErrNoRows {
+		return errors.New(consts.CodeInternalError, err)
+	}
+
+	err = uc.roomsRep.UpsertCancellationPolicy(ctx, tenant, policy, version, entry)
+	if err == sql.ErrNoRows {
+		return errors.Get(consts.CodeVersionMismatch)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
@@ -1,13 +1,26 @@
 package usecases
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	auditRepository "github.com/booking_backend/internal/audit/repository"
 	bookingRepository "github.com/booking_backend/internal/booking/repository"
 	bookingUseCase "github.com/booking_backend/internal/booking/usecases"
 	"github.com/booking_backend/internal/consts"
 	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/query"
+	"github.com/booking_backend/internal/helpers/transaction"
 	"github.com/booking_backend/internal/models"
+	outboxRepository "github.com/booking_backend/internal/outbox/repository"
+	"github.com/booking_backend/internal/payment/gateway"
+	paymentRepository "github.com/booking_backend/internal/payment/repository"
+	paymentUseCase "github.com/booking_backend/internal/payment/usecases"
+	promoRepository "github.com/booking_backend/internal/promo/repository"
+	promoUseCase "github.com/booking_backend/internal/promo/usecases"
+	propertyRepository "github.com/booking_backend/internal/property/repository"
+	propertyUseCase "github.com/booking_backend/internal/property/usecases"
 	fixtureModels "github.com/booking_backend/internal/room/fixtures"
 	"github.com/booking_backend/internal/room/repository"
 	"github.com/go-testfixtures/testfixtures/v3"
@@ -17,6 +30,7 @@ import (
 	"os"
 	"sort"
 	"testing"
+	"time"
 )
 
 var (
@@ -24,6 +38,10 @@ var (
 	fixtures *testfixtures.Loader
 )
 
+var ctx = context.Background()
+
+var actor = &models.Actor{Subject: "manager@hotel", RequestID: "request"}
+
 func GetTestDBConnString() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		"localhost", 5432, "postgres", "postgres", "booking_test")
@@ -57,11 +75,11 @@ func prepareTestDatabase() {
 func TestRoomUseCase_CreateRoom_OK(t *testing.T) {
 	prepareTestDatabase()
 
-	rep := repository.NewRoomRepository(db)
-	roomUseCase := NewRoomUseCase(rep)
+	rep := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(rep, propertyRepository.NewPropertyRepository(db))
 	roomModel := fixtureModels.NewDataBuilder().CreateNewRoomModel()
 
-	err := roomUseCase.CreateRoom(roomModel)
+	err := roomUseCase.CreateRoom(ctx, actor, roomModel)
 
 	assert.Nil(t, err)
 	assert.Equal(t, uint64(10001), roomModel.ID)
@@ -69,35 +87,79 @@ func TestRoomUseCase_CreateRoom_OK(t *testing.T) {
 
 func TestRoomUseCase_DeleteRoomAndBookings(t *testing.T) {
 	prepareTestDatabase()
-	roomRepository := repository.NewRoomRepository(db)
-	roomUseCase := NewRoomUseCase(roomRepository)
-	bookingRep := bookingRepository.NewBookingRepository(db)
-	bookingUseCase := bookingUseCase.NewBookingUseCase(bookingRep, roomRepository)
-
-	customErr := roomUseCase.DeleteRoomAndBookings(4)
+	roomRepository := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(roomRepository, propertyRepository.NewPropertyRepository(db))
+	bookingRep := bookingRepository.NewBookingRepository(db, query.DefaultTimeout)
+	paymentUseCase := paymentUseCase.NewPaymentUseCase(
+		paymentRepository.NewPaymentRepository(db), gateway.NewFakeGateway())
+	propertyUseCase := propertyUseCase.NewPropertyUseCase(
+		propertyRepository.NewPropertyRepository(db))
+	transactions := transaction.NewManager(db, query.DefaultTimeout, transaction.RetryPolicy{Attempts: 1})
+	bookingUseCase := bookingUseCase.NewBookingUseCase(transactions, bookingRep, roomRepository,
+		propertyUseCase, promoUseCase.NewPromoUseCase(promoRepository.NewPromoRepository(db)),
+		paymentUseCase, time.Minute)
+
+	_, customErr := roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, 4, models.AnyVersion)
 	assert.Nil(t, customErr)
 
-	rooms, customErr := roomUseCase.GetRoomsList(&models.Sort{
+	rooms, customErr := roomUseCase.GetRoomsList(ctx, models.DefaultTenantID, &models.Sort{
 		OrderBy: "created",
 		Desc:    false,
 	})
 	assert.Nil(t, customErr)
 	assert.Equal(t, fixtureModels.NewDataBuilder().CreateRoomsWithoutForthOrderByCreate(), rooms)
 
-	_, customErr = bookingUseCase.GetRoomBookings(4)
+	_, customErr = bookingUseCase.GetRoomBookings(ctx, models.DefaultTenantID, 4)
 	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), customErr)
 
-	bookings, err := bookingRep.SelectRoomBookings(4)
+	bookings, err := bookingRep.SelectRoomBookings(ctx, models.DefaultTenantID, 4)
 	assert.NoError(t, err)
 	assert.Nil(t, bookings)
 }
 
+func TestRoomUseCase_DeleteRoomAndBookings_VersionMismatch(t *testing.T) {
+	prepareTestDatabase()
+	roomUseCase := NewRoomUseCase(repository.NewRoomRepository(db, query.DefaultTimeout),
+		propertyRepository.NewPropertyRepository(db))
+
+	room, customErr := roomUseCase.GetRoom(ctx, models.DefaultTenantID, 4)
+	assert.Nil(t, customErr)
+
+	_, customErr = roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, 4, room.Version+1)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), customErr)
+
+	_, customErr = roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, 4, room.Version)
+	assert.Nil(t, customErr)
+}
+
+func TestRoomUseCase_SetCancellationPolicy_Version(t *testing.T) {
+	prepareTestDatabase()
+	roomUseCase := NewRoomUseCase(repository.NewRoomRepository(db, query.DefaultTimeout),
+		propertyRepository.NewPropertyRepository(db))
+	policy := &models.CancellationPolicy{Room: 4, FreeDays: 3, PenaltyType: models.PenaltyTypePercent,
+		PenaltyPercent: 50}
+
+	room, customErr := roomUseCase.GetRoom(ctx, models.DefaultTenantID, 4)
+	assert.Nil(t, customErr)
+
+	customErr = roomUseCase.SetCancellationPolicy(ctx, models.DefaultTenantID, actor, policy, room.Version)
+	assert.Nil(t, customErr)
+
+	// The version seen before the change is stale now
+	customErr = roomUseCase.SetCancellationPolicy(ctx, models.DefaultTenantID, actor, policy, room.Version)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), customErr)
+
+	changed, customErr := roomUseCase.GetRoom(ctx, models.DefaultTenantID, 4)
+	assert.Nil(t, customErr)
+	assert.Equal(t, room.Version+1, changed.Version)
+}
+
 func TestRoomUseCase_GetRoomsList(t *testing.T) {
 	prepareTestDatabase()
-	roomRepository := repository.NewRoomRepository(db)
-	roomUseCase := NewRoomUseCase(roomRepository)
+	roomRepository := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(roomRepository, propertyRepository.NewPropertyRepository(db))
 
-	rooms, customErr := roomUseCase.GetRoomsList(&models.Sort{
+	rooms, customErr := roomUseCase.GetRoomsList(ctx, models.DefaultTenantID, &models.Sort{
 		OrderBy: "created",
 		Desc:    false,
 	})
@@ -113,10 +175,10 @@ func TestRoomUseCase_GetRoomsList(t *testing.T) {
 
 func TestRoomUseCase_GetRoomsList_Created_ASC(t *testing.T) {
 	prepareTestDatabase()
-	roomRepository := repository.NewRoomRepository(db)
-	roomUseCase := NewRoomUseCase(roomRepository)
+	roomRepository := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(roomRepository, propertyRepository.NewPropertyRepository(db))
 
-	rooms, customErr := roomUseCase.GetRoomsList(&models.Sort{
+	rooms, customErr := roomUseCase.GetRoomsList(ctx, models.DefaultTenantID, &models.Sort{
 		OrderBy: "created",
 		Desc:    true,
 	})
@@ -132,10 +194,10 @@ func TestRoomUseCase_GetRoomsList_Created_ASC(t *testing.T) {
 
 func TestRoomUseCase_GetRoomsList_Price(t *testing.T) {
 	prepareTestDatabase()
-	roomRepository := repository.NewRoomRepository(db)
-	roomUseCase := NewRoomUseCase(roomRepository)
+	roomRepository := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(roomRepository, propertyRepository.NewPropertyRepository(db))
 
-	rooms, customErr := roomUseCase.GetRoomsList(&models.Sort{
+	rooms, customErr := roomUseCase.GetRoomsList(ctx, models.DefaultTenantID, &models.Sort{
 		OrderBy: "price",
 		Desc:    false,
 	})
@@ -151,10 +213,10 @@ func TestRoomUseCase_GetRoomsList_Price(t *testing.T) {
 
 func TestRoomUseCase_GetRoomsList_Price_DESC(t *testing.T) {
 	prepareTestDatabase()
-	roomRepository := repository.NewRoomRepository(db)
-	roomUseCase := NewRoomUseCase(roomRepository)
+	roomRepository := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(roomRepository, propertyRepository.NewPropertyRepository(db))
 
-	rooms, customErr := roomUseCase.GetRoomsList(&models.Sort{
+	rooms, customErr := roomUseCase.GetRoomsList(ctx, models.DefaultTenantID, &models.Sort{
 		OrderBy: "price",
 		Desc:    true,
 	})
@@ -170,15 +232,15 @@ func TestRoomUseCase_GetRoomsList_Price_DESC(t *testing.T) {
 
 func TestRoomUseCase_GetRoomsList_Empty(t *testing.T) {
 	prepareTestDatabase()
-	roomRepository := repository.NewRoomRepository(db)
-	roomUseCase := NewRoomUseCase(roomRepository)
+	roomRepository := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(roomRepository, propertyRepository.NewPropertyRepository(db))
 
 	for _, id := range []uint64{1, 2, 3, 4} {
-		customErr := roomUseCase.DeleteRoomAndBookings(id)
+		_, customErr := roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, id, models.AnyVersion)
 		assert.Nil(t, customErr)
 	}
 
-	rooms, customErr := roomUseCase.GetRoomsList(&models.Sort{
+	rooms, customErr := roomUseCase.GetRoomsList(ctx, models.DefaultTenantID, &models.Sort{
 		OrderBy: "price",
 		Desc:    true,
 	})
@@ -186,3 +248,134 @@ func TestRoomUseCase_GetRoomsList_Empty(t *testing.T) {
 	assert.Nil(t, customErr)
 	assert.Equal(t, []*models.Room{}, rooms)
 }
+
+func TestRoomUseCase_OtherTenant(t *testing.T) {
+	prepareTestDatabase()
+	roomRepository := repository.NewRoomRepository(db, query.DefaultTimeout)
+	roomUseCase := NewRoomUseCase(roomRepository, propertyRepository.NewPropertyRepository(db))
+	bookingRep := bookingRepository.NewBookingRepository(db, query.DefaultTimeout)
+	otherRoom := fixtureModels.NewDataBuilder().CreateOtherTenantRoom()
+
+	_, customErr := roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, otherRoom.ID,
+		models.AnyVersion)
+	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), customErr)
+
+	bookings, err := bookingRep.SelectRoomBookings(ctx, models.DefaultTenantID, otherRoom.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, bookings)
+
+	bookings, err = bookingRep.SelectRoomBookings(ctx, otherRoom.Tenant, otherRoom.ID)
+	assert.NoError(t, err)
+	assert.Len(t, bookings, 1)
+}
+
+func TestRoomUseCase_DeleteRoomAndBookings_Audit(t *testing.T) {
+	prepareTestDatabase()
+	roomUseCase := NewRoomUseCase(repository.NewRoomRepository(db, query.DefaultTimeout),
+		propertyRepository.NewPropertyRepository(db))
+	auditRep := auditRepository.NewAuditRepository(db)
+	deletedBy := &models.Actor{Subject: "auditor@hotel", RequestID: fmt.Sprint(time.Now().UnixNano())}
+	since := time.Now().Add(-time.Minute)
+
+	_, customErr := roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, deletedBy, 4, models.AnyVersion)
+	assert.Nil(t, customErr)
+
+	// Bookings deleted along with the room are recorded too
+	entries, err := auditRep.SelectEntries(&models.AuditFilter{
+		Tenant: models.DefaultTenantID,
+		From:   &since,
+	})
+	assert.NoError(t, err)
+	var deleted []string
+	for _, entry := range entries {
+		if entry.RequestID != deletedBy.RequestID {
+			continue
+		}
+		assert.Equal(t, deletedBy.Subject, entry.Actor)
+		assert.Equal(t, models.AuditActionDelete, entry.Action)
+		assert.NotNil(t, entry.Before)
+		assert.Nil(t, entry.After)
+		deleted = append(deleted, fmt.Sprintf("%s %d", entry.Entity, entry.EntityID))
+	}
+	sort.Strings(deleted)
+	assert.Equal(t, []string{"booking 2", "booking 3", "booking 4", "room 4"}, deleted)
+}
+
+func TestRoomUseCase_RestoreRoom(t *testing.T) {
+	prepareTestDatabase()
+	roomUseCase := NewRoomUseCase(repository.NewRoomRepository(db, query.DefaultTimeout),
+		propertyRepository.NewPropertyRepository(db))
+	bookingRep := bookingRepository.NewBookingRepository(db, query.DefaultTimeout)
+
+	deletedVersion, customErr := roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, 4,
+		models.AnyVersion)
+	assert.Nil(t, customErr)
+
+	_, customErr = roomUseCase.RestoreRoom(ctx, models.DefaultTenantID, actor, 4, deletedVersion-1)
+	assert.Equal(t, errors.Get(consts.CodeVersionMismatch), customErr)
+
+	restored, customErr := roomUseCase.RestoreRoom(ctx, models.DefaultTenantID, actor, 4, deletedVersion)
+	assert.Nil(t, customErr)
+	assert.Equal(t, uint64(4), restored.ID)
+
+	rooms, customErr := roomUseCase.GetRoomsList(ctx, models.DefaultTenantID, &models.Sort{
+		OrderBy: "created",
+		Desc:    false,
+	})
+	assert.Nil(t, customErr)
+	assert.Len(t, rooms, len(fixtureModels.NewDataBuilder().CreateAllExistedRooms()))
+
+	// Bookings deleted along with the room come back too
+	bookings, err := bookingRep.SelectRoomBookings(ctx, models.DefaultTenantID, 4)
+	assert.NoError(t, err)
+	assert.Len(t, bookings, 3)
+
+	// Restoring an active room changes nothing
+	again, customErr := roomUseCase.RestoreRoom(ctx, models.DefaultTenantID, actor, 4, models.AnyVersion)
+	assert.Nil(t, customErr)
+	assert.Equal(t, restored, again)
+}
+
+func TestRoomUseCase_RestoreRoom_Purged(t *testing.T) {
+	prepareTestDatabase()
+	roomUseCase := NewRoomUseCase(repository.NewRoomRepository(db, query.DefaultTimeout),
+		propertyRepository.NewPropertyRepository(db))
+
+	_, customErr := roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, 4, models.AnyVersion)
+	assert.Nil(t, customErr)
+
+	purged, customErr := roomUseCase.PurgeDeletedRooms(ctx, time.Now().Add(time.Minute))
+	assert.Nil(t, customErr)
+	assert.Equal(t, int64(1), purged)
+
+	_, customErr = roomUseCase.RestoreRoom(ctx, models.DefaultTenantID, actor, 4, models.AnyVersion)
+	assert.Equal(t, errors.Get(consts.CodeRoomDoesNotExist), customErr)
+}
+
+func TestRoomUseCase_DeleteRoomAndBookings_Event(t *testing.T) {
+	prepareTestDatabase()
+	roomUseCase := NewRoomUseCase(repository.NewRoomRepository(db, query.DefaultTimeout),
+		propertyRepository.NewPropertyRepository(db))
+	outboxRep := outboxRepository.NewOutboxRepository(db)
+
+	_, customErr := roomUseCase.DeleteRoomAndBookings(ctx, models.DefaultTenantID, actor, 4, models.AnyVersion)
+	assert.Nil(t, customErr)
+
+	// The event is stored along with the deletion and waits for the dispatcher
+	events, err := outboxRep.ClaimUndelivered("test", 1000, time.Minute)
+	assert.NoError(t, err)
+	var deleted []*models.Event
+	for _, event := range events {
+		if event.Type == models.EventRoomDeleted {
+			deleted = append(deleted, event)
+		}
+	}
+	if assert.NotEmpty(t, deleted) {
+		last := deleted[len(deleted)-1]
+		assert.Equal(t, models.DefaultTenantID, last.Tenant)
+		assert.Contains(t, string(last.Payload.(json.RawMessage)), `"room_id":4`)
+		assert.NoError(t, outboxRep.MarkDelivered("test", last.ID))
+		_, err = outboxRep.MarkSent([]string{"test"})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,119 @@
+package delivery
+
+import (
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/webhook"
+	"github.com/booking_backend/tools/principal"
+	"github.com/booking_backend/tools/request_reader"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type WebhookHandler struct {
+	webhookUseCase webhook.WebhookUseCase
+}
+
+func NewWebhookHandler(useCase webhook.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{webhookUseCase: useCase}
+}
+
+func (wh *WebhookHandler) Configure(e *echo.Echo) {
+	e.POST("webhooks/create", wh.CreateWebhook(), principal.Require(rbac.ManageWebhooks))
+	e.GET("webhooks/list", wh.GetWebhooks(), principal.Require(rbac.ManageWebhooks))
+	e.DELETE("webhooks/:id", wh.DeleteWebhook(), principal.Require(rbac.ManageWebhooks))
+	e.GET("webhooks/:id/deliveries", wh.GetDeliveries(), principal.Require(rbac.ManageWebhooks))
+}
+
+type Webhook struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+func (wh *WebhookHandler) CreateWebhook() echo.HandlerFunc {
+	type Request struct {
+		URL        string   `form:"url" validate:"required,url,max=2048"`
+		EventTypes []string `form:"event_types" validate:"required,min=1,dive,oneof=RoomCreated RoomDeleted BookingCreated BookingConfirmed BookingCancelled BookingRescheduled BookingReminder DailyArrivals"`
+		Secret     string   `form:"secret" validate:"omitempty,min=16,max=128"`
+	}
+
+	return func(context echo.Context) error {
+		req := &Request{}
+		if customErr := request_reader.NewRequestReader(context).Read(req); customErr != nil {
+			logrus.Info(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		hook := &models.Webhook{
+			URL:        req.URL,
+			EventTypes: req.EventTypes,
+			Secret:     req.Secret,
+			Created:    time.Now(),
+			Tenant:     principal.Tenant(context),
+		}
+		if customErr := wh.webhookUseCase.CreateWebhook(hook); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusCreated, Webhook{Webhook: hook, Secret: hook.Secret})
+	}
+}
+
+func (wh *WebhookHandler) GetWebhooks() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		hooks, customErr := wh.webhookUseCase.GetWebhooks(principal.Tenant(context))
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, hooks)
+	}
+}
+
+func (wh *WebhookHandler) DeleteWebhook() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		webhookID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		if customErr := wh.webhookUseCase.DeleteWebhook(principal.Tenant(context), webhookID); customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, response.Response{
+			Message: "success",
+		})
+	}
+}
+
+// GetDeliveries shows the latest deliveries of the webhook first
+func (wh *WebhookHandler) GetDeliveries() echo.HandlerFunc {
+	return func(context echo.Context) error {
+		webhookID, parseErr := strconv.ParseUint(context.Param("id"), 10, 64)
+		if parseErr != nil {
+			customErr := errors.New(CodeInternalError, parseErr)
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		deliveries, customErr := wh.webhookUseCase.GetDeliveries(principal.Tenant(context), webhookID)
+		if customErr != nil {
+			logrus.Error(customErr)
+			return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+		}
+
+		return context.JSON(http.StatusOK, deliveries)
+	}
+}
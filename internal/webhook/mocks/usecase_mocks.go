@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock_webhook is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	errors "github.com/booking_backend/internal/helpers/errors"
+	models "github.com/booking_backend/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWebhookUseCase is a mock of WebhookUseCase interface
+type MockWebhookUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookUseCaseMockRecorder
+}
+
+// MockWebhookUseCaseMockRecorder is the mock recorder for MockWebhookUseCase
+type MockWebhookUseCaseMockRecorder struct {
+	mock *MockWebhookUseCase
+}
+
+// NewMockWebhookUseCase creates a new mock instance
+func NewMockWebhookUseCase(ctrl *gomock.Controller) *MockWebhookUseCase {
+	mock := &MockWebhookUseCase{ctrl: ctrl}
+	mock.recorder = &MockWebhookUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWebhookUseCase) EXPECT() *MockWebhookUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method
+func (m *MockWebhookUseCase) CreateWebhook(webhook *models.Webhook) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", webhook)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook
+func (mr *MockWebhookUseCaseMockRecorder) CreateWebhook(webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookUseCase)(nil).CreateWebhook), webhook)
+}
+
+// GetWebhooks mocks base method
+func (m *MockWebhookUseCase) GetWebhooks(tenant uint64) ([]*models.Webhook, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", tenant)
+	ret0, _ := ret[0].([]*models.Webhook)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks
+func (mr *MockWebhookUseCaseMockRecorder) GetWebhooks(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookUseCase)(nil).GetWebhooks), tenant)
+}
+
+// DeleteWebhook mocks base method
+func (m *MockWebhookUseCase) DeleteWebhook(tenant, id uint64) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", tenant, id)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook
+func (mr *MockWebhookUseCaseMockRecorder) DeleteWebhook(tenant, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookUseCase)(nil).DeleteWebhook), tenant, id)
+}
+
+// GetDeliveries mocks base method
+func (m *MockWebhookUseCase) GetDeliveries(tenant, webhookID uint64) ([]*models.WebhookDelivery, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", tenant, webhookID)
+	ret0, _ := ret[0].([]*models.WebhookDelivery)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries
+func (mr *MockWebhookUseCaseMockRecorder) GetDeliveries(tenant, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookUseCase)(nil).GetDeliveries), tenant, webhookID)
+}
+
+// Enqueue mocks base method
+func (m *MockWebhookUseCase) Enqueue(event *models.Event) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", event)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue
+func (mr *MockWebhookUseCaseMockRecorder) Enqueue(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockWebhookUseCase)(nil).Enqueue), event)
+}
+
+// DeliverDue mocks base method
+func (m *MockWebhookUseCase) DeliverDue(ctx context.Context) (int, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverDue", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// DeliverDue indicates an expected call of DeliverDue
+func (mr *MockWebhookUseCaseMockRecorder) DeliverDue(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverDue", reflect.TypeOf((*MockWebhookUseCase)(nil).DeliverDue), ctx)
+}
@@ -0,0 +1,24 @@
+package webhook
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+var ErrWebhookDoesNotExist = errors.New("webhook doesn't exist")
+
+type WebhookRepository interface {
+	Insert(webhook *models.Webhook) error
+	SelectByID(tenant uint64, id uint64) (*models.Webhook, error)
+	SelectWebhooks(tenant uint64) ([]*models.Webhook, error)
+	Delete(tenant uint64, id uint64) error
+	// InsertDeliveries queues the body for every webhook of the event tenant
+	// subscribed to the event type, an event is queued once for a webhook
+	InsertDeliveries(event *models.Event, body []byte) (int64, error)
+	// ClaimDueDeliveries takes the deliveries whose attempt is due for the
+	// lease, other senders skip them until the lease expires
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	SelectDeliveries(tenant uint64, webhookID uint64, limit int) ([]*models.WebhookDelivery, error)
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/webhook"
+	"github.com/lib/pq"
+	"time"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) webhook.WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row scanner) (*models.Webhook, error) {
+	hook := &models.Webhook{}
+	if err := row.Scan(&hook.ID, &hook.URL, pq.Array(&hook.EventTypes),
+		&hook.Secret, &hook.Created, &hook.Tenant); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (rep *WebhookRepository) Insert(hook *models.Webhook) error {
+	return rep.db.QueryRow(`
+		INSERT INTO webhooks(url, event_types, secret, created, tenant)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		hook.URL, pq.Array(hook.EventTypes), hook.Secret, hook.Created, hook.Tenant).
+		Scan(&hook.ID)
+}
+
+func (rep *WebhookRepository) SelectByID(tenant uint64, id uint64) (*models.Webhook, error) {
+	row := rep.db.QueryRow(`
+		SELECT id, url, event_types, secret, created, tenant
+		FROM webhooks
+		WHERE id=$1 AND tenant=$2`, id, tenant)
+	return scanWebhook(row)
+}
+
+func (rep *WebhookRepository) SelectWebhooks(tenant uint64) ([]*models.Webhook, error) {
+	rows, err := rep.db.Query(`
+		SELECT id, url, event_types, secret, created, tenant
+		FROM webhooks
+		WHERE tenant=$1
+		ORDER BY id`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []*models.Webhook
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// Delete removes the webhook together with its delivery history
+func (rep *WebhookRepository) Delete(tenant uint64, id uint64) error {
+	res, err := rep.db.Exec(`
+		DELETE
+		FROM webhooks
+		WHERE id=$1 AND tenant=$2`, id, tenant)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return webhook.ErrWebhookDoesNotExist
+	}
+	return nil
+}
+
+func (rep *WebhookRepository) InsertDeliveries(event *models.Event, body []byte) (int64, error) {
+	res, err := rep.db.Exec(`
+		INSERT INTO webhook_deliveries(webhook, event, event_type, body, status, next_attempt)
+		SELECT id, $1, $2, $3, $4, now()
+		FROM webhooks
+		WHERE tenant=$5 AND $2=ANY(event_types)
+		ON CONFLICT (webhook, event) DO NOTHING`,
+		event.ID, event.Type, body, models.DeliveryStatusPending, event.Tenant)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+const deliveryColumns = `d.id, d.webhook, d.event, d.event_type, d.status, d.attempts,
+	d.next_attempt, d.response_code, d.last_error, d.created, d.delivered`
+
+func scanDelivery(row scanner, dest ...interface{}) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var nextAttempt, delivered sql.NullTime
+	if err := row.Scan(append([]interface{}{&delivery.ID, &delivery.Webhook, &delivery.Event,
+		&delivery.EventType, &delivery.Status, &delivery.Attempts, &nextAttempt,
+		&delivery.ResponseCode, &delivery.LastError, &delivery.Created, &delivered},
+		dest...)...); err != nil {
+		return nil, err
+	}
+	if nextAttempt.Valid {
+		delivery.NextAttempt = &nextAttempt.Time
+	}
+	if delivered.Valid {
+		delivery.Delivered = &delivered.Time
+	}
+	return delivery, nil
+}
+
+// ClaimDueDeliveries returns the pending deliveries whose attempt is due
+// along with the body, the URL and the secret to send them. Their next attempt
+// is moved to the end of the lease, so the deliveries are not sent twice by
+// concurrent senders and are sent again if the sender stops before recording
+// the attempt. The rows locked by another sender are skipped
+func (rep *WebhookRepository) ClaimDueDeliveries(limit int,
+	lease time.Duration) ([]*models.WebhookDelivery, error) {
+	rows, err := rep.db.Query(`
+		UPDATE webhook_deliveries d
+		SET next_attempt=now() + make_interval(secs => $3)
+		FROM webhooks w
+		WHERE w.id = d.webhook AND d.id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status=$1 AND next_attempt <= now()
+			ORDER BY next_attempt, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED)
+		RETURNING `+deliveryColumns+`, d.body, w.url, w.secret`,
+		models.DeliveryStatusPending, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var body []byte
+		var url, secret string
+		delivery, err := scanDelivery(rows, &body, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Body, delivery.URL, delivery.Secret = body, url, secret
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery records the result of the attempt
+func (rep *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	_, err := rep.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status=$1, attempts=$2, next_attempt=$3, response_code=$4, last_error=$5, delivered=$6
+		WHERE id=$7`,
+		delivery.Status, delivery.Attempts, delivery.NextAttempt, delivery.ResponseCode,
+		delivery.LastError, delivery.Delivered, delivery.ID)
+	return err
+}
+
+// SelectDeliveries returns the latest deliveries of the webhook first
+func (rep *WebhookRepository) SelectDeliveries(tenant uint64, webhookID uint64,
+	limit int) ([]*models.WebhookDelivery, error) {
+	rows, err := rep.db.Query(`
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook
+		WHERE d.webhook=$1 AND w.tenant=$2
+		ORDER BY d.id DESC
+		LIMIT $3`, webhookID, tenant, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
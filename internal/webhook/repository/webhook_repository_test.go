@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWebhookRepository_Insert(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	hook := &models.Webhook{
+		URL:        "https://partner.example/hooks",
+		EventTypes: []string{models.EventBookingCreated, models.EventBookingCancelled},
+		Secret:     "whsec_0123456789abcdef",
+		Created:    time.Now(),
+		Tenant:     models.DefaultTenantID,
+	}
+	mock.ExpectQuery(`INSERT INTO webhooks`).
+		WithArgs(hook.URL, "{\"BookingCreated\",\"BookingCancelled\"}", hook.Secret,
+			hook.Created, hook.Tenant).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	webhookPgRep := NewWebhookRepository(db)
+	assert.NoError(t, webhookPgRep.Insert(hook))
+	assert.Equal(t, uint64(2), hook.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_Delete_OtherTenant(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM webhooks`).
+		WithArgs(uint64(2), uint64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	webhookPgRep := NewWebhookRepository(db)
+	assert.Equal(t, webhook.ErrWebhookDoesNotExist, webhookPgRep.Delete(2, 2))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_InsertDeliveries(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	event := &models.Event{ID: 15, Type: models.EventBookingCreated, Tenant: models.DefaultTenantID}
+	body := []byte(`{"event_id":15}`)
+	mock.ExpectExec(`INSERT INTO webhook_deliveries(.+)ON CONFLICT`).
+		WithArgs(event.ID, event.Type, body, models.DeliveryStatusPending, event.Tenant).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	webhookPgRep := NewWebhookRepository(db)
+	queued, err := webhookPgRep.InsertDeliveries(event, body)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), queued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ClaimDueDeliveries(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	created := time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC)
+	next := created.Add(time.Minute)
+	expected := &models.WebhookDelivery{
+		ID:          9,
+		Webhook:     2,
+		Event:       15,
+		EventType:   models.EventBookingCreated,
+		Status:      models.DeliveryStatusPending,
+		Attempts:    1,
+		NextAttempt: &next,
+		LastError:   "webhook responded 500 Internal Server Error",
+		Created:     created,
+		Body:        []byte(`{"event_id":15}`),
+		URL:         "https://partner.example/hooks",
+		Secret:      "whsec_0123456789abcdef",
+	}
+	mock.ExpectQuery(`UPDATE webhook_deliveries d SET next_attempt(.+)FOR UPDATE SKIP LOCKED\) RETURNING`).
+		WithArgs(models.DeliveryStatusPending, 100, float64(600)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook", "event", "event_type", "status",
+			"attempts", "next_attempt", "response_code", "last_error", "created", "delivered",
+			"body", "url", "secret"}).
+			AddRow(expected.ID, expected.Webhook, expected.Event, expected.EventType,
+				expected.Status, expected.Attempts, next, 0, expected.LastError, created, nil,
+				expected.Body, expected.URL, expected.Secret))
+
+	webhookPgRep := NewWebhookRepository(db)
+	deliveries, err := webhookPgRep.ClaimDueDeliveries(100, 10*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.WebhookDelivery{expected}, deliveries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package sender
+
+import (
+	"context"
+	"github.com/booking_backend/internal/webhook"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// WebhookSender periodically sends the webhook deliveries whose attempt is due
+type WebhookSender struct {
+	webhookUseCase webhook.WebhookUseCase
+	interval       time.Duration
+}
+
+func NewWebhookSender(useCase webhook.WebhookUseCase, interval time.Duration) *WebhookSender {
+	return &WebhookSender{webhookUseCase: useCase, interval: interval}
+}
+
+// Run blocks until the context is cancelled
+func (ws *WebhookSender) Run(ctx context.Context) {
+	ticker := time.NewTicker(ws.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ws.Send(ctx)
+		}
+	}
+}
+
+// Send stops with the context, so the deliveries don't hold the shutdown
+func (ws *WebhookSender) Send(ctx context.Context) {
+	delivered, customErr := ws.webhookUseCase.DeliverDue(ctx)
+	if customErr != nil {
+		logrus.Error(customErr)
+		return
+	}
+	if delivered > 0 {
+		logrus.Infof("delivered %d webhook events", delivered)
+	}
+}
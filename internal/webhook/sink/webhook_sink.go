@@ -0,0 +1,25 @@
+package sink
+
+import (
+	"errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/outbox"
+	"github.com/booking_backend/internal/webhook"
+)
+
+// WebhookSink only queues the events for the webhooks, they are sent by the
+// sender with their own retries
+type WebhookSink struct {
+	webhookUseCase webhook.WebhookUseCase
+}
+
+func NewWebhookSink(useCase webhook.WebhookUseCase) outbox.Sink {
+	return &WebhookSink{webhookUseCase: useCase}
+}
+
+func (ws *WebhookSink) Deliver(event *models.Event) error {
+	if customErr := ws.webhookUseCase.Enqueue(event); customErr != nil {
+		return errors.New(customErr.Message)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"context"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"time"
+)
+
+// RetryPolicy describes how many times a delivery is attempted. The pause
+// between attempts starts with Backoff and doubles after every attempt
+type RetryPolicy struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+type WebhookUseCase interface {
+	// CreateWebhook generates the secret unless it is given
+	CreateWebhook(webhook *models.Webhook) *errors.Error
+	GetWebhooks(tenant uint64) ([]*models.Webhook, *errors.Error)
+	DeleteWebhook(tenant uint64, id uint64) *errors.Error
+	GetDeliveries(tenant uint64, webhookID uint64) ([]*models.WebhookDelivery, *errors.Error)
+	Enqueue(event *models.Event) *errors.Error
+	// DeliverDue sends the deliveries whose attempt is due and returns how
+	// many of them were delivered. It stops once the context is cancelled
+	DeliverDue(ctx context.Context) (int, *errors.Error)
+}
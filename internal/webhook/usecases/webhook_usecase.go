@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/safehttp"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/webhook"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+
+	secretPrefix = "whsec_"
+	secretBytes  = 32
+	// historyLimit is how many latest deliveries of a webhook are shown
+	historyLimit = 100
+	dueBatchSize = 100
+	// defaultLease is how long a batch is claimed for when the client has no timeout
+	defaultLease = 15 * time.Minute
+)
+
+type WebhookUseCase struct {
+	webhookRepo webhook.WebhookRepository
+	client      *http.Client
+	retry       webhook.RetryPolicy
+}
+
+// NewWebhookUseCase takes the client sending the deliveries, it is expected
+// to connect only to public addresses and not to follow redirects, see
+// safehttp.NewClient
+func NewWebhookUseCase(webhookRepository webhook.WebhookRepository, client *http.Client,
+	retry webhook.RetryPolicy) webhook.WebhookUseCase {
+	return &WebhookUseCase{webhookRepo: webhookRepository, client: client, retry: retry}
+}
+
+// Sign is computed over the raw body, so receivers check it before parsing
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateWebhook accepts only the URLs of public addresses. The addresses are
+// checked again by the client when the deliveries are sent, as DNS may change
+func (uc *WebhookUseCase) CreateWebhook(hook *models.Webhook) *errors.Error {
+	if err := safehttp.CheckURL(context.Background(), hook.URL); err != nil {
+		return errors.New(consts.CodeURLForbidden, err)
+	}
+
+	if hook.Secret == "" {
+		random := make([]byte, secretBytes)
+		if _, err := rand.Read(random); err != nil {
+			return errors.New(consts.CodeInternalError, err)
+		}
+		hook.Secret = secretPrefix + base64.RawURLEncoding.EncodeToString(random)
+	}
+
+	if err := uc.webhookRepo.Insert(hook); err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+func (uc *WebhookUseCase) GetWebhooks(tenant uint64) ([]*models.Webhook, *errors.Error) {
+	hooks, err := uc.webhookRepo.SelectWebhooks(tenant)
+	if err == nil && hooks == nil {
+		return []*models.Webhook{}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return hooks, nil
+}
+
+func (uc *WebhookUseCase) DeleteWebhook(tenant uint64, id uint64) *errors.Error {
+	err := uc.webhookRepo.Delete(tenant, id)
+	if err == webhook.ErrWebhookDoesNotExist {
+		return errors.Get(consts.CodeWebhookDoesNotExist)
+	} else if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+func (uc *WebhookUseCase) GetDeliveries(tenant uint64,
+	webhookID uint64) ([]*models.WebhookDelivery, *errors.Error) {
+	_, err := uc.webhookRepo.SelectByID(tenant, webhookID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Get(consts.CodeWebhookDoesNotExist)
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+
+	deliveries, err := uc.webhookRepo.SelectDeliveries(tenant, webhookID, historyLimit)
+	if err == nil && deliveries == nil {
+		return []*models.WebhookDelivery{}, nil
+	} else if err != nil {
+		return nil, errors.New(consts.CodeInternalError, err)
+	}
+	return deliveries, nil
+}
+
+// Enqueue fixes the body of the event, so every attempt sends the same bytes
+func (uc *WebhookUseCase) Enqueue(event *models.Event) *errors.Error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+
+	if _, err := uc.webhookRepo.InsertDeliveries(event, body); err != nil {
+		return errors.New(consts.CodeInternalError, err)
+	}
+	return nil
+}
+
+// lease covers the batch sent one by one, each within the client timeout
+func (uc *WebhookUseCase) lease() time.Duration {
+	if uc.client.Timeout <= 0 {
+		return defaultLease
+	}
+	return dueBatchSize * uc.client.Timeout
+}
+
+// DeliverDue leaves the rest of the batch on shutdown, the deliveries are
+// sent again once their lease expires. An attempt cut short by the shutdown
+// is not counted
+func (uc *WebhookUseCase) DeliverDue(ctx context.Context) (int, *errors.Error) {
+	deliveries, err := uc.webhookRepo.ClaimDueDeliveries(dueBatchSize, uc.lease())
+	if err != nil {
+		return 0, errors.New(consts.CodeInternalError, err)
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil || !uc.attempt(ctx, delivery) {
+			break
+		}
+		if err := uc.webhookRepo.UpdateDelivery(delivery); err != nil {
+			return delivered, errors.New(consts.CodeInternalError, err)
+		}
+		if delivery.Status == models.DeliveryStatusDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// send posts the body, any response but 2xx is a failure
+func (uc *WebhookUseCase) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL,
+		bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderSignature, Sign(delivery.Secret, delivery.Body))
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, fmt.Sprint(delivery.ID))
+
+	response, err := uc.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// The connection is reused only when the body is read
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return response.StatusCode, fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// attempt sends the delivery and schedules the next attempt if it fails.
+// The delivery is dead once it runs out of attempts. False is returned when
+// the attempt is interrupted by the context and is not to be recorded
+func (uc *WebhookUseCase) attempt(ctx context.Context, delivery *models.WebhookDelivery) bool {
+	code, err := uc.send(ctx, delivery)
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+	delivery.Attempts++
+	now := time.Now()
+	delivery.ResponseCode = code
+	delivery.NextAttempt = nil
+
+	if err == nil {
+		delivery.Status = models.DeliveryStatusDelivered
+		delivery.Delivered = &now
+		delivery.LastError = ""
+		return true
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= uc.retry.Attempts {
+		delivery.Status = models.DeliveryStatusDead
+		return true
+	}
+	next := now.Add(uc.retry.Backoff << uint(delivery.Attempts-1))
+	delivery.NextAttempt = &next
+	return true
+}
@@ -0,0 +1,268 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/internal/webhook"
+	"github.com/booking_backend/internal/webhook/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secret = "whsec_0123456789abcdef"
+
+var retry = webhook.RetryPolicy{Attempts: 3, Backoff: time.Minute}
+
+// receiver checks the signature the way a partner does and answers with status
+func receiver(t *testing.T, status int, received chan<- *http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		assert.True(t, hmac.Equal([]byte(expected), []byte(r.Header.Get(HeaderSignature))))
+
+		received <- r
+		w.WriteHeader(status)
+	}))
+}
+
+func dueDelivery(url string, attempts int) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:        9,
+		Webhook:   2,
+		Event:     15,
+		EventType: models.EventBookingCreated,
+		Status:    models.DeliveryStatusPending,
+		Attempts:  attempts,
+		Body:      []byte(`{"event_id":15,"type":"BookingCreated","payload":{"booking_id":5}}`),
+		URL:       url,
+		Secret:    secret,
+	}
+}
+
+func TestWebhookUseCase_DeliverDue_Signed(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	received := make(chan *http.Request, 1)
+	server := receiver(t, http.StatusNoContent, received)
+	defer server.Close()
+
+	delivery := dueDelivery(server.URL, 0)
+	webhookRep.EXPECT().ClaimDueDeliveries(dueBatchSize, gomock.Any()).
+		Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRep.EXPECT().UpdateDelivery(delivery).
+		DoAndReturn(func(delivery *models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryStatusDelivered, delivery.Status)
+			assert.Equal(t, 1, delivery.Attempts)
+			assert.Equal(t, http.StatusNoContent, delivery.ResponseCode)
+			assert.NotNil(t, delivery.Delivered)
+			assert.Nil(t, delivery.NextAttempt)
+			return nil
+		})
+
+	uc := NewWebhookUseCase(webhookRep, server.Client(), retry)
+	delivered, err := uc.DeliverDue(context.Background())
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, 1, delivered)
+
+	request := <-received
+	assert.Equal(t, models.EventBookingCreated, request.Header.Get(HeaderEvent))
+	assert.Equal(t, "9", request.Header.Get(HeaderDelivery))
+}
+
+func TestWebhookUseCase_DeliverDue_Backoff(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	received := make(chan *http.Request, 1)
+	server := receiver(t, http.StatusInternalServerError, received)
+	defer server.Close()
+
+	// The second failed attempt waits twice as long as the first one
+	delivery := dueDelivery(server.URL, 1)
+	webhookRep.EXPECT().ClaimDueDeliveries(dueBatchSize, gomock.Any()).
+		Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRep.EXPECT().UpdateDelivery(delivery).
+		DoAndReturn(func(delivery *models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryStatusPending, delivery.Status)
+			assert.Equal(t, 2, delivery.Attempts)
+			assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+			assert.True(t, strings.Contains(delivery.LastError, "500"))
+			assert.WithinDuration(t, time.Now().Add(2*retry.Backoff), *delivery.NextAttempt, time.Second)
+			return nil
+		})
+
+	uc := NewWebhookUseCase(webhookRep, server.Client(), retry)
+	delivered, err := uc.DeliverDue(context.Background())
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, 0, delivered)
+	<-received
+}
+
+func TestWebhookUseCase_DeliverDue_DeadLetter(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	received := make(chan *http.Request, 1)
+	server := receiver(t, http.StatusGone, received)
+	defer server.Close()
+
+	delivery := dueDelivery(server.URL, retry.Attempts-1)
+	webhookRep.EXPECT().ClaimDueDeliveries(dueBatchSize, gomock.Any()).
+		Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRep.EXPECT().UpdateDelivery(delivery).
+		DoAndReturn(func(delivery *models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryStatusDead, delivery.Status)
+			assert.Equal(t, retry.Attempts, delivery.Attempts)
+			assert.Nil(t, delivery.NextAttempt)
+			return nil
+		})
+
+	uc := NewWebhookUseCase(webhookRep, server.Client(), retry)
+	_, err := uc.DeliverDue(context.Background())
+	assert.Equal(t, (*errors.Error)(nil), err)
+	<-received
+}
+
+func TestWebhookUseCase_DeliverDue_Unreachable(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	delivery := dueDelivery(url, 0)
+	webhookRep.EXPECT().ClaimDueDeliveries(dueBatchSize, gomock.Any()).
+		Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRep.EXPECT().UpdateDelivery(delivery).
+		DoAndReturn(func(delivery *models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryStatusPending, delivery.Status)
+			assert.Equal(t, 0, delivery.ResponseCode)
+			assert.NotEmpty(t, delivery.LastError)
+			assert.WithinDuration(t, time.Now().Add(retry.Backoff), *delivery.NextAttempt, time.Second)
+			return nil
+		})
+
+	uc := NewWebhookUseCase(webhookRep, http.DefaultClient, retry)
+	_, err := uc.DeliverDue(context.Background())
+	assert.Equal(t, (*errors.Error)(nil), err)
+}
+
+func TestWebhookUseCase_CreateWebhook_GeneratesSecret(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	hook := &models.Webhook{URL: "https://203.0.113.10/hooks", Tenant: models.DefaultTenantID,
+		EventTypes: []string{models.EventBookingCreated}}
+	webhookRep.EXPECT().Insert(hook).Return(nil)
+
+	uc := NewWebhookUseCase(webhookRep, http.DefaultClient, retry)
+	err := uc.CreateWebhook(hook)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.True(t, strings.HasPrefix(hook.Secret, secretPrefix))
+	assert.Len(t, hook.Secret, len(secretPrefix)+43)
+}
+
+func TestWebhookUseCase_CreateWebhook_NotPublic(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := NewWebhookUseCase(mocks.NewMockWebhookRepository(ctrl), http.DefaultClient, retry)
+	for _, url := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://127.0.0.1:9000/rooms",
+		"https://10.0.0.5/hooks",
+		"ftp://203.0.113.10/hooks",
+	} {
+		err := uc.CreateWebhook(&models.Webhook{URL: url, Tenant: models.DefaultTenantID,
+			EventTypes: []string{models.EventBookingCreated}})
+		if assert.NotNil(t, err, url) {
+			assert.Equal(t, consts.CodeURLForbidden, err.Code)
+		}
+	}
+}
+
+func TestWebhookUseCase_DeliverDue_Shutdown(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// The interrupted attempt is not recorded and the rest of the batch is
+	// left for the next sender once the lease expires
+	webhookRep.EXPECT().ClaimDueDeliveries(dueBatchSize, dueBatchSize*time.Second).
+		Return([]*models.WebhookDelivery{dueDelivery(server.URL, 0), dueDelivery(server.URL, 0)}, nil)
+
+	uc := NewWebhookUseCase(webhookRep, &http.Client{Timeout: time.Second}, retry)
+	delivered, err := uc.DeliverDue(ctx)
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, 0, delivered)
+}
+
+func TestWebhookUseCase_Enqueue(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	event := &models.Event{ID: 15, Type: models.EventBookingCancelled, Tenant: models.DefaultTenantID,
+		Payload: json.RawMessage(`{"booking_id":5}`),
+		Created: time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC)}
+	webhookRep.EXPECT().
+		InsertDeliveries(event, []byte(`{"event_id":15,"type":"BookingCancelled","payload":{"booking_id":5},"created":"2022-01-03T10:00:00Z"}`)).
+		Return(int64(2), nil)
+
+	uc := NewWebhookUseCase(webhookRep, http.DefaultClient, retry)
+	assert.Equal(t, (*errors.Error)(nil), uc.Enqueue(event))
+}
+
+func TestWebhookUseCase_GetDeliveries_OtherTenant(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhookRep := mocks.NewMockWebhookRepository(ctrl)
+
+	webhookRep.EXPECT().SelectByID(uint64(2), uint64(1)).Return(nil, sql.ErrNoRows)
+
+	uc := NewWebhookUseCase(webhookRep, http.DefaultClient, retry)
+	_, err := uc.GetDeliveries(2, 1)
+	assert.Equal(t, errors.Get(consts.CodeWebhookDoesNotExist), err)
+}
@@ -1,25 +1,30 @@
-CREATE TABLE IF NOT EXISTS rooms
-(
-    id          SERIAL PRIMARY KEY,
-    description text,
-    price       int         NOT NULL,
-    created     timestamptz NOT NULL DEFAULT now()
-);
-CREATE INDEX cover_index ON rooms (id, description, price, created);
-CREATE INDEX price_order_by_asc_rooms ON rooms (price ASC);
-CREATE INDEX price_order_by_desc_rooms ON rooms (price DESC);
-CREATE INDEX created_order_by_asc_rooms ON rooms (created ASC);
-CREATE INDEX created_order_by_desc_rooms ON rooms (created DESC);
-
-CREATE TABLE IF NOT EXISTS bookings
-(
-    id         serial PRIMARY KEY,
-    date_start date NOT NULL,
-    date_end   date NOT NULL,
-    room       int  NOT NULL,
-
-    FOREIGN KEY (room) REFERENCES rooms (id) ON DELETE CASCADE
-);
-CREATE INDEX cover_bookings ON bookings (id, date_start, date_end, room);
-CREATE INDEX room_bookings ON bookings (room);
-CREATE INDEX date_start_order_by_bookings ON bookings (date_start ASC);
+-- The schema is built by the migrations in their order, a change of the schema
+-- is a new migration file, the applied ones are never edited
+\ir migrations/0001_rooms_and_bookings.sql
+\ir migrations/0002_reservations.sql
+\ir migrations/0003_booking_holds.sql
+\ir migrations/0004_cancellation_policies.sql
+\ir migrations/0005_payments.sql
+\ir migrations/0006_refunds.sql
+\ir migrations/0007_invoices.sql
+\ir migrations/0008_tax_rules.sql
+\ir migrations/0009_promo_codes.sql
+\ir migrations/0010_room_blocks.sql
+\ir migrations/0011_external_room_blocks.sql
+\ir migrations/0012_api_keys.sql
+\ir migrations/0013_roles.sql
+\ir migrations/0014_tenants.sql
+\ir migrations/0015_audit_log.sql
+\ir migrations/0016_soft_delete.sql
+\ir migrations/0017_outbox.sql
+\ir migrations/0018_webhooks.sql
+\ir migrations/0019_guest_contacts.sql
+\ir migrations/0020_jobs.sql
+\ir migrations/0021_idempotency_keys.sql
+\ir migrations/0022_versions.sql
+\ir migrations/0023_reservation_versions.sql
+\ir migrations/0024_tenant_properties_and_promo_codes.sql
+\ir migrations/0025_property_time_zones.sql
+\ir migrations/0026_booking_updates.sql
+\ir migrations/0027_outbox_cursors.sql
+\ir migrations/0028_outbox_deliveries.sql
@@ -0,0 +1,37 @@
+-- The schema before the migrations, it may already be there on the databases
+-- created by the former init.sql
+CREATE TABLE IF NOT EXISTS rooms
+(
+    id          SERIAL PRIMARY KEY,
+    description text,
+    price       int         NOT NULL,
+    created     timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS cover_index ON rooms (id, description, price, created);
+CREATE INDEX IF NOT EXISTS price_order_by_asc_rooms ON rooms (price ASC);
+CREATE INDEX IF NOT EXISTS price_order_by_desc_rooms ON rooms (price DESC);
+CREATE INDEX IF NOT EXISTS created_order_by_asc_rooms ON rooms (created ASC);
+CREATE INDEX IF NOT EXISTS created_order_by_desc_rooms ON rooms (created DESC);
+
+CREATE TABLE IF NOT EXISTS bookings
+(
+    id         serial PRIMARY KEY,
+    date_start date NOT NULL,
+    date_end   date NOT NULL,
+    room       int  NOT NULL,
+
+    FOREIGN KEY (room) REFERENCES rooms (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS cover_bookings ON bookings (id, date_start, date_end, room);
+CREATE INDEX IF NOT EXISTS room_bookings ON bookings (room);
+CREATE INDEX IF NOT EXISTS date_start_order_by_bookings ON bookings (date_start ASC);
+
+-- Every migration records its version, /readyz reports the instance ready
+-- only once the version it needs is applied
+CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version int         PRIMARY KEY,
+    applied timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO schema_migrations(version) VALUES (1);
@@ -0,0 +1,10 @@
+CREATE TABLE reservations
+(
+    id      serial PRIMARY KEY,
+    created timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE bookings ADD COLUMN reservation int REFERENCES reservations (id) ON DELETE CASCADE;
+CREATE INDEX reservation_bookings ON bookings (reservation);
+
+INSERT INTO schema_migrations(version) VALUES (2);
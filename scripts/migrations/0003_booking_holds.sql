@@ -0,0 +1,6 @@
+ALTER TABLE bookings
+    ADD COLUMN status       text NOT NULL DEFAULT 'confirmed',
+    ADD COLUMN hold_expires timestamptz;
+CREATE INDEX held_bookings ON bookings (hold_expires) WHERE status = 'held';
+
+INSERT INTO schema_migrations(version) VALUES (3);
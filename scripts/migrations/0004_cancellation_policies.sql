@@ -0,0 +1,15 @@
+ALTER TABLE bookings
+    ADD COLUMN cancellation_fee int NOT NULL DEFAULT 0,
+    ADD COLUMN cancelled        timestamptz;
+
+CREATE TABLE cancellation_policies
+(
+    room            int PRIMARY KEY,
+    free_days       int  NOT NULL DEFAULT 0,
+    penalty_type    text NOT NULL,
+    penalty_percent int  NOT NULL DEFAULT 0,
+
+    FOREIGN KEY (room) REFERENCES rooms (id) ON DELETE CASCADE
+);
+
+INSERT INTO schema_migrations(version) VALUES (4);
@@ -0,0 +1,17 @@
+ALTER TABLE bookings ADD COLUMN amount int NOT NULL DEFAULT 0;
+
+CREATE TABLE payments
+(
+    id        serial PRIMARY KEY,
+    booking   int         NOT NULL,
+    type      text        NOT NULL,
+    amount    int         NOT NULL,
+    reference text        NOT NULL DEFAULT '',
+    status    text        NOT NULL,
+    created   timestamptz NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (booking) REFERENCES bookings (id) ON DELETE CASCADE
+);
+CREATE INDEX booking_payments ON payments (booking, id);
+
+INSERT INTO schema_migrations(version) VALUES (5);
@@ -0,0 +1,5 @@
+ALTER TABLE bookings
+    ADD COLUMN refund_status text NOT NULL DEFAULT '',
+    ADD COLUMN refund_amount int  NOT NULL DEFAULT 0;
+
+INSERT INTO schema_migrations(version) VALUES (6);
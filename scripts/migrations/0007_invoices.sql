@@ -0,0 +1,27 @@
+CREATE TABLE properties
+(
+    id             serial PRIMARY KEY,
+    name           text NOT NULL,
+    invoice_number int  NOT NULL DEFAULT 0
+);
+INSERT INTO properties(name) VALUES ('default');
+
+ALTER TABLE rooms ADD COLUMN property int NOT NULL DEFAULT 1 REFERENCES properties (id);
+
+-- Invoices are kept when the booking is deleted, the document is never updated
+CREATE TABLE invoices
+(
+    id       serial PRIMARY KEY,
+    booking  int         NOT NULL UNIQUE,
+    property int         NOT NULL,
+    number   int         NOT NULL,
+    issued   timestamptz NOT NULL,
+    document jsonb       NOT NULL,
+
+    FOREIGN KEY (property) REFERENCES properties (id),
+    UNIQUE (property, number)
+);
+CREATE RULE invoices_no_update AS ON UPDATE TO invoices DO INSTEAD NOTHING;
+CREATE RULE invoices_no_delete AS ON DELETE TO invoices DO INSTEAD NOTHING;
+
+INSERT INTO schema_migrations(version) VALUES (7);
@@ -0,0 +1,16 @@
+CREATE TABLE tax_rules
+(
+    property     int PRIMARY KEY,
+    vat_percent  int  NOT NULL DEFAULT 0,
+    tax_mode     text NOT NULL DEFAULT 'exclusive',
+    tourist_tax  int  NOT NULL DEFAULT 0,
+    cleaning_fee int  NOT NULL DEFAULT 0,
+
+    FOREIGN KEY (property) REFERENCES properties (id) ON DELETE CASCADE
+);
+
+ALTER TABLE bookings
+    ADD COLUMN guests int NOT NULL DEFAULT 1,
+    ADD COLUMN quote  jsonb;
+
+INSERT INTO schema_migrations(version) VALUES (8);
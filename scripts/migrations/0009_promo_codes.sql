@@ -0,0 +1,21 @@
+CREATE TABLE promo_codes
+(
+    code           text PRIMARY KEY,
+    discount_type  text        NOT NULL,
+    discount_value int         NOT NULL,
+    valid_from     date        NOT NULL,
+    valid_to       date        NOT NULL,
+    usage_limit    int         NOT NULL DEFAULT 0,
+    room           int,
+    property       int,
+    min_nights     int         NOT NULL DEFAULT 0,
+    created        timestamptz NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (room) REFERENCES rooms (id) ON DELETE CASCADE,
+    FOREIGN KEY (property) REFERENCES properties (id) ON DELETE CASCADE
+);
+
+ALTER TABLE bookings ADD COLUMN promo_code text REFERENCES promo_codes (code);
+CREATE INDEX promo_code_bookings ON bookings (promo_code) WHERE promo_code IS NOT NULL;
+
+INSERT INTO schema_migrations(version) VALUES (9);
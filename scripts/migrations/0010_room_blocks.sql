@@ -0,0 +1,15 @@
+-- Blocks close the room on the dates without a booking, e.g. for maintenance
+CREATE TABLE room_blocks
+(
+    id         serial PRIMARY KEY,
+    room       int         NOT NULL,
+    date_start date        NOT NULL,
+    date_end   date        NOT NULL,
+    reason     text        NOT NULL DEFAULT '',
+    created    timestamptz NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (room) REFERENCES rooms (id) ON DELETE CASCADE
+);
+CREATE INDEX room_blocks_dates ON room_blocks (room, date_start);
+
+INSERT INTO schema_migrations(version) VALUES (10);
@@ -0,0 +1,7 @@
+-- Bookings made on other channels are imported as blocks with their feed and event UID
+ALTER TABLE room_blocks
+    ADD COLUMN source     text NOT NULL DEFAULT '',
+    ADD COLUMN source_uid text NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX external_room_blocks ON room_blocks (room, source, source_uid) WHERE source <> '';
+
+INSERT INTO schema_migrations(version) VALUES (11);
@@ -0,0 +1,13 @@
+-- Only the SHA-256 hash of an API key is stored
+CREATE TABLE api_keys
+(
+    id        serial PRIMARY KEY,
+    name      text        NOT NULL,
+    prefix    text        NOT NULL,
+    hash      text        NOT NULL UNIQUE,
+    created   timestamptz NOT NULL DEFAULT now(),
+    last_used timestamptz,
+    revoked   timestamptz
+);
+
+INSERT INTO schema_migrations(version) VALUES (12);
@@ -0,0 +1,8 @@
+ALTER TABLE bookings ADD COLUMN guest text NOT NULL DEFAULT '';
+CREATE INDEX guest_bookings ON bookings (guest) WHERE guest <> '';
+
+-- The keys issued before the roles keep the access they had
+ALTER TABLE api_keys ADD COLUMN role text NOT NULL DEFAULT 'admin';
+ALTER TABLE api_keys ALTER COLUMN role DROP DEFAULT;
+
+INSERT INTO schema_migrations(version) VALUES (13);
@@ -0,0 +1,19 @@
+-- Every hotel chain sharing the deployment is a tenant, rooms and bookings
+-- of one tenant are never visible to another. The existing ones go to the
+-- default tenant
+CREATE TABLE tenants
+(
+    id   serial PRIMARY KEY,
+    name text NOT NULL
+);
+INSERT INTO tenants(name) VALUES ('default');
+
+ALTER TABLE rooms ADD COLUMN tenant int NOT NULL DEFAULT 1 REFERENCES tenants (id);
+CREATE INDEX tenant_rooms ON rooms (tenant);
+
+ALTER TABLE bookings ADD COLUMN tenant int NOT NULL DEFAULT 1 REFERENCES tenants (id);
+CREATE INDEX tenant_bookings ON bookings (tenant, id);
+
+ALTER TABLE api_keys ADD COLUMN tenant int NOT NULL DEFAULT 1 REFERENCES tenants (id);
+
+INSERT INTO schema_migrations(version) VALUES (14);
@@ -0,0 +1,22 @@
+-- Audit entries outlive the rooms and bookings they describe and are never changed
+CREATE TABLE audit_log
+(
+    id         serial PRIMARY KEY,
+    tenant     int         NOT NULL,
+    actor      text        NOT NULL,
+    action     text        NOT NULL,
+    entity     text        NOT NULL,
+    entity_id  int         NOT NULL,
+    before     jsonb,
+    after      jsonb,
+    request_id text        NOT NULL DEFAULT '',
+    created    timestamptz NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (tenant) REFERENCES tenants (id)
+);
+CREATE INDEX entity_audit_log ON audit_log (tenant, entity, entity_id, created);
+CREATE INDEX created_audit_log ON audit_log (tenant, created);
+CREATE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING;
+CREATE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING;
+
+INSERT INTO schema_migrations(version) VALUES (15);
@@ -0,0 +1,6 @@
+ALTER TABLE rooms ADD COLUMN deleted_at timestamptz;
+CREATE INDEX deleted_rooms ON rooms (deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE bookings ADD COLUMN deleted_at timestamptz;
+
+INSERT INTO schema_migrations(version) VALUES (16);
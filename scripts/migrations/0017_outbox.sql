@@ -0,0 +1,15 @@
+-- Events are written in the transaction of the change and sent by the dispatcher
+CREATE TABLE outbox
+(
+    id      serial PRIMARY KEY,
+    tenant  int         NOT NULL,
+    type    text        NOT NULL,
+    payload jsonb       NOT NULL,
+    created timestamptz NOT NULL DEFAULT now(),
+    sent    timestamptz,
+
+    FOREIGN KEY (tenant) REFERENCES tenants (id)
+);
+CREATE INDEX unsent_outbox ON outbox (id) WHERE sent IS NULL;
+
+INSERT INTO schema_migrations(version) VALUES (17);
@@ -0,0 +1,35 @@
+CREATE TABLE webhooks
+(
+    id          serial PRIMARY KEY,
+    url         text        NOT NULL,
+    event_types text[]      NOT NULL,
+    secret      text        NOT NULL,
+    created     timestamptz NOT NULL DEFAULT now(),
+    tenant      int         NOT NULL,
+
+    FOREIGN KEY (tenant) REFERENCES tenants (id)
+);
+CREATE INDEX tenant_webhooks ON webhooks (tenant);
+
+-- Deliveries are the history of a webhook, dead ones are kept as the dead letters
+CREATE TABLE webhook_deliveries
+(
+    id            serial PRIMARY KEY,
+    webhook       int         NOT NULL,
+    event         int         NOT NULL,
+    event_type    text        NOT NULL,
+    body          jsonb       NOT NULL,
+    status        text        NOT NULL,
+    attempts      int         NOT NULL DEFAULT 0,
+    next_attempt  timestamptz,
+    response_code int         NOT NULL DEFAULT 0,
+    last_error    text        NOT NULL DEFAULT '',
+    created       timestamptz NOT NULL DEFAULT now(),
+    delivered     timestamptz,
+
+    FOREIGN KEY (webhook) REFERENCES webhooks (id) ON DELETE CASCADE,
+    UNIQUE (webhook, event)
+);
+CREATE INDEX due_webhook_deliveries ON webhook_deliveries (next_attempt) WHERE status = 'pending';
+
+INSERT INTO schema_migrations(version) VALUES (18);
@@ -0,0 +1,5 @@
+ALTER TABLE bookings
+    ADD COLUMN email    text NOT NULL DEFAULT '',
+    ADD COLUMN language text NOT NULL DEFAULT '';
+
+INSERT INTO schema_migrations(version) VALUES (19);
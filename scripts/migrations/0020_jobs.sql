@@ -0,0 +1,21 @@
+-- Jobs survive restarts, the unique key keeps a planned job from being planned again
+CREATE TABLE jobs
+(
+    id         serial PRIMARY KEY,
+    type       text        NOT NULL,
+    tenant     int         NOT NULL,
+    booking    int         NOT NULL DEFAULT 0,
+    date       date        NOT NULL,
+    run_at     timestamptz NOT NULL,
+    status     text        NOT NULL DEFAULT 'pending',
+    attempts   int         NOT NULL DEFAULT 0,
+    last_error text        NOT NULL DEFAULT '',
+    created    timestamptz NOT NULL DEFAULT now(),
+    finished   timestamptz,
+
+    FOREIGN KEY (tenant) REFERENCES tenants (id),
+    UNIQUE (type, tenant, booking, date)
+);
+CREATE INDEX due_jobs ON jobs (run_at) WHERE status = 'pending';
+
+INSERT INTO schema_migrations(version) VALUES (20);
@@ -0,0 +1,17 @@
+-- The response is stored once the request with the key is done, status_code is 0 until then
+CREATE TABLE idempotency_keys
+(
+    tenant       int         NOT NULL,
+    subject      text        NOT NULL,
+    key          text        NOT NULL,
+    request_hash text        NOT NULL,
+    status_code  int         NOT NULL DEFAULT 0,
+    response     bytea,
+    created      timestamptz NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant, subject, key),
+    FOREIGN KEY (tenant) REFERENCES tenants (id)
+);
+CREATE INDEX created_idempotency_keys ON idempotency_keys (created);
+
+INSERT INTO schema_migrations(version) VALUES (21);
@@ -0,0 +1,4 @@
+ALTER TABLE rooms ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE bookings ADD COLUMN version int NOT NULL DEFAULT 1;
+
+INSERT INTO schema_migrations(version) VALUES (22);
@@ -0,0 +1,3 @@
+ALTER TABLE reservations ADD COLUMN version int NOT NULL DEFAULT 1;
+
+INSERT INTO schema_migrations(version) VALUES (23);
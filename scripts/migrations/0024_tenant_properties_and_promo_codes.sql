@@ -0,0 +1,12 @@
+ALTER TABLE properties ADD COLUMN tenant int NOT NULL DEFAULT 1 REFERENCES tenants (id);
+CREATE INDEX tenant_properties ON properties (tenant);
+
+-- Every tenant has promo codes of its own, several tenants may use the same code
+ALTER TABLE promo_codes ADD COLUMN tenant int NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE bookings DROP CONSTRAINT bookings_promo_code_fkey;
+ALTER TABLE promo_codes
+    DROP CONSTRAINT promo_codes_pkey,
+    ADD PRIMARY KEY (tenant, code);
+ALTER TABLE bookings ADD FOREIGN KEY (tenant, promo_code) REFERENCES promo_codes (tenant, code);
+
+INSERT INTO schema_migrations(version) VALUES (24);
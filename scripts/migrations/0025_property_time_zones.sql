@@ -0,0 +1,4 @@
+-- Dates at the property, such as "today" of the cancellation policy, are local
+ALTER TABLE properties ADD COLUMN time_zone text NOT NULL DEFAULT 'UTC';
+
+INSERT INTO schema_migrations(version) VALUES (25);
@@ -0,0 +1,14 @@
+ALTER TABLE bookings ADD COLUMN updated timestamptz NOT NULL DEFAULT now();
+
+-- Every change of a booking bumps its version, the calendar feed publishes the time of the last one
+CREATE FUNCTION touch_booking() RETURNS trigger AS $$
+BEGIN
+    IF NEW.version <> OLD.version THEN
+        NEW.updated = now();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER touch_bookings BEFORE UPDATE ON bookings FOR EACH ROW EXECUTE PROCEDURE touch_booking();
+
+INSERT INTO schema_migrations(version) VALUES (26);
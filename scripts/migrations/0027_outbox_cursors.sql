@@ -0,0 +1,10 @@
+-- Every sink goes through the outbox on its own: event is the last one it got,
+-- so a failing sink doesn't hold the others back. An event is sent once all
+-- the sinks got it
+CREATE TABLE outbox_cursors
+(
+    sink  text PRIMARY KEY,
+    event int  NOT NULL
+);
+
+INSERT INTO schema_migrations(version) VALUES (27);
@@ -0,0 +1,24 @@
+-- Every sink gets every event on its own, so a failing sink doesn't hold the
+-- others back. A delivery is claimed by a dispatcher until lease_until, an
+-- event is sent once all the sinks got it
+CREATE TABLE outbox_deliveries
+(
+    event       int  NOT NULL,
+    sink        text NOT NULL,
+    lease_until timestamptz,
+    delivered   timestamptz,
+
+    PRIMARY KEY (event, sink),
+    FOREIGN KEY (event) REFERENCES outbox (id) ON DELETE CASCADE
+);
+CREATE INDEX undelivered_outbox ON outbox_deliveries (sink, event) WHERE delivered IS NULL;
+
+-- The unsent events a sink got up to its cursor are not sent to it again
+INSERT INTO outbox_deliveries(event, sink, delivered)
+SELECT o.id, c.sink, now()
+FROM outbox o
+         JOIN outbox_cursors c ON o.id <= c.event
+WHERE o.sent IS NULL;
+DROP TABLE outbox_cursors;
+
+INSERT INTO schema_migrations(version) VALUES (28);
@@ -0,0 +1,48 @@
+package etag
+
+import (
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/labstack/echo/v4"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// Format makes the strong ETag of the version
+func Format(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
+}
+
+func Set(context echo.Context, version uint64) {
+	context.Response().Header().Set(HeaderETag, Format(version))
+}
+
+// Match returns the version the caller has seen, taken from If-Match. The
+// header is required, a weak or malformed ETag never matches the version
+func Match(context echo.Context) (uint64, *errors.Error) {
+	header := strings.TrimSpace(context.Request().Header.Get(HeaderIfMatch))
+	if header == "" {
+		return 0, errors.Get(CodePreconditionRequired)
+	}
+	if header == "*" {
+		return models.AnyVersion, nil
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err == nil {
+		var version uint64
+		version, err = strconv.ParseUint(unquoted, 10, 64)
+		if err == nil && version != models.AnyVersion {
+			return version, nil
+		}
+	}
+	// The shared error is returned as is, the header of the caller must not
+	// end up in the message every later caller gets
+	return 0, errors.Get(CodeVersionMismatch)
+}
@@ -0,0 +1,40 @@
+package etag
+
+import (
+	"github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func match(ifMatch string) (uint64, *errors.Error) {
+	req := httptest.NewRequest(http.MethodDelete, "/rooms/1", nil)
+	if ifMatch != "" {
+		req.Header.Set(HeaderIfMatch, ifMatch)
+	}
+	return Match(echo.New().NewContext(req, httptest.NewRecorder()))
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	version, err := match(Format(3))
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, uint64(3), version)
+
+	version, err = match("*")
+	assert.Equal(t, (*errors.Error)(nil), err)
+	assert.Equal(t, models.AnyVersion, version)
+
+	_, err = match("")
+	assert.Equal(t, errors.Get(consts.CodePreconditionRequired), err)
+
+	for _, malformed := range []string{`W/"3"`, `3`, `"three"`, `"0"`} {
+		_, err = match(malformed)
+		assert.Equal(t, errors.Get(consts.CodeVersionMismatch).Code, err.Code)
+	}
+}
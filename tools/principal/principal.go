@@ -0,0 +1,73 @@
+package principal
+
+import (
+	. "github.com/booking_backend/internal/consts"
+	"github.com/booking_backend/internal/helpers/errors"
+	"github.com/booking_backend/internal/helpers/rbac"
+	"github.com/booking_backend/internal/models"
+	"github.com/booking_backend/tools/response"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const contextKey = "principal"
+
+func Set(context echo.Context, principal *models.Principal) {
+	context.Set(contextKey, principal)
+}
+
+// Get returns the caller authenticated by the middleware
+func Get(context echo.Context) *models.Principal {
+	principal, _ := context.Get(contextKey).(*models.Principal)
+	return principal
+}
+
+// Tenant returns the tenant the caller's data is scoped to. There is no
+// tenant with a zero id, so nothing is found for an anonymous caller
+func Tenant(context echo.Context) uint64 {
+	principal := Get(context)
+	if principal == nil {
+		return 0
+	}
+	return principal.Tenant
+}
+
+// Actor returns who the changes made by the request are recorded for
+func Actor(context echo.Context) *models.Actor {
+	actor := &models.Actor{RequestID: context.Response().Header().Get(echo.HeaderXRequestID)}
+	if principal := Get(context); principal != nil {
+		actor.Subject = principal.Subject
+	}
+	return actor
+}
+
+func Can(context echo.Context, permission rbac.Permission) bool {
+	principal := Get(context)
+	return principal != nil && rbac.Can(principal.Role, permission)
+}
+
+// Owns tells whether the caller is the guest the booking was made for
+func Owns(context echo.Context, booking *models.Booking) bool {
+	principal := Get(context)
+	return principal != nil && booking.Guest != "" && booking.Guest == principal.Subject
+}
+
+func Forbidden(context echo.Context) error {
+	customErr := errors.Get(CodeForbidden)
+	logrus.Info(customErr)
+	return context.JSON(customErr.HTTPCode, response.Response{Error: customErr})
+}
+
+// Require lets the request through if the caller has any of the permissions
+func Require(permissions ...rbac.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			for _, permission := range permissions {
+				if Can(context, permission) {
+					return next(context)
+				}
+			}
+			return Forbidden(context)
+		}
+	}
+}